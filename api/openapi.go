@@ -0,0 +1,31 @@
+package api
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+//go:embed openapi.json
+var spec []byte
+
+// Spec возвращает описание HTTP API в формате OpenAPI 3, как оно отдается по /api/openapi.json.
+func Spec() []byte {
+	return spec
+}
+
+// Load разбирает спецификацию и проверяет, что она корректна.
+func Load() (*openapi3.T, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load openapi spec: %w", err)
+	}
+
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("invalid openapi spec: %w", err)
+	}
+
+	return doc, nil
+}
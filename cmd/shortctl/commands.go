@@ -0,0 +1,257 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/patrick-devel/shorturl/pkg/client"
+)
+
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+)
+
+type link struct {
+	CorrelationID string `json:"correlation_id,omitempty"`
+	ShortURL      string `json:"short_url"`
+	OriginalURL   string `json:"original_url"`
+}
+
+type command struct {
+	client *client.Client
+	stdin  io.Reader
+	out    printer
+}
+
+func (c command) exec(ctx context.Context, name string, args []string) error {
+	switch name {
+	case "shorten":
+		return c.shorten(ctx, args)
+	case "batch":
+		return c.batch(ctx, args)
+	case "ls":
+		return c.list(ctx, args)
+	case "rm":
+		return c.remove(ctx, args)
+	case "resolve":
+		return c.resolve(ctx, args)
+	case "register":
+		return c.session(ctx, args, c.client.Register)
+	case "login":
+		return c.session(ctx, args, c.client.Login)
+	default:
+		return fmt.Errorf("%w: unknown command %q", errUsage, name)
+	}
+}
+
+func (c command) shorten(ctx context.Context, args []string) error {
+	urls, err := c.argsOrStdin(args)
+	if err != nil {
+		return err
+	}
+
+	links := make([]link, 0, len(urls))
+	for _, u := range urls {
+		shortURL, err := c.client.Shorten(ctx, u)
+		// повтор не ошибка: сервис вернул уже существующую ссылку
+		if err != nil && !errors.Is(err, client.ErrDuplicate) {
+			return fmt.Errorf("%s: %w", u, err)
+		}
+		links = append(links, link{ShortURL: shortURL, OriginalURL: u})
+	}
+
+	return c.out.links(links, false)
+}
+
+func (c command) batch(ctx context.Context, args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("%w: batch reads urls from stdin only", errUsage)
+	}
+
+	urls, err := readLines(c.stdin)
+	if err != nil {
+		return err
+	}
+	if len(urls) == 0 {
+		return errors.New("no urls on stdin")
+	}
+
+	// correlation_id - номер строки, по нему ответ сопоставляется с исходным URL
+	items := make([]client.BatchItem, 0, len(urls))
+	originals := make(map[string]string, len(urls))
+	for i, u := range urls {
+		id := strconv.Itoa(i + 1)
+		items = append(items, client.BatchItem{CorrelationID: id, OriginalURL: u})
+		originals[id] = u
+	}
+
+	results, err := c.client.ShortenBatch(ctx, items)
+	if err != nil {
+		return err
+	}
+
+	links := make([]link, 0, len(results))
+	for _, r := range results {
+		links = append(links, link{CorrelationID: r.CorrelationID, ShortURL: r.ShortURL, OriginalURL: originals[r.CorrelationID]})
+	}
+
+	return c.out.links(links, true)
+}
+
+func (c command) list(ctx context.Context, args []string) error {
+	if len(args) != 0 {
+		return fmt.Errorf("%w: ls takes no arguments", errUsage)
+	}
+
+	userLinks, err := c.client.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	links := make([]link, 0, len(userLinks))
+	for _, l := range userLinks {
+		links = append(links, link{ShortURL: l.ShortURL, OriginalURL: l.OriginalURL})
+	}
+
+	return c.out.links(links, false)
+}
+
+// remove ничего не печатает: сервис удаляет асинхронно, подтверждать нечего.
+// Полные короткие ссылки передаются клиенту как есть: по ним он узнает домен ссылки.
+func (c command) remove(ctx context.Context, args []string) error {
+	codes, err := c.argsOrStdin(args)
+	if err != nil {
+		return err
+	}
+
+	return c.client.Delete(ctx, codes...)
+}
+
+func (c command) resolve(ctx context.Context, args []string) error {
+	codes, err := c.argsOrStdin(args)
+	if err != nil {
+		return err
+	}
+
+	links := make([]link, 0, len(codes))
+	for _, code := range codes {
+		destination, err := c.client.Resolve(ctx, code)
+		if err != nil {
+			return fmt.Errorf("%s: %w", code, err)
+		}
+		links = append(links, link{ShortURL: code, OriginalURL: destination})
+	}
+
+	return c.out.links(links, false)
+}
+
+// session читает пароль из stdin, а не из аргументов, чтобы он не попал в историю команд и список процессов.
+func (c command) session(ctx context.Context, args []string,
+	auth func(ctx context.Context, email, password string) (client.User, error)) error {
+	if len(args) != 1 {
+		return fmt.Errorf("%w: email expected", errUsage)
+	}
+
+	password, err := bufio.NewReader(c.stdin).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to read stdin: %w", err)
+	}
+	password = strings.TrimRight(password, "\r\n")
+	if password == "" {
+		return fmt.Errorf("%w: password expected on stdin", errUsage)
+	}
+
+	user, err := auth(ctx, args[0], password)
+	if err != nil {
+		return err
+	}
+
+	return c.out.user(user)
+}
+
+func (c command) argsOrStdin(args []string) ([]string, error) {
+	if len(args) != 0 {
+		return args, nil
+	}
+
+	lines, err := readLines(c.stdin)
+	if err != nil {
+		return nil, err
+	}
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("%w: nothing to do, pass arguments or lines on stdin", errUsage)
+	}
+
+	return lines, nil
+}
+
+// readLines берет первое поле каждой строки, поэтому на вход годится и таблица без заголовка:
+// shortctl ls | tail -n +2 | shortctl rm. Пустые строки пропускаются.
+func readLines(r io.Reader) ([]string, error) {
+	var lines []string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		if fields := strings.Fields(scanner.Text()); len(fields) != 0 {
+			lines = append(lines, fields[0])
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read stdin: %w", err)
+	}
+
+	return lines, nil
+}
+
+type printer struct {
+	w      io.Writer
+	format string
+}
+
+func (p printer) links(links []link, withID bool) error {
+	if p.format == formatJSON {
+		enc := json.NewEncoder(p.w)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(links)
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	if withID {
+		fmt.Fprintln(tw, "ID\tSHORT URL\tORIGINAL URL")
+	} else {
+		fmt.Fprintln(tw, "SHORT URL\tORIGINAL URL")
+	}
+	for _, l := range links {
+		if withID {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", l.CorrelationID, l.ShortURL, l.OriginalURL)
+		} else {
+			fmt.Fprintf(tw, "%s\t%s\n", l.ShortURL, l.OriginalURL)
+		}
+	}
+
+	return tw.Flush()
+}
+
+func (p printer) user(u client.User) error {
+	if p.format == formatJSON {
+		enc := json.NewEncoder(p.w)
+		enc.SetIndent("", "  ")
+
+		return enc.Encode(u)
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tEMAIL")
+	fmt.Fprintf(tw, "%s\t%s\n", u.ID, u.Email)
+
+	return tw.Flush()
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/patrick-devel/shorturl/pkg/client"
+)
+
+const usage = `shortctl - клиент сокращателя ссылок.
+
+Использование:
+  shortctl [флаги] shorten [URL...]   сократить URL; без аргументов читает их из stdin
+  shortctl [флаги] batch              сократить URL из stdin одним запросом
+  shortctl [флаги] ls                 ссылки текущего пользователя
+  shortctl [флаги] rm [CODE...]       удалить ссылки по кодам или коротким ссылкам; без аргументов читает их из stdin
+  shortctl [флаги] resolve [CODE...]  куда ведет ссылка; без аргументов читает коды из stdin
+  shortctl [флаги] register EMAIL     завести аккаунт, пароль - первая строка stdin; ссылки клиента остаются за ним
+  shortctl [флаги] login EMAIL        войти в аккаунт, пароль - первая строка stdin; ссылки клиента переходят к нему
+
+Флаги:
+`
+
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+var errUsage = errors.New("usage")
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	code := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+	stop()
+
+	os.Exit(code)
+}
+
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("shortctl", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	server := flags.String("s", "", "Адрес сервиса. По умолчанию из конфигурации или "+defaultServer)
+	format := flags.String("o", formatTable, "Формат вывода: table или json")
+	configPath := flags.String("c", defaultConfigPath(), "Файл конфигурации с адресом сервиса и токеном, также SHORTCTL_CONFIG")
+	flags.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		flags.PrintDefaults()
+	}
+
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+	if flags.NArg() == 0 || (*format != formatTable && *format != formatJSON) {
+		flags.Usage()
+
+		return exitUsage
+	}
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintln(stderr, "shortctl:", err)
+
+		return exitError
+	}
+	if *server != "" && *server != cfg.Server {
+		// токен выдан другим сервисом, там он недействителен
+		cfg = config{Server: *server}
+	}
+	if cfg.Server == "" {
+		cfg.Server = defaultServer
+	}
+
+	c, err := client.New(cfg.Server, client.WithToken(cfg.Token), client.WithRefreshToken(cfg.RefreshToken))
+	if err != nil {
+		fmt.Fprintln(stderr, "shortctl:", err)
+
+		return exitError
+	}
+
+	cmd := command{client: c, stdin: stdin, out: printer{w: stdout, format: *format}}
+	err = cmd.exec(ctx, flags.Arg(0), flags.Args()[1:])
+
+	// токен сохраняется и после ошибки: ссылки, созданные до нее, не должны потеряться
+	if c.Token() != cfg.Token || c.RefreshToken() != cfg.RefreshToken || *server != "" {
+		cfg.Token, cfg.RefreshToken = c.Token(), c.RefreshToken()
+		if saveErr := saveConfig(*configPath, cfg); saveErr != nil {
+			fmt.Fprintln(stderr, "shortctl:", saveErr)
+		}
+	}
+
+	switch {
+	case errors.Is(err, errUsage):
+		flags.Usage()
+
+		return exitUsage
+	case err != nil:
+		fmt.Fprintln(stderr, "shortctl:", err)
+
+		return exitError
+	default:
+		return exitOK
+	}
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/patrick-devel/shorturl/internal/models"
+	"github.com/patrick-devel/shorturl/internal/router"
+	"github.com/patrick-devel/shorturl/internal/service"
+	"github.com/patrick-devel/shorturl/internal/storage"
+	"github.com/patrick-devel/shorturl/internal/stream"
+	"github.com/patrick-devel/shorturl/internal/tokens"
+)
+
+func newServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	srv := httptest.NewUnstartedServer(nil)
+	baseURL := &url.URL{Scheme: "http", Host: srv.Listener.Addr().String()}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	shortService := service.New(baseURL, storage.NewMemoryStorage(map[models.LinkRef]models.Event{}), ctx)
+	issuer, err := tokens.New([]tokens.Key{tokens.NewKey("test-secret")})
+	require.NoError(t, err)
+	mux, err := router.New(shortService, stream.NewHub(0), issuer, logger)
+	require.NoError(t, err)
+
+	srv.Config.Handler = mux
+	srv.Start()
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+type result struct {
+	code   int
+	stdout string
+	stderr string
+}
+
+func runCLI(configPath, stdin string, args ...string) result {
+	var stdout, stderr bytes.Buffer
+	code := run(context.Background(), append([]string{"-c", configPath}, args...), strings.NewReader(stdin), &stdout, &stderr)
+
+	return result{code: code, stdout: stdout.String(), stderr: stderr.String()}
+}
+
+func TestShortctl(t *testing.T) {
+	srv := newServer(t)
+	configPath := filepath.Join(t.TempDir(), "shortctl", "config.json")
+
+	res := runCLI(configPath, "", "-s", srv.URL, "shorten", "https://practicum.yandex.ru/")
+	require.Equal(t, exitOK, res.code, res.stderr)
+	assert.Contains(t, res.stdout, "SHORT URL")
+	assert.Contains(t, res.stdout, "https://practicum.yandex.ru/")
+
+	// токен и адрес сохранены, дальше флаг -s не нужен
+	info, err := os.Stat(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	cfg, err := loadConfig(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, srv.URL, cfg.Server)
+	assert.NotEmpty(t, cfg.Token)
+	assert.NotEmpty(t, cfg.RefreshToken)
+
+	res = runCLI(configPath, "https://go.dev/\n\nhttps://pkg.go.dev/\n", "-o", "json", "batch")
+	require.Equal(t, exitOK, res.code, res.stderr)
+	var batch []link
+	require.NoError(t, json.Unmarshal([]byte(res.stdout), &batch))
+	require.Len(t, batch, 2)
+	assert.Equal(t, link{CorrelationID: "2", ShortURL: batch[1].ShortURL, OriginalURL: "https://pkg.go.dev/"}, batch[1])
+
+	res = runCLI(configPath, "", "-o", "json", "ls")
+	require.Equal(t, exitOK, res.code, res.stderr)
+	var links []link
+	require.NoError(t, json.Unmarshal([]byte(res.stdout), &links))
+	assert.Len(t, links, 3)
+
+	// вывод таблицы без заголовка подается на вход следующей команде
+	res = runCLI(configPath, "", "ls")
+	require.Equal(t, exitOK, res.code, res.stderr)
+	rows := strings.SplitN(res.stdout, "\n", 2)[1]
+	res = runCLI(configPath, rows, "resolve")
+	require.Equal(t, exitOK, res.code, res.stderr)
+	assert.Contains(t, res.stdout, "https://go.dev/")
+	assert.Contains(t, res.stdout, "https://pkg.go.dev/")
+
+	res = runCLI(configPath, rows, "rm")
+	assert.Equal(t, exitOK, res.code, res.stderr)
+	assert.Empty(t, res.stdout)
+}
+
+func TestShortctlAccount(t *testing.T) {
+	srv := newServer(t)
+	laptop := filepath.Join(t.TempDir(), "laptop.json")
+	desktop := filepath.Join(t.TempDir(), "desktop.json")
+
+	res := runCLI(laptop, "", "-s", srv.URL, "shorten", "https://practicum.yandex.ru/")
+	require.Equal(t, exitOK, res.code, res.stderr)
+	res = runCLI(laptop, "correct horse\n", "register", "user@example.com")
+	require.Equal(t, exitOK, res.code, res.stderr)
+	assert.Contains(t, res.stdout, "user@example.com")
+
+	res = runCLI(desktop, "", "-s", srv.URL, "shorten", "https://go.dev/")
+	require.Equal(t, exitOK, res.code, res.stderr)
+	res = runCLI(desktop, "wrong password", "login", "user@example.com")
+	assert.Equal(t, exitError, res.code)
+	assert.Contains(t, res.stderr, "401")
+
+	// пароль с пробелами читается строкой целиком
+	res = runCLI(desktop, "correct horse\r\n", "-o", "json", "login", "user@example.com")
+	require.Equal(t, exitOK, res.code, res.stderr)
+	assert.Contains(t, res.stdout, `"email": "user@example.com"`)
+
+	for _, configPath := range []string{laptop, desktop} {
+		res = runCLI(configPath, "", "-o", "json", "ls")
+		require.Equal(t, exitOK, res.code, res.stderr)
+		var links []link
+		require.NoError(t, json.Unmarshal([]byte(res.stdout), &links))
+		assert.Len(t, links, 2)
+	}
+
+	res = runCLI(desktop, "", "login", "user@example.com")
+	assert.Equal(t, exitUsage, res.code)
+	res = runCLI(desktop, "correct horse\n", "login")
+	assert.Equal(t, exitUsage, res.code)
+}
+
+func TestShortctlErrors(t *testing.T) {
+	srv := newServer(t)
+	configPath := filepath.Join(t.TempDir(), "config.json")
+
+	tests := []struct {
+		name      string
+		args      []string
+		stdin     string
+		expCode   int
+		expStderr string
+	}{
+		{
+			name:      "NoCommand",
+			args:      []string{"-s", srv.URL},
+			expCode:   exitUsage,
+			expStderr: "Использование",
+		},
+		{
+			name:      "UnknownCommand",
+			args:      []string{"-s", srv.URL, "mv"},
+			expCode:   exitUsage,
+			expStderr: "Использование",
+		},
+		{
+			name:      "UnknownFormat",
+			args:      []string{"-s", srv.URL, "-o", "yaml", "ls"},
+			expCode:   exitUsage,
+			expStderr: "Использование",
+		},
+		{
+			name:      "NothingOnStdin",
+			args:      []string{"-s", srv.URL, "shorten"},
+			expCode:   exitUsage,
+			expStderr: "Использование",
+		},
+		{
+			name:      "ListWithoutToken",
+			args:      []string{"-s", srv.URL, "ls"},
+			expCode:   exitError,
+			expStderr: "401",
+		},
+		{
+			name:      "ResolveUnknown",
+			args:      []string{"-s", srv.URL, "resolve", "missing"},
+			expCode:   exitError,
+			expStderr: "missing: shortener: 404",
+		},
+		{
+			name:      "PolicyBlocked",
+			args:      []string{"-s", srv.URL, "shorten", srv.URL + "/loop"},
+			expCode:   exitError,
+			expStderr: "422",
+		},
+	}
+
+	for _, tc := range tests {
+		testcase := tc
+		t.Run(testcase.name, func(t *testing.T) {
+			res := runCLI(configPath, testcase.stdin, testcase.args...)
+
+			assert.Equal(t, testcase.expCode, res.code)
+			assert.Contains(t, res.stderr, testcase.expStderr)
+		})
+	}
+}
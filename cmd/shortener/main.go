@@ -3,33 +3,76 @@ package main
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
-	"net/http"
+	"net"
 	"net/url"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
-	"github.com/gin-gonic/gin"
 	"github.com/golang-migrate/migrate/v4"
 	_ "github.com/golang-migrate/migrate/v4/database/postgres"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 	_ "github.com/lib/pq"
 	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
 
 	"github.com/patrick-devel/shorturl/config"
-	"github.com/patrick-devel/shorturl/internal/handlers"
-	middlewares "github.com/patrick-devel/shorturl/internal/middlwares"
+	"github.com/patrick-devel/shorturl/internal/domains"
+	"github.com/patrick-devel/shorturl/internal/grpcserver"
+	"github.com/patrick-devel/shorturl/internal/healthcheck"
 	"github.com/patrick-devel/shorturl/internal/models"
+	"github.com/patrick-devel/shorturl/internal/ratelimit"
+	"github.com/patrick-devel/shorturl/internal/router"
 	"github.com/patrick-devel/shorturl/internal/service"
+	"github.com/patrick-devel/shorturl/internal/sso"
 	"github.com/patrick-devel/shorturl/internal/storage"
+	"github.com/patrick-devel/shorturl/internal/stream"
+	"github.com/patrick-devel/shorturl/internal/tokens"
+	"github.com/patrick-devel/shorturl/internal/webhook"
+	pb "github.com/patrick-devel/shorturl/pkg/shortenerpb"
 )
 
 type storager interface {
-	ReadEvent(ctx context.Context, hash string) (string, error)
+	ReadEvent(ctx context.Context, ref models.LinkRef) (models.Event, error)
+	ReadEventByOriginalURL(ctx context.Context, domain, originalURL string) (models.Event, error)
 	WriteEvent(ctx context.Context, event models.Event) error
 	WriteEvents(_ context.Context, events []models.Event) error
 	ReadEventsByCreatorID(ctx context.Context, userID string) ([]models.Event, error)
-	SetDeleteByShortURL(shorts []string) error
+	CountEventsByCreatorID(ctx context.Context, userID string) (int, error)
+	ReadEventsByWorkspaceIDs(ctx context.Context, workspaceIDs []string) ([]models.Event, error)
+	UpdateEvent(ctx context.Context, event models.Event) error
+	SetDeleted(refs []models.LinkRef) error
+	WriteClick(ctx context.Context, click models.Click) error
+	ReadClickStats(ctx context.Context, ref models.LinkRef) (models.ClickStats, error)
+	ReadLinkHealth(ctx context.Context, refs []models.LinkRef) (map[models.LinkRef]models.LinkHealth, error)
+	WriteWebhook(ctx context.Context, webhook models.Webhook) error
+	ReadWebhooksByCreatorID(ctx context.Context, creatorID string) ([]models.Webhook, error)
+	DeleteWebhook(ctx context.Context, creatorID, id string) error
+	WriteWebhookDelivery(ctx context.Context, delivery models.WebhookDelivery) error
+	ReadWebhookDeliveries(ctx context.Context, webhookID string, deadOnly bool) ([]models.WebhookDelivery, error)
+	WriteAPIKey(ctx context.Context, key models.APIKey) error
+	ReadAPIKeysByCreatorID(ctx context.Context, creatorID string) ([]models.APIKey, error)
+	ReadAPIKeyByHash(ctx context.Context, hash string) (models.APIKey, error)
+	DeleteAPIKey(ctx context.Context, creatorID, id string) error
+	TouchAPIKey(ctx context.Context, id string, usedAt time.Time) error
+	WriteUser(ctx context.Context, user models.User) error
+	ReadUserByEmail(ctx context.Context, email string) (models.User, error)
+	ReadUserByID(ctx context.Context, id string) (models.User, error)
+	WriteIdentity(ctx context.Context, identity models.Identity) error
+	ReadIdentity(ctx context.Context, issuer, subject string) (models.Identity, error)
+	ReadIdentitiesByUserID(ctx context.Context, userID string) ([]models.Identity, error)
+	WriteWorkspace(ctx context.Context, workspace models.Workspace, owner models.WorkspaceMember) error
+	ReadWorkspacesByUserID(ctx context.Context, userID string) ([]models.Workspace, error)
+	ReadWorkspaceMember(ctx context.Context, workspaceID, userID string) (models.WorkspaceMember, error)
+	ReadWorkspaceMembers(ctx context.Context, workspaceID string) ([]models.WorkspaceMember, error)
+	WriteWorkspaceMember(ctx context.Context, member models.WorkspaceMember) error
+	DeleteWorkspaceMember(ctx context.Context, workspaceID, userID string) error
+	ReassignCreator(ctx context.Context, from, to string) error
+	ReadActiveEvents(ctx context.Context) ([]models.Event, error)
+	WriteLinkHealth(ctx context.Context, ref models.LinkRef, health models.LinkHealth) error
 }
 
 func makeMigrate(dsn string) {
@@ -37,8 +80,9 @@ func makeMigrate(dsn string) {
 	if err != nil {
 		logrus.Fatal(err)
 	}
-	if err := m.Up(); err != nil {
-		logrus.Error(err)
+	// на старой схеме новый код работать не будет, поэтому без миграций не стартуем
+	if err := m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		logrus.Fatal(err)
 	}
 }
 
@@ -55,6 +99,8 @@ func main() {
 		baseURL = &parsedFlags.TemplateLink.url
 	}
 
+	shortDomains := domains.Parse(envOrFlag("SHORT_DOMAINS", parsedFlags.ShortDomains))
+
 	databaseDSN := os.Getenv("DATABASE_DSN")
 	if databaseDSN == "" {
 		databaseDSN = parsedFlags.DatabaseDSN
@@ -65,12 +111,113 @@ func main() {
 		fileStorage = parsedFlags.FilePath
 	}
 
+	redirectCode := parsedFlags.RedirectCode
+	if envCode := os.Getenv("REDIRECT_CODE"); envCode != "" {
+		redirectCode, err = strconv.Atoi(envCode)
+		if err != nil {
+			logrus.Fatal(fmt.Errorf("invalid REDIRECT_CODE: %w", err))
+		}
+	}
+
+	healthCheck := parsedFlags.HealthCheck
+	if envInterval := os.Getenv("HEALTH_CHECK_INTERVAL"); envInterval != "" {
+		healthCheck, err = time.ParseDuration(envInterval)
+		if err != nil {
+			logrus.Fatal(fmt.Errorf("invalid HEALTH_CHECK_INTERVAL: %w", err))
+		}
+	}
+
+	grpcAddr := os.Getenv("GRPC_ADDRESS")
+	if grpcAddr == "" {
+		grpcAddr = parsedFlags.GRPCAddr
+	}
+
+	// JWT_SIGNING_KEYS - несколько секретов через запятую для ротации, первый подписывает новые токены
+	signingKeys := os.Getenv("JWT_SIGNING_KEYS")
+	if signingKeys == "" {
+		signingKeys = os.Getenv("JWT_SIGNING_KEY")
+	}
+	// пустой список ключей отвергнет Build
+	jwtKeys, _ := tokens.ParseKeys(signingKeys)
+
+	accessTTL := parsedFlags.AccessTTL
+	if envTTL := os.Getenv("JWT_ACCESS_TTL"); envTTL != "" {
+		accessTTL, err = time.ParseDuration(envTTL)
+		if err != nil {
+			logrus.Fatal(fmt.Errorf("invalid JWT_ACCESS_TTL: %w", err))
+		}
+	}
+
+	refreshTTL := parsedFlags.RefreshTTL
+	if envTTL := os.Getenv("JWT_REFRESH_TTL"); envTTL != "" {
+		refreshTTL, err = time.ParseDuration(envTTL)
+		if err != nil {
+			logrus.Fatal(fmt.Errorf("invalid JWT_REFRESH_TTL: %w", err))
+		}
+	}
+
+	// секрет клиента читается только из окружения, как и ключи подписи
+	oidcConfig := sso.Config{
+		IssuerURL:    envOrFlag("OIDC_ISSUER_URL", parsedFlags.OIDCIssuer),
+		ClientID:     envOrFlag("OIDC_CLIENT_ID", parsedFlags.OIDCClientID),
+		ClientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
+		RedirectURL:  envOrFlag("OIDC_REDIRECT_URL", parsedFlags.OIDCRedirect),
+	}
+	for _, scope := range strings.Split(os.Getenv("OIDC_SCOPES"), ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			oidcConfig.Scopes = append(oidcConfig.Scopes, scope)
+		}
+	}
+
+	rateLimits := ratelimit.Limits{
+		Create:   parseLimit("RATE_LIMIT_CREATE", parsedFlags.RateLimitCreate),
+		Batch:    parseLimit("RATE_LIMIT_BATCH", parsedFlags.RateLimitBatch),
+		Delete:   parseLimit("RATE_LIMIT_DELETE", parsedFlags.RateLimitDelete),
+		Redirect: parseLimit("RATE_LIMIT_REDIRECT", parsedFlags.RateLimitRedirect),
+		Auth:     parseLimit("RATE_LIMIT_AUTH", parsedFlags.RateLimitAuth),
+	}
+
+	var trustedProxies []string
+	for _, proxy := range strings.Split(envOrFlag("TRUSTED_PROXIES", parsedFlags.TrustedProxies), ",") {
+		if proxy = strings.TrimSpace(proxy); proxy != "" {
+			trustedProxies = append(trustedProxies, proxy)
+		}
+	}
+
+	quota := models.Quota{Links: parsedFlags.QuotaLinks, BatchSize: parsedFlags.QuotaBatch}
+	if envLinks := os.Getenv("QUOTA_LINKS"); envLinks != "" {
+		quota.Links, err = strconv.Atoi(envLinks)
+		if err != nil {
+			logrus.Fatal(fmt.Errorf("invalid QUOTA_LINKS: %w", err))
+		}
+	}
+	if envBatch := os.Getenv("QUOTA_BATCH_SIZE"); envBatch != "" {
+		quota.BatchSize, err = strconv.Atoi(envBatch)
+		if err != nil {
+			logrus.Fatal(fmt.Errorf("invalid QUOTA_BATCH_SIZE: %w", err))
+		}
+	}
+	quotaOverrides, err := service.ParseQuotaOverrides(envOrFlag("QUOTA_OVERRIDES", parsedFlags.QuotaOverrides))
+	if err != nil {
+		logrus.Fatal(fmt.Errorf("invalid QUOTA_OVERRIDES: %w", err))
+	}
+
 	cfg, err := config.
 		NewConfigBuilder().
 		WithAddress(addr).
 		WithBaseURL(*baseURL).
+		WithShortDomains(shortDomains).
 		WithFileStoragePath(fileStorage).
 		WithDatabaseDSN(databaseDSN).
+		WithRedirectCode(redirectCode).
+		WithHealthCheckInterval(healthCheck).
+		WithGRPCAddress(grpcAddr).
+		WithJWTKeys(jwtKeys).
+		WithTokenTTL(accessTTL, refreshTTL).
+		WithOIDC(oidcConfig).
+		WithRateLimits(rateLimits).
+		WithTrustedProxies(trustedProxies).
+		WithQuotas(quota, quotaOverrides).
 		Build()
 	if err != nil {
 		logrus.Fatal(fmt.Errorf("do not build config: %w", err))
@@ -86,8 +233,6 @@ func main() {
 	}
 	logger.SetLevel(level)
 
-	loggingMdlwr := middlewares.LoggingMiddleware(logger)
-
 	var store storager
 	var db *sql.DB
 
@@ -113,42 +258,91 @@ func main() {
 		}
 		defer cfg.RemoveTemp()
 	default:
-		cache := map[string]string{}
+		cache := map[models.LinkRef]models.Event{}
 		store = storage.NewMemoryStorage(cache)
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	jwtSecret := os.Getenv("JWT_SIGNING_KEY")
-	shortService := service.New(&cfg.BaseURL, store, ctx)
-	authMidlwr := middlewares.AuthMiddleware(jwtSecret, logger)
-
-	mux := gin.New()
-	mux.Use(loggingMdlwr)
-	mux.Use(middlewares.GzipMiddleware())
-	mux.POST("/", authMidlwr, handlers.MakeShortLinkHandler(shortService))
-	mux.GET(fmt.Sprintf("%s/:id", cfg.BaseURL.Path), handlers.RedirectShortLinkHandler(shortService))
-	mux.POST("/api/shorten", authMidlwr, handlers.MakeShortURLJSONHandler(shortService))
-	mux.POST("/api/shorten/batch", authMidlwr, handlers.MakeShortURLBulk(shortService))
-	mux.GET("/api/user/urls", authMidlwr, handlers.GetURLsByCreatorID(shortService))
-	mux.DELETE("/api/user/urls", authMidlwr, handlers.DeleteShortUrls(shortService))
-
-	mux.GET("/ping", func(c *gin.Context) {
-		if db != nil {
-			if err := db.Ping(); err != nil {
-				c.JSON(http.StatusInternalServerError, "")
-
-				return
-			}
+	dispatcher := webhook.New(store)
+	go dispatcher.Run(ctx)
+
+	hub := stream.NewHub(0)
+
+	registry, err := domains.New(&cfg.BaseURL, cfg.ShortDomains...)
+	if err != nil {
+		logrus.Fatal(err)
+	}
+
+	shortService := service.New(&cfg.BaseURL, store, ctx,
+		service.WithRedirectCode(cfg.RedirectCode),
+		service.WithDomains(registry),
+		service.WithQuotas(cfg.Quota, cfg.QuotaOverrides),
+		service.WithPublisher(dispatcher),
+		service.WithPublisher(hub),
+	)
+
+	if cfg.HealthCheckInterval > 0 {
+		go healthcheck.New(store, cfg.HealthCheckInterval).Run(ctx)
+	}
+
+	issuer, err := tokens.New(cfg.JWTKeys, tokens.WithAccessTTL(cfg.AccessTokenTTL), tokens.WithRefreshTTL(cfg.RefreshTokenTTL))
+	if err != nil {
+		logrus.Fatal(err)
+	}
+
+	routerOpts := []router.Option{
+		router.WithBasePath(cfg.BaseURL.Path),
+		router.WithRateLimits(ratelimit.NewMemoryStore(), cfg.RateLimits),
+		router.WithTrustedProxies(cfg.TrustedProxies),
+	}
+	if db != nil {
+		routerOpts = append(routerOpts, router.WithPing(db.Ping))
+	}
+	if cfg.OIDC.Enabled() {
+		routerOpts = append(routerOpts, router.WithOIDC(sso.New(cfg.OIDC)))
+	}
+	mux, err := router.New(shortService, hub, issuer, logger, routerOpts...)
+	if err != nil {
+		logrus.Fatal(err)
+	}
+
+	if cfg.GRPCAddr != "" {
+		listener, err := net.Listen("tcp", cfg.GRPCAddr)
+		if err != nil {
+			logrus.Fatal(fmt.Errorf("grpc listen failed: %w", err))
 		}
-		c.JSON(http.StatusOK, gin.H{
-			"message": "pong",
-		})
-	})
-	mux.HandleMethodNotAllowed = true
+
+		grpcServer := grpc.NewServer(grpc.UnaryInterceptor(grpcserver.AuthInterceptor(issuer, shortService, logger)))
+		pb.RegisterShortenerServer(grpcServer, grpcserver.New(shortService))
+		defer grpcServer.GracefulStop()
+
+		go func() {
+			if err := grpcServer.Serve(listener); err != nil {
+				logrus.Errorf("grpc server stopped: %v", err)
+			}
+		}()
+	}
 
 	err = mux.Run(cfg.Addr)
 	if err != nil {
 		panic(err)
 	}
 }
+
+func parseLimit(env, flagValue string) ratelimit.Limit {
+	limit, err := ratelimit.ParseLimit(envOrFlag(env, flagValue))
+	if err != nil {
+		logrus.Fatal(fmt.Errorf("invalid %s: %w", env, err))
+	}
+
+	return limit
+}
+
+func envOrFlag(env, flagValue string) string {
+	if value := os.Getenv(env); value != "" {
+		return value
+	}
+
+	return flagValue
+}
@@ -3,16 +3,26 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net/http"
 	"net/url"
 	"os"
+	"time"
 
 	"github.com/sirupsen/logrus"
+
+	"github.com/patrick-devel/shorturl/internal/domains"
+	"github.com/patrick-devel/shorturl/internal/models"
+	"github.com/patrick-devel/shorturl/internal/ratelimit"
+	"github.com/patrick-devel/shorturl/internal/sso"
+	"github.com/patrick-devel/shorturl/internal/storage"
+	"github.com/patrick-devel/shorturl/internal/tokens"
 )
 
 var (
-	defaultAddr     = ":8080"
-	defaultBaseURL  = url.URL{Scheme: "http", Host: "localhost:8080"}
-	defaultLogLevel = "INFO"
+	defaultAddr         = ":8080"
+	defaultBaseURL      = url.URL{Scheme: "http", Host: "localhost:8080"}
+	defaultLogLevel     = "INFO"
+	defaultRedirectCode = http.StatusTemporaryRedirect
 )
 
 type Config struct {
@@ -20,10 +30,38 @@ type Config struct {
 	BaseURL  url.URL
 	LogLevel string
 
+	// ShortDomains - дополнительные короткие домены, кроме домена из BaseURL.
+	ShortDomains []string
+
 	FileStoragePath string
 	fileIsTemp      bool
 
 	DatabaseDSN string
+
+	RedirectCode int
+
+	// GRPCAddr - адрес gRPC-сервера, пустой адрес - gRPC не запускается.
+	GRPCAddr string
+
+	// HealthCheckInterval - период проверки ссылок, 0 отключает проверку.
+	HealthCheckInterval time.Duration
+
+	// JWTKeys - ключи подписи токенов, первым подписываются новые токены.
+	JWTKeys []tokens.Key
+	// AccessTokenTTL и RefreshTokenTTL - сроки жизни токенов, 0 - значения по умолчанию.
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	// OIDC - клиент внешнего провайдера для входа, без IssuerURL вход через OIDC выключен.
+	OIDC sso.Config
+
+	// RateLimits - лимиты частоты запросов по группам маршрутов, нулевой лимит ничего не ограничивает.
+	RateLimits ratelimit.Limits
+	// TrustedProxies - прокси, чьему X-Forwarded-For верим при определении IP клиента.
+	TrustedProxies []string
+
+	// Quota - квота пользователей по умолчанию, QuotaOverrides - квоты отдельных пользователей по UUID.
+	Quota          models.Quota
+	QuotaOverrides map[string]models.Quota
 }
 
 func (c *Config) RemoveTemp() {
@@ -32,6 +70,10 @@ func (c *Config) RemoveTemp() {
 		if err != nil {
 			logrus.Warning(err)
 		}
+		err = os.Remove(storage.AccountsPath(c.FileStoragePath))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			logrus.Warning(err)
+		}
 	}
 }
 
@@ -57,6 +99,14 @@ func (cb *ConfigBuilder) WithBaseURL(baseURL url.URL) *ConfigBuilder {
 	return cb
 }
 
+func (cb *ConfigBuilder) WithShortDomains(hosts []string) *ConfigBuilder {
+	if len(hosts) != 0 {
+		cb.config.ShortDomains = hosts
+	}
+
+	return cb
+}
+
 func (cb *ConfigBuilder) WithFileStoragePath(path string) *ConfigBuilder {
 	if path != "" {
 		cb.config.FileStoragePath = path
@@ -73,6 +123,78 @@ func (cb *ConfigBuilder) WithDatabaseDSN(dsn string) *ConfigBuilder {
 	return cb
 }
 
+func (cb *ConfigBuilder) WithRedirectCode(code int) *ConfigBuilder {
+	if code != 0 {
+		cb.config.RedirectCode = code
+	}
+
+	return cb
+}
+
+func (cb *ConfigBuilder) WithGRPCAddress(addr string) *ConfigBuilder {
+	if addr != "" {
+		cb.config.GRPCAddr = addr
+	}
+
+	return cb
+}
+
+func (cb *ConfigBuilder) WithHealthCheckInterval(interval time.Duration) *ConfigBuilder {
+	if interval > 0 {
+		cb.config.HealthCheckInterval = interval
+	}
+
+	return cb
+}
+
+func (cb *ConfigBuilder) WithJWTKeys(keys []tokens.Key) *ConfigBuilder {
+	if len(keys) != 0 {
+		cb.config.JWTKeys = keys
+	}
+
+	return cb
+}
+
+func (cb *ConfigBuilder) WithTokenTTL(access, refresh time.Duration) *ConfigBuilder {
+	if access > 0 {
+		cb.config.AccessTokenTTL = access
+	}
+	if refresh > 0 {
+		cb.config.RefreshTokenTTL = refresh
+	}
+
+	return cb
+}
+
+func (cb *ConfigBuilder) WithOIDC(oidc sso.Config) *ConfigBuilder {
+	if oidc.Enabled() {
+		cb.config.OIDC = oidc
+	}
+
+	return cb
+}
+
+func (cb *ConfigBuilder) WithRateLimits(limits ratelimit.Limits) *ConfigBuilder {
+	cb.config.RateLimits = limits
+
+	return cb
+}
+
+func (cb *ConfigBuilder) WithTrustedProxies(proxies []string) *ConfigBuilder {
+	if len(proxies) != 0 {
+		cb.config.TrustedProxies = proxies
+	}
+
+	return cb
+}
+
+func (cb *ConfigBuilder) WithQuotas(defaults models.Quota, overrides map[string]models.Quota) *ConfigBuilder {
+	cb.config.Quota = defaults
+	cb.config.QuotaOverrides = overrides
+
+	return cb
+}
+
 func (cb *ConfigBuilder) existOrCreateFile() error {
 	_, err := os.Stat(cb.config.FileStoragePath)
 	if errors.Is(err, os.ErrNotExist) {
@@ -95,6 +217,31 @@ func (cb *ConfigBuilder) Build() (*Config, error) {
 		cb.config.BaseURL = defaultBaseURL
 	}
 
+	if _, err := domains.New(&cb.config.BaseURL, cb.config.ShortDomains...); err != nil {
+		return cb.config, err
+	}
+
+	if cb.config.RedirectCode == 0 {
+		cb.config.RedirectCode = defaultRedirectCode
+	}
+
+	if !models.ValidRedirectCode(cb.config.RedirectCode) {
+		return cb.config, fmt.Errorf("redirect code %d is not supported", cb.config.RedirectCode)
+	}
+
+	// без ключа токен подписывается пустой строкой, и подделать его может кто угодно
+	if len(cb.config.JWTKeys) == 0 {
+		return cb.config, tokens.ErrNoKeys
+	}
+
+	if err := cb.config.OIDC.Validate(); err != nil {
+		return cb.config, err
+	}
+
+	if cb.config.Quota.Links < 0 || cb.config.Quota.BatchSize < 0 {
+		return cb.config, fmt.Errorf("quota %d links, %d per batch is negative", cb.config.Quota.Links, cb.config.Quota.BatchSize)
+	}
+
 	if cb.config.FileStoragePath != "" {
 		if err := cb.existOrCreateFile(); err != nil {
 			return cb.config, fmt.Errorf("file path do not created: %w", err)
@@ -19,3 +19,10 @@ func GetUserIDFromContext(ctx context.Context) string {
 
 	return userID
 }
+
+// GetDomainFromContext - короткий домен ссылки из запроса, пустая строка - основной домен.
+func GetDomainFromContext(ctx context.Context) string {
+	domain, _ := ctx.Value(string(middlewares.ContextDomain)).(string)
+
+	return domain
+}
@@ -0,0 +1,108 @@
+// Package domains - короткие домены инсталляции. Основной домен задает BASE_URL, остальные
+// отличаются от него только хостом: схема и путь у всех доменов общие.
+package domains
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrInvalidDomain = errors.New("short domain is not a host name")
+	ErrUnknownDomain = errors.New("short domain is not registered")
+)
+
+// Registry - список коротких доменов. Ссылка хранит домен как хост, основной домен - пустой строкой,
+// поэтому ссылки основного домена не зависят от того, как он записан в BASE_URL.
+type Registry struct {
+	base  url.URL
+	hosts []string
+}
+
+func New(base *url.URL, hosts ...string) (*Registry, error) {
+	r := &Registry{base: *base}
+	for _, host := range hosts {
+		host, err := normalize(host)
+		if err != nil {
+			return nil, err
+		}
+		if host == "" || host == r.Default() || slices.Contains(r.hosts, host) {
+			continue
+		}
+		r.hosts = append(r.hosts, host)
+	}
+
+	return r, nil
+}
+
+// Parse разбирает список доменов через запятую, как в SHORT_DOMAINS.
+func Parse(value string) []string {
+	var hosts []string
+	for _, host := range strings.Split(value, ",") {
+		if host = strings.TrimSpace(host); host != "" {
+			hosts = append(hosts, host)
+		}
+	}
+
+	return hosts
+}
+
+func normalize(host string) (string, error) {
+	host = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(host), "."))
+	u, err := url.Parse("//" + host)
+	if err != nil || u.Host != host || u.Path != "" || u.User != nil {
+		return "", fmt.Errorf("%w: %q", ErrInvalidDomain, host)
+	}
+
+	return host, nil
+}
+
+// Default - хост основного домена.
+func (r *Registry) Default() string {
+	return strings.ToLower(r.base.Host)
+}
+
+// Hosts - все домены, основной первым.
+func (r *Registry) Hosts() []string {
+	return append([]string{r.Default()}, r.hosts...)
+}
+
+// Lookup приводит домен из запроса пользователя к виду, в котором он хранится в ссылке.
+func (r *Registry) Lookup(domain string) (string, error) {
+	host, err := normalize(domain)
+	if err != nil {
+		return "", err
+	}
+	if host == "" || host == r.Default() {
+		return "", nil
+	}
+	if !slices.Contains(r.hosts, host) {
+		return "", fmt.Errorf("%w: %s", ErrUnknownDomain, host)
+	}
+
+	return host, nil
+}
+
+// Resolve определяет домен ссылки по заголовку Host. Незнакомый хост - основной домен:
+// так редиректы продолжают работать за прокси, который переписывает Host.
+func (r *Registry) Resolve(host string) string {
+	domain, err := r.Lookup(host)
+	if err != nil {
+		return ""
+	}
+
+	return domain
+}
+
+// URL - адрес, под которым живут ссылки домена.
+func (r *Registry) URL(domain string) *url.URL {
+	u := r.base
+	if domain != "" {
+		u.Host = domain
+	}
+
+	return &u
+}
@@ -0,0 +1,65 @@
+package domains_test
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/patrick-devel/shorturl/internal/domains"
+)
+
+func TestRegistry(t *testing.T) {
+	base := &url.URL{Scheme: "https", Host: "short.example.com", Path: "/s"}
+	registry, err := domains.New(base, "Go.Example.com", "short.example.com", "go.example.com", "sale.example.ru.")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"short.example.com", "go.example.com", "sale.example.ru"}, registry.Hosts())
+
+	tests := []struct {
+		name   string
+		domain string
+		exp    string
+		expErr error
+	}{
+		{name: "Default", domain: "", exp: ""},
+		{name: "DefaultByHost", domain: "short.example.com", exp: ""},
+		{name: "Extra", domain: "GO.example.com", exp: "go.example.com"},
+		{name: "Unknown", domain: "evil.example.com", expErr: domains.ErrUnknownDomain},
+		{name: "NotAHost", domain: "go.example.com/path", expErr: domains.ErrInvalidDomain},
+	}
+
+	for _, tc := range tests {
+		testcase := tc
+		t.Run(testcase.name, func(t *testing.T) {
+			domain, err := registry.Lookup(testcase.domain)
+			if testcase.expErr != nil {
+				assert.ErrorIs(t, err, testcase.expErr)
+				// по Host незнакомый домен считается основным
+				assert.Equal(t, "", registry.Resolve(testcase.domain))
+
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testcase.exp, domain)
+			assert.Equal(t, testcase.exp, registry.Resolve(testcase.domain))
+		})
+	}
+
+	assert.Equal(t, "https://short.example.com/s", registry.URL("").String())
+	assert.Equal(t, "https://go.example.com/s", registry.URL("go.example.com").String())
+}
+
+func TestNewRejectsInvalidHost(t *testing.T) {
+	base := &url.URL{Scheme: "http", Host: "localhost:8080"}
+	for _, host := range []string{"https://go.example.com", "go.example.com/s", "user@go.example.com"} {
+		_, err := domains.New(base, host)
+		assert.ErrorIs(t, err, domains.ErrInvalidDomain, host)
+	}
+}
+
+func TestParse(t *testing.T) {
+	assert.Equal(t, []string{"go.example.com", "sale.example.ru"}, domains.Parse(" go.example.com, ,sale.example.ru,"))
+	assert.Empty(t, domains.Parse(""))
+}
@@ -4,7 +4,10 @@ import (
 	"bufio"
 	"encoding/json"
 	"errors"
+	"io"
 	"os"
+	"path"
+	"sync"
 
 	"github.com/patrick-devel/shorturl/internal/models"
 )
@@ -29,7 +32,11 @@ func NewProducer(fileName string) (*Producer, error) {
 }
 
 func (p *Producer) WriteEvent(event *models.Event) error {
-	return p.encoder.Encode(&event)
+	// адрес ссылки зависит от BASE_URL, в файле остается только код
+	stored := *event
+	stored.ShortURL = ""
+
+	return p.encoder.Encode(&stored)
 }
 
 func (p *Producer) Close() error {
@@ -37,6 +44,7 @@ func (p *Producer) Close() error {
 }
 
 type Consumer struct {
+	mu      sync.Mutex
 	file    *os.File
 	scanner *bufio.Scanner
 }
@@ -53,18 +61,34 @@ func NewConsumer(filename string) (*Consumer, error) {
 	}, nil
 }
 
-func (c *Consumer) ReadEvent(shortURL string) (*models.Event, error) {
-	for c.scanner.Scan() {
-		data := c.scanner.Bytes()
+// rewind начинает чтение файла сначала, иначе сканер отдаст события только один раз.
+func (c *Consumer) rewind() error {
+	if _, err := c.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	c.scanner = bufio.NewScanner(c.file)
+
+	return nil
+}
+
+// Изменения ссылок дописываются в конец файла, поэтому актуальна последняя запись.
+func (c *Consumer) ReadEvent(ref models.LinkRef) (*models.Event, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-		event := models.Event{}
-		err := json.Unmarshal(data, &event)
+	if err := c.rewind(); err != nil {
+		return nil, err
+	}
+
+	var found *models.Event
+	for c.scanner.Scan() {
+		event, err := decodeEvent(c.scanner.Bytes())
 		if err != nil {
 			return nil, err
 		}
 
-		if event.ShortURL == shortURL {
-			return &event, nil
+		if event.Ref() == ref {
+			found = &event
 		}
 	}
 
@@ -72,30 +96,76 @@ func (c *Consumer) ReadEvent(shortURL string) (*models.Event, error) {
 		return nil, c.scanner.Err()
 	}
 
-	return nil, ErrNotFoundEvent
+	if found == nil {
+		return nil, ErrNotFoundEvent
+	}
+
+	return found, nil
 }
 
 func (c *Consumer) ReadEventsByUserID(userID string) ([]models.Event, error) {
+	return c.readEvents(func(event models.Event) bool {
+		return event.CreatorID == userID
+	})
+}
+
+// ReadEvents возвращает актуальные версии всех ссылок из файла.
+func (c *Consumer) ReadEvents() ([]models.Event, error) {
+	return c.readEvents(func(models.Event) bool { return true })
+}
+
+func (c *Consumer) readEvents(match func(event models.Event) bool) ([]models.Event, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.rewind(); err != nil {
+		return nil, err
+	}
+
 	var events []models.Event
+	index := map[models.LinkRef]int{}
 	for c.scanner.Scan() {
-		data := c.scanner.Bytes()
-
-		event := models.Event{}
-		err := json.Unmarshal(data, &event)
+		event, err := decodeEvent(c.scanner.Bytes())
 		if err != nil {
 			return nil, err
 		}
 
-		if event.CreatorID == userID {
-			events = append(events, event)
+		if i, ok := index[event.Ref()]; ok {
+			events[i] = event
+			continue
 		}
+		index[event.Ref()] = len(events)
+		events = append(events, event)
 	}
 
 	if c.scanner.Err() != nil {
 		return nil, c.scanner.Err()
 	}
 
-	return events, nil
+	// фильтр применяется к последней версии: у ссылки мог смениться владелец
+	matched := events[:0]
+	for _, event := range events {
+		if match(event) {
+			matched = append(matched, event)
+		}
+	}
+
+	return matched, nil
+}
+
+// decodeEvent читает строку файла. Старые записи хранили полный адрес ссылки, код берем из его пути.
+func decodeEvent(data []byte) (models.Event, error) {
+	event := models.Event{}
+	if err := json.Unmarshal(data, &event); err != nil {
+		return event, err
+	}
+
+	if event.Code == "" {
+		event.Code = path.Base(event.ShortURL)
+	}
+	event.ShortURL = ""
+
+	return event, nil
 }
 
 func (c *Consumer) Close() error {
@@ -0,0 +1,118 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	middlewares "github.com/patrick-devel/shorturl/internal/middlwares"
+	"github.com/patrick-devel/shorturl/internal/models"
+	"github.com/patrick-devel/shorturl/internal/storage"
+	"github.com/patrick-devel/shorturl/internal/tokens"
+	pb "github.com/patrick-devel/shorturl/pkg/shortenerpb"
+)
+
+const (
+	authorizationKey = "authorization"
+	refreshKey       = "x-refresh-token"
+	apiKeyKey        = "x-api-key"
+)
+
+// issuesToken - методы, которые как POST в HTTP выдают новый токен анонимному пользователю.
+var issuesToken = map[string]bool{
+	pb.Shortener_Shorten_FullMethodName:      true,
+	pb.Shortener_ShortenBatch_FullMethodName: true,
+}
+
+// public - методы, доступные без токена, как редирект по короткой ссылке.
+var public = map[string]bool{
+	pb.Shortener_Resolve_FullMethodName: true,
+}
+
+// scopes - права API-ключа, нужные методу, как у маршрутов HTTP.
+var scopes = map[string]string{
+	pb.Shortener_Shorten_FullMethodName:        models.ScopeShorten,
+	pb.Shortener_ShortenBatch_FullMethodName:   models.ScopeShorten,
+	pb.Shortener_ListUserURLs_FullMethodName:   models.ScopeRead,
+	pb.Shortener_DeleteUserURLs_FullMethodName: models.ScopeDelete,
+}
+
+type apiKeyVerifier interface {
+	VerifyAPIKey(ctx context.Context, raw string) (models.APIKey, error)
+}
+
+// AuthInterceptor повторяет AuthMiddleware и APIKeyMiddleware: токен берется из метаданных authorization,
+// ключ - из x-api-key, новая пара отдается в заголовках ответа authorization и x-refresh-token.
+// Обновляется пара через HTTP, POST /api/auth/refresh.
+func AuthInterceptor(issuer *tokens.Issuer, keys apiKeyVerifier, logger *logrus.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		var authToken, apiKey string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(authorizationKey); len(values) > 0 {
+				authToken = strings.TrimPrefix(values[0], "Bearer ")
+			}
+			if values := md.Get(apiKeyKey); len(values) > 0 {
+				apiKey = values[0]
+			}
+		}
+
+		if apiKey != "" {
+			key, err := keys.VerifyAPIKey(ctx, apiKey)
+			if errors.Is(err, storage.ErrAPIKeyNotFound) {
+				return nil, status.Error(codes.Unauthenticated, "api key not valid")
+			}
+			if err != nil {
+				logger.Error(err)
+
+				return nil, status.Error(codes.Internal, "failed to check api key")
+			}
+			if scope, ok := scopes[info.FullMethod]; ok && !key.Allows(scope) {
+				return nil, status.Errorf(codes.PermissionDenied, "api key has no %q scope", scope)
+			}
+
+			return handler(withUserID(ctx, key.CreatorID), req)
+		}
+
+		if authToken == "" && issuesToken[info.FullMethod] {
+			uid := uuid.NewString()
+			pair, err := issuer.Issue(uid)
+			if err != nil {
+				logger.Error(err)
+
+				return nil, status.Error(codes.Internal, "failed to issue token")
+			}
+
+			if err := grpc.SetHeader(ctx, metadata.Pairs(authorizationKey, pair.Access, refreshKey, pair.Refresh)); err != nil {
+				logger.Error(err)
+			}
+
+			return handler(withUserID(ctx, uid), req)
+		}
+
+		if authToken == "" && public[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		uid, err := issuer.UserID(authToken)
+		if errors.Is(err, tokens.ErrExpired) {
+			return nil, status.Error(codes.Unauthenticated, "token expired")
+		}
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, "token not valid")
+		}
+
+		return handler(withUserID(ctx, uid), req)
+	}
+}
+
+func withUserID(ctx context.Context, uid string) context.Context {
+	// ключ совпадает с тем, что кладет gin, его читает ctxaux
+	return context.WithValue(ctx, string(middlewares.ContextUserID), uid)
+}
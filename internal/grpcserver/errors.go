@@ -0,0 +1,59 @@
+package grpcserver
+
+import (
+	"errors"
+	"net/url"
+
+	"github.com/sirupsen/logrus"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/patrick-devel/shorturl/internal/domains"
+	shortservice "github.com/patrick-devel/shorturl/internal/service"
+	"github.com/patrick-devel/shorturl/internal/storage"
+)
+
+// statusFromError повторяет problemFromError из HTTP API: ошибки сервиса и хранилища превращаются
+// в коды gRPC здесь и только здесь.
+func statusFromError(err error) error {
+	var urlErr *url.Error
+
+	switch {
+	case errors.Is(err, shortservice.ErrInvalidURL), errors.As(err, &urlErr),
+		errors.Is(err, shortservice.ErrInvalidRedirectCode), errors.Is(err, shortservice.ErrInvalidTargetingRule),
+		errors.Is(err, shortservice.ErrInvalidVariants), errors.Is(err, shortservice.ErrInvalidPassthrough),
+		errors.Is(err, shortservice.ErrInvalidUTM), errors.Is(err, shortservice.ErrInvalidTitle),
+		errors.Is(err, shortservice.ErrInvalidOpenGraph),
+		errors.Is(err, domains.ErrUnknownDomain), errors.Is(err, domains.ErrInvalidDomain):
+		return status.Error(codes.InvalidArgument, err.Error())
+	// ссылка на сам сервис и удаленная ссылка: запрос корректен, но в этом состоянии не выполним
+	case errors.Is(err, shortservice.ErrPolicyBlocked), errors.Is(err, storage.ErrEventDeleted):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, storage.ErrDuplicateURL):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, storage.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, shortservice.ErrUnauthorized):
+		return status.Error(codes.Unauthenticated, err.Error())
+	case errors.Is(err, shortservice.ErrNotOwner), errors.Is(err, shortservice.ErrWorkspaceRole):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, shortservice.ErrLinkQuotaExceeded), errors.Is(err, shortservice.ErrBatchTooLarge):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	default:
+		logrus.WithError(err).Error("unexpected error")
+
+		return status.Error(codes.Internal, "internal error")
+	}
+}
+
+// duplicateError - URL уже сокращен. Существующая ссылка уходит в деталях ошибки,
+// как поле result в ответе 409 HTTP API.
+func duplicateError(shortURL string) error {
+	st := status.Newf(codes.AlreadyExists, "url already shortened: %s", shortURL)
+	if detailed, err := st.WithDetails(&errdetails.ResourceInfo{ResourceType: "short_url", ResourceName: shortURL}); err == nil {
+		st = detailed
+	}
+
+	return st.Err()
+}
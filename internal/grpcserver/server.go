@@ -0,0 +1,112 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"net/url"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	middlewares "github.com/patrick-devel/shorturl/internal/middlwares"
+	"github.com/patrick-devel/shorturl/internal/models"
+	"github.com/patrick-devel/shorturl/internal/storage"
+	pb "github.com/patrick-devel/shorturl/pkg/shortenerpb"
+)
+
+type shortService interface {
+	MakeShortURL(ctx context.Context, originalURL, uid string, opts models.LinkOptions) (string, error)
+	MakeShortURLs(ctx context.Context, bulk models.ListRequestBulk) ([]models.Event, error)
+	GetShortLink(ctx context.Context, hash string) (models.Event, error)
+	RedirectCode(event models.Event) int
+	LinksByCreatorID(ctx context.Context, filter models.LinkFilter) ([]models.Event, error)
+	DeleteShortURL(ctx context.Context, shortUrls []string) error
+}
+
+type Server struct {
+	pb.UnimplementedShortenerServer
+
+	service shortService
+}
+
+func New(service shortService) *Server {
+	return &Server{service: service}
+}
+
+func (s *Server) Shorten(ctx context.Context, req *pb.ShortenRequest) (*pb.ShortenResponse, error) {
+	originalURL, err := url.ParseRequestURI(req.GetUrl())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	shortURL, err := s.service.MakeShortURL(ctx, originalURL.String(), "", models.LinkOptions{})
+	if errors.Is(err, storage.ErrDuplicateURL) {
+		return nil, duplicateError(shortURL)
+	}
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+
+	return &pb.ShortenResponse{ShortUrl: shortURL}, nil
+}
+
+func (s *Server) ShortenBatch(ctx context.Context, req *pb.ShortenBatchRequest) (*pb.ShortenBatchResponse, error) {
+	bulk := make(models.ListRequestBulk, 0, len(req.GetItems()))
+	for _, item := range req.GetItems() {
+		originalURL, err := url.ParseRequestURI(item.GetOriginalUrl())
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "item %s: %v", item.GetCorrelationId(), err)
+		}
+		bulk = append(bulk, models.RequestBulk{OriginalURL: *originalURL, CorrelationID: item.GetCorrelationId()})
+	}
+
+	events, err := s.service.MakeShortURLs(ctx, bulk)
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+
+	resp := &pb.ShortenBatchResponse{Items: make([]*pb.BatchResult, 0, len(events))}
+	for _, e := range events {
+		resp.Items = append(resp.Items, &pb.BatchResult{CorrelationId: e.UUID, ShortUrl: e.ShortURL})
+	}
+
+	return resp, nil
+}
+
+// Resolve только смотрит, куда ведет ссылка: переход не считается, правила и варианты не применяются.
+func (s *Server) Resolve(ctx context.Context, req *pb.ResolveRequest) (*pb.ResolveResponse, error) {
+	event, err := s.service.GetShortLink(withDomain(ctx, req.GetDomain()), req.GetId())
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+
+	return &pb.ResolveResponse{OriginalUrl: event.OriginalURL, StatusCode: int32(s.service.RedirectCode(event))}, nil
+}
+
+func (s *Server) ListUserURLs(ctx context.Context, req *pb.ListUserURLsRequest) (*pb.ListUserURLsResponse, error) {
+	events, err := s.service.LinksByCreatorID(ctx, models.LinkFilter{Campaign: req.GetCampaign()})
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+
+	resp := &pb.ListUserURLsResponse{Urls: make([]*pb.UserURL, 0, len(events))}
+	for _, e := range events {
+		resp.Urls = append(resp.Urls, &pb.UserURL{ShortUrl: e.ShortURL, OriginalUrl: e.OriginalURL})
+	}
+
+	return resp, nil
+}
+
+// DeleteUserURLs, как и DELETE /api/user/urls, только ставит ссылки в очередь на удаление.
+func (s *Server) DeleteUserURLs(ctx context.Context, req *pb.DeleteUserURLsRequest) (*pb.DeleteUserURLsResponse, error) {
+	if err := s.service.DeleteShortURL(withDomain(ctx, req.GetDomain()), req.GetIds()); err != nil {
+		return nil, statusFromError(err)
+	}
+
+	return &pb.DeleteUserURLsResponse{}, nil
+}
+
+// withDomain кладет в контекст домен ссылки из запроса, как QueryDomainMiddleware в HTTP API.
+func withDomain(ctx context.Context, domain string) context.Context {
+	return context.WithValue(ctx, string(middlewares.ContextDomain), domain)
+}
@@ -0,0 +1,335 @@
+package grpcserver_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/patrick-devel/shorturl/internal/ctxaux"
+	"github.com/patrick-devel/shorturl/internal/domains"
+	"github.com/patrick-devel/shorturl/internal/grpcserver"
+	middlewares "github.com/patrick-devel/shorturl/internal/middlwares"
+	"github.com/patrick-devel/shorturl/internal/models"
+	"github.com/patrick-devel/shorturl/internal/service"
+	"github.com/patrick-devel/shorturl/internal/storage"
+	"github.com/patrick-devel/shorturl/internal/tokens"
+	pb "github.com/patrick-devel/shorturl/pkg/shortenerpb"
+)
+
+var jwtKeys = []tokens.Key{tokens.NewKey("secret")}
+
+// staleToken - токены, выданные час назад тем же ключом: access уже истек, refresh еще жив.
+func staleToken(t *testing.T) tokens.Pair {
+	t.Helper()
+
+	issuer, err := tokens.New(jwtKeys, tokens.WithClock(func() time.Time { return time.Now().Add(-time.Hour) }))
+	require.NoError(t, err)
+	pair, err := issuer.Issue("stale-user")
+	require.NoError(t, err)
+
+	return pair
+}
+
+func newClient(t *testing.T, opts ...service.Option) (pb.ShortenerClient, *service.ShortLinkService) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	baseURL := &url.URL{Scheme: "http", Host: "localhost:8080"}
+	shortService := service.New(baseURL, storage.NewMemoryStorage(map[models.LinkRef]models.Event{}), ctx, opts...)
+
+	issuer, err := tokens.New(jwtKeys)
+	require.NoError(t, err)
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(grpc.UnaryInterceptor(grpcserver.AuthInterceptor(issuer, shortService, logrus.New())))
+	pb.RegisterShortenerServer(server, grpcserver.New(shortService))
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	return pb.NewShortenerClient(conn), shortService
+}
+
+func TestShortenerFlow(t *testing.T) {
+	client, shortService := newClient(t)
+	ctx := context.Background()
+
+	var header metadata.MD
+	shortened, err := client.Shorten(ctx, &pb.ShortenRequest{Url: "https://practicum.yandex.ru/"}, grpc.Header(&header))
+	require.NoError(t, err)
+	assert.Contains(t, shortened.GetShortUrl(), "http://localhost:8080/")
+
+	access := header.Get("authorization")
+	require.Len(t, access, 1)
+	assert.Len(t, header.Get("x-refresh-token"), 1)
+	authCtx := metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+access[0])
+
+	batch, err := client.ShortenBatch(authCtx, &pb.ShortenBatchRequest{Items: []*pb.BatchItem{
+		{CorrelationId: "1", OriginalUrl: "https://example.com/a"},
+		{CorrelationId: "2", OriginalUrl: "https://example.com/b"},
+	}})
+	require.NoError(t, err)
+	require.Len(t, batch.GetItems(), 2)
+	assert.Equal(t, "1", batch.GetItems()[0].GetCorrelationId())
+
+	list, err := client.ListUserURLs(authCtx, &pb.ListUserURLsRequest{})
+	require.NoError(t, err)
+	assert.Len(t, list.GetUrls(), 3)
+
+	id := shortened.GetShortUrl()[len("http://localhost:8080/"):]
+	resolved, err := client.Resolve(ctx, &pb.ResolveRequest{Id: id})
+	require.NoError(t, err)
+	assert.Equal(t, "https://practicum.yandex.ru/", resolved.GetOriginalUrl())
+	assert.EqualValues(t, 307, resolved.GetStatusCode())
+
+	// Resolve не переход по ссылке
+	preview, err := shortService.PreviewLink(ctx, id)
+	require.NoError(t, err)
+	assert.Zero(t, preview.Clicks)
+
+	_, err = client.DeleteUserURLs(authCtx, &pb.DeleteUserURLsRequest{Ids: []string{id}})
+	require.NoError(t, err)
+}
+
+func TestShortenerErrors(t *testing.T) {
+	client, _ := newClient(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		call    func() error
+		expCode codes.Code
+	}{
+		{
+			name: "ListWithoutToken",
+			call: func() error {
+				_, err := client.ListUserURLs(ctx, &pb.ListUserURLsRequest{})
+				return err
+			},
+			expCode: codes.Unauthenticated,
+		},
+		{
+			name: "BadToken",
+			call: func() error {
+				_, err := client.ListUserURLs(metadata.AppendToOutgoingContext(ctx, "authorization", "garbage"),
+					&pb.ListUserURLsRequest{})
+				return err
+			},
+			expCode: codes.Unauthenticated,
+		},
+		{
+			name: "ExpiredToken",
+			call: func() error {
+				_, err := client.ListUserURLs(metadata.AppendToOutgoingContext(ctx, "authorization", staleToken(t).Access),
+					&pb.ListUserURLsRequest{})
+				return err
+			},
+			expCode: codes.Unauthenticated,
+		},
+		{
+			name: "RefreshTokenAsAccess",
+			call: func() error {
+				_, err := client.ListUserURLs(metadata.AppendToOutgoingContext(ctx, "authorization", staleToken(t).Refresh),
+					&pb.ListUserURLsRequest{})
+				return err
+			},
+			expCode: codes.Unauthenticated,
+		},
+		{
+			name: "InvalidURL",
+			call: func() error {
+				_, err := client.Shorten(ctx, &pb.ShortenRequest{Url: "not a url"})
+				return err
+			},
+			expCode: codes.InvalidArgument,
+		},
+		{
+			name: "SelfLink",
+			call: func() error {
+				_, err := client.Shorten(ctx, &pb.ShortenRequest{Url: "http://LOCALHOST:8080/abc"})
+				return err
+			},
+			expCode: codes.FailedPrecondition,
+		},
+		{
+			name: "SelfLinkInBatch",
+			call: func() error {
+				_, err := client.ShortenBatch(ctx, &pb.ShortenBatchRequest{Items: []*pb.BatchItem{
+					{CorrelationId: "1", OriginalUrl: "https://practicum.yandex.ru/"},
+					{CorrelationId: "2", OriginalUrl: "http://localhost:8080/abc"},
+				}})
+				return err
+			},
+			expCode: codes.FailedPrecondition,
+		},
+		{
+			name: "ResolveUnknown",
+			call: func() error {
+				_, err := client.Resolve(ctx, &pb.ResolveRequest{Id: "missing"})
+				return err
+			},
+			expCode: codes.NotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		testcase := tc
+		t.Run(testcase.name, func(t *testing.T) {
+			err := testcase.call()
+			require.Error(t, err)
+			assert.Equal(t, testcase.expCode, status.Code(err))
+		})
+	}
+}
+
+func TestAPIKey(t *testing.T) {
+	client, shortService := newClient(t)
+	ctx := context.Background()
+
+	owner := context.WithValue(ctx, string(middlewares.ContextUserID), "owner")
+	shortenOnly, err := shortService.CreateAPIKey(owner, models.RequestAPIKey{Scopes: []string{models.ScopeShorten}})
+	require.NoError(t, err)
+	full, err := shortService.CreateAPIKey(owner, models.RequestAPIKey{})
+	require.NoError(t, err)
+
+	var header metadata.MD
+	_, err = client.Shorten(metadata.AppendToOutgoingContext(ctx, "x-api-key", shortenOnly.Key),
+		&pb.ShortenRequest{Url: "https://practicum.yandex.ru/"}, grpc.Header(&header))
+	require.NoError(t, err)
+	// по ключу новый пользователь не заводится
+	assert.Empty(t, header.Get("authorization"))
+
+	_, err = client.ListUserURLs(metadata.AppendToOutgoingContext(ctx, "x-api-key", shortenOnly.Key), &pb.ListUserURLsRequest{})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	list, err := client.ListUserURLs(metadata.AppendToOutgoingContext(ctx, "x-api-key", full.Key), &pb.ListUserURLsRequest{})
+	require.NoError(t, err)
+	assert.Len(t, list.GetUrls(), 1)
+
+	_, err = client.ListUserURLs(metadata.AppendToOutgoingContext(ctx, "x-api-key", "sk_unknown"), &pb.ListUserURLsRequest{})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+}
+
+func TestShortenDuplicate(t *testing.T) {
+	client, _ := newClient(t)
+	ctx := context.Background()
+
+	first, err := client.Shorten(ctx, &pb.ShortenRequest{Url: "https://go.dev/"})
+	require.NoError(t, err)
+
+	_, err = client.Shorten(ctx, &pb.ShortenRequest{Url: "https://go.dev/"})
+	st := status.Convert(err)
+	require.Equal(t, codes.AlreadyExists, st.Code())
+	// существующая ссылка приходит в деталях, как result в ответе 409
+	require.Len(t, st.Details(), 1)
+	info, ok := st.Details()[0].(*errdetails.ResourceInfo)
+	require.True(t, ok)
+	assert.Equal(t, first.GetShortUrl(), info.GetResourceName())
+}
+
+func TestDomain(t *testing.T) {
+	registry, err := domains.New(&url.URL{Scheme: "http", Host: "localhost:8080"}, "go.example.com")
+	require.NoError(t, err)
+	client, shortService := newClient(t, service.WithDomains(registry))
+	ctx := context.Background()
+
+	owner := context.WithValue(ctx, string(middlewares.ContextUserID), "owner")
+	shortURL, err := shortService.MakeShortURL(owner, "https://go.dev/", "", models.LinkOptions{Domain: "go.example.com"})
+	require.NoError(t, err)
+	id := path.Base(shortURL)
+
+	resolved, err := client.Resolve(ctx, &pb.ResolveRequest{Id: id, Domain: "go.example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, "https://go.dev/", resolved.GetOriginalUrl())
+
+	// на основном домене такой ссылки нет
+	_, err = client.Resolve(ctx, &pb.ResolveRequest{Id: id})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+	_, err = client.Resolve(ctx, &pb.ResolveRequest{Id: id, Domain: "other.example.com"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// удаление асинхронное, поэтому смотрим, с каким доменом его вызвал сервер
+	recorder := &deleteRecorder{ShortLinkService: shortService}
+	_, err = grpcserver.New(recorder).DeleteUserURLs(owner, &pb.DeleteUserURLsRequest{Ids: []string{id}, Domain: "go.example.com"})
+	require.NoError(t, err)
+	assert.Equal(t, "go.example.com", recorder.domain)
+}
+
+// deleteRecorder запоминает домен, с которым вызвано удаление.
+type deleteRecorder struct {
+	*service.ShortLinkService
+
+	domain string
+}
+
+func (r *deleteRecorder) DeleteShortURL(ctx context.Context, shortUrls []string) error {
+	r.domain = ctxaux.GetDomainFromContext(ctx)
+
+	return r.ShortLinkService.DeleteShortURL(ctx, shortUrls)
+}
+
+// brokenLinks отдает ошибку хранилища на любой поиск ссылки.
+type brokenLinks struct {
+	*service.ShortLinkService
+
+	err error
+}
+
+func (b brokenLinks) GetShortLink(context.Context, string) (models.Event, error) {
+	return models.Event{}, b.err
+}
+
+func TestResolveErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		expCode codes.Code
+	}{
+		{name: "NotFound", err: fmt.Errorf("fetch url failed or not found: %w", storage.ErrNotFound), expCode: codes.NotFound},
+		{name: "Deleted", err: storage.ErrEventDeleted, expCode: codes.FailedPrecondition},
+		{name: "UnknownDomain", err: domains.ErrUnknownDomain, expCode: codes.InvalidArgument},
+		{name: "Unauthorized", err: service.ErrUnauthorized, expCode: codes.Unauthenticated},
+		{name: "NotOwner", err: service.ErrNotOwner, expCode: codes.PermissionDenied},
+		{name: "Quota", err: service.ErrLinkQuotaExceeded, expCode: codes.ResourceExhausted},
+		{name: "StorageDown", err: errors.New("connection refused"), expCode: codes.Internal},
+	}
+
+	for _, tc := range tests {
+		testcase := tc
+		t.Run(testcase.name, func(t *testing.T) {
+			server := grpcserver.New(brokenLinks{err: testcase.err})
+
+			_, err := server.Resolve(context.Background(), &pb.ResolveRequest{Id: "abc"})
+			require.Error(t, err)
+			assert.Equal(t, testcase.expCode, status.Code(err))
+			// подробности внутренних ошибок клиенту не нужны
+			if testcase.expCode == codes.Internal {
+				assert.NotContains(t, status.Convert(err).Message(), testcase.err.Error())
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/patrick-devel/shorturl/internal/models"
+)
+
+type apiKeyService interface {
+	CreateAPIKey(ctx context.Context, request models.RequestAPIKey) (models.APIKey, error)
+	APIKeys(ctx context.Context) ([]models.APIKey, error)
+	DeleteAPIKey(ctx context.Context, id string) error
+}
+
+// CreateAPIKey выпускает ключ, значение ключа есть только в этом ответе.
+func CreateAPIKey(service apiKeyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request models.RequestAPIKey
+
+		if err := c.ShouldBindJSON(&request); err != nil {
+			abortWithBindError(c, err)
+
+			return
+		}
+
+		key, err := service.CreateAPIKey(c.Copy(), request)
+		if err != nil {
+			abortWithError(c, err)
+
+			return
+		}
+
+		c.JSON(http.StatusCreated, key)
+	}
+}
+
+func GetAPIKeys(service apiKeyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keys, err := service.APIKeys(c.Copy())
+		if err != nil {
+			abortWithError(c, err)
+
+			return
+		}
+
+		c.JSON(http.StatusOK, keys)
+	}
+}
+
+func DeleteAPIKey(service apiKeyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		err := service.DeleteAPIKey(c.Copy(), c.Param("id"))
+		if err != nil {
+			abortWithError(c, err)
+
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
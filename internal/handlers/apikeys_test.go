@@ -0,0 +1,128 @@
+package handlers_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/patrick-devel/shorturl/internal/handlers"
+	mockhandlers "github.com/patrick-devel/shorturl/internal/handlers/mocks"
+	"github.com/patrick-devel/shorturl/internal/models"
+	"github.com/patrick-devel/shorturl/internal/service"
+)
+
+func TestAPIKeyHandlers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mockhandlers.NewMockapiKeyService(ctrl)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.POST("/api/user/keys", handlers.CreateAPIKey(mockService))
+	router.GET("/api/user/keys", handlers.GetAPIKeys(mockService))
+	router.DELETE("/api/user/keys/:id", handlers.DeleteAPIKey(mockService))
+
+	request := models.RequestAPIKey{Name: "crm", Scopes: []string{models.ScopeShorten}}
+
+	tests := []struct {
+		name     string
+		method   string
+		target   string
+		body     string
+		mockExec func()
+		expCode  int
+		expBody  string
+	}{
+		{
+			name:   "Create",
+			method: http.MethodPost,
+			target: "/api/user/keys",
+			body:   `{"name": "crm", "scopes": ["shorten"]}`,
+			mockExec: func() {
+				mockService.EXPECT().CreateAPIKey(gomock.Any(), request).
+					Return(models.APIKey{ID: "k1", Name: "crm", Prefix: "sk_0123456", Key: "sk_0123456789", Scopes: request.Scopes}, nil)
+			},
+			expCode: http.StatusCreated,
+			expBody: `"key":"sk_0123456789"`,
+		},
+		{
+			name:   "CreateInvalid",
+			method: http.MethodPost,
+			target: "/api/user/keys",
+			body:   `{"scopes": ["admin"]}`,
+			mockExec: func() {
+				mockService.EXPECT().CreateAPIKey(gomock.Any(), gomock.Any()).Return(models.APIKey{}, service.ErrInvalidAPIKeyRequest)
+			},
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:    "CreateBadBody",
+			method:  http.MethodPost,
+			target:  "/api/user/keys",
+			body:    `{"scopes": "shorten"}`,
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:   "List",
+			method: http.MethodGet,
+			target: "/api/user/keys",
+			mockExec: func() {
+				mockService.EXPECT().APIKeys(gomock.Any()).Return([]models.APIKey{{ID: "k1", Prefix: "sk_0123456"}}, nil)
+			},
+			expCode: http.StatusOK,
+			expBody: `"prefix":"sk_0123456"`,
+		},
+		{
+			name:   "Delete",
+			method: http.MethodDelete,
+			target: "/api/user/keys/k1",
+			mockExec: func() {
+				mockService.EXPECT().DeleteAPIKey(gomock.Any(), "k1").Return(nil)
+			},
+			expCode: http.StatusNoContent,
+		},
+		{
+			name:   "DeleteNotFound",
+			method: http.MethodDelete,
+			target: "/api/user/keys/k1",
+			mockExec: func() {
+				mockService.EXPECT().DeleteAPIKey(gomock.Any(), "k1").Return(service.ErrAPIKeyNotFound)
+			},
+			expCode: http.StatusNotFound,
+		},
+		{
+			name:   "DeleteFailed",
+			method: http.MethodDelete,
+			target: "/api/user/keys/k1",
+			mockExec: func() {
+				mockService.EXPECT().DeleteAPIKey(gomock.Any(), "k1").Return(errors.New("db is down"))
+			},
+			expCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tests {
+		testcase := tc
+		t.Run(testcase.name, func(t *testing.T) {
+			if testcase.mockExec != nil {
+				testcase.mockExec()
+			}
+
+			req := httptest.NewRequest(testcase.method, testcase.target, strings.NewReader(testcase.body))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, testcase.expCode, w.Code)
+			if testcase.expBody != "" {
+				assert.Contains(t, w.Body.String(), testcase.expBody)
+			}
+		})
+	}
+}
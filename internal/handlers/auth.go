@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	middlewares "github.com/patrick-devel/shorturl/internal/middlwares"
+	"github.com/patrick-devel/shorturl/internal/problem"
+	"github.com/patrick-devel/shorturl/internal/tokens"
+)
+
+type tokenRefresher interface {
+	Refresh(refresh string) (tokens.Pair, error)
+	RefreshTTL() time.Duration
+}
+
+type refreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+// RefreshTokens меняет refresh-токен на новую пару. Токен берется из тела, а если его там нет - из cookie.
+func RefreshTokens(issuer tokenRefresher) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request refreshRequest
+
+		// тело необязательно: браузер присылает токен в cookie
+		if err := c.ShouldBindJSON(&request); err != nil && !errors.Is(err, io.EOF) {
+			abortWithBindError(c, err)
+
+			return
+		}
+		if request.RefreshToken == "" {
+			request.RefreshToken = middlewares.RefreshToken(c)
+		}
+
+		pair, err := issuer.Refresh(request.RefreshToken)
+		if err != nil {
+			// истекший refresh-токен не обновить, отличать его от поддельного клиенту незачем
+			problem.Abort(c, problem.New(http.StatusUnauthorized, problem.CodeUnauthorized, "refresh token is invalid or expired"))
+
+			return
+		}
+
+		middlewares.SetTokens(c, pair, issuer.RefreshTTL())
+		c.JSON(http.StatusOK, tokenResponse{
+			AccessToken:  pair.Access,
+			RefreshToken: pair.Refresh,
+			ExpiresIn:    int(pair.ExpiresIn.Seconds()),
+		})
+	}
+}
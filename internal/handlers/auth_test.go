@@ -0,0 +1,114 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/patrick-devel/shorturl/internal/handlers"
+	mockhandlers "github.com/patrick-devel/shorturl/internal/handlers/mocks"
+	"github.com/patrick-devel/shorturl/internal/tokens"
+)
+
+func TestRefreshTokens(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	pair := tokens.Pair{Access: "a2", Refresh: "r2", ExpiresIn: 15 * time.Minute}
+
+	tests := []struct {
+		name      string
+		body      string
+		cookie    string
+		token     string
+		err       error
+		expCode   int
+		expAccess string
+	}{
+		{
+			name:      "TokenInBody",
+			body:      `{"refresh_token": "r1"}`,
+			token:     "r1",
+			expCode:   http.StatusOK,
+			expAccess: "a2",
+		},
+		{
+			name:      "TokenInCookie",
+			cookie:    "r1",
+			token:     "r1",
+			expCode:   http.StatusOK,
+			expAccess: "a2",
+		},
+		{
+			name:      "BodyWinsOverCookie",
+			body:      `{"refresh_token": "r1"}`,
+			cookie:    "stale",
+			token:     "r1",
+			expCode:   http.StatusOK,
+			expAccess: "a2",
+		},
+		{
+			name:    "Expired",
+			body:    `{"refresh_token": "r1"}`,
+			token:   "r1",
+			err:     tokens.ErrExpired,
+			expCode: http.StatusUnauthorized,
+		},
+		{
+			name:    "NoToken",
+			token:   "",
+			err:     tokens.ErrInvalid,
+			expCode: http.StatusUnauthorized,
+		},
+		{
+			name:    "InvalidBody",
+			body:    `{"refresh_token": 42}`,
+			expCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range tests {
+		testcase := tc
+		t.Run(testcase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			issuer := mockhandlers.NewMocktokenRefresher(ctrl)
+			if testcase.expCode != http.StatusBadRequest {
+				if testcase.err != nil {
+					issuer.EXPECT().Refresh(testcase.token).Return(tokens.Pair{}, testcase.err)
+				} else {
+					issuer.EXPECT().Refresh(testcase.token).Return(pair, nil)
+					issuer.EXPECT().RefreshTTL().Return(time.Hour)
+				}
+			}
+
+			router := gin.New()
+			router.POST("/api/auth/refresh", handlers.RefreshTokens(issuer))
+
+			req := httptest.NewRequest(http.MethodPost, "/api/auth/refresh", strings.NewReader(testcase.body))
+			req.Header.Set("Content-Type", "application/json")
+			if testcase.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: "user_id_refresh", Value: testcase.cookie})
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, testcase.expCode, w.Code)
+			if testcase.expAccess == "" {
+				assert.Empty(t, w.Header().Get("Authorization"))
+
+				return
+			}
+			assert.Equal(t, testcase.expAccess, w.Header().Get("Authorization"))
+			assert.Equal(t, pair.Refresh, w.Header().Get("X-Refresh-Token"))
+			assert.JSONEq(t, `{"access_token": "a2", "refresh_token": "r2", "expires_in": 900}`, w.Body.String())
+		})
+	}
+}
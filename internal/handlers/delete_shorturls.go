@@ -18,8 +18,8 @@ func DeleteShortUrls(service serviceDeleter) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var reqShortURLs RequestDeleteShortURL
 
-		if err := c.BindJSON(&reqShortURLs); err != nil {
-			c.JSON(http.StatusBadRequest, "")
+		if err := c.ShouldBindJSON(&reqShortURLs); err != nil {
+			abortWithBindError(c, err)
 
 			return
 		}
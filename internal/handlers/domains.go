@@ -0,0 +1,18 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type domainService interface {
+	Domains() []string
+}
+
+// GetDomains - короткие домены, которые можно передать в domain при сокращении, основной первым.
+func GetDomains(service domainService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, service.Domains())
+	}
+}
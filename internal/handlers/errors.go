@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"github.com/patrick-devel/shorturl/internal/domains"
+	"github.com/patrick-devel/shorturl/internal/problem"
+	"github.com/patrick-devel/shorturl/internal/qr"
+	shortservice "github.com/patrick-devel/shorturl/internal/service"
+	"github.com/patrick-devel/shorturl/internal/sso"
+	"github.com/patrick-devel/shorturl/internal/storage"
+)
+
+var errInvalidBody = errors.New("request body is invalid")
+
+// problemFromError - единственное место, где ошибки сервиса и хранилища превращаются в ответ.
+func problemFromError(err error) problem.Problem {
+	var urlErr *url.Error
+
+	switch {
+	// раньше *url.Error: ошибка соединения с провайдером тоже *url.Error, но это не кривой URL клиента
+	case errors.Is(err, sso.ErrUnavailable):
+		logrus.WithError(err).Warn("identity provider is unavailable")
+
+		return problem.New(http.StatusBadGateway, problem.CodeSSOUnavailable, "")
+	case errors.Is(err, shortservice.ErrInvalidURL), errors.As(err, &urlErr):
+		return problem.New(http.StatusBadRequest, problem.CodeInvalidURL, err.Error())
+	case errors.Is(err, shortservice.ErrPolicyBlocked):
+		return problem.New(http.StatusUnprocessableEntity, problem.CodePolicyBlocked, err.Error())
+	case errors.Is(err, errInvalidBody), invalidOptions(err),
+		errors.Is(err, shortservice.ErrInvalidWebhook), errors.Is(err, shortservice.ErrInvalidAPIKeyRequest),
+		errors.Is(err, shortservice.ErrInvalidAccount), errors.Is(err, qr.ErrInvalidOptions),
+		errors.Is(err, sso.ErrInvalidFlow), errors.Is(err, shortservice.ErrInvalidWorkspace),
+		errors.Is(err, domains.ErrUnknownDomain), errors.Is(err, domains.ErrInvalidDomain):
+		return problem.New(http.StatusBadRequest, problem.CodeInvalidRequest, err.Error())
+	case errors.Is(err, storage.ErrDuplicateURL):
+		return problem.New(http.StatusConflict, problem.CodeDuplicate, "")
+	case errors.Is(err, shortservice.ErrEmailTaken):
+		return problem.New(http.StatusConflict, problem.CodeEmailTaken, "")
+	case errors.Is(err, storage.ErrEventDeleted):
+		return problem.New(http.StatusGone, problem.CodeGone, "")
+	case errors.Is(err, storage.ErrNotFound),
+		errors.Is(err, storage.ErrWebhookNotFound), errors.Is(err, shortservice.ErrWebhookNotFound),
+		errors.Is(err, shortservice.ErrAPIKeyNotFound), errors.Is(err, shortservice.ErrWorkspaceNotFound),
+		errors.Is(err, storage.ErrWorkspaceMemberNotFound):
+		return problem.New(http.StatusNotFound, problem.CodeNotFound, "")
+	case errors.Is(err, shortservice.ErrUnauthorized):
+		return problem.New(http.StatusUnauthorized, problem.CodeUnauthorized, "")
+	case errors.Is(err, shortservice.ErrInvalidCredentials), errors.Is(err, sso.ErrRejected):
+		return problem.New(http.StatusUnauthorized, problem.CodeUnauthorized, err.Error())
+	case errors.Is(err, shortservice.ErrNotOwner), errors.Is(err, shortservice.ErrWorkspaceRole):
+		return problem.New(http.StatusForbidden, problem.CodeForbidden, err.Error())
+	case errors.Is(err, shortservice.ErrLinkQuotaExceeded), errors.Is(err, shortservice.ErrBatchTooLarge):
+		return problem.New(http.StatusForbidden, problem.CodeQuotaExceeded, err.Error())
+	default:
+		logrus.WithError(err).Error("unexpected error")
+
+		return problem.Internal()
+	}
+}
+
+func abortWithError(c *gin.Context, err error) {
+	problem.Abort(c, problemFromError(err))
+}
+
+// abortWithBindError - тело не разобралось. Кривой URL внутри JSON отдается как invalid_url.
+func abortWithBindError(c *gin.Context, err error) {
+	abortWithError(c, fmt.Errorf("%w: %w", errInvalidBody, err))
+}
+
+func invalidOptions(err error) bool {
+	return errors.Is(err, shortservice.ErrInvalidRedirectCode) ||
+		errors.Is(err, shortservice.ErrInvalidTargetingRule) ||
+		errors.Is(err, shortservice.ErrInvalidVariants) ||
+		errors.Is(err, shortservice.ErrInvalidPassthrough) ||
+		errors.Is(err, shortservice.ErrInvalidUTM) ||
+		errors.Is(err, shortservice.ErrInvalidTitle) ||
+		errors.Is(err, shortservice.ErrInvalidOpenGraph)
+}
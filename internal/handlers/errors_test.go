@@ -0,0 +1,123 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/patrick-devel/shorturl/internal/handlers"
+	mockhandlers "github.com/patrick-devel/shorturl/internal/handlers/mocks"
+	"github.com/patrick-devel/shorturl/internal/problem"
+	shortservice "github.com/patrick-devel/shorturl/internal/service"
+	"github.com/patrick-devel/shorturl/internal/storage"
+)
+
+func TestProblemResponses(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		body       string
+		result     string
+		err        error
+		expCode    int
+		expProblem string
+		expResult  string
+	}{
+		{
+			name:       "InvalidBody",
+			body:       `{"url": 42}`,
+			expCode:    http.StatusBadRequest,
+			expProblem: problem.CodeInvalidRequest,
+		},
+		{
+			name:       "InvalidURL",
+			body:       `{"url": "https://\\]]practicum.yandex.ru/"}`,
+			expCode:    http.StatusBadRequest,
+			expProblem: problem.CodeInvalidURL,
+		},
+		{
+			name:       "InvalidOptions",
+			body:       `{"url": "https://practicum.yandex.ru/"}`,
+			err:        fmt.Errorf("%w: 303", shortservice.ErrInvalidRedirectCode),
+			expCode:    http.StatusBadRequest,
+			expProblem: problem.CodeInvalidRequest,
+		},
+		{
+			name:       "Duplicate",
+			body:       `{"url": "https://practicum.yandex.ru/"}`,
+			result:     "http://localhost:8080/abc",
+			err:        storage.ErrDuplicateURL,
+			expCode:    http.StatusConflict,
+			expProblem: problem.CodeDuplicate,
+			expResult:  "http://localhost:8080/abc",
+		},
+		{
+			name:       "PolicyBlocked",
+			body:       `{"url": "http://localhost:8080/abc"}`,
+			err:        fmt.Errorf("%w: localhost:8080 points to the shortener itself", shortservice.ErrPolicyBlocked),
+			expCode:    http.StatusUnprocessableEntity,
+			expProblem: problem.CodePolicyBlocked,
+		},
+		{
+			name:       "QuotaExceeded",
+			body:       `{"url": "https://practicum.yandex.ru/"}`,
+			err:        fmt.Errorf("%w: 1000 of 1000 links used", shortservice.ErrLinkQuotaExceeded),
+			expCode:    http.StatusForbidden,
+			expProblem: problem.CodeQuotaExceeded,
+		},
+		{
+			name:       "Unauthorized",
+			body:       `{"url": "https://practicum.yandex.ru/"}`,
+			err:        shortservice.ErrUnauthorized,
+			expCode:    http.StatusUnauthorized,
+			expProblem: problem.CodeUnauthorized,
+		},
+		{
+			name:       "Internal",
+			body:       `{"url": "https://practicum.yandex.ru/"}`,
+			err:        errors.New("connection reset"),
+			expCode:    http.StatusInternalServerError,
+			expProblem: problem.CodeInternal,
+		},
+	}
+
+	for _, tc := range tests {
+		testcase := tc
+		t.Run(testcase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockService := mockhandlers.NewMockshortService(ctrl)
+			router := gin.New()
+			router.POST("/api/shorten", handlers.MakeShortURLJSONHandler(mockService))
+
+			mockService.EXPECT().MakeShortURL(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(testcase.result, testcase.err).MaxTimes(1)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/shorten", strings.NewReader(testcase.body))
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			assert.Equal(t, testcase.expCode, recorder.Code)
+			assert.Equal(t, problem.ContentType, recorder.Header().Get("Content-Type"))
+
+			var p problem.Problem
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &p))
+			assert.Equal(t, testcase.expCode, p.Status)
+			assert.Equal(t, testcase.expProblem, p.Code)
+			assert.Equal(t, "urn:shorturl:problem:"+testcase.expProblem, p.Type)
+			assert.NotEmpty(t, p.Title)
+			assert.Equal(t, testcase.expResult, p.Result)
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/patrick-devel/shorturl/internal/models"
+)
+
+type healthService interface {
+	BrokenLinks(ctx context.Context) ([]models.ResponseBrokenURL, error)
+}
+
+func GetBrokenURLs(service healthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		broken, err := service.BrokenLinks(c.Copy())
+		if err != nil {
+			abortWithError(c, err)
+
+			return
+		}
+
+		c.JSON(http.StatusOK, broken)
+	}
+}
@@ -0,0 +1,89 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/patrick-devel/shorturl/internal/handlers"
+	mockhandlers "github.com/patrick-devel/shorturl/internal/handlers/mocks"
+	"github.com/patrick-devel/shorturl/internal/models"
+)
+
+func TestGetBrokenURLs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mockhandlers.NewMockhealthService(ctrl)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.GET("/api/user/urls/broken", handlers.GetBrokenURLs(mockService))
+
+	checked := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	broken := []models.ResponseBrokenURL{{
+		ShortURL:    "http://localhost/abc",
+		OriginalURL: "https://practicum.yandex.ru/gone",
+		LinkHealth:  models.LinkHealth{StatusCode: http.StatusNotFound, LatencyMS: 42, CheckedAt: checked},
+	}}
+
+	tests := []struct {
+		name     string
+		mockExec func()
+		expCode  int
+		expBody  []models.ResponseBrokenURL
+	}{
+		{
+			name: "OK",
+			mockExec: func() {
+				mockService.EXPECT().BrokenLinks(gomock.Any()).Return(broken, nil)
+			},
+			expCode: http.StatusOK,
+			expBody: broken,
+		},
+		{
+			name: "Empty",
+			mockExec: func() {
+				mockService.EXPECT().BrokenLinks(gomock.Any()).Return([]models.ResponseBrokenURL{}, nil)
+			},
+			expCode: http.StatusOK,
+			expBody: []models.ResponseBrokenURL{},
+		},
+		{
+			name: "Error",
+			mockExec: func() {
+				mockService.EXPECT().BrokenLinks(gomock.Any()).Return(nil, errors.New("db is down"))
+			},
+			expCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tests {
+		testcase := tc
+		t.Run(testcase.name, func(t *testing.T) {
+			testcase.mockExec()
+
+			req := httptest.NewRequest(http.MethodGet, "/api/user/urls/broken", http.NoBody)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			resp := recorder.Result()
+			defer resp.Body.Close()
+
+			assert.Equal(t, testcase.expCode, resp.StatusCode)
+			if testcase.expBody != nil {
+				var body []models.ResponseBrokenURL
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+				assert.Equal(t, testcase.expBody, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/handlers/apikeys.go
+
+// Package mock_handlers is a generated GoMock package.
+package mock_handlers
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/patrick-devel/shorturl/internal/models"
+)
+
+// MockapiKeyService is a mock of apiKeyService interface.
+type MockapiKeyService struct {
+	ctrl     *gomock.Controller
+	recorder *MockapiKeyServiceMockRecorder
+}
+
+// MockapiKeyServiceMockRecorder is the mock recorder for MockapiKeyService.
+type MockapiKeyServiceMockRecorder struct {
+	mock *MockapiKeyService
+}
+
+// NewMockapiKeyService creates a new mock instance.
+func NewMockapiKeyService(ctrl *gomock.Controller) *MockapiKeyService {
+	mock := &MockapiKeyService{ctrl: ctrl}
+	mock.recorder = &MockapiKeyServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockapiKeyService) EXPECT() *MockapiKeyServiceMockRecorder {
+	return m.recorder
+}
+
+// APIKeys mocks base method.
+func (m *MockapiKeyService) APIKeys(ctx context.Context) ([]models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "APIKeys", ctx)
+	ret0, _ := ret[0].([]models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// APIKeys indicates an expected call of APIKeys.
+func (mr *MockapiKeyServiceMockRecorder) APIKeys(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "APIKeys", reflect.TypeOf((*MockapiKeyService)(nil).APIKeys), ctx)
+}
+
+// CreateAPIKey mocks base method.
+func (m *MockapiKeyService) CreateAPIKey(ctx context.Context, request models.RequestAPIKey) (models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", ctx, request)
+	ret0, _ := ret[0].(models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MockapiKeyServiceMockRecorder) CreateAPIKey(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MockapiKeyService)(nil).CreateAPIKey), ctx, request)
+}
+
+// DeleteAPIKey mocks base method.
+func (m *MockapiKeyService) DeleteAPIKey(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAPIKey", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAPIKey indicates an expected call of DeleteAPIKey.
+func (mr *MockapiKeyServiceMockRecorder) DeleteAPIKey(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAPIKey", reflect.TypeOf((*MockapiKeyService)(nil).DeleteAPIKey), ctx, id)
+}
@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/handlers/health.go
+
+// Package mock_handlers is a generated GoMock package.
+package mock_handlers
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/patrick-devel/shorturl/internal/models"
+)
+
+// MockhealthService is a mock of healthService interface.
+type MockhealthService struct {
+	ctrl     *gomock.Controller
+	recorder *MockhealthServiceMockRecorder
+}
+
+// MockhealthServiceMockRecorder is the mock recorder for MockhealthService.
+type MockhealthServiceMockRecorder struct {
+	mock *MockhealthService
+}
+
+// NewMockhealthService creates a new mock instance.
+func NewMockhealthService(ctrl *gomock.Controller) *MockhealthService {
+	mock := &MockhealthService{ctrl: ctrl}
+	mock.recorder = &MockhealthServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockhealthService) EXPECT() *MockhealthServiceMockRecorder {
+	return m.recorder
+}
+
+// BrokenLinks mocks base method.
+func (m *MockhealthService) BrokenLinks(ctx context.Context) ([]models.ResponseBrokenURL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BrokenLinks", ctx)
+	ret0, _ := ret[0].([]models.ResponseBrokenURL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BrokenLinks indicates an expected call of BrokenLinks.
+func (mr *MockhealthServiceMockRecorder) BrokenLinks(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BrokenLinks", reflect.TypeOf((*MockhealthService)(nil).BrokenLinks), ctx)
+}
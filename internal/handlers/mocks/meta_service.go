@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/handlers/unfurl.go
+
+// Package mock_handlers is a generated GoMock package.
+package mock_handlers
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/patrick-devel/shorturl/internal/models"
+)
+
+// MockmetaService is a mock of metaService interface.
+type MockmetaService struct {
+	ctrl     *gomock.Controller
+	recorder *MockmetaServiceMockRecorder
+}
+
+// MockmetaServiceMockRecorder is the mock recorder for MockmetaService.
+type MockmetaServiceMockRecorder struct {
+	mock *MockmetaService
+}
+
+// NewMockmetaService creates a new mock instance.
+func NewMockmetaService(ctrl *gomock.Controller) *MockmetaService {
+	mock := &MockmetaService{ctrl: ctrl}
+	mock.recorder = &MockmetaServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockmetaService) EXPECT() *MockmetaServiceMockRecorder {
+	return m.recorder
+}
+
+// SetLinkMeta mocks base method.
+func (m *MockmetaService) SetLinkMeta(ctx context.Context, hash string, meta models.LinkMeta) (models.LinkMeta, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetLinkMeta", ctx, hash, meta)
+	ret0, _ := ret[0].(models.LinkMeta)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetLinkMeta indicates an expected call of SetLinkMeta.
+func (mr *MockmetaServiceMockRecorder) SetLinkMeta(ctx, hash, meta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLinkMeta", reflect.TypeOf((*MockmetaService)(nil).SetLinkMeta), ctx, hash, meta)
+}
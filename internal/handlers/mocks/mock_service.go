@@ -1,5 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: /Users/kspopova/GolandProjects/shorturl/internal/handlers/shorturl.go
+// Source: internal/handlers/shorturl.go
 
 // Package mock_handlers is a generated GoMock package.
 package mock_handlers
@@ -36,48 +36,64 @@ func (m *MockshortService) EXPECT() *MockshortServiceMockRecorder {
 }
 
 // GetOriginalURL mocks base method.
-func (m *MockshortService) GetOriginalURL(ctx context.Context, hash string) (string, error) {
+func (m *MockshortService) GetOriginalURL(ctx context.Context, hash string, visitor models.Visitor) (models.Redirect, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetOriginalURL", ctx, hash)
-	ret0, _ := ret[0].(string)
+	ret := m.ctrl.Call(m, "GetOriginalURL", ctx, hash, visitor)
+	ret0, _ := ret[0].(models.Redirect)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetOriginalURL indicates an expected call of GetOriginalURL.
-func (mr *MockshortServiceMockRecorder) GetOriginalURL(ctx, hash interface{}) *gomock.Call {
+func (mr *MockshortServiceMockRecorder) GetOriginalURL(ctx, hash, visitor interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOriginalURL", reflect.TypeOf((*MockshortService)(nil).GetOriginalURL), ctx, hash, visitor)
+}
+
+// GetUnfurl mocks base method.
+func (m *MockshortService) GetUnfurl(ctx context.Context, hash string) (models.Unfurl, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUnfurl", ctx, hash)
+	ret0, _ := ret[0].(models.Unfurl)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetUnfurl indicates an expected call of GetUnfurl.
+func (mr *MockshortServiceMockRecorder) GetUnfurl(ctx, hash interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOriginalURL", reflect.TypeOf((*MockshortService)(nil).GetOriginalURL), ctx, hash)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUnfurl", reflect.TypeOf((*MockshortService)(nil).GetUnfurl), ctx, hash)
 }
 
 // LinksByCreatorID mocks base method.
-func (m *MockshortService) LinksByCreatorID(ctx context.Context) ([]models.Event, error) {
+func (m *MockshortService) LinksByCreatorID(ctx context.Context, filter models.LinkFilter) ([]models.Event, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "LinksByCreatorID", ctx)
+	ret := m.ctrl.Call(m, "LinksByCreatorID", ctx, filter)
 	ret0, _ := ret[0].([]models.Event)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // LinksByCreatorID indicates an expected call of LinksByCreatorID.
-func (mr *MockshortServiceMockRecorder) LinksByCreatorID(ctx interface{}) *gomock.Call {
+func (mr *MockshortServiceMockRecorder) LinksByCreatorID(ctx, filter interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LinksByCreatorID", reflect.TypeOf((*MockshortService)(nil).LinksByCreatorID), ctx)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LinksByCreatorID", reflect.TypeOf((*MockshortService)(nil).LinksByCreatorID), ctx, filter)
 }
 
 // MakeShortURL mocks base method.
-func (m *MockshortService) MakeShortURL(ctx context.Context, originalURL, uid string) (string, error) {
+func (m *MockshortService) MakeShortURL(ctx context.Context, originalURL, uid string, opts models.LinkOptions) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "MakeShortURL", ctx, originalURL, uid)
+	ret := m.ctrl.Call(m, "MakeShortURL", ctx, originalURL, uid, opts)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // MakeShortURL indicates an expected call of MakeShortURL.
-func (mr *MockshortServiceMockRecorder) MakeShortURL(ctx, originalURL, uid interface{}) *gomock.Call {
+func (mr *MockshortServiceMockRecorder) MakeShortURL(ctx, originalURL, uid, opts interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MakeShortURL", reflect.TypeOf((*MockshortService)(nil).MakeShortURL), ctx, originalURL, uid)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MakeShortURL", reflect.TypeOf((*MockshortService)(nil).MakeShortURL), ctx, originalURL, uid, opts)
 }
 
 // MakeShortURLs mocks base method.
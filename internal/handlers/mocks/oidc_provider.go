@@ -0,0 +1,106 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/handlers/oidc.go
+
+// Package mock_handlers is a generated GoMock package.
+package mock_handlers
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/patrick-devel/shorturl/internal/models"
+	sso "github.com/patrick-devel/shorturl/internal/sso"
+)
+
+// MockoidcProvider is a mock of oidcProvider interface.
+type MockoidcProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockoidcProviderMockRecorder
+}
+
+// MockoidcProviderMockRecorder is the mock recorder for MockoidcProvider.
+type MockoidcProviderMockRecorder struct {
+	mock *MockoidcProvider
+}
+
+// NewMockoidcProvider creates a new mock instance.
+func NewMockoidcProvider(ctrl *gomock.Controller) *MockoidcProvider {
+	mock := &MockoidcProvider{ctrl: ctrl}
+	mock.recorder = &MockoidcProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockoidcProvider) EXPECT() *MockoidcProviderMockRecorder {
+	return m.recorder
+}
+
+// Begin mocks base method.
+func (m *MockoidcProvider) Begin(ctx context.Context) (string, sso.Flow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Begin", ctx)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(sso.Flow)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Begin indicates an expected call of Begin.
+func (mr *MockoidcProviderMockRecorder) Begin(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Begin", reflect.TypeOf((*MockoidcProvider)(nil).Begin), ctx)
+}
+
+// Finish mocks base method.
+func (m *MockoidcProvider) Finish(ctx context.Context, flow sso.Flow, code string) (models.Identity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Finish", ctx, flow, code)
+	ret0, _ := ret[0].(models.Identity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Finish indicates an expected call of Finish.
+func (mr *MockoidcProviderMockRecorder) Finish(ctx, flow, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Finish", reflect.TypeOf((*MockoidcProvider)(nil).Finish), ctx, flow, code)
+}
+
+// MockidentityService is a mock of identityService interface.
+type MockidentityService struct {
+	ctrl     *gomock.Controller
+	recorder *MockidentityServiceMockRecorder
+}
+
+// MockidentityServiceMockRecorder is the mock recorder for MockidentityService.
+type MockidentityServiceMockRecorder struct {
+	mock *MockidentityService
+}
+
+// NewMockidentityService creates a new mock instance.
+func NewMockidentityService(ctrl *gomock.Controller) *MockidentityService {
+	mock := &MockidentityService{ctrl: ctrl}
+	mock.recorder = &MockidentityServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockidentityService) EXPECT() *MockidentityServiceMockRecorder {
+	return m.recorder
+}
+
+// LoginWithIdentity mocks base method.
+func (m *MockidentityService) LoginWithIdentity(ctx context.Context, identity models.Identity) (models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoginWithIdentity", ctx, identity)
+	ret0, _ := ret[0].(models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoginWithIdentity indicates an expected call of LoginWithIdentity.
+func (mr *MockidentityServiceMockRecorder) LoginWithIdentity(ctx, identity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoginWithIdentity", reflect.TypeOf((*MockidentityService)(nil).LoginWithIdentity), ctx, identity)
+}
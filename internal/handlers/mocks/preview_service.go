@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/handlers/preview.go
+
+// Package mock_handlers is a generated GoMock package.
+package mock_handlers
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/patrick-devel/shorturl/internal/models"
+)
+
+// MockpreviewService is a mock of previewService interface.
+type MockpreviewService struct {
+	ctrl     *gomock.Controller
+	recorder *MockpreviewServiceMockRecorder
+}
+
+// MockpreviewServiceMockRecorder is the mock recorder for MockpreviewService.
+type MockpreviewServiceMockRecorder struct {
+	mock *MockpreviewService
+}
+
+// NewMockpreviewService creates a new mock instance.
+func NewMockpreviewService(ctrl *gomock.Controller) *MockpreviewService {
+	mock := &MockpreviewService{ctrl: ctrl}
+	mock.recorder = &MockpreviewServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockpreviewService) EXPECT() *MockpreviewServiceMockRecorder {
+	return m.recorder
+}
+
+// PreviewLink mocks base method.
+func (m *MockpreviewService) PreviewLink(ctx context.Context, hash string) (models.Preview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PreviewLink", ctx, hash)
+	ret0, _ := ret[0].(models.Preview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PreviewLink indicates an expected call of PreviewLink.
+func (mr *MockpreviewServiceMockRecorder) PreviewLink(ctx, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PreviewLink", reflect.TypeOf((*MockpreviewService)(nil).PreviewLink), ctx, hash)
+}
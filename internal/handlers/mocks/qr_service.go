@@ -0,0 +1,66 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/handlers/qr.go
+
+// Package mock_handlers is a generated GoMock package.
+package mock_handlers
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/patrick-devel/shorturl/internal/models"
+)
+
+// MockqrService is a mock of qrService interface.
+type MockqrService struct {
+	ctrl     *gomock.Controller
+	recorder *MockqrServiceMockRecorder
+}
+
+// MockqrServiceMockRecorder is the mock recorder for MockqrService.
+type MockqrServiceMockRecorder struct {
+	mock *MockqrService
+}
+
+// NewMockqrService creates a new mock instance.
+func NewMockqrService(ctrl *gomock.Controller) *MockqrService {
+	mock := &MockqrService{ctrl: ctrl}
+	mock.recorder = &MockqrServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockqrService) EXPECT() *MockqrServiceMockRecorder {
+	return m.recorder
+}
+
+// GetShortLink mocks base method.
+func (m *MockqrService) GetShortLink(ctx context.Context, hash string) (models.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetShortLink", ctx, hash)
+	ret0, _ := ret[0].(models.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetShortLink indicates an expected call of GetShortLink.
+func (mr *MockqrServiceMockRecorder) GetShortLink(ctx, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetShortLink", reflect.TypeOf((*MockqrService)(nil).GetShortLink), ctx, hash)
+}
+
+// LinksByCreatorID mocks base method.
+func (m *MockqrService) LinksByCreatorID(ctx context.Context, filter models.LinkFilter) ([]models.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LinksByCreatorID", ctx, filter)
+	ret0, _ := ret[0].([]models.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LinksByCreatorID indicates an expected call of LinksByCreatorID.
+func (mr *MockqrServiceMockRecorder) LinksByCreatorID(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LinksByCreatorID", reflect.TypeOf((*MockqrService)(nil).LinksByCreatorID), ctx, filter)
+}
@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/handlers/quota.go
+
+// Package mock_handlers is a generated GoMock package.
+package mock_handlers
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/patrick-devel/shorturl/internal/models"
+)
+
+// MockquotaService is a mock of quotaService interface.
+type MockquotaService struct {
+	ctrl     *gomock.Controller
+	recorder *MockquotaServiceMockRecorder
+}
+
+// MockquotaServiceMockRecorder is the mock recorder for MockquotaService.
+type MockquotaServiceMockRecorder struct {
+	mock *MockquotaService
+}
+
+// NewMockquotaService creates a new mock instance.
+func NewMockquotaService(ctrl *gomock.Controller) *MockquotaService {
+	mock := &MockquotaService{ctrl: ctrl}
+	mock.recorder = &MockquotaServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockquotaService) EXPECT() *MockquotaServiceMockRecorder {
+	return m.recorder
+}
+
+// Quota mocks base method.
+func (m *MockquotaService) Quota(ctx context.Context) (models.QuotaUsage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Quota", ctx)
+	ret0, _ := ret[0].(models.QuotaUsage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Quota indicates an expected call of Quota.
+func (mr *MockquotaServiceMockRecorder) Quota(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Quota", reflect.TypeOf((*MockquotaService)(nil).Quota), ctx)
+}
@@ -0,0 +1,65 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/handlers/targeting.go
+
+// Package mock_handlers is a generated GoMock package.
+package mock_handlers
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/patrick-devel/shorturl/internal/models"
+)
+
+// MocktargetingService is a mock of targetingService interface.
+type MocktargetingService struct {
+	ctrl     *gomock.Controller
+	recorder *MocktargetingServiceMockRecorder
+}
+
+// MocktargetingServiceMockRecorder is the mock recorder for MocktargetingService.
+type MocktargetingServiceMockRecorder struct {
+	mock *MocktargetingService
+}
+
+// NewMocktargetingService creates a new mock instance.
+func NewMocktargetingService(ctrl *gomock.Controller) *MocktargetingService {
+	mock := &MocktargetingService{ctrl: ctrl}
+	mock.recorder = &MocktargetingServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MocktargetingService) EXPECT() *MocktargetingServiceMockRecorder {
+	return m.recorder
+}
+
+// SetTargetingRules mocks base method.
+func (m *MocktargetingService) SetTargetingRules(ctx context.Context, hash string, rules []models.TargetingRule) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTargetingRules", ctx, hash, rules)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTargetingRules indicates an expected call of SetTargetingRules.
+func (mr *MocktargetingServiceMockRecorder) SetTargetingRules(ctx, hash, rules interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTargetingRules", reflect.TypeOf((*MocktargetingService)(nil).SetTargetingRules), ctx, hash, rules)
+}
+
+// TargetingRules mocks base method.
+func (m *MocktargetingService) TargetingRules(ctx context.Context, hash string) ([]models.TargetingRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TargetingRules", ctx, hash)
+	ret0, _ := ret[0].([]models.TargetingRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TargetingRules indicates an expected call of TargetingRules.
+func (mr *MocktargetingServiceMockRecorder) TargetingRules(ctx, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TargetingRules", reflect.TypeOf((*MocktargetingService)(nil).TargetingRules), ctx, hash)
+}
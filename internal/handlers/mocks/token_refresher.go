@@ -0,0 +1,65 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/handlers/auth.go
+
+// Package mock_handlers is a generated GoMock package.
+package mock_handlers
+
+import (
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	tokens "github.com/patrick-devel/shorturl/internal/tokens"
+)
+
+// MocktokenRefresher is a mock of tokenRefresher interface.
+type MocktokenRefresher struct {
+	ctrl     *gomock.Controller
+	recorder *MocktokenRefresherMockRecorder
+}
+
+// MocktokenRefresherMockRecorder is the mock recorder for MocktokenRefresher.
+type MocktokenRefresherMockRecorder struct {
+	mock *MocktokenRefresher
+}
+
+// NewMocktokenRefresher creates a new mock instance.
+func NewMocktokenRefresher(ctrl *gomock.Controller) *MocktokenRefresher {
+	mock := &MocktokenRefresher{ctrl: ctrl}
+	mock.recorder = &MocktokenRefresherMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MocktokenRefresher) EXPECT() *MocktokenRefresherMockRecorder {
+	return m.recorder
+}
+
+// Refresh mocks base method.
+func (m *MocktokenRefresher) Refresh(refresh string) (tokens.Pair, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Refresh", refresh)
+	ret0, _ := ret[0].(tokens.Pair)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Refresh indicates an expected call of Refresh.
+func (mr *MocktokenRefresherMockRecorder) Refresh(refresh interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Refresh", reflect.TypeOf((*MocktokenRefresher)(nil).Refresh), refresh)
+}
+
+// RefreshTTL mocks base method.
+func (m *MocktokenRefresher) RefreshTTL() time.Duration {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshTTL")
+	ret0, _ := ret[0].(time.Duration)
+	return ret0
+}
+
+// RefreshTTL indicates an expected call of RefreshTTL.
+func (mr *MocktokenRefresherMockRecorder) RefreshTTL() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshTTL", reflect.TypeOf((*MocktokenRefresher)(nil).RefreshTTL))
+}
@@ -0,0 +1,120 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/handlers/users.go
+
+// Package mock_handlers is a generated GoMock package.
+package mock_handlers
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/patrick-devel/shorturl/internal/models"
+	tokens "github.com/patrick-devel/shorturl/internal/tokens"
+)
+
+// MockuserService is a mock of userService interface.
+type MockuserService struct {
+	ctrl     *gomock.Controller
+	recorder *MockuserServiceMockRecorder
+}
+
+// MockuserServiceMockRecorder is the mock recorder for MockuserService.
+type MockuserServiceMockRecorder struct {
+	mock *MockuserService
+}
+
+// NewMockuserService creates a new mock instance.
+func NewMockuserService(ctrl *gomock.Controller) *MockuserService {
+	mock := &MockuserService{ctrl: ctrl}
+	mock.recorder = &MockuserServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockuserService) EXPECT() *MockuserServiceMockRecorder {
+	return m.recorder
+}
+
+// Login mocks base method.
+func (m *MockuserService) Login(ctx context.Context, request models.Credentials) (models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Login", ctx, request)
+	ret0, _ := ret[0].(models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Login indicates an expected call of Login.
+func (mr *MockuserServiceMockRecorder) Login(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MockuserService)(nil).Login), ctx, request)
+}
+
+// Register mocks base method.
+func (m *MockuserService) Register(ctx context.Context, request models.Credentials) (models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Register", ctx, request)
+	ret0, _ := ret[0].(models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Register indicates an expected call of Register.
+func (mr *MockuserServiceMockRecorder) Register(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MockuserService)(nil).Register), ctx, request)
+}
+
+// MocksessionIssuer is a mock of sessionIssuer interface.
+type MocksessionIssuer struct {
+	ctrl     *gomock.Controller
+	recorder *MocksessionIssuerMockRecorder
+}
+
+// MocksessionIssuerMockRecorder is the mock recorder for MocksessionIssuer.
+type MocksessionIssuerMockRecorder struct {
+	mock *MocksessionIssuer
+}
+
+// NewMocksessionIssuer creates a new mock instance.
+func NewMocksessionIssuer(ctrl *gomock.Controller) *MocksessionIssuer {
+	mock := &MocksessionIssuer{ctrl: ctrl}
+	mock.recorder = &MocksessionIssuerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MocksessionIssuer) EXPECT() *MocksessionIssuerMockRecorder {
+	return m.recorder
+}
+
+// Issue mocks base method.
+func (m *MocksessionIssuer) Issue(uid string) (tokens.Pair, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Issue", uid)
+	ret0, _ := ret[0].(tokens.Pair)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Issue indicates an expected call of Issue.
+func (mr *MocksessionIssuerMockRecorder) Issue(uid interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Issue", reflect.TypeOf((*MocksessionIssuer)(nil).Issue), uid)
+}
+
+// RefreshTTL mocks base method.
+func (m *MocksessionIssuer) RefreshTTL() time.Duration {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RefreshTTL")
+	ret0, _ := ret[0].(time.Duration)
+	return ret0
+}
+
+// RefreshTTL indicates an expected call of RefreshTTL.
+func (mr *MocksessionIssuerMockRecorder) RefreshTTL() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RefreshTTL", reflect.TypeOf((*MocksessionIssuer)(nil).RefreshTTL))
+}
@@ -0,0 +1,66 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/handlers/variants.go
+
+// Package mock_handlers is a generated GoMock package.
+package mock_handlers
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/patrick-devel/shorturl/internal/models"
+)
+
+// MockvariantService is a mock of variantService interface.
+type MockvariantService struct {
+	ctrl     *gomock.Controller
+	recorder *MockvariantServiceMockRecorder
+}
+
+// MockvariantServiceMockRecorder is the mock recorder for MockvariantService.
+type MockvariantServiceMockRecorder struct {
+	mock *MockvariantService
+}
+
+// NewMockvariantService creates a new mock instance.
+func NewMockvariantService(ctrl *gomock.Controller) *MockvariantService {
+	mock := &MockvariantService{ctrl: ctrl}
+	mock.recorder = &MockvariantServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockvariantService) EXPECT() *MockvariantServiceMockRecorder {
+	return m.recorder
+}
+
+// ClickStats mocks base method.
+func (m *MockvariantService) ClickStats(ctx context.Context, hash string) (models.ClickStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClickStats", ctx, hash)
+	ret0, _ := ret[0].(models.ClickStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClickStats indicates an expected call of ClickStats.
+func (mr *MockvariantServiceMockRecorder) ClickStats(ctx, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClickStats", reflect.TypeOf((*MockvariantService)(nil).ClickStats), ctx, hash)
+}
+
+// SetVariants mocks base method.
+func (m *MockvariantService) SetVariants(ctx context.Context, hash string, variants []models.Variant, sticky bool) ([]models.Variant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetVariants", ctx, hash, variants, sticky)
+	ret0, _ := ret[0].([]models.Variant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetVariants indicates an expected call of SetVariants.
+func (mr *MockvariantServiceMockRecorder) SetVariants(ctx, hash, variants, sticky interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetVariants", reflect.TypeOf((*MockvariantService)(nil).SetVariants), ctx, hash, variants, sticky)
+}
@@ -0,0 +1,125 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/handlers/workspaces.go
+
+// Package mock_handlers is a generated GoMock package.
+package mock_handlers
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/patrick-devel/shorturl/internal/models"
+)
+
+// MockworkspaceService is a mock of workspaceService interface.
+type MockworkspaceService struct {
+	ctrl     *gomock.Controller
+	recorder *MockworkspaceServiceMockRecorder
+}
+
+// MockworkspaceServiceMockRecorder is the mock recorder for MockworkspaceService.
+type MockworkspaceServiceMockRecorder struct {
+	mock *MockworkspaceService
+}
+
+// NewMockworkspaceService creates a new mock instance.
+func NewMockworkspaceService(ctrl *gomock.Controller) *MockworkspaceService {
+	mock := &MockworkspaceService{ctrl: ctrl}
+	mock.recorder = &MockworkspaceServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockworkspaceService) EXPECT() *MockworkspaceServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateWorkspace mocks base method.
+func (m *MockworkspaceService) CreateWorkspace(ctx context.Context, request models.RequestWorkspace) (models.Workspace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWorkspace", ctx, request)
+	ret0, _ := ret[0].(models.Workspace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWorkspace indicates an expected call of CreateWorkspace.
+func (mr *MockworkspaceServiceMockRecorder) CreateWorkspace(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWorkspace", reflect.TypeOf((*MockworkspaceService)(nil).CreateWorkspace), ctx, request)
+}
+
+// RemoveWorkspaceMember mocks base method.
+func (m *MockworkspaceService) RemoveWorkspaceMember(ctx context.Context, workspaceID, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveWorkspaceMember", ctx, workspaceID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveWorkspaceMember indicates an expected call of RemoveWorkspaceMember.
+func (mr *MockworkspaceServiceMockRecorder) RemoveWorkspaceMember(ctx, workspaceID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveWorkspaceMember", reflect.TypeOf((*MockworkspaceService)(nil).RemoveWorkspaceMember), ctx, workspaceID, userID)
+}
+
+// SetLinkWorkspace mocks base method.
+func (m *MockworkspaceService) SetLinkWorkspace(ctx context.Context, hash string, request models.RequestLinkWorkspace) (models.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetLinkWorkspace", ctx, hash, request)
+	ret0, _ := ret[0].(models.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetLinkWorkspace indicates an expected call of SetLinkWorkspace.
+func (mr *MockworkspaceServiceMockRecorder) SetLinkWorkspace(ctx, hash, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLinkWorkspace", reflect.TypeOf((*MockworkspaceService)(nil).SetLinkWorkspace), ctx, hash, request)
+}
+
+// SetWorkspaceMember mocks base method.
+func (m *MockworkspaceService) SetWorkspaceMember(ctx context.Context, workspaceID, userID string, request models.RequestWorkspaceMember) (models.WorkspaceMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetWorkspaceMember", ctx, workspaceID, userID, request)
+	ret0, _ := ret[0].(models.WorkspaceMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetWorkspaceMember indicates an expected call of SetWorkspaceMember.
+func (mr *MockworkspaceServiceMockRecorder) SetWorkspaceMember(ctx, workspaceID, userID, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWorkspaceMember", reflect.TypeOf((*MockworkspaceService)(nil).SetWorkspaceMember), ctx, workspaceID, userID, request)
+}
+
+// WorkspaceMembers mocks base method.
+func (m *MockworkspaceService) WorkspaceMembers(ctx context.Context, workspaceID string) ([]models.WorkspaceMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WorkspaceMembers", ctx, workspaceID)
+	ret0, _ := ret[0].([]models.WorkspaceMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WorkspaceMembers indicates an expected call of WorkspaceMembers.
+func (mr *MockworkspaceServiceMockRecorder) WorkspaceMembers(ctx, workspaceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WorkspaceMembers", reflect.TypeOf((*MockworkspaceService)(nil).WorkspaceMembers), ctx, workspaceID)
+}
+
+// Workspaces mocks base method.
+func (m *MockworkspaceService) Workspaces(ctx context.Context) ([]models.Workspace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Workspaces", ctx)
+	ret0, _ := ret[0].([]models.Workspace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Workspaces indicates an expected call of Workspaces.
+func (mr *MockworkspaceServiceMockRecorder) Workspaces(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Workspaces", reflect.TypeOf((*MockworkspaceService)(nil).Workspaces), ctx)
+}
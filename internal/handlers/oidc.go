@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/patrick-devel/shorturl/internal/models"
+	"github.com/patrick-devel/shorturl/internal/sso"
+)
+
+const (
+	flowCookie     = "oidc_flow"
+	flowCookiePath = "/api/auth/oidc"
+	// flowTTL - сколько пользователь может провести на странице провайдера.
+	flowTTL = 10 * time.Minute
+)
+
+type oidcProvider interface {
+	Begin(ctx context.Context) (string, sso.Flow, error)
+	Finish(ctx context.Context, flow sso.Flow, code string) (models.Identity, error)
+}
+
+type identityService interface {
+	LoginWithIdentity(ctx context.Context, identity models.Identity) (models.User, error)
+}
+
+// OIDCLogin отправляет браузер к провайдеру. State, nonce и PKCE verifier ждут возврата в cookie.
+func OIDCLogin(provider oidcProvider) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authURL, flow, err := provider.Begin(c.Request.Context())
+		if err != nil {
+			abortWithError(c, err)
+
+			return
+		}
+
+		// Lax: cookie нужна на callback, куда браузер приходит редиректом с сайта провайдера
+		c.SetSameSite(http.SameSiteLaxMode)
+		c.SetCookie(flowCookie, flow.Encode(), int(flowTTL.Seconds()), flowCookiePath, "", false, true)
+		c.Redirect(http.StatusFound, authURL)
+	}
+}
+
+// OIDCCallback завершает вход: сверяет state, меняет код на ID-токен и выдает токены пользователя, как Login.
+func OIDCCallback(provider oidcProvider, service identityService, issuer sessionIssuer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, _ := c.Cookie(flowCookie)
+		// flow одноразовый, повторить callback с тем же кодом нельзя
+		c.SetCookie(flowCookie, "", -1, flowCookiePath, "", false, true)
+
+		flow, err := sso.DecodeFlow(value)
+		if err != nil || c.Query("state") != flow.State {
+			abortWithError(c, fmt.Errorf("%w: state does not match, start login again", sso.ErrInvalidFlow))
+
+			return
+		}
+		if reason := c.Query("error"); reason != "" {
+			abortWithError(c, fmt.Errorf("%w: %s", sso.ErrRejected, reason))
+
+			return
+		}
+		code := c.Query("code")
+		if code == "" {
+			abortWithError(c, fmt.Errorf("%w: code is missing", sso.ErrInvalidFlow))
+
+			return
+		}
+
+		identity, err := provider.Finish(c.Request.Context(), flow, code)
+		if err != nil {
+			abortWithError(c, err)
+
+			return
+		}
+
+		user, err := service.LoginWithIdentity(c.Copy(), identity)
+		if err != nil {
+			abortWithError(c, err)
+
+			return
+		}
+
+		startSession(c, user, issuer, http.StatusOK)
+	}
+}
@@ -0,0 +1,220 @@
+package handlers_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/patrick-devel/shorturl/internal/handlers"
+	mockhandlers "github.com/patrick-devel/shorturl/internal/handlers/mocks"
+	"github.com/patrick-devel/shorturl/internal/models"
+	"github.com/patrick-devel/shorturl/internal/problem"
+	"github.com/patrick-devel/shorturl/internal/sso"
+	"github.com/patrick-devel/shorturl/internal/tokens"
+)
+
+// unreachableIssuer - настоящая ошибка провайдера, до которого не достучаться: внутри нее *url.Error.
+func unreachableIssuer(t *testing.T) error {
+	t.Helper()
+
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	_, _, err := sso.New(sso.Config{IssuerURL: closed.URL, ClientID: "client"}).Begin(context.Background())
+	require.ErrorIs(t, err, sso.ErrUnavailable)
+
+	return err
+}
+
+func TestOIDCLogin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	flow := sso.Flow{State: "state", Nonce: "nonce", Verifier: "verifier"}
+
+	tests := []struct {
+		name        string
+		err         error
+		expCode     int
+		expLocation string
+	}{
+		{
+			name:        "Redirect",
+			expCode:     http.StatusFound,
+			expLocation: "https://idp.example.com/authorize?state=state",
+		},
+		{
+			name:    "ProviderUnavailable",
+			err:     unreachableIssuer(t),
+			expCode: http.StatusBadGateway,
+		},
+	}
+
+	for _, tc := range tests {
+		testcase := tc
+		t.Run(testcase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			provider := mockhandlers.NewMockoidcProvider(ctrl)
+			provider.EXPECT().Begin(gomock.Any()).Return(testcase.expLocation, flow, testcase.err)
+
+			router := gin.New()
+			router.GET("/api/auth/oidc/login", handlers.OIDCLogin(provider))
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/auth/oidc/login", nil))
+
+			require.Equal(t, testcase.expCode, w.Code)
+			assert.Equal(t, testcase.expLocation, w.Header().Get("Location"))
+			if testcase.err != nil {
+				assert.Contains(t, w.Body.String(), `"code":"`+problem.CodeSSOUnavailable+`"`)
+				assert.Empty(t, w.Header().Get("Set-Cookie"))
+
+				return
+			}
+
+			cookie := w.Result().Cookies()[0]
+			assert.Equal(t, "oidc_flow", cookie.Name)
+			assert.Equal(t, flow.Encode(), cookie.Value)
+			assert.Equal(t, "/api/auth/oidc", cookie.Path)
+			assert.True(t, cookie.HttpOnly)
+			assert.Equal(t, http.SameSiteLaxMode, cookie.SameSite)
+		})
+	}
+}
+
+func TestOIDCCallback(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	flow := sso.Flow{State: "state", Nonce: "nonce", Verifier: "verifier"}
+	identity := models.Identity{Issuer: "https://idp.example.com", Subject: "subject-1", Email: "user@example.com"}
+	user := models.User{ID: "u1", Email: identity.Email, CreatedAt: time.Now().UTC()}
+	pair := tokens.Pair{Access: "a1", Refresh: "r1", ExpiresIn: 15 * time.Minute}
+
+	tests := []struct {
+		name       string
+		query      string
+		cookie     string
+		finish     bool
+		finishErr  error
+		loginErr   error
+		expCode    int
+		expProblem string
+	}{
+		{
+			name:    "OK",
+			query:   "?state=state&code=code",
+			cookie:  flow.Encode(),
+			finish:  true,
+			expCode: http.StatusOK,
+		},
+		{
+			name:       "NoCookie",
+			query:      "?state=state&code=code",
+			expCode:    http.StatusBadRequest,
+			expProblem: problem.CodeInvalidRequest,
+		},
+		{
+			name:       "StateMismatch",
+			query:      "?state=forged&code=code",
+			cookie:     flow.Encode(),
+			expCode:    http.StatusBadRequest,
+			expProblem: problem.CodeInvalidRequest,
+		},
+		{
+			name:       "NoCode",
+			query:      "?state=state",
+			cookie:     flow.Encode(),
+			expCode:    http.StatusBadRequest,
+			expProblem: problem.CodeInvalidRequest,
+		},
+		{
+			name:       "AccessDenied",
+			query:      "?state=state&error=access_denied",
+			cookie:     flow.Encode(),
+			expCode:    http.StatusUnauthorized,
+			expProblem: problem.CodeUnauthorized,
+		},
+		{
+			name:       "TokenRejected",
+			query:      "?state=state&code=code",
+			cookie:     flow.Encode(),
+			finish:     true,
+			finishErr:  sso.ErrRejected,
+			expCode:    http.StatusUnauthorized,
+			expProblem: problem.CodeUnauthorized,
+		},
+		{
+			name:       "ProviderUnavailable",
+			query:      "?state=state&code=code",
+			cookie:     flow.Encode(),
+			finish:     true,
+			finishErr:  unreachableIssuer(t),
+			expCode:    http.StatusBadGateway,
+			expProblem: problem.CodeSSOUnavailable,
+		},
+		{
+			name:       "LoginFailed",
+			query:      "?state=state&code=code",
+			cookie:     flow.Encode(),
+			finish:     true,
+			loginErr:   errors.New("db is down"),
+			expCode:    http.StatusInternalServerError,
+			expProblem: problem.CodeInternal,
+		},
+	}
+
+	for _, tc := range tests {
+		testcase := tc
+		t.Run(testcase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			provider := mockhandlers.NewMockoidcProvider(ctrl)
+			identities := mockhandlers.NewMockidentityService(ctrl)
+			issuer := mockhandlers.NewMocksessionIssuer(ctrl)
+
+			if testcase.finish {
+				provider.EXPECT().Finish(gomock.Any(), flow, "code").Return(identity, testcase.finishErr)
+				if testcase.finishErr == nil {
+					identities.EXPECT().LoginWithIdentity(gomock.Any(), identity).Return(user, testcase.loginErr)
+				}
+				if testcase.finishErr == nil && testcase.loginErr == nil {
+					issuer.EXPECT().Issue(user.ID).Return(pair, nil)
+					issuer.EXPECT().RefreshTTL().Return(time.Hour)
+				}
+			}
+
+			router := gin.New()
+			router.GET("/api/auth/oidc/callback", handlers.OIDCCallback(provider, identities, issuer))
+
+			req := httptest.NewRequest(http.MethodGet, "/api/auth/oidc/callback"+testcase.query, nil)
+			if testcase.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: "oidc_flow", Value: testcase.cookie})
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, testcase.expCode, w.Code)
+			// cookie с flow стирается при любом исходе
+			assert.Contains(t, w.Header().Values("Set-Cookie")[0], "oidc_flow=;")
+			if testcase.expProblem != "" {
+				assert.Contains(t, w.Body.String(), `"code":"`+testcase.expProblem+`"`)
+				assert.Empty(t, w.Header().Get("Authorization"))
+
+				return
+			}
+
+			assert.Equal(t, pair.Access, w.Header().Get("Authorization"))
+			assert.Contains(t, w.Body.String(), `"email":"user@example.com"`)
+		})
+	}
+}
@@ -0,0 +1,801 @@
+package handlers_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/patrick-devel/shorturl/api"
+	"github.com/patrick-devel/shorturl/internal/domains"
+	"github.com/patrick-devel/shorturl/internal/models"
+	"github.com/patrick-devel/shorturl/internal/router"
+	mockrouter "github.com/patrick-devel/shorturl/internal/router/mocks"
+	"github.com/patrick-devel/shorturl/internal/service"
+	"github.com/patrick-devel/shorturl/internal/sso"
+	"github.com/patrick-devel/shorturl/internal/storage"
+	"github.com/patrick-devel/shorturl/internal/stream"
+	"github.com/patrick-devel/shorturl/internal/tokens"
+)
+
+type contractMocks struct {
+	service *mockrouter.MocklinkService
+	oidc    *mockrouter.MockoidcProvider
+}
+
+// contractRouter - настоящий роутер приложения с моками вместо сервиса и OIDC-провайдера.
+func contractRouter(t *testing.T, m contractMocks, issuer *tokens.Issuer) *gin.Engine {
+	t.Helper()
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	mux, err := router.New(m.service, stream.NewHub(0), issuer, logger, router.WithOIDC(m.oidc))
+	require.NoError(t, err)
+
+	return mux
+}
+
+func TestHandlersMatchOpenAPI(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// в kin-openapi нет декодеров для этих типов, тело проверяем как строку
+	for _, contentType := range []string{"text/html", "text/event-stream", "image/svg+xml"} {
+		openapi3filter.RegisterBodyDecoder(contentType, openapi3filter.PlainBodyDecoder)
+	}
+
+	doc, err := api.Load()
+	require.NoError(t, err)
+	specRouter, err := gorillamux.NewRouter(doc)
+	require.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	m := contractMocks{
+		service: mockrouter.NewMocklinkService(ctrl),
+		oidc:    mockrouter.NewMockoidcProvider(ctrl),
+	}
+	// ссылки ищутся по домену из Host, в тесте все они на основном
+	m.service.EXPECT().ResolveDomain(gomock.Any()).Return("").AnyTimes()
+
+	keys := []tokens.Key{tokens.NewKey("test-secret")}
+	issuer, err := tokens.New(keys)
+	require.NoError(t, err)
+	session, err := issuer.Issue("user")
+	require.NoError(t, err)
+	// пара, выданная два месяца назад: refresh-токен уже истек
+	staleIssuer, err := tokens.New(keys, tokens.WithClock(func() time.Time { return time.Now().AddDate(0, -2, 0) }))
+	require.NoError(t, err)
+	stale, err := staleIssuer.Issue("user")
+	require.NoError(t, err)
+
+	mux := contractRouter(t, m, issuer)
+
+	shortURL := "http://localhost:8080/abc"
+	event := models.Event{ShortURL: shortURL, OriginalURL: "https://practicum.yandex.ru/", UUID: "1"}
+
+	tests := []struct {
+		name     string
+		method   string
+		target   string
+		header   map[string]string
+		body     string
+		mockExec func()
+		expCode  int
+	}{
+		{
+			name:   "ShortenText",
+			method: http.MethodPost,
+			target: "/",
+			header: map[string]string{"Content-Type": "text/plain"},
+			body:   "https://practicum.yandex.ru/",
+			mockExec: func() {
+				m.service.EXPECT().MakeShortURL(gomock.Any(), gomock.Any(), "", gomock.Any()).Return(shortURL, nil)
+			},
+			expCode: http.StatusCreated,
+		},
+		{
+			name:   "ShortenTextDuplicate",
+			method: http.MethodPost,
+			target: "/",
+			header: map[string]string{"Content-Type": "application/x-gzip"},
+			body:   "https://practicum.yandex.ru/",
+			mockExec: func() {
+				m.service.EXPECT().MakeShortURL(gomock.Any(), gomock.Any(), "", gomock.Any()).Return(shortURL, storage.ErrDuplicateURL)
+			},
+			expCode: http.StatusConflict,
+		},
+		{
+			name:   "Redirect",
+			method: http.MethodGet,
+			target: "/abc",
+			mockExec: func() {
+				m.service.EXPECT().GetOriginalURL(gomock.Any(), "abc", gomock.Any()).
+					Return(models.Redirect{URL: event.OriginalURL, StatusCode: http.StatusTemporaryRedirect}, nil)
+			},
+			expCode: http.StatusTemporaryRedirect,
+		},
+		{
+			name:   "RedirectGone",
+			method: http.MethodGet,
+			target: "/abc",
+			mockExec: func() {
+				m.service.EXPECT().GetOriginalURL(gomock.Any(), "abc", gomock.Any()).Return(models.Redirect{}, storage.ErrEventDeleted)
+			},
+			expCode: http.StatusGone,
+		},
+		{
+			name:   "PreviewJSON",
+			method: http.MethodGet,
+			target: "/abc+",
+			header: map[string]string{"Accept": "application/json"},
+			mockExec: func() {
+				m.service.EXPECT().PreviewLink(gomock.Any(), "abc").
+					Return(models.Preview{ShortURL: shortURL, OriginalURL: event.OriginalURL, Clicks: 3}, nil)
+			},
+			expCode: http.StatusOK,
+		},
+		{
+			name:   "PreviewHTML",
+			method: http.MethodGet,
+			target: "/abc?preview=1",
+			mockExec: func() {
+				m.service.EXPECT().PreviewLink(gomock.Any(), "abc").
+					Return(models.Preview{ShortURL: shortURL, OriginalURL: event.OriginalURL}, nil)
+			},
+			expCode: http.StatusOK,
+		},
+		{
+			name:   "QRCode",
+			method: http.MethodGet,
+			target: "/abc/qr?format=svg",
+			mockExec: func() {
+				m.service.EXPECT().GetShortLink(gomock.Any(), "abc").Return(event, nil)
+			},
+			expCode: http.StatusOK,
+		},
+		{
+			name:    "QRCodeBadOptions",
+			method:  http.MethodGet,
+			target:  "/abc/qr?size=1",
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:    "Ping",
+			method:  http.MethodGet,
+			target:  "/ping",
+			expCode: http.StatusOK,
+		},
+		{
+			name:    "OpenAPI",
+			method:  http.MethodGet,
+			target:  "/api/openapi.json",
+			expCode: http.StatusOK,
+		},
+		{
+			name:   "Shorten",
+			method: http.MethodPost,
+			target: "/api/shorten",
+			header: map[string]string{"Content-Type": "application/json"},
+			body:   `{"url": "https://practicum.yandex.ru/", "utm": {"source": "news"}}`,
+			mockExec: func() {
+				m.service.EXPECT().MakeShortURL(gomock.Any(), event.OriginalURL, "", gomock.Any()).Return(shortURL, nil)
+			},
+			expCode: http.StatusCreated,
+		},
+		{
+			name:   "ShortenOnDomain",
+			method: http.MethodPost,
+			target: "/api/shorten",
+			header: map[string]string{"Content-Type": "application/json"},
+			body:   `{"url": "https://practicum.yandex.ru/", "domain": "go.example.com"}`,
+			mockExec: func() {
+				m.service.EXPECT().MakeShortURL(gomock.Any(), event.OriginalURL, "", models.LinkOptions{Domain: "go.example.com"}).
+					Return("http://go.example.com/abc", nil)
+			},
+			expCode: http.StatusCreated,
+		},
+		{
+			name:   "ShortenUnknownDomain",
+			method: http.MethodPost,
+			target: "/api/shorten",
+			header: map[string]string{"Content-Type": "application/json"},
+			body:   `{"url": "https://practicum.yandex.ru/", "domain": "evil.example.com"}`,
+			mockExec: func() {
+				m.service.EXPECT().MakeShortURL(gomock.Any(), event.OriginalURL, "", gomock.Any()).Return("", domains.ErrUnknownDomain)
+			},
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:   "ShortenQuotaExceeded",
+			method: http.MethodPost,
+			target: "/api/shorten",
+			header: map[string]string{"Content-Type": "application/json"},
+			body:   `{"url": "https://practicum.yandex.ru/"}`,
+			mockExec: func() {
+				m.service.EXPECT().MakeShortURL(gomock.Any(), event.OriginalURL, "", gomock.Any()).Return("", service.ErrLinkQuotaExceeded)
+			},
+			expCode: http.StatusForbidden,
+		},
+		{
+			name:   "ShortenDuplicate",
+			method: http.MethodPost,
+			target: "/api/shorten",
+			header: map[string]string{"Content-Type": "application/json"},
+			body:   `{"url": "https://practicum.yandex.ru/"}`,
+			mockExec: func() {
+				m.service.EXPECT().MakeShortURL(gomock.Any(), event.OriginalURL, "", gomock.Any()).Return(shortURL, storage.ErrDuplicateURL)
+			},
+			expCode: http.StatusConflict,
+		},
+		{
+			name:   "ShortenInvalidOptions",
+			method: http.MethodPost,
+			target: "/api/shorten",
+			header: map[string]string{"Content-Type": "application/json"},
+			body:   `{"url": "https://practicum.yandex.ru/", "passthrough": "all"}`,
+			mockExec: func() {
+				m.service.EXPECT().MakeShortURL(gomock.Any(), event.OriginalURL, "", gomock.Any()).Return("", service.ErrInvalidPassthrough)
+			},
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:   "ShortenBatch",
+			method: http.MethodPost,
+			target: "/api/shorten/batch",
+			header: map[string]string{"Content-Type": "application/json"},
+			body:   `[{"correlation_id": "1", "original_url": "https://practicum.yandex.ru/"}]`,
+			mockExec: func() {
+				m.service.EXPECT().MakeShortURLs(gomock.Any(), gomock.Any()).Return([]models.Event{event}, nil)
+			},
+			expCode: http.StatusCreated,
+		},
+		{
+			name:   "ShortenBatchDuplicate",
+			method: http.MethodPost,
+			target: "/api/shorten/batch",
+			header: map[string]string{"Content-Type": "application/json"},
+			body:   `[{"correlation_id": "1", "original_url": "https://practicum.yandex.ru/", "redirect_code": 301}]`,
+			mockExec: func() {
+				duplicate := event
+				duplicate.Duplicate = true
+				m.service.EXPECT().MakeShortURLs(gomock.Any(), gomock.Any()).Return([]models.Event{duplicate}, nil)
+			},
+			expCode: http.StatusCreated,
+		},
+		{
+			name:    "RefreshTokens",
+			method:  http.MethodPost,
+			target:  "/api/auth/refresh",
+			header:  map[string]string{"Content-Type": "application/json"},
+			body:    `{"refresh_token": "` + session.Refresh + `"}`,
+			expCode: http.StatusOK,
+		},
+		{
+			name:    "RefreshTokensExpired",
+			method:  http.MethodPost,
+			target:  "/api/auth/refresh",
+			header:  map[string]string{"Cookie": "user_id_refresh=" + stale.Refresh},
+			expCode: http.StatusUnauthorized,
+		},
+		{
+			name:   "Register",
+			method: http.MethodPost,
+			target: "/api/auth/register",
+			header: map[string]string{"Content-Type": "application/json"},
+			body:   `{"email": "user@example.com", "password": "correct horse"}`,
+			mockExec: func() {
+				m.service.EXPECT().Register(gomock.Any(), models.Credentials{Email: "user@example.com", Password: "correct horse"}).
+					Return(models.User{ID: "u1", Email: "user@example.com", CreatedAt: time.Now()}, nil)
+			},
+			expCode: http.StatusCreated,
+		},
+		{
+			name:   "RegisterEmailTaken",
+			method: http.MethodPost,
+			target: "/api/auth/register",
+			header: map[string]string{"Content-Type": "application/json"},
+			body:   `{"email": "user@example.com", "password": "correct horse"}`,
+			mockExec: func() {
+				m.service.EXPECT().Register(gomock.Any(), gomock.Any()).Return(models.User{}, service.ErrEmailTaken)
+			},
+			expCode: http.StatusConflict,
+		},
+		{
+			name:   "RegisterInvalid",
+			method: http.MethodPost,
+			target: "/api/auth/register",
+			header: map[string]string{"Content-Type": "application/json"},
+			body:   `{"email": "not an email", "password": "correct horse"}`,
+			mockExec: func() {
+				m.service.EXPECT().Register(gomock.Any(), gomock.Any()).Return(models.User{}, service.ErrInvalidAccount)
+			},
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:   "Login",
+			method: http.MethodPost,
+			target: "/api/auth/login",
+			header: map[string]string{"Content-Type": "application/json"},
+			body:   `{"email": "user@example.com", "password": "correct horse"}`,
+			mockExec: func() {
+				m.service.EXPECT().Login(gomock.Any(), gomock.Any()).
+					Return(models.User{ID: "u1", Email: "user@example.com", CreatedAt: time.Now()}, nil)
+			},
+			expCode: http.StatusOK,
+		},
+		{
+			name:   "LoginWrongPassword",
+			method: http.MethodPost,
+			target: "/api/auth/login",
+			header: map[string]string{"Content-Type": "application/json"},
+			body:   `{"email": "user@example.com", "password": "wrong password"}`,
+			mockExec: func() {
+				m.service.EXPECT().Login(gomock.Any(), gomock.Any()).Return(models.User{}, service.ErrInvalidCredentials)
+			},
+			expCode: http.StatusUnauthorized,
+		},
+		{
+			name:   "OIDCLogin",
+			method: http.MethodGet,
+			target: "/api/auth/oidc/login",
+			mockExec: func() {
+				m.oidc.EXPECT().Begin(gomock.Any()).
+					Return("https://idp.example.com/authorize", sso.Flow{State: "s", Nonce: "n", Verifier: "v"}, nil)
+			},
+			expCode: http.StatusFound,
+		},
+		{
+			name:   "OIDCCallback",
+			method: http.MethodGet,
+			target: "/api/auth/oidc/callback?state=s&code=c",
+			header: map[string]string{"Cookie": "oidc_flow=s.n.v"},
+			mockExec: func() {
+				identity := models.Identity{Issuer: "https://idp.example.com", Subject: "subject-1"}
+				m.oidc.EXPECT().Finish(gomock.Any(), sso.Flow{State: "s", Nonce: "n", Verifier: "v"}, "c").Return(identity, nil)
+				m.service.EXPECT().LoginWithIdentity(gomock.Any(), identity).
+					Return(models.User{ID: "u1", CreatedAt: time.Now()}, nil)
+			},
+			expCode: http.StatusOK,
+		},
+		{
+			name:   "OIDCCallbackUnavailable",
+			method: http.MethodGet,
+			target: "/api/auth/oidc/callback?state=s&code=c",
+			header: map[string]string{"Cookie": "oidc_flow=s.n.v"},
+			mockExec: func() {
+				m.oidc.EXPECT().Finish(gomock.Any(), gomock.Any(), "c").Return(models.Identity{}, sso.ErrUnavailable)
+			},
+			expCode: http.StatusBadGateway,
+		},
+		{
+			name:    "OIDCCallbackStateMismatch",
+			method:  http.MethodGet,
+			target:  "/api/auth/oidc/callback?state=forged&code=c",
+			header:  map[string]string{"Cookie": "oidc_flow=s.n.v"},
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:    "OIDCCallbackDenied",
+			method:  http.MethodGet,
+			target:  "/api/auth/oidc/callback?state=s&error=access_denied",
+			header:  map[string]string{"Cookie": "oidc_flow=s.n.v"},
+			expCode: http.StatusUnauthorized,
+		},
+		{
+			name:   "ListUserURLs",
+			method: http.MethodGet,
+			target: "/api/user/urls?campaign=spring",
+			mockExec: func() {
+				m.service.EXPECT().LinksByCreatorID(gomock.Any(), models.LinkFilter{Campaign: "spring"}).Return([]models.Event{event}, nil)
+			},
+			expCode: http.StatusOK,
+		},
+		{
+			name:   "ListUserURLsEmpty",
+			method: http.MethodGet,
+			target: "/api/user/urls",
+			mockExec: func() {
+				m.service.EXPECT().LinksByCreatorID(gomock.Any(), gomock.Any()).Return(nil, nil)
+			},
+			expCode: http.StatusNoContent,
+		},
+		{
+			name:   "DeleteUserURLs",
+			method: http.MethodDelete,
+			target: "/api/user/urls",
+			header: map[string]string{"Content-Type": "application/json"},
+			body:   `["abc"]`,
+			mockExec: func() {
+				m.service.EXPECT().DeleteShortURL(gomock.Any(), []string{"abc"}).Return(nil).AnyTimes()
+			},
+			expCode: http.StatusAccepted,
+		},
+		{
+			name:   "BrokenURLs",
+			method: http.MethodGet,
+			target: "/api/user/urls/broken",
+			mockExec: func() {
+				m.service.EXPECT().BrokenLinks(gomock.Any()).Return([]models.ResponseBrokenURL{{
+					ShortURL:    shortURL,
+					OriginalURL: event.OriginalURL,
+					LinkHealth:  models.LinkHealth{StatusCode: http.StatusNotFound, CheckedAt: time.Now()},
+				}}, nil)
+			},
+			expCode: http.StatusOK,
+		},
+		{
+			name:    "Stream",
+			method:  http.MethodGet,
+			target:  "/api/user/urls/stream",
+			expCode: http.StatusOK,
+		},
+		{
+			name:   "QRCodeArchive",
+			method: http.MethodGet,
+			target: "/api/user/urls/qr.zip",
+			mockExec: func() {
+				m.service.EXPECT().LinksByCreatorID(gomock.Any(), gomock.Any()).Return([]models.Event{event}, nil)
+			},
+			expCode: http.StatusOK,
+		},
+		{
+			name:   "GetTargeting",
+			method: http.MethodGet,
+			target: "/api/user/urls/abc/targeting",
+			mockExec: func() {
+				m.service.EXPECT().TargetingRules(gomock.Any(), "abc").Return(nil, nil)
+			},
+			expCode: http.StatusOK,
+		},
+		{
+			name:   "GetTargetingForeign",
+			method: http.MethodGet,
+			target: "/api/user/urls/abc/targeting",
+			mockExec: func() {
+				m.service.EXPECT().TargetingRules(gomock.Any(), "abc").Return(nil, service.ErrNotOwner)
+			},
+			expCode: http.StatusForbidden,
+		},
+		{
+			name:   "SetTargeting",
+			method: http.MethodPut,
+			target: "/api/user/urls/abc/targeting",
+			header: map[string]string{"Content-Type": "application/json"},
+			body:   `[{"device": "ios", "url": "https://apps.apple.com/app"}]`,
+			mockExec: func() {
+				m.service.EXPECT().SetTargetingRules(gomock.Any(), "abc", gomock.Any()).Return(nil)
+			},
+			expCode: http.StatusOK,
+		},
+		{
+			name:   "SetVariants",
+			method: http.MethodPut,
+			target: "/api/user/urls/abc/variants",
+			header: map[string]string{"Content-Type": "application/json"},
+			body:   `{"variants": [{"name": "a", "url": "https://example.com/a", "weight": 1}], "sticky": true}`,
+			mockExec: func() {
+				m.service.EXPECT().SetVariants(gomock.Any(), "abc", gomock.Any(), true).
+					Return([]models.Variant{{Name: "a", URL: "https://example.com/a", Weight: 1}}, nil)
+			},
+			expCode: http.StatusOK,
+		},
+		{
+			name:   "SetVariantsInvalid",
+			method: http.MethodPut,
+			target: "/api/user/urls/abc/variants",
+			header: map[string]string{"Content-Type": "application/json"},
+			body:   `{"variants": [{"url": "https://example.com/a", "weight": 1}]}`,
+			mockExec: func() {
+				m.service.EXPECT().SetVariants(gomock.Any(), "abc", gomock.Any(), false).Return(nil, service.ErrInvalidVariants)
+			},
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:   "ClickStats",
+			method: http.MethodGet,
+			target: "/api/user/urls/abc/stats",
+			mockExec: func() {
+				m.service.EXPECT().ClickStats(gomock.Any(), "abc").
+					Return(models.ClickStats{ShortURL: shortURL, Clicks: 2, Variants: map[string]int{"a": 2}}, nil)
+			},
+			expCode: http.StatusOK,
+		},
+		{
+			name:   "ClickStatsNotFound",
+			method: http.MethodGet,
+			target: "/api/user/urls/abc/stats",
+			mockExec: func() {
+				m.service.EXPECT().ClickStats(gomock.Any(), "abc").Return(models.ClickStats{}, storage.ErrNotFound)
+			},
+			expCode: http.StatusNotFound,
+		},
+		{
+			name:   "SetMeta",
+			method: http.MethodPut,
+			target: "/api/user/urls/abc/meta",
+			header: map[string]string{"Content-Type": "application/json"},
+			body:   `{"title": "Курсы", "open_graph": {"title": "Курсы"}}`,
+			mockExec: func() {
+				m.service.EXPECT().SetLinkMeta(gomock.Any(), "abc", gomock.Any()).
+					Return(models.LinkMeta{Title: "Курсы", OpenGraph: &models.OpenGraph{Title: "Курсы"}}, nil)
+			},
+			expCode: http.StatusOK,
+		},
+		{
+			name:   "CreateWebhook",
+			method: http.MethodPost,
+			target: "/api/user/webhooks",
+			header: map[string]string{"Content-Type": "application/json"},
+			body:   `{"url": "https://crm.example.com/hook", "events": ["link.created"]}`,
+			mockExec: func() {
+				m.service.EXPECT().CreateWebhook(gomock.Any(), gomock.Any()).
+					Return(models.Webhook{ID: "w1", URL: "https://crm.example.com/hook", Events: []string{"link.created"}, Secret: "s"}, nil)
+			},
+			expCode: http.StatusCreated,
+		},
+		{
+			name:   "ListWebhooks",
+			method: http.MethodGet,
+			target: "/api/user/webhooks",
+			mockExec: func() {
+				m.service.EXPECT().Webhooks(gomock.Any()).Return(nil, nil)
+			},
+			expCode: http.StatusOK,
+		},
+		{
+			name:   "DeleteWebhook",
+			method: http.MethodDelete,
+			target: "/api/user/webhooks/w1",
+			mockExec: func() {
+				m.service.EXPECT().DeleteWebhook(gomock.Any(), "w1").Return(nil)
+			},
+			expCode: http.StatusNoContent,
+		},
+		{
+			name:   "DeleteWebhookNotFound",
+			method: http.MethodDelete,
+			target: "/api/user/webhooks/w1",
+			mockExec: func() {
+				m.service.EXPECT().DeleteWebhook(gomock.Any(), "w1").Return(service.ErrWebhookNotFound)
+			},
+			expCode: http.StatusNotFound,
+		},
+		{
+			name:   "WebhookDeliveries",
+			method: http.MethodGet,
+			target: "/api/user/webhooks/w1/deliveries?status=dead",
+			mockExec: func() {
+				m.service.EXPECT().WebhookDeliveries(gomock.Any(), "w1", true).
+					Return([]models.WebhookDelivery{{ID: "d1", WebhookID: "w1", Event: "link.created", Attempt: 5, Dead: true}}, nil)
+			},
+			expCode: http.StatusOK,
+		},
+		{
+			name:   "CreateAPIKey",
+			method: http.MethodPost,
+			target: "/api/user/keys",
+			header: map[string]string{"Content-Type": "application/json"},
+			body:   `{"name": "crm", "scopes": ["shorten", "read"]}`,
+			mockExec: func() {
+				m.service.EXPECT().CreateAPIKey(gomock.Any(), models.RequestAPIKey{Name: "crm", Scopes: []string{"shorten", "read"}}).
+					Return(models.APIKey{ID: "k1", Name: "crm", Prefix: "sk_0123456", Key: "sk_0123456789", Scopes: []string{"shorten", "read"}}, nil)
+			},
+			expCode: http.StatusCreated,
+		},
+		{
+			name:   "ListAPIKeys",
+			method: http.MethodGet,
+			target: "/api/user/keys",
+			mockExec: func() {
+				used := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+				m.service.EXPECT().APIKeys(gomock.Any()).
+					Return([]models.APIKey{{ID: "k1", Prefix: "sk_0123456", Scopes: []string{}, LastUsedAt: &used}}, nil)
+			},
+			expCode: http.StatusOK,
+		},
+		{
+			name:   "DeleteAPIKey",
+			method: http.MethodDelete,
+			target: "/api/user/keys/k1",
+			mockExec: func() {
+				m.service.EXPECT().DeleteAPIKey(gomock.Any(), "k1").Return(nil)
+			},
+			expCode: http.StatusNoContent,
+		},
+		{
+			name:   "DeleteAPIKeyNotFound",
+			method: http.MethodDelete,
+			target: "/api/user/keys/k1",
+			mockExec: func() {
+				m.service.EXPECT().DeleteAPIKey(gomock.Any(), "k1").Return(service.ErrAPIKeyNotFound)
+			},
+			expCode: http.StatusNotFound,
+		},
+		{
+			name:   "GetQuota",
+			method: http.MethodGet,
+			target: "/api/user/quota",
+			mockExec: func() {
+				m.service.EXPECT().Quota(gomock.Any()).
+					Return(models.QuotaUsage{Quota: models.Quota{Links: 1000, BatchSize: 100}, LinksUsed: 3}, nil)
+			},
+			expCode: http.StatusOK,
+		},
+		{
+			name:   "ListDomains",
+			method: http.MethodGet,
+			target: "/api/domains",
+			mockExec: func() {
+				m.service.EXPECT().Domains().Return([]string{"localhost:8080", "go.example.com"})
+			},
+			expCode: http.StatusOK,
+		},
+		{
+			name:   "SetLinkWorkspace",
+			method: http.MethodPut,
+			target: "/api/user/urls/abc/workspace",
+			header: map[string]string{"Content-Type": "application/json"},
+			body:   `{"workspace_id": "ws1"}`,
+			mockExec: func() {
+				moved := event
+				moved.WorkspaceID = "ws1"
+				m.service.EXPECT().SetLinkWorkspace(gomock.Any(), "abc", models.RequestLinkWorkspace{WorkspaceID: "ws1"}).
+					Return(moved, nil)
+			},
+			expCode: http.StatusOK,
+		},
+		{
+			name:   "SetLinkWorkspaceForbidden",
+			method: http.MethodPut,
+			target: "/api/user/urls/abc/workspace",
+			header: map[string]string{"Content-Type": "application/json"},
+			body:   `{"workspace_id": "ws1"}`,
+			mockExec: func() {
+				m.service.EXPECT().SetLinkWorkspace(gomock.Any(), "abc", gomock.Any()).
+					Return(models.Event{}, service.ErrWorkspaceRole)
+			},
+			expCode: http.StatusForbidden,
+		},
+		{
+			name:   "CreateWorkspace",
+			method: http.MethodPost,
+			target: "/api/user/workspaces",
+			header: map[string]string{"Content-Type": "application/json"},
+			body:   `{"name": "Маркетинг"}`,
+			mockExec: func() {
+				m.service.EXPECT().CreateWorkspace(gomock.Any(), models.RequestWorkspace{Name: "Маркетинг"}).
+					Return(models.Workspace{ID: "ws1", Name: "Маркетинг", CreatedAt: time.Now(), Role: models.RoleOwner}, nil)
+			},
+			expCode: http.StatusCreated,
+		},
+		{
+			name:   "ListWorkspaces",
+			method: http.MethodGet,
+			target: "/api/user/workspaces",
+			mockExec: func() {
+				m.service.EXPECT().Workspaces(gomock.Any()).Return(nil, nil)
+			},
+			expCode: http.StatusOK,
+		},
+		{
+			name:   "ListWorkspaceMembers",
+			method: http.MethodGet,
+			target: "/api/user/workspaces/ws1/members",
+			mockExec: func() {
+				m.service.EXPECT().WorkspaceMembers(gomock.Any(), "ws1").
+					Return([]models.WorkspaceMember{{UserID: "user", Role: models.RoleOwner, AddedAt: time.Now()}}, nil)
+			},
+			expCode: http.StatusOK,
+		},
+		{
+			name:   "ListWorkspaceMembersNotFound",
+			method: http.MethodGet,
+			target: "/api/user/workspaces/ws1/members",
+			mockExec: func() {
+				m.service.EXPECT().WorkspaceMembers(gomock.Any(), "ws1").Return(nil, service.ErrWorkspaceNotFound)
+			},
+			expCode: http.StatusNotFound,
+		},
+		{
+			name:   "SetWorkspaceMember",
+			method: http.MethodPut,
+			target: "/api/user/workspaces/ws1/members/u2",
+			header: map[string]string{"Content-Type": "application/json"},
+			body:   `{"role": "editor"}`,
+			mockExec: func() {
+				m.service.EXPECT().SetWorkspaceMember(gomock.Any(), "ws1", "u2", models.RequestWorkspaceMember{Role: models.RoleEditor}).
+					Return(models.WorkspaceMember{UserID: "u2", Role: models.RoleEditor, AddedAt: time.Now()}, nil)
+			},
+			expCode: http.StatusOK,
+		},
+		{
+			name:   "DeleteWorkspaceMember",
+			method: http.MethodDelete,
+			target: "/api/user/workspaces/ws1/members/u2",
+			mockExec: func() {
+				m.service.EXPECT().RemoveWorkspaceMember(gomock.Any(), "ws1", "u2").Return(nil)
+			},
+			expCode: http.StatusNoContent,
+		},
+		{
+			name:   "DeleteLastWorkspaceOwner",
+			method: http.MethodDelete,
+			target: "/api/user/workspaces/ws1/members/user",
+			mockExec: func() {
+				m.service.EXPECT().RemoveWorkspaceMember(gomock.Any(), "ws1", "user").Return(service.ErrInvalidWorkspace)
+			},
+			expCode: http.StatusBadRequest,
+		},
+	}
+
+	covered := map[string]bool{}
+
+	for _, tc := range tests {
+		testcase := tc
+		t.Run(testcase.name, func(t *testing.T) {
+			if testcase.mockExec != nil {
+				testcase.mockExec()
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+
+			req := httptest.NewRequest(testcase.method, testcase.target, strings.NewReader(testcase.body)).WithContext(ctx)
+			if testcase.body == "" {
+				req.Body = http.NoBody
+			}
+			req.Header.Set("Authorization", session.Access)
+			for k, v := range testcase.header {
+				req.Header.Set(k, v)
+			}
+
+			route, pathParams, err := specRouter.FindRoute(req)
+			require.NoError(t, err, "route is not described in spec")
+			covered[route.Operation.OperationID] = true
+
+			requestInput := &openapi3filter.RequestValidationInput{
+				Request:    req,
+				PathParams: pathParams,
+				Route:      route,
+				Options:    &openapi3filter.Options{AuthenticationFunc: openapi3filter.NoopAuthenticationFunc},
+			}
+			require.NoError(t, openapi3filter.ValidateRequest(ctx, requestInput))
+
+			resp := httptest.NewRecorder()
+			mux.ServeHTTP(resp, req)
+			require.Equal(t, testcase.expCode, resp.Code, resp.Body.String())
+
+			assert.NoError(t, validateResponse(ctx, requestInput, resp))
+		})
+	}
+
+	for path, item := range doc.Paths.Map() {
+		for method, op := range item.Operations() {
+			assert.True(t, covered[op.OperationID], "%s %s (%s) has no contract test", method, path, op.OperationID)
+		}
+	}
+}
+
+func validateResponse(ctx context.Context, requestInput *openapi3filter.RequestValidationInput, resp *httptest.ResponseRecorder) error {
+	input := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: requestInput,
+		Status:                 resp.Code,
+		Header:                 resp.Header(),
+		Options:                &openapi3filter.Options{IncludeResponseStatus: true},
+	}
+	input.SetBodyBytes(resp.Body.Bytes())
+
+	return openapi3filter.ValidateResponse(ctx, input)
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"context"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+
+	"github.com/patrick-devel/shorturl/internal/models"
+)
+
+const previewSuffix = "+"
+
+var previewTemplate = template.Must(template.New("preview").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="robots" content="noindex">
+<title>{{if .Title}}{{.Title}}{{else}}Link preview{{end}}</title>
+</head>
+<body>
+<h1>{{if .Title}}{{.Title}}{{else}}Link preview{{end}}</h1>
+<p>{{.ShortURL}} leads to:</p>
+<p><a href="{{.OriginalURL}}" rel="nofollow noopener">{{.OriginalURL}}</a></p>
+<ul>
+{{- if .CreatedAt}}
+<li>Created: {{.CreatedAt.Format "2006-01-02 15:04 MST"}}</li>
+{{- end}}
+<li>Clicks: {{.Clicks}}</li>
+</ul>
+</body>
+</html>
+`))
+
+type previewService interface {
+	PreviewLink(ctx context.Context, hash string) (models.Preview, error)
+}
+
+// WithPreview отдает превью для /:id+ и /:id?preview=1, остальные запросы - в next.
+func WithPreview(preview, next gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if strings.HasSuffix(c.Param("id"), previewSuffix) || c.Query("preview") == "1" {
+			preview(c)
+
+			return
+		}
+
+		next(c)
+	}
+}
+
+func PreviewHandler(service previewService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		hash := strings.TrimSuffix(c.Param("id"), previewSuffix)
+
+		preview, err := service.PreviewLink(c.Copy(), hash)
+		if err != nil {
+			abortWithError(c, err)
+
+			return
+		}
+
+		c.Header("Cache-Control", "private, no-cache")
+
+		format := c.NegotiateFormat(gin.MIMEHTML, gin.MIMEJSON)
+		if c.Query("format") == "json" {
+			format = gin.MIMEJSON
+		}
+
+		if format == gin.MIMEJSON {
+			c.JSON(http.StatusOK, preview)
+
+			return
+		}
+
+		c.Render(http.StatusOK, render.HTML{Template: previewTemplate, Name: "preview", Data: preview})
+	}
+}
@@ -0,0 +1,133 @@
+package handlers_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/patrick-devel/shorturl/internal/handlers"
+	mockhandlers "github.com/patrick-devel/shorturl/internal/handlers/mocks"
+	"github.com/patrick-devel/shorturl/internal/models"
+	"github.com/patrick-devel/shorturl/internal/storage"
+)
+
+func TestPreviewHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mockhandlers.NewMockpreviewService(ctrl)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.GET("/:id", handlers.WithPreview(
+		handlers.PreviewHandler(mockService),
+		func(c *gin.Context) { c.Status(http.StatusTemporaryRedirect) },
+	))
+
+	created := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	preview := models.Preview{
+		ShortURL:    "http://localhost/abc",
+		OriginalURL: "https://practicum.yandex.ru/",
+		Title:       "Practicum <courses>",
+		CreatedAt:   &created,
+		Clicks:      42,
+	}
+
+	tests := []struct {
+		name     string
+		target   string
+		accept   string
+		mockExec func()
+		expCode  int
+		expType  string
+		expBody  []string
+	}{
+		{
+			name:   "HTMLPlus",
+			target: "/abc+",
+			mockExec: func() {
+				mockService.EXPECT().PreviewLink(gomock.Any(), "abc").Return(preview, nil)
+			},
+			expCode: http.StatusOK,
+			expType: "text/html; charset=utf-8",
+			expBody: []string{"https://practicum.yandex.ru/", "Practicum &lt;courses&gt;", "Clicks: 42", "2024-05-01"},
+		},
+		{
+			name:   "JSONQuery",
+			target: "/abc?preview=1&format=json",
+			mockExec: func() {
+				mockService.EXPECT().PreviewLink(gomock.Any(), "abc").Return(preview, nil)
+			},
+			expCode: http.StatusOK,
+			expType: "application/json; charset=utf-8",
+			expBody: []string{`"original_url":"https://practicum.yandex.ru/"`, `"clicks":42`},
+		},
+		{
+			name:   "JSONAccept",
+			target: "/abc+",
+			accept: "application/json",
+			mockExec: func() {
+				mockService.EXPECT().PreviewLink(gomock.Any(), "abc").Return(preview, nil)
+			},
+			expCode: http.StatusOK,
+			expType: "application/json; charset=utf-8",
+		},
+		{
+			name:     "Redirect",
+			target:   "/abc",
+			mockExec: func() {},
+			expCode:  http.StatusTemporaryRedirect,
+		},
+		{
+			name:   "NotFound",
+			target: "/abc+",
+			mockExec: func() {
+				mockService.EXPECT().PreviewLink(gomock.Any(), "abc").Return(models.Preview{}, storage.ErrNotFound)
+			},
+			expCode: http.StatusNotFound,
+		},
+		{
+			name:   "Gone",
+			target: "/abc+",
+			mockExec: func() {
+				mockService.EXPECT().PreviewLink(gomock.Any(), "abc").Return(models.Preview{}, storage.ErrEventDeleted)
+			},
+			expCode: http.StatusGone,
+		},
+	}
+
+	for _, tc := range tests {
+		testcase := tc
+		t.Run(testcase.name, func(t *testing.T) {
+			testcase.mockExec()
+
+			req := httptest.NewRequest(http.MethodGet, testcase.target, http.NoBody)
+			if testcase.accept != "" {
+				req.Header.Set("Accept", testcase.accept)
+			}
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			resp := recorder.Result()
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, testcase.expCode, resp.StatusCode)
+			if testcase.expType != "" {
+				assert.Equal(t, testcase.expType, resp.Header.Get("Content-Type"))
+			}
+			for _, part := range testcase.expBody {
+				assert.Contains(t, string(body), part)
+			}
+		})
+	}
+}
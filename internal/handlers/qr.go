@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"path"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"github.com/patrick-devel/shorturl/internal/models"
+	"github.com/patrick-devel/shorturl/internal/qr"
+)
+
+const qrMaxAge = 24 * time.Hour
+
+type qrService interface {
+	GetShortLink(ctx context.Context, hash string) (models.Event, error)
+	LinksByCreatorID(ctx context.Context, filter models.LinkFilter) ([]models.Event, error)
+}
+
+// SubpathHandler отдает запросы вида /:id/<name> зарегистрированным обработчикам,
+// остальные хвосты пути уходят в fallback (редирект с передачей пути).
+func SubpathHandler(fallback gin.HandlerFunc, subpaths map[string]gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if handler, ok := subpaths[c.Param("rest")]; ok {
+			handler(c)
+
+			return
+		}
+
+		fallback(c)
+	}
+}
+
+func QRCodeHandler(service qrService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		opts, err := qr.ParseOptions(c.Query)
+		if err != nil {
+			abortWithError(c, err)
+
+			return
+		}
+
+		event, err := service.GetShortLink(c.Copy(), c.Param("id"))
+		if err != nil {
+			abortWithError(c, err)
+
+			return
+		}
+
+		etag := qrETag(event.ShortURL, opts)
+		c.Header("ETag", etag)
+		c.Header("Cache-Control", fmt.Sprintf("public, max-age=%d", int(qrMaxAge.Seconds())))
+		if c.GetHeader("If-None-Match") == etag {
+			c.Status(http.StatusNotModified)
+
+			return
+		}
+
+		image, err := qr.Render(event.ShortURL, opts)
+		if err != nil {
+			abortWithError(c, err)
+
+			return
+		}
+
+		c.Data(http.StatusOK, opts.ContentType(), image)
+	}
+}
+
+func QRCodeBatchHandler(service qrService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		opts, err := qr.ParseOptions(c.Query)
+		if err != nil {
+			abortWithError(c, err)
+
+			return
+		}
+
+		events, err := service.LinksByCreatorID(c.Copy(), models.LinkFilter{Campaign: c.Query("campaign"), Workspace: c.Query("workspace")})
+		if err != nil {
+			abortWithError(c, err)
+
+			return
+		}
+
+		if len(events) == 0 {
+			c.JSON(http.StatusNoContent, "urls not found")
+
+			return
+		}
+
+		var buf bytes.Buffer
+		archive := zip.NewWriter(&buf)
+		for _, e := range events {
+			image, err := qr.Render(e.ShortURL, opts)
+			if err != nil {
+				logrus.WithError(err).Errorf("render qr for %s failed", e.ShortURL)
+				abortWithError(c, err)
+
+				return
+			}
+
+			file, err := archive.Create(path.Base(e.ShortURL) + "." + opts.Format)
+			if err == nil {
+				_, err = file.Write(image)
+			}
+			if err != nil {
+				abortWithError(c, err)
+
+				return
+			}
+		}
+
+		if err := archive.Close(); err != nil {
+			abortWithError(c, err)
+
+			return
+		}
+
+		c.Header("Content-Disposition", `attachment; filename="qr-codes.zip"`)
+		c.Data(http.StatusOK, "application/zip", buf.Bytes())
+	}
+}
+
+func qrETag(shortURL string, opts qr.Options) string {
+	sum := sha256.Sum256([]byte(shortURL + "|" + opts.Key()))
+
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
@@ -0,0 +1,188 @@
+package handlers_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"image/png"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/patrick-devel/shorturl/internal/handlers"
+	mockhandlers "github.com/patrick-devel/shorturl/internal/handlers/mocks"
+	"github.com/patrick-devel/shorturl/internal/models"
+	"github.com/patrick-devel/shorturl/internal/storage"
+)
+
+func TestQRCodeHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mockhandlers.NewMockqrService(ctrl)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.GET("/:id/*rest", handlers.SubpathHandler(
+		func(c *gin.Context) { c.Status(http.StatusTeapot) },
+		map[string]gin.HandlerFunc{"/qr": handlers.QRCodeHandler(mockService)},
+	))
+
+	event := models.Event{ShortURL: "http://localhost/abc", OriginalURL: "https://practicum.yandex.ru/"}
+
+	tests := []struct {
+		name        string
+		target      string
+		mockExec    func()
+		expCode     int
+		contentType string
+	}{
+		{
+			name:   "PNG",
+			target: "/abc/qr?size=128&ecc=H&margin=2",
+			mockExec: func() {
+				mockService.EXPECT().GetShortLink(gomock.Any(), "abc").Return(event, nil)
+			},
+			expCode:     http.StatusOK,
+			contentType: "image/png",
+		},
+		{
+			name:   "SVG",
+			target: "/abc/qr?format=svg&fg=112233&bg=ffffff",
+			mockExec: func() {
+				mockService.EXPECT().GetShortLink(gomock.Any(), "abc").Return(event, nil)
+			},
+			expCode:     http.StatusOK,
+			contentType: "image/svg+xml",
+		},
+		{
+			name:     "BadFormat",
+			target:   "/abc/qr?format=gif",
+			mockExec: func() {},
+			expCode:  http.StatusBadRequest,
+		},
+		{
+			name:     "BadECC",
+			target:   "/abc/qr?ecc=X",
+			mockExec: func() {},
+			expCode:  http.StatusBadRequest,
+		},
+		{
+			name:   "NotFound",
+			target: "/abc/qr",
+			mockExec: func() {
+				mockService.EXPECT().GetShortLink(gomock.Any(), "abc").Return(models.Event{}, storage.ErrNotFound)
+			},
+			expCode: http.StatusNotFound,
+		},
+		{
+			name:   "Gone",
+			target: "/abc/qr",
+			mockExec: func() {
+				mockService.EXPECT().GetShortLink(gomock.Any(), "abc").Return(models.Event{}, storage.ErrEventDeleted)
+			},
+			expCode: http.StatusGone,
+		},
+		{
+			name:     "OtherSubpath",
+			target:   "/abc/docs",
+			mockExec: func() {},
+			expCode:  http.StatusTeapot,
+		},
+	}
+
+	for _, tc := range tests {
+		testcase := tc
+		t.Run(testcase.name, func(t *testing.T) {
+			testcase.mockExec()
+
+			req := httptest.NewRequest(http.MethodGet, testcase.target, http.NoBody)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			resp := recorder.Result()
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, testcase.expCode, resp.StatusCode)
+			if testcase.contentType != "" {
+				assert.Equal(t, testcase.contentType, resp.Header.Get("Content-Type"))
+				assert.NotEmpty(t, resp.Header.Get("ETag"))
+				assert.NotEmpty(t, body)
+			}
+			if testcase.contentType == "image/png" {
+				img, err := png.Decode(bytes.NewReader(body))
+				require.NoError(t, err)
+				assert.Equal(t, 128, img.Bounds().Dx())
+			}
+		})
+	}
+}
+
+func TestQRCodeHandler_NotModified(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mockhandlers.NewMockqrService(ctrl)
+	mockService.EXPECT().GetShortLink(gomock.Any(), "abc").
+		Return(models.Event{ShortURL: "http://localhost/abc"}, nil).Times(2)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.GET("/:id/qr", handlers.QRCodeHandler(mockService))
+
+	req := httptest.NewRequest(http.MethodGet, "/abc/qr", http.NoBody)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	etag := recorder.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+
+	req = httptest.NewRequest(http.MethodGet, "/abc/qr", http.NoBody)
+	req.Header.Set("If-None-Match", etag)
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	assert.Equal(t, http.StatusNotModified, recorder.Code)
+	assert.Empty(t, recorder.Body.Bytes())
+}
+
+func TestQRCodeBatchHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mockhandlers.NewMockqrService(ctrl)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.GET("/api/user/urls/qr.zip", handlers.QRCodeBatchHandler(mockService))
+
+	mockService.EXPECT().LinksByCreatorID(gomock.Any(), gomock.Any()).Return([]models.Event{
+		{ShortURL: "http://localhost/abc"},
+		{ShortURL: "http://localhost/def"},
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/user/urls/qr.zip?format=svg", http.NoBody)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/zip", recorder.Header().Get("Content-Type"))
+
+	archive, err := zip.NewReader(bytes.NewReader(recorder.Body.Bytes()), int64(recorder.Body.Len()))
+	require.NoError(t, err)
+
+	var names []string
+	for _, f := range archive.File {
+		names = append(names, f.Name)
+	}
+	assert.Equal(t, []string{"abc.svg", "def.svg"}, names)
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/patrick-devel/shorturl/internal/models"
+)
+
+type quotaService interface {
+	Quota(ctx context.Context) (models.QuotaUsage, error)
+}
+
+// GetQuota - квота текущего пользователя и сколько ссылок у него уже есть.
+func GetQuota(service quotaService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		usage, err := service.Quota(c.Copy())
+		if err != nil {
+			abortWithError(c, err)
+
+			return
+		}
+
+		c.JSON(http.StatusOK, usage)
+	}
+}
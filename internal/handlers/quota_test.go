@@ -0,0 +1,80 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/patrick-devel/shorturl/internal/handlers"
+	mockhandlers "github.com/patrick-devel/shorturl/internal/handlers/mocks"
+	"github.com/patrick-devel/shorturl/internal/models"
+	shortservice "github.com/patrick-devel/shorturl/internal/service"
+)
+
+func TestGetQuota(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mockhandlers.NewMockquotaService(ctrl)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/api/user/quota", handlers.GetQuota(mockService))
+
+	usage := models.QuotaUsage{Quota: models.Quota{Links: 1000, BatchSize: 100}, LinksUsed: 998}
+
+	tests := []struct {
+		name     string
+		mockExec func()
+		expCode  int
+		expBody  *models.QuotaUsage
+	}{
+		{
+			name: "OK",
+			mockExec: func() {
+				mockService.EXPECT().Quota(gomock.Any()).Return(usage, nil)
+			},
+			expCode: http.StatusOK,
+			expBody: &usage,
+		},
+		{
+			name: "Unauthorized",
+			mockExec: func() {
+				mockService.EXPECT().Quota(gomock.Any()).Return(models.QuotaUsage{}, shortservice.ErrUnauthorized)
+			},
+			expCode: http.StatusUnauthorized,
+		},
+		{
+			name: "Error",
+			mockExec: func() {
+				mockService.EXPECT().Quota(gomock.Any()).Return(models.QuotaUsage{}, errors.New("db is down"))
+			},
+			expCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tests {
+		testcase := tc
+		t.Run(testcase.name, func(t *testing.T) {
+			testcase.mockExec()
+
+			req := httptest.NewRequest(http.MethodGet, "/api/user/quota", http.NoBody)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			assert.Equal(t, testcase.expCode, recorder.Code)
+			if testcase.expBody != nil {
+				var body models.QuotaUsage
+				require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+				assert.Equal(t, *testcase.expBody, body)
+			}
+		})
+	}
+}
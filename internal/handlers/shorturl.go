@@ -3,47 +3,55 @@ package handlers
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
 	"github.com/patrick-devel/shorturl/internal/models"
-	"github.com/patrick-devel/shorturl/internal/storage"
+	"github.com/patrick-devel/shorturl/internal/problem"
+)
+
+const (
+	permanentRedirectMaxAge = 24 * time.Hour
+	variantCookieMaxAge     = 30 * 24 * time.Hour
+	variantCookiePrefix     = "shorturl_variant_"
 )
 
 type shortService interface {
-	MakeShortURL(ctx context.Context, originalURL, uid string) (string, error)
-	GetOriginalURL(ctx context.Context, hash string) (string, error)
+	MakeShortURL(ctx context.Context, originalURL, uid string, opts models.LinkOptions) (string, error)
+	GetOriginalURL(ctx context.Context, hash string, visitor models.Visitor) (models.Redirect, error)
+	GetUnfurl(ctx context.Context, hash string) (models.Unfurl, bool, error)
 	MakeShortURLs(ctx context.Context, bulk models.ListRequestBulk) ([]models.Event, error)
-	LinksByCreatorID(ctx context.Context) ([]models.Event, error)
+	LinksByCreatorID(ctx context.Context, filter models.LinkFilter) ([]models.Event, error)
 }
 
 func MakeShortLinkHandler(service shortService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		body, err := io.ReadAll(c.Request.Body)
 		if err != nil {
-			c.String(http.StatusBadRequest, err.Error())
+			abortWithBindError(c, err)
 
 			return
 		}
 
 		originalURL, err := url.ParseRequestURI(string(body))
 		if err != nil {
-			c.String(http.StatusBadRequest, err.Error())
+			abortWithError(c, err)
 
 			return
 		}
 
-		shortLink, err := service.MakeShortURL(c.Copy(), originalURL.String(), "")
+		shortLink, err := service.MakeShortURL(c.Copy(), originalURL.String(), "", models.LinkOptions{})
 		if err != nil {
-			if errors.Is(err, storage.ErrDuplicateURL) {
-				c.String(http.StatusConflict, shortLink)
-
-				return
-			}
-			c.String(http.StatusInternalServerError, err.Error())
+			p := problemFromError(err)
+			// при повторе shortLink - уже существующая ссылка
+			p.Result = shortLink
+			problem.Abort(c, p)
 
 			return
 		}
@@ -54,20 +62,54 @@ func MakeShortLinkHandler(service shortService) gin.HandlerFunc {
 
 func RedirectShortLinkHandler(service shortService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		originalURL, err := service.GetOriginalURL(c.Copy(), c.Request.RequestURI)
-		if err != nil {
-			if errors.Is(err, storage.ErrEventDeleted) {
-				c.String(http.StatusGone, "")
+		if isUnfurlBot(c.Request.UserAgent()) && c.Param("rest") == "" {
+			unfurl, ok, err := service.GetUnfurl(c.Copy(), c.Param("id"))
+			if err == nil && ok {
+				renderUnfurl(c, unfurl)
 
 				return
 			}
+		}
+
+		variantCookie := variantCookiePrefix + c.Param("id")
+		previousVariant, _ := c.Cookie(variantCookie)
 
-			c.String(http.StatusNotFound, err.Error())
+		visitor := models.Visitor{
+			UserAgent:      c.Request.UserAgent(),
+			AcceptLanguage: c.GetHeader("Accept-Language"),
+			Query:          c.Request.URL.Query(),
+			Path:           strings.TrimPrefix(c.Param("rest"), "/"),
+			Variant:        previousVariant,
+		}
+
+		redirect, err := service.GetOriginalURL(c.Copy(), c.Param("id"), visitor)
+		if err != nil {
+			abortWithError(c, err)
 
 			return
 		}
 
-		c.Redirect(http.StatusTemporaryRedirect, originalURL)
+		if redirect.Sticky {
+			c.SetCookie(variantCookie, redirect.Variant, int(variantCookieMaxAge.Seconds()), "/", "", false, true)
+		}
+
+		cacheControl := redirectCacheControl(redirect.StatusCode)
+		if redirect.Variant != "" {
+			// при сплит-тесте каждый переход должен дойти до нас, иначе вариант не засчитается
+			cacheControl = redirectCacheControl(http.StatusTemporaryRedirect)
+		}
+		c.Header("Cache-Control", cacheControl)
+		c.Redirect(redirect.StatusCode, redirect.URL)
+	}
+}
+
+// Постоянные редиректы браузер может кешировать, временные должны каждый раз приходить к нам.
+func redirectCacheControl(code int) string {
+	switch code {
+	case http.StatusMovedPermanently, http.StatusPermanentRedirect:
+		return fmt.Sprintf("public, max-age=%d", int(permanentRedirectMaxAge.Seconds()))
+	default:
+		return "private, no-cache, no-store"
 	}
 }
 
@@ -75,20 +117,17 @@ func MakeShortURLJSONHandler(service shortService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var request models.Request
 
-		if err := c.BindJSON(&request); err != nil {
-			c.JSON(http.StatusBadRequest, "")
+		if err := c.ShouldBindJSON(&request); err != nil {
+			abortWithBindError(c, err)
 
 			return
 		}
 
-		shortLink, err := service.MakeShortURL(c.Copy(), request.URL.String(), "")
+		shortLink, err := service.MakeShortURL(c.Copy(), request.URL.String(), "", request.LinkOptions)
 		if err != nil {
-			if errors.Is(err, storage.ErrDuplicateURL) {
-				c.JSON(http.StatusConflict, &models.Response{Result: shortLink})
-
-				return
-			}
-			c.JSON(http.StatusInternalServerError, "")
+			p := problemFromError(err)
+			p.Result = shortLink
+			problem.Abort(c, p)
 
 			return
 		}
@@ -101,14 +140,14 @@ func MakeShortURLBulk(service shortService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		var request models.ListRequestBulk
 
-		if err := c.BindJSON(&request); err != nil {
-			c.JSON(http.StatusBadRequest, "")
+		if err := c.ShouldBindJSON(&request); err != nil {
+			abortWithBindError(c, err)
 
 			return
 		}
 
 		if len(request) == 0 {
-			c.JSON(http.StatusBadRequest, "")
+			abortWithBindError(c, errors.New("batch is empty"))
 
 			return
 		}
@@ -117,7 +156,7 @@ func MakeShortURLBulk(service shortService) gin.HandlerFunc {
 
 		events, err := service.MakeShortURLs(c.Copy(), request)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, "")
+			abortWithError(c, err)
 
 			return
 		}
@@ -126,6 +165,7 @@ func MakeShortURLBulk(service shortService) gin.HandlerFunc {
 			response = append(response, models.ResponseBulk{
 				ShortURL:      e.ShortURL,
 				CorrelationID: e.UUID,
+				Duplicate:     e.Duplicate,
 			})
 		}
 
@@ -135,9 +175,11 @@ func MakeShortURLBulk(service shortService) gin.HandlerFunc {
 
 func GetURLsByCreatorID(service shortService) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		events, err := service.LinksByCreatorID(c.Copy())
+		filter := models.LinkFilter{Campaign: c.Query("campaign"), Workspace: c.Query("workspace")}
+
+		events, err := service.LinksByCreatorID(c.Copy(), filter)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, "failed to get urls")
+			abortWithError(c, err)
 
 			return
 		}
@@ -145,7 +187,9 @@ func GetURLsByCreatorID(service shortService) gin.HandlerFunc {
 		var resp []models.ResponseGetURLs
 
 		for _, e := range events {
-			resp = append(resp, models.ResponseGetURLs{ShortURL: e.ShortURL, OriginalURL: e.OriginalURL})
+			resp = append(resp, models.ResponseGetURLs{
+				ShortURL: e.ShortURL, OriginalURL: e.OriginalURL, UTM: e.UTM, WorkspaceID: e.WorkspaceID,
+			})
 		}
 		if len(resp) == 0 {
 			c.JSON(http.StatusNoContent, "urls not found")
@@ -19,6 +19,7 @@ import (
 	mockhandlers "github.com/patrick-devel/shorturl/internal/handlers/mocks"
 	middlewares "github.com/patrick-devel/shorturl/internal/middlwares"
 	"github.com/patrick-devel/shorturl/internal/models"
+	"github.com/patrick-devel/shorturl/internal/storage"
 )
 
 func TestMakeShortLinkHandler(t *testing.T) {
@@ -74,7 +75,7 @@ func TestMakeShortLinkHandler(t *testing.T) {
 		t.Run(testcase.name, func(t *testing.T) {
 			req := httptest.NewRequest(testcase.method, "/", testcase.body)
 			recorder := httptest.NewRecorder()
-			mockService.EXPECT().MakeShortURL(gomock.Any(), gomock.Any(), gomock.Any()).Return("http://localhost/test", nil).AnyTimes()
+			mockService.EXPECT().MakeShortURL(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return("http://localhost/test", nil).AnyTimes()
 			router.ServeHTTP(recorder, req)
 
 			resp := recorder.Result()
@@ -115,7 +116,18 @@ func TestRedirectShortLinkHandler(t *testing.T) {
 			hash:    "dkadwda",
 			expCode: http.StatusTemporaryRedirect,
 			mockExec: func() {
-				mockService.EXPECT().GetOriginalURL(gomock.Any(), gomock.Any()).Return(baseURL, nil)
+				mockService.EXPECT().GetOriginalURL(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(models.Redirect{URL: baseURL, StatusCode: http.StatusTemporaryRedirect}, nil)
+			},
+		},
+		{
+			name:    "Permanent",
+			method:  http.MethodGet,
+			hash:    "perm",
+			expCode: http.StatusMovedPermanently,
+			mockExec: func() {
+				mockService.EXPECT().GetOriginalURL(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(models.Redirect{URL: baseURL, StatusCode: http.StatusMovedPermanently}, nil)
 			},
 		},
 		{
@@ -131,7 +143,7 @@ func TestRedirectShortLinkHandler(t *testing.T) {
 			hash:    "not_exist",
 			expCode: http.StatusNotFound,
 			mockExec: func() {
-				mockService.EXPECT().GetOriginalURL(gomock.Any(), gomock.Any()).Return("", errors.New("not found link")).Times(1)
+				mockService.EXPECT().GetOriginalURL(gomock.Any(), gomock.Any(), gomock.Any()).Return(models.Redirect{}, storage.ErrNotFound).Times(1)
 			},
 		},
 	}
@@ -151,8 +163,13 @@ func TestRedirectShortLinkHandler(t *testing.T) {
 			defer resp.Body.Close()
 
 			assert.Equal(t, testcase.expCode, resp.StatusCode)
-			if resp.StatusCode == http.StatusTemporaryRedirect {
+			switch resp.StatusCode {
+			case http.StatusTemporaryRedirect:
+				assert.Equal(t, baseURL, resp.Header.Get("Location"))
+				assert.Contains(t, resp.Header.Get("Cache-Control"), "no-store")
+			case http.StatusMovedPermanently:
 				assert.Equal(t, baseURL, resp.Header.Get("Location"))
+				assert.Contains(t, resp.Header.Get("Cache-Control"), "max-age=")
 			}
 		})
 	}
@@ -215,7 +232,7 @@ func TestMakeShortLinkJSONHandler(t *testing.T) {
 			recorder := httptest.NewRecorder()
 
 			mockService.EXPECT().
-				MakeShortURL(gomock.Any(), gomock.Any(), gomock.Any()).
+				MakeShortURL(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
 				Return("http://localhost/123sda", nil)
 
 			router.ServeHTTP(recorder, req)
@@ -340,7 +357,7 @@ func TestMakeShortByCreator(t *testing.T) {
 			name:   "OK",
 			method: http.MethodGet,
 			mockExec: func() {
-				mockService.EXPECT().LinksByCreatorID(gomock.Any()).Return([]models.Event{{
+				mockService.EXPECT().LinksByCreatorID(gomock.Any(), gomock.Any()).Return([]models.Event{{
 					OriginalURL: "https://practicum.yandex.ru/",
 					ShortURL:    "http://localhost/123sda"}}, nil)
 			},
@@ -350,7 +367,7 @@ func TestMakeShortByCreator(t *testing.T) {
 			name:   "NoContent",
 			method: http.MethodGet,
 			mockExec: func() {
-				mockService.EXPECT().LinksByCreatorID(gomock.Any()).Return([]models.Event{}, nil).Times(1)
+				mockService.EXPECT().LinksByCreatorID(gomock.Any(), gomock.Any()).Return([]models.Event{}, nil).Times(1)
 			},
 			expCode: http.StatusNoContent,
 		},
@@ -358,7 +375,7 @@ func TestMakeShortByCreator(t *testing.T) {
 			name:   "Error",
 			method: http.MethodGet,
 			mockExec: func() {
-				mockService.EXPECT().LinksByCreatorID(gomock.Any()).Return(nil, errors.New("failed")).Times(1)
+				mockService.EXPECT().LinksByCreatorID(gomock.Any(), gomock.Any()).Return(nil, errors.New("failed")).Times(1)
 			},
 			expCode: http.StatusInternalServerError,
 		},
@@ -387,3 +404,103 @@ func TestMakeShortByCreator(t *testing.T) {
 		})
 	}
 }
+
+func TestRedirectPassthrough(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mockhandlers.NewMockshortService(ctrl)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	redirect := handlers.RedirectShortLinkHandler(mockService)
+	router.GET("/:id", redirect)
+	router.GET("/:id/*rest", redirect)
+
+	tests := []struct {
+		name     string
+		target   string
+		expPath  string
+		expQuery string
+	}{
+		{
+			name:     "Query",
+			target:   "/abc?gclid=123",
+			expQuery: "gclid=123",
+		},
+		{
+			name:    "Path",
+			target:  "/abc/docs/intro",
+			expPath: "docs/intro",
+		},
+		{
+			name:     "PathAndQuery",
+			target:   "/abc/docs?gclid=123",
+			expPath:  "docs",
+			expQuery: "gclid=123",
+		},
+		{
+			name:   "TrailingSlash",
+			target: "/abc/",
+		},
+	}
+
+	for _, tc := range tests {
+		testcase := tc
+		t.Run(testcase.name, func(t *testing.T) {
+			mockService.EXPECT().
+				GetOriginalURL(gomock.Any(), "abc", gomock.Any()).
+				DoAndReturn(func(_ interface{}, _ string, visitor models.Visitor) (models.Redirect, error) {
+					assert.Equal(t, testcase.expPath, visitor.Path)
+					assert.Equal(t, testcase.expQuery, visitor.Query.Encode())
+
+					return models.Redirect{URL: "https://example.com/", StatusCode: http.StatusFound}, nil
+				})
+
+			req := httptest.NewRequest(http.MethodGet, testcase.target, http.NoBody)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			resp := recorder.Result()
+			defer resp.Body.Close()
+
+			assert.Equal(t, http.StatusFound, resp.StatusCode)
+		})
+	}
+}
+
+func TestGetURLsByCampaign(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mockhandlers.NewMockshortService(ctrl)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.GET("/api/user/urls", handlers.GetURLsByCreatorID(mockService))
+
+	mockService.EXPECT().
+		LinksByCreatorID(gomock.Any(), models.LinkFilter{Campaign: "spring"}).
+		Return([]models.Event{{
+			ShortURL:    "http://localhost/123sda",
+			OriginalURL: "https://practicum.yandex.ru/?utm_campaign=spring&utm_source=newsletter",
+			LinkOptions: models.LinkOptions{UTM: &models.UTM{Source: "newsletter", Campaign: "spring"}},
+		}}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/user/urls?campaign=spring", http.NoBody)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	resp := recorder.Result()
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.JSONEq(t, `[{
+		"short_url": "http://localhost/123sda",
+		"original_url": "https://practicum.yandex.ru/?utm_campaign=spring&utm_source=newsletter",
+		"utm": {"source": "newsletter", "campaign": "spring"}
+	}]`, string(body))
+}
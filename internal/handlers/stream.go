@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/patrick-devel/shorturl/internal/ctxaux"
+	shortservice "github.com/patrick-devel/shorturl/internal/service"
+	"github.com/patrick-devel/shorturl/internal/stream"
+)
+
+type streamHub interface {
+	Subscribe(userID string, lastEventID uint64) *stream.Subscription
+}
+
+// StreamEvents отдает события по ссылкам пользователя в формате Server-Sent Events.
+func StreamEvents(hub streamHub, heartbeat time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := ctxaux.GetUserIDFromContext(c)
+		if userID == "" {
+			abortWithError(c, shortservice.ErrUnauthorized)
+
+			return
+		}
+
+		lastEventID, _ := strconv.ParseUint(c.GetHeader("Last-Event-ID"), 10, 64)
+
+		sub := hub.Subscribe(userID, lastEventID)
+		defer sub.Close()
+
+		c.Header("Content-Type", "text/event-stream")
+		c.Header("Cache-Control", "no-cache")
+		c.Header("Connection", "keep-alive")
+		c.Header("X-Accel-Buffering", "no")
+		c.Status(http.StatusOK)
+		c.Writer.WriteHeaderNow()
+		c.Writer.Flush()
+
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-c.Request.Context().Done():
+				return
+			case <-ticker.C:
+				if _, err := fmt.Fprint(c.Writer, ": heartbeat\n\n"); err != nil {
+					return
+				}
+			case msg, ok := <-sub.Events():
+				if !ok {
+					return
+				}
+
+				data, err := json.Marshal(msg.Notification)
+				if err != nil {
+					continue
+				}
+				if _, err := fmt.Fprintf(c.Writer, "id: %d\nevent: %s\ndata: %s\n\n", msg.ID, msg.Notification.Type, data); err != nil {
+					return
+				}
+			}
+			c.Writer.Flush()
+		}
+	}
+}
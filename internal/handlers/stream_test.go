@@ -0,0 +1,160 @@
+package handlers_test
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/patrick-devel/shorturl/internal/handlers"
+	middlewares "github.com/patrick-devel/shorturl/internal/middlwares"
+	"github.com/patrick-devel/shorturl/internal/models"
+	"github.com/patrick-devel/shorturl/internal/stream"
+)
+
+func streamServer(t *testing.T, hub *stream.Hub, heartbeat time.Duration) *httptest.Server {
+	t.Helper()
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middlewares.GzipMiddleware())
+	router.GET("/api/user/urls/stream", func(c *gin.Context) {
+		c.Set(string(middlewares.ContextUserID), "user")
+	}, handlers.StreamEvents(hub, heartbeat))
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func openStream(t *testing.T, ctx context.Context, url string, header map[string]string) *bufio.Reader {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	require.NoError(t, err)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	var body io.Reader = resp.Body
+	if resp.Header.Get("Content-Encoding") == "gzip" {
+		zr, err := gzip.NewReader(resp.Body)
+		require.NoError(t, err)
+		body = zr
+	}
+
+	return bufio.NewReader(body)
+}
+
+// readEvent читает строки до пустой строки, которой заканчивается событие SSE.
+func readEvent(t *testing.T, r *bufio.Reader) []string {
+	t.Helper()
+
+	done := make(chan []string, 1)
+	go func() {
+		var lines []string
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				done <- lines
+				return
+			}
+			line = strings.TrimRight(line, "\n")
+			if line == "" {
+				done <- lines
+				return
+			}
+			lines = append(lines, line)
+		}
+	}()
+
+	select {
+	case lines := <-done:
+		return lines
+	case <-time.After(2 * time.Second):
+		t.Fatal("no event received")
+		return nil
+	}
+}
+
+func TestStreamEvents(t *testing.T) {
+	hub := stream.NewHub(10)
+	server := streamServer(t, hub, time.Hour)
+
+	tests := []struct {
+		name   string
+		header map[string]string
+	}{
+		{name: "Plain", header: map[string]string{"Accept-Encoding": "identity"}},
+		{name: "Gzip", header: map[string]string{"Accept-Encoding": "gzip"}},
+	}
+
+	for _, tc := range tests {
+		testcase := tc
+		t.Run(testcase.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+
+			r := openStream(t, ctx, server.URL+"/api/user/urls/stream", testcase.header)
+			require.Eventually(t, func() bool { return hub.Subscribers() == 1 }, time.Second, 10*time.Millisecond)
+
+			hub.Publish(models.Notification{Type: models.NotificationLinkClicked, CreatorID: "other", ShortURL: "http://localhost/xyz"})
+			hub.Publish(models.Notification{Type: models.NotificationLinkClicked, CreatorID: "user", ShortURL: "http://localhost/abc"})
+
+			lines := readEvent(t, r)
+			require.Len(t, lines, 3)
+			assert.Regexp(t, `^id: \d+$`, lines[0])
+			assert.Equal(t, "event: link.clicked", lines[1])
+			assert.Contains(t, lines[2], `"short_url":"http://localhost/abc"`)
+
+			cancel()
+			assert.Eventually(t, func() bool { return hub.Subscribers() == 0 }, time.Second, 10*time.Millisecond)
+		})
+	}
+}
+
+func TestStreamEventsResume(t *testing.T) {
+	hub := stream.NewHub(10)
+	server := streamServer(t, hub, time.Hour)
+
+	for _, short := range []string{"a", "b", "c"} {
+		hub.Publish(models.Notification{Type: models.NotificationLinkCreated, CreatorID: "user", ShortURL: short})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	r := openStream(t, ctx, server.URL+"/api/user/urls/stream", map[string]string{"Last-Event-ID": "1"})
+
+	assert.Equal(t, "id: 2", readEvent(t, r)[0])
+	assert.Equal(t, "id: 3", readEvent(t, r)[0])
+}
+
+func TestStreamEventsHeartbeat(t *testing.T) {
+	hub := stream.NewHub(10)
+	server := streamServer(t, hub, 20*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	r := openStream(t, ctx, server.URL+"/api/user/urls/stream", nil)
+
+	assert.Equal(t, []string{": heartbeat"}, readEvent(t, r))
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/patrick-devel/shorturl/internal/models"
+)
+
+type targetingService interface {
+	TargetingRules(ctx context.Context, hash string) ([]models.TargetingRule, error)
+	SetTargetingRules(ctx context.Context, hash string, rules []models.TargetingRule) error
+}
+
+func GetTargetingRules(service targetingService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rules, err := service.TargetingRules(c.Copy(), c.Param("id"))
+		if err != nil {
+			abortWithError(c, err)
+
+			return
+		}
+
+		if rules == nil {
+			rules = []models.TargetingRule{}
+		}
+
+		c.JSON(http.StatusOK, rules)
+	}
+}
+
+func SetTargetingRules(service targetingService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var rules []models.TargetingRule
+
+		if err := c.ShouldBindJSON(&rules); err != nil {
+			abortWithBindError(c, err)
+
+			return
+		}
+
+		err := service.SetTargetingRules(c.Copy(), c.Param("id"), rules)
+		if err != nil {
+			abortWithError(c, err)
+
+			return
+		}
+
+		c.JSON(http.StatusOK, rules)
+	}
+}
@@ -0,0 +1,164 @@
+package handlers_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/patrick-devel/shorturl/internal/handlers"
+	mockhandlers "github.com/patrick-devel/shorturl/internal/handlers/mocks"
+	"github.com/patrick-devel/shorturl/internal/models"
+	"github.com/patrick-devel/shorturl/internal/service"
+	"github.com/patrick-devel/shorturl/internal/storage"
+)
+
+func TestGetTargetingRules(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mockhandlers.NewMocktargetingService(ctrl)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.GET("/api/user/urls/:id/targeting", handlers.GetTargetingRules(mockService))
+
+	tests := []struct {
+		name     string
+		mockExec func()
+		expCode  int
+		expBody  string
+	}{
+		{
+			name: "OK",
+			mockExec: func() {
+				mockService.EXPECT().TargetingRules(gomock.Any(), "abc").
+					Return([]models.TargetingRule{{Device: models.DeviceIOS, URL: "https://apps.apple.com/app"}}, nil)
+			},
+			expCode: http.StatusOK,
+			expBody: `[{"device":"ios","url":"https://apps.apple.com/app"}]`,
+		},
+		{
+			name: "Empty",
+			mockExec: func() {
+				mockService.EXPECT().TargetingRules(gomock.Any(), "abc").Return(nil, nil)
+			},
+			expCode: http.StatusOK,
+			expBody: `[]`,
+		},
+		{
+			name: "Forbidden",
+			mockExec: func() {
+				mockService.EXPECT().TargetingRules(gomock.Any(), "abc").Return(nil, service.ErrNotOwner)
+			},
+			expCode: http.StatusForbidden,
+		},
+		{
+			name: "NotFound",
+			mockExec: func() {
+				mockService.EXPECT().TargetingRules(gomock.Any(), "abc").Return(nil, storage.ErrNotFound)
+			},
+			expCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		testcase := tc
+		t.Run(testcase.name, func(t *testing.T) {
+			testcase.mockExec()
+
+			req := httptest.NewRequest(http.MethodGet, "/api/user/urls/abc/targeting", http.NoBody)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			resp := recorder.Result()
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, testcase.expCode, resp.StatusCode)
+			if testcase.expBody != "" {
+				assert.JSONEq(t, testcase.expBody, string(body))
+			}
+		})
+	}
+}
+
+func TestSetTargetingRules(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mockhandlers.NewMocktargetingService(ctrl)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.PUT("/api/user/urls/:id/targeting", handlers.SetTargetingRules(mockService))
+
+	rules := `[{"device": "android", "url": "https://play.google.com/store"}, {"language": "de", "url": "https://example.com/de"}]`
+
+	tests := []struct {
+		name     string
+		body     string
+		mockExec func()
+		expCode  int
+	}{
+		{
+			name: "OK",
+			body: rules,
+			mockExec: func() {
+				mockService.EXPECT().SetTargetingRules(gomock.Any(), "abc", []models.TargetingRule{
+					{Device: models.DeviceAndroid, URL: "https://play.google.com/store"},
+					{Language: "de", URL: "https://example.com/de"},
+				}).Return(nil)
+			},
+			expCode: http.StatusOK,
+		},
+		{
+			name:     "BadJSON",
+			body:     `{"device": "ios"`,
+			mockExec: func() {},
+			expCode:  http.StatusBadRequest,
+		},
+		{
+			name: "InvalidRule",
+			body: `[{"device": "tv", "url": "https://example.com"}]`,
+			mockExec: func() {
+				mockService.EXPECT().SetTargetingRules(gomock.Any(), "abc", gomock.Any()).
+					Return(fmt.Errorf("%w: unknown device", service.ErrInvalidTargetingRule))
+			},
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name: "Forbidden",
+			body: rules,
+			mockExec: func() {
+				mockService.EXPECT().SetTargetingRules(gomock.Any(), "abc", gomock.Any()).Return(service.ErrNotOwner)
+			},
+			expCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range tests {
+		testcase := tc
+		t.Run(testcase.name, func(t *testing.T) {
+			testcase.mockExec()
+
+			req := httptest.NewRequest(http.MethodPut, "/api/user/urls/abc/targeting", strings.NewReader(testcase.body))
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			resp := recorder.Result()
+			defer resp.Body.Close()
+
+			assert.Equal(t, testcase.expCode, resp.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+
+	"github.com/patrick-devel/shorturl/internal/models"
+)
+
+// Боты мессенджеров и соцсетей, которые строят превью ссылки по og-тегам.
+var unfurlBots = []string{
+	"slackbot",
+	"slack-imgproxy",
+	"telegrambot",
+	"twitterbot",
+	"facebookexternalhit",
+	"facebot",
+	"discordbot",
+	"linkedinbot",
+	"whatsapp",
+	"skypeuripreview",
+	"vkshare",
+	"viber",
+	"redditbot",
+	"embedly",
+}
+
+var unfurlTemplate = template.Must(template.New("unfurl").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.OpenGraph.Title}}</title>
+<meta property="og:url" content="{{.ShortURL}}">
+{{- if .OpenGraph.Title}}
+<meta property="og:title" content="{{.OpenGraph.Title}}">
+{{- end}}
+{{- if .OpenGraph.Description}}
+<meta property="og:description" content="{{.OpenGraph.Description}}">
+<meta name="description" content="{{.OpenGraph.Description}}">
+{{- end}}
+{{- if .OpenGraph.Image}}
+<meta property="og:image" content="{{.OpenGraph.Image}}">
+<meta name="twitter:card" content="summary_large_image">
+{{- end}}
+<meta http-equiv="refresh" content="0;url={{.OriginalURL}}">
+</head>
+<body>
+<a href="{{.OriginalURL}}">{{.OriginalURL}}</a>
+</body>
+</html>
+`))
+
+type metaService interface {
+	SetLinkMeta(ctx context.Context, hash string, meta models.LinkMeta) (models.LinkMeta, error)
+}
+
+func isUnfurlBot(userAgent string) bool {
+	ua := strings.ToLower(userAgent)
+	for _, bot := range unfurlBots {
+		if strings.Contains(ua, bot) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func renderUnfurl(c *gin.Context, unfurl models.Unfurl) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.Render(http.StatusOK, render.HTML{Template: unfurlTemplate, Name: "unfurl", Data: unfurl})
+}
+
+func SetLinkMeta(service metaService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request models.LinkMeta
+
+		if err := c.ShouldBindJSON(&request); err != nil {
+			abortWithBindError(c, err)
+
+			return
+		}
+
+		meta, err := service.SetLinkMeta(c.Copy(), c.Param("id"), request)
+		if err != nil {
+			abortWithError(c, err)
+
+			return
+		}
+
+		c.JSON(http.StatusOK, meta)
+	}
+}
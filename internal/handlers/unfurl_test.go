@@ -0,0 +1,178 @@
+package handlers_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/patrick-devel/shorturl/internal/handlers"
+	mockhandlers "github.com/patrick-devel/shorturl/internal/handlers/mocks"
+	"github.com/patrick-devel/shorturl/internal/models"
+	"github.com/patrick-devel/shorturl/internal/service"
+)
+
+func TestRedirectUnfurl(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mockhandlers.NewMockshortService(ctrl)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.GET("/:id", handlers.RedirectShortLinkHandler(mockService))
+
+	destination := "https://practicum.yandex.ru/"
+	unfurl := models.Unfurl{
+		ShortURL:    "http://localhost/abc",
+		OriginalURL: destination,
+		OpenGraph: models.OpenGraph{
+			Title:       "Spring sale",
+			Description: "Up to 50% off",
+			Image:       "https://cdn.example.com/sale.png",
+		},
+	}
+
+	tests := []struct {
+		name      string
+		userAgent string
+		mockExec  func()
+		expCode   int
+		expBody   []string
+	}{
+		{
+			name:      "Slack",
+			userAgent: "Slackbot-LinkExpanding 1.0 (+https://api.slack.com/robots)",
+			mockExec: func() {
+				mockService.EXPECT().GetUnfurl(gomock.Any(), "abc").Return(unfurl, true, nil)
+			},
+			expCode: http.StatusOK,
+			expBody: []string{
+				`<meta property="og:title" content="Spring sale">`,
+				`<meta property="og:description" content="Up to 50% off">`,
+				`<meta property="og:image" content="https://cdn.example.com/sale.png">`,
+				`<meta http-equiv="refresh" content="0;url=https://practicum.yandex.ru/">`,
+			},
+		},
+		{
+			name:      "TelegramWithoutMeta",
+			userAgent: "TelegramBot (like TwitterBot)",
+			mockExec: func() {
+				mockService.EXPECT().GetUnfurl(gomock.Any(), "abc").Return(models.Unfurl{}, false, nil)
+				mockService.EXPECT().GetOriginalURL(gomock.Any(), "abc", gomock.Any()).
+					Return(models.Redirect{URL: destination, StatusCode: http.StatusTemporaryRedirect}, nil)
+			},
+			expCode: http.StatusTemporaryRedirect,
+		},
+		{
+			name:      "Browser",
+			userAgent: "Mozilla/5.0 (X11; Linux x86_64) Firefox/125.0",
+			mockExec: func() {
+				mockService.EXPECT().GetOriginalURL(gomock.Any(), "abc", gomock.Any()).
+					Return(models.Redirect{URL: destination, StatusCode: http.StatusTemporaryRedirect}, nil)
+			},
+			expCode: http.StatusTemporaryRedirect,
+		},
+	}
+
+	for _, tc := range tests {
+		testcase := tc
+		t.Run(testcase.name, func(t *testing.T) {
+			testcase.mockExec()
+
+			req := httptest.NewRequest(http.MethodGet, "/abc", http.NoBody)
+			req.Header.Set("User-Agent", testcase.userAgent)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			resp := recorder.Result()
+			defer resp.Body.Close()
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, testcase.expCode, resp.StatusCode)
+			for _, part := range testcase.expBody {
+				assert.Contains(t, string(body), part)
+			}
+		})
+	}
+}
+
+func TestSetLinkMeta(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mockhandlers.NewMockmetaService(ctrl)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.PUT("/api/user/urls/:id/meta", handlers.SetLinkMeta(mockService))
+
+	meta := models.LinkMeta{
+		Title:     "Sale",
+		OpenGraph: &models.OpenGraph{Title: "Spring sale", Image: "https://cdn.example.com/sale.png"},
+	}
+
+	tests := []struct {
+		name     string
+		body     string
+		mockExec func()
+		expCode  int
+	}{
+		{
+			name: "OK",
+			body: `{"title": "Sale", "open_graph": {"title": "Spring sale", "image": "https://cdn.example.com/sale.png"}}`,
+			mockExec: func() {
+				mockService.EXPECT().SetLinkMeta(gomock.Any(), "abc", meta).Return(meta, nil)
+			},
+			expCode: http.StatusOK,
+		},
+		{
+			name: "BadImage",
+			body: `{"open_graph": {"image": "ftp://cdn.example.com/sale.png"}}`,
+			mockExec: func() {
+				mockService.EXPECT().SetLinkMeta(gomock.Any(), "abc", gomock.Any()).
+					Return(models.LinkMeta{}, service.ErrInvalidOpenGraph)
+			},
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name: "Forbidden",
+			body: `{"title": "Sale"}`,
+			mockExec: func() {
+				mockService.EXPECT().SetLinkMeta(gomock.Any(), "abc", gomock.Any()).
+					Return(models.LinkMeta{}, service.ErrNotOwner)
+			},
+			expCode: http.StatusForbidden,
+		},
+		{
+			name:     "BadJSON",
+			body:     `{"title": `,
+			mockExec: func() {},
+			expCode:  http.StatusBadRequest,
+		},
+	}
+
+	for _, tc := range tests {
+		testcase := tc
+		t.Run(testcase.name, func(t *testing.T) {
+			testcase.mockExec()
+
+			req := httptest.NewRequest(http.MethodPut, "/api/user/urls/abc/meta", strings.NewReader(testcase.body))
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			resp := recorder.Result()
+			defer resp.Body.Close()
+
+			assert.Equal(t, testcase.expCode, resp.StatusCode)
+		})
+	}
+}
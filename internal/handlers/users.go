@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	middlewares "github.com/patrick-devel/shorturl/internal/middlwares"
+	"github.com/patrick-devel/shorturl/internal/models"
+	"github.com/patrick-devel/shorturl/internal/problem"
+	"github.com/patrick-devel/shorturl/internal/tokens"
+)
+
+type userService interface {
+	Register(ctx context.Context, request models.Credentials) (models.User, error)
+	Login(ctx context.Context, request models.Credentials) (models.User, error)
+}
+
+type sessionIssuer interface {
+	Issue(uid string) (tokens.Pair, error)
+	RefreshTTL() time.Duration
+}
+
+type sessionResponse struct {
+	User models.User `json:"user"`
+	tokenResponse
+}
+
+// Register заводит аккаунт и сразу выдает токены его пользователя.
+func Register(service userService, issuer sessionIssuer) gin.HandlerFunc {
+	return session(service.Register, issuer, http.StatusCreated)
+}
+
+// Login выдает токены пользователя. Дальше запросы идут с его UUID, как и у анонимных пользователей.
+func Login(service userService, issuer sessionIssuer) gin.HandlerFunc {
+	return session(service.Login, issuer, http.StatusOK)
+}
+
+func session(auth func(ctx context.Context, request models.Credentials) (models.User, error),
+	issuer sessionIssuer, status int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request models.Credentials
+
+		if err := c.ShouldBindJSON(&request); err != nil {
+			abortWithBindError(c, err)
+
+			return
+		}
+
+		user, err := auth(c.Copy(), request)
+		if err != nil {
+			abortWithError(c, err)
+
+			return
+		}
+
+		startSession(c, user, issuer, status)
+	}
+}
+
+// startSession выдает токены вошедшего пользователя и отвечает ими вместе с профилем.
+func startSession(c *gin.Context, user models.User, issuer sessionIssuer, status int) {
+	pair, err := issuer.Issue(user.ID)
+	if err != nil {
+		problem.Abort(c, problem.Internal())
+
+		return
+	}
+
+	middlewares.SetTokens(c, pair, issuer.RefreshTTL())
+	c.JSON(status, sessionResponse{
+		User: user,
+		tokenResponse: tokenResponse{
+			AccessToken:  pair.Access,
+			RefreshToken: pair.Refresh,
+			ExpiresIn:    int(pair.ExpiresIn.Seconds()),
+		},
+	})
+}
@@ -0,0 +1,145 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/patrick-devel/shorturl/internal/handlers"
+	mockhandlers "github.com/patrick-devel/shorturl/internal/handlers/mocks"
+	"github.com/patrick-devel/shorturl/internal/models"
+	"github.com/patrick-devel/shorturl/internal/problem"
+	"github.com/patrick-devel/shorturl/internal/service"
+	"github.com/patrick-devel/shorturl/internal/tokens"
+)
+
+func TestSessionHandlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	credentials := models.Credentials{Email: "user@example.com", Password: "correct horse"}
+	user := models.User{ID: "u1", Email: credentials.Email, CreatedAt: time.Now().UTC()}
+	pair := tokens.Pair{Access: "a1", Refresh: "r1", ExpiresIn: 15 * time.Minute}
+
+	tests := []struct {
+		name       string
+		target     string
+		body       string
+		err        error
+		issueErr   error
+		expCode    int
+		expProblem string
+	}{
+		{
+			name:    "Register",
+			target:  "/api/auth/register",
+			body:    `{"email": "user@example.com", "password": "correct horse"}`,
+			expCode: http.StatusCreated,
+		},
+		{
+			name:    "Login",
+			target:  "/api/auth/login",
+			body:    `{"email": "user@example.com", "password": "correct horse"}`,
+			expCode: http.StatusOK,
+		},
+		{
+			name:       "RegisterEmailTaken",
+			target:     "/api/auth/register",
+			body:       `{"email": "user@example.com", "password": "correct horse"}`,
+			err:        service.ErrEmailTaken,
+			expCode:    http.StatusConflict,
+			expProblem: problem.CodeEmailTaken,
+		},
+		{
+			name:       "LoginWrongPassword",
+			target:     "/api/auth/login",
+			body:       `{"email": "user@example.com", "password": "correct horse"}`,
+			err:        service.ErrInvalidCredentials,
+			expCode:    http.StatusUnauthorized,
+			expProblem: problem.CodeUnauthorized,
+		},
+		{
+			name:       "InvalidBody",
+			target:     "/api/auth/login",
+			body:       `{"email": 42}`,
+			expCode:    http.StatusBadRequest,
+			expProblem: problem.CodeInvalidRequest,
+		},
+		{
+			name:       "IssueFailed",
+			target:     "/api/auth/login",
+			body:       `{"email": "user@example.com", "password": "correct horse"}`,
+			issueErr:   errors.New("no keys"),
+			expCode:    http.StatusInternalServerError,
+			expProblem: problem.CodeInternal,
+		},
+	}
+
+	for _, tc := range tests {
+		testcase := tc
+		t.Run(testcase.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			users := mockhandlers.NewMockuserService(ctrl)
+			issuer := mockhandlers.NewMocksessionIssuer(ctrl)
+
+			if testcase.expProblem != problem.CodeInvalidRequest {
+				var call *gomock.Call
+				if strings.HasSuffix(testcase.target, "register") {
+					call = users.EXPECT().Register(gomock.Any(), credentials)
+				} else {
+					call = users.EXPECT().Login(gomock.Any(), credentials)
+				}
+				if testcase.err != nil {
+					call.Return(models.User{}, testcase.err)
+				} else {
+					call.Return(user, nil)
+					issuer.EXPECT().Issue(user.ID).Return(pair, testcase.issueErr)
+					if testcase.issueErr == nil {
+						issuer.EXPECT().RefreshTTL().Return(time.Hour)
+					}
+				}
+			}
+
+			router := gin.New()
+			router.POST("/api/auth/register", handlers.Register(users, issuer))
+			router.POST("/api/auth/login", handlers.Login(users, issuer))
+
+			req := httptest.NewRequest(http.MethodPost, testcase.target, strings.NewReader(testcase.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			require.Equal(t, testcase.expCode, w.Code)
+			if testcase.expProblem != "" {
+				assert.Contains(t, w.Body.String(), `"code":"`+testcase.expProblem+`"`)
+				assert.Empty(t, w.Header().Get("Authorization"))
+
+				return
+			}
+
+			assert.Equal(t, pair.Access, w.Header().Get("Authorization"))
+			assert.Equal(t, pair.Refresh, w.Header().Get("X-Refresh-Token"))
+
+			var body struct {
+				User        models.User `json:"user"`
+				AccessToken string      `json:"access_token"`
+				ExpiresIn   int         `json:"expires_in"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+			assert.Equal(t, user.ID, body.User.ID)
+			assert.Equal(t, pair.Access, body.AccessToken)
+			assert.Equal(t, 900, body.ExpiresIn)
+			assert.NotContains(t, w.Body.String(), "password")
+		})
+	}
+}
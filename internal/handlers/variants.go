@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/patrick-devel/shorturl/internal/models"
+)
+
+type variantService interface {
+	SetVariants(ctx context.Context, hash string, variants []models.Variant, sticky bool) ([]models.Variant, error)
+	ClickStats(ctx context.Context, hash string) (models.ClickStats, error)
+}
+
+func SetVariants(service variantService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request models.RequestVariants
+
+		if err := c.ShouldBindJSON(&request); err != nil {
+			abortWithBindError(c, err)
+
+			return
+		}
+
+		variants, err := service.SetVariants(c.Copy(), c.Param("id"), request.Variants, request.Sticky)
+		if err != nil {
+			abortWithError(c, err)
+
+			return
+		}
+
+		if variants == nil {
+			variants = []models.Variant{}
+		}
+
+		c.JSON(http.StatusOK, models.RequestVariants{Variants: variants, Sticky: request.Sticky})
+	}
+}
+
+func GetClickStats(service variantService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		stats, err := service.ClickStats(c.Copy(), c.Param("id"))
+		if err != nil {
+			abortWithError(c, err)
+
+			return
+		}
+
+		c.JSON(http.StatusOK, stats)
+	}
+}
@@ -0,0 +1,194 @@
+package handlers_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/patrick-devel/shorturl/internal/handlers"
+	mockhandlers "github.com/patrick-devel/shorturl/internal/handlers/mocks"
+	"github.com/patrick-devel/shorturl/internal/models"
+	"github.com/patrick-devel/shorturl/internal/service"
+	"github.com/patrick-devel/shorturl/internal/storage"
+)
+
+func TestSetVariants(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mockhandlers.NewMockvariantService(ctrl)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.PUT("/api/user/urls/:id/variants", handlers.SetVariants(mockService))
+
+	body := `{"variants": [{"url": "https://example.com/a", "weight": 70}, {"url": "https://example.com/b", "weight": 30}], "sticky": true}`
+
+	tests := []struct {
+		name     string
+		body     string
+		mockExec func()
+		expCode  int
+	}{
+		{
+			name: "OK",
+			body: body,
+			mockExec: func() {
+				mockService.EXPECT().SetVariants(gomock.Any(), "abc", gomock.Len(2), true).
+					Return([]models.Variant{
+						{Name: "A", URL: "https://example.com/a", Weight: 70},
+						{Name: "B", URL: "https://example.com/b", Weight: 30},
+					}, nil)
+			},
+			expCode: http.StatusOK,
+		},
+		{
+			name:     "BadJSON",
+			body:     `{"variants": `,
+			mockExec: func() {},
+			expCode:  http.StatusBadRequest,
+		},
+		{
+			name: "Invalid",
+			body: `{"variants": [{"url": "https://example.com/a", "weight": 0}]}`,
+			mockExec: func() {
+				mockService.EXPECT().SetVariants(gomock.Any(), "abc", gomock.Any(), false).
+					Return(nil, service.ErrInvalidVariants)
+			},
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name: "Forbidden",
+			body: body,
+			mockExec: func() {
+				mockService.EXPECT().SetVariants(gomock.Any(), "abc", gomock.Any(), true).
+					Return(nil, service.ErrNotOwner)
+			},
+			expCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range tests {
+		testcase := tc
+		t.Run(testcase.name, func(t *testing.T) {
+			testcase.mockExec()
+
+			req := httptest.NewRequest(http.MethodPut, "/api/user/urls/abc/variants", strings.NewReader(testcase.body))
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			resp := recorder.Result()
+			defer resp.Body.Close()
+
+			assert.Equal(t, testcase.expCode, resp.StatusCode)
+		})
+	}
+}
+
+func TestGetClickStats(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mockhandlers.NewMockvariantService(ctrl)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.GET("/api/user/urls/:id/stats", handlers.GetClickStats(mockService))
+
+	tests := []struct {
+		name     string
+		mockExec func()
+		expCode  int
+		expBody  string
+	}{
+		{
+			name: "OK",
+			mockExec: func() {
+				mockService.EXPECT().ClickStats(gomock.Any(), "abc").Return(models.ClickStats{
+					ShortURL: "http://localhost/abc",
+					Clicks:   10,
+					Variants: map[string]int{"A": 7, "B": 3},
+				}, nil)
+			},
+			expCode: http.StatusOK,
+			expBody: `{"short_url": "http://localhost/abc", "clicks": 10, "variants": {"A": 7, "B": 3}}`,
+		},
+		{
+			name: "NotFound",
+			mockExec: func() {
+				mockService.EXPECT().ClickStats(gomock.Any(), "abc").Return(models.ClickStats{}, storage.ErrNotFound)
+			},
+			expCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		testcase := tc
+		t.Run(testcase.name, func(t *testing.T) {
+			testcase.mockExec()
+
+			req := httptest.NewRequest(http.MethodGet, "/api/user/urls/abc/stats", http.NoBody)
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			resp := recorder.Result()
+			defer resp.Body.Close()
+
+			respBody, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			assert.Equal(t, testcase.expCode, resp.StatusCode)
+			if testcase.expBody != "" {
+				assert.JSONEq(t, testcase.expBody, string(respBody))
+			}
+		})
+	}
+}
+
+func TestRedirectStickyVariant(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mockhandlers.NewMockshortService(ctrl)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.GET("/:id", handlers.RedirectShortLinkHandler(mockService))
+
+	mockService.EXPECT().
+		GetOriginalURL(gomock.Any(), "abc", gomock.Any()).
+		DoAndReturn(func(_ interface{}, _ string, visitor models.Visitor) (models.Redirect, error) {
+			assert.Equal(t, "B", visitor.Variant)
+
+			return models.Redirect{
+				URL:        "https://example.com/b",
+				StatusCode: http.StatusMovedPermanently,
+				Variant:    "B",
+				Sticky:     true,
+			}, nil
+		})
+
+	req := httptest.NewRequest(http.MethodGet, "/abc", http.NoBody)
+	req.AddCookie(&http.Cookie{Name: "shorturl_variant_abc", Value: "B"})
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, req)
+
+	resp := recorder.Result()
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusMovedPermanently, resp.StatusCode)
+	assert.Equal(t, "https://example.com/b", resp.Header.Get("Location"))
+	assert.Contains(t, resp.Header.Get("Cache-Control"), "no-store")
+
+	cookies := resp.Cookies()
+	require.Len(t, cookies, 1)
+	assert.Equal(t, "shorturl_variant_abc", cookies[0].Name)
+	assert.Equal(t, "B", cookies[0].Value)
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/patrick-devel/shorturl/internal/models"
+)
+
+type webhookService interface {
+	CreateWebhook(ctx context.Context, request models.RequestWebhook) (models.Webhook, error)
+	Webhooks(ctx context.Context) ([]models.Webhook, error)
+	DeleteWebhook(ctx context.Context, id string) error
+	WebhookDeliveries(ctx context.Context, id string, deadOnly bool) ([]models.WebhookDelivery, error)
+}
+
+func CreateWebhook(service webhookService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request models.RequestWebhook
+
+		if err := c.ShouldBindJSON(&request); err != nil {
+			abortWithBindError(c, err)
+
+			return
+		}
+
+		webhook, err := service.CreateWebhook(c.Copy(), request)
+		if err != nil {
+			abortWithError(c, err)
+
+			return
+		}
+
+		c.JSON(http.StatusCreated, webhook)
+	}
+}
+
+func GetWebhooks(service webhookService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		webhooks, err := service.Webhooks(c.Copy())
+		if err != nil {
+			abortWithError(c, err)
+
+			return
+		}
+
+		c.JSON(http.StatusOK, webhooks)
+	}
+}
+
+func DeleteWebhook(service webhookService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		err := service.DeleteWebhook(c.Copy(), c.Param("id"))
+		if err != nil {
+			abortWithError(c, err)
+
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// GetWebhookDeliveries отдает журнал доставок, ?status=dead - только недоставленные.
+func GetWebhookDeliveries(service webhookService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		deadOnly := c.Query("status") == "dead"
+
+		deliveries, err := service.WebhookDeliveries(c.Copy(), c.Param("id"), deadOnly)
+		if err != nil {
+			abortWithError(c, err)
+
+			return
+		}
+
+		c.JSON(http.StatusOK, deliveries)
+	}
+}
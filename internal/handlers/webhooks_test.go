@@ -0,0 +1,131 @@
+package handlers_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/patrick-devel/shorturl/internal/handlers"
+	mockhandlers "github.com/patrick-devel/shorturl/internal/handlers/mocks"
+	"github.com/patrick-devel/shorturl/internal/models"
+	"github.com/patrick-devel/shorturl/internal/service"
+)
+
+func TestWebhookHandlers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mockhandlers.NewMockwebhookService(ctrl)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.POST("/api/user/webhooks", handlers.CreateWebhook(mockService))
+	router.GET("/api/user/webhooks", handlers.GetWebhooks(mockService))
+	router.DELETE("/api/user/webhooks/:id", handlers.DeleteWebhook(mockService))
+	router.GET("/api/user/webhooks/:id/deliveries", handlers.GetWebhookDeliveries(mockService))
+
+	request := models.RequestWebhook{URL: "https://crm.example.com/hook", Events: []string{models.NotificationLinkCreated}}
+
+	tests := []struct {
+		name     string
+		method   string
+		target   string
+		body     string
+		mockExec func()
+		expCode  int
+		expBody  string
+	}{
+		{
+			name:   "Create",
+			method: http.MethodPost,
+			target: "/api/user/webhooks",
+			body:   `{"url": "https://crm.example.com/hook", "events": ["link.created"]}`,
+			mockExec: func() {
+				mockService.EXPECT().CreateWebhook(gomock.Any(), request).
+					Return(models.Webhook{ID: "w1", URL: request.URL, Events: request.Events, Secret: "abc"}, nil)
+			},
+			expCode: http.StatusCreated,
+			expBody: `"secret":"abc"`,
+		},
+		{
+			name:   "CreateInvalid",
+			method: http.MethodPost,
+			target: "/api/user/webhooks",
+			body:   `{"url": "ftp://crm.example.com/hook"}`,
+			mockExec: func() {
+				mockService.EXPECT().CreateWebhook(gomock.Any(), gomock.Any()).Return(models.Webhook{}, service.ErrInvalidWebhook)
+			},
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:   "List",
+			method: http.MethodGet,
+			target: "/api/user/webhooks",
+			mockExec: func() {
+				mockService.EXPECT().Webhooks(gomock.Any()).Return([]models.Webhook{{ID: "w1", URL: request.URL}}, nil)
+			},
+			expCode: http.StatusOK,
+			expBody: `"id":"w1"`,
+		},
+		{
+			name:   "Delete",
+			method: http.MethodDelete,
+			target: "/api/user/webhooks/w1",
+			mockExec: func() {
+				mockService.EXPECT().DeleteWebhook(gomock.Any(), "w1").Return(nil)
+			},
+			expCode: http.StatusNoContent,
+		},
+		{
+			name:   "DeleteNotFound",
+			method: http.MethodDelete,
+			target: "/api/user/webhooks/w2",
+			mockExec: func() {
+				mockService.EXPECT().DeleteWebhook(gomock.Any(), "w2").Return(service.ErrWebhookNotFound)
+			},
+			expCode: http.StatusNotFound,
+		},
+		{
+			name:   "DeadLetters",
+			method: http.MethodGet,
+			target: "/api/user/webhooks/w1/deliveries?status=dead",
+			mockExec: func() {
+				mockService.EXPECT().WebhookDeliveries(gomock.Any(), "w1", true).
+					Return([]models.WebhookDelivery{{ID: "d1", WebhookID: "w1", Attempt: 5, Dead: true}}, nil)
+			},
+			expCode: http.StatusOK,
+			expBody: `"dead":true`,
+		},
+		{
+			name:   "DeliveriesError",
+			method: http.MethodGet,
+			target: "/api/user/webhooks/w1/deliveries",
+			mockExec: func() {
+				mockService.EXPECT().WebhookDeliveries(gomock.Any(), "w1", false).Return(nil, errors.New("db is down"))
+			},
+			expCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range tests {
+		testcase := tc
+		t.Run(testcase.name, func(t *testing.T) {
+			testcase.mockExec()
+
+			req := httptest.NewRequest(testcase.method, testcase.target, strings.NewReader(testcase.body))
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			assert.Equal(t, testcase.expCode, recorder.Code)
+			if testcase.expBody != "" {
+				assert.Contains(t, recorder.Body.String(), testcase.expBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/patrick-devel/shorturl/internal/models"
+)
+
+type workspaceService interface {
+	CreateWorkspace(ctx context.Context, request models.RequestWorkspace) (models.Workspace, error)
+	Workspaces(ctx context.Context) ([]models.Workspace, error)
+	WorkspaceMembers(ctx context.Context, workspaceID string) ([]models.WorkspaceMember, error)
+	SetWorkspaceMember(ctx context.Context, workspaceID, userID string, request models.RequestWorkspaceMember) (models.WorkspaceMember, error)
+	RemoveWorkspaceMember(ctx context.Context, workspaceID, userID string) error
+	SetLinkWorkspace(ctx context.Context, hash string, request models.RequestLinkWorkspace) (models.Event, error)
+}
+
+func CreateWorkspace(service workspaceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request models.RequestWorkspace
+
+		if err := c.ShouldBindJSON(&request); err != nil {
+			abortWithBindError(c, err)
+
+			return
+		}
+
+		workspace, err := service.CreateWorkspace(c.Copy(), request)
+		if err != nil {
+			abortWithError(c, err)
+
+			return
+		}
+
+		c.JSON(http.StatusCreated, workspace)
+	}
+}
+
+func GetWorkspaces(service workspaceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		workspaces, err := service.Workspaces(c.Copy())
+		if err != nil {
+			abortWithError(c, err)
+
+			return
+		}
+
+		c.JSON(http.StatusOK, workspaces)
+	}
+}
+
+func GetWorkspaceMembers(service workspaceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		members, err := service.WorkspaceMembers(c.Copy(), c.Param("id"))
+		if err != nil {
+			abortWithError(c, err)
+
+			return
+		}
+
+		c.JSON(http.StatusOK, members)
+	}
+}
+
+// SetWorkspaceMember добавляет участника по его UUID или меняет роль существующего.
+func SetWorkspaceMember(service workspaceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request models.RequestWorkspaceMember
+
+		if err := c.ShouldBindJSON(&request); err != nil {
+			abortWithBindError(c, err)
+
+			return
+		}
+
+		member, err := service.SetWorkspaceMember(c.Copy(), c.Param("id"), c.Param("user_id"), request)
+		if err != nil {
+			abortWithError(c, err)
+
+			return
+		}
+
+		c.JSON(http.StatusOK, member)
+	}
+}
+
+func DeleteWorkspaceMember(service workspaceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		err := service.RemoveWorkspaceMember(c.Copy(), c.Param("id"), c.Param("user_id"))
+		if err != nil {
+			abortWithError(c, err)
+
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// SetLinkWorkspace переносит ссылку в пространство, пустой workspace_id возвращает ее в личные.
+func SetLinkWorkspace(service workspaceService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var request models.RequestLinkWorkspace
+
+		if err := c.ShouldBindJSON(&request); err != nil {
+			abortWithBindError(c, err)
+
+			return
+		}
+
+		event, err := service.SetLinkWorkspace(c.Copy(), c.Param("id"), request)
+		if err != nil {
+			abortWithError(c, err)
+
+			return
+		}
+
+		c.JSON(http.StatusOK, models.ResponseGetURLs{
+			ShortURL:    event.ShortURL,
+			OriginalURL: event.OriginalURL,
+			UTM:         event.UTM,
+			WorkspaceID: event.WorkspaceID,
+		})
+	}
+}
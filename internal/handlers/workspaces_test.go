@@ -0,0 +1,168 @@
+package handlers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/patrick-devel/shorturl/internal/handlers"
+	mockhandlers "github.com/patrick-devel/shorturl/internal/handlers/mocks"
+	"github.com/patrick-devel/shorturl/internal/models"
+	"github.com/patrick-devel/shorturl/internal/service"
+	"github.com/patrick-devel/shorturl/internal/storage"
+)
+
+func TestWorkspaceHandlers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockService := mockhandlers.NewMockworkspaceService(ctrl)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.Default()
+	router.POST("/api/user/workspaces", handlers.CreateWorkspace(mockService))
+	router.GET("/api/user/workspaces", handlers.GetWorkspaces(mockService))
+	router.GET("/api/user/workspaces/:id/members", handlers.GetWorkspaceMembers(mockService))
+	router.PUT("/api/user/workspaces/:id/members/:user_id", handlers.SetWorkspaceMember(mockService))
+	router.DELETE("/api/user/workspaces/:id/members/:user_id", handlers.DeleteWorkspaceMember(mockService))
+	router.PUT("/api/user/urls/:id/workspace", handlers.SetLinkWorkspace(mockService))
+
+	tests := []struct {
+		name     string
+		method   string
+		target   string
+		body     string
+		mockExec func()
+		expCode  int
+		expBody  string
+	}{
+		{
+			name:   "Create",
+			method: http.MethodPost,
+			target: "/api/user/workspaces",
+			body:   `{"name": "Marketing"}`,
+			mockExec: func() {
+				mockService.EXPECT().CreateWorkspace(gomock.Any(), models.RequestWorkspace{Name: "Marketing"}).
+					Return(models.Workspace{ID: "ws1", Name: "Marketing", Role: models.RoleOwner}, nil)
+			},
+			expCode: http.StatusCreated,
+			expBody: `"role":"owner"`,
+		},
+		{
+			name:   "CreateInvalid",
+			method: http.MethodPost,
+			target: "/api/user/workspaces",
+			body:   `{"name": ""}`,
+			mockExec: func() {
+				mockService.EXPECT().CreateWorkspace(gomock.Any(), gomock.Any()).Return(models.Workspace{}, service.ErrInvalidWorkspace)
+			},
+			expCode: http.StatusBadRequest,
+		},
+		{
+			name:   "List",
+			method: http.MethodGet,
+			target: "/api/user/workspaces",
+			mockExec: func() {
+				mockService.EXPECT().Workspaces(gomock.Any()).
+					Return([]models.Workspace{{ID: "ws1", Name: "Marketing", Role: models.RoleViewer}}, nil)
+			},
+			expCode: http.StatusOK,
+			expBody: `"role":"viewer"`,
+		},
+		{
+			name:   "MembersOfForeignWorkspace",
+			method: http.MethodGet,
+			target: "/api/user/workspaces/ws2/members",
+			mockExec: func() {
+				mockService.EXPECT().WorkspaceMembers(gomock.Any(), "ws2").Return(nil, service.ErrWorkspaceNotFound)
+			},
+			expCode: http.StatusNotFound,
+		},
+		{
+			name:   "SetMember",
+			method: http.MethodPut,
+			target: "/api/user/workspaces/ws1/members/u2",
+			body:   `{"role": "editor"}`,
+			mockExec: func() {
+				mockService.EXPECT().SetWorkspaceMember(gomock.Any(), "ws1", "u2", models.RequestWorkspaceMember{Role: models.RoleEditor}).
+					Return(models.WorkspaceMember{WorkspaceID: "ws1", UserID: "u2", Role: models.RoleEditor}, nil)
+			},
+			expCode: http.StatusOK,
+			expBody: `"user_id":"u2"`,
+		},
+		{
+			name:   "SetMemberByEditor",
+			method: http.MethodPut,
+			target: "/api/user/workspaces/ws1/members/u3",
+			body:   `{"role": "owner"}`,
+			mockExec: func() {
+				mockService.EXPECT().SetWorkspaceMember(gomock.Any(), "ws1", "u3", gomock.Any()).
+					Return(models.WorkspaceMember{}, service.ErrWorkspaceRole)
+			},
+			expCode: http.StatusForbidden,
+		},
+		{
+			name:   "RemoveMember",
+			method: http.MethodDelete,
+			target: "/api/user/workspaces/ws1/members/u2",
+			mockExec: func() {
+				mockService.EXPECT().RemoveWorkspaceMember(gomock.Any(), "ws1", "u2").Return(nil)
+			},
+			expCode: http.StatusNoContent,
+		},
+		{
+			name:   "RemoveUnknownMember",
+			method: http.MethodDelete,
+			target: "/api/user/workspaces/ws1/members/u9",
+			mockExec: func() {
+				mockService.EXPECT().RemoveWorkspaceMember(gomock.Any(), "ws1", "u9").Return(storage.ErrWorkspaceMemberNotFound)
+			},
+			expCode: http.StatusNotFound,
+		},
+		{
+			name:   "MoveLink",
+			method: http.MethodPut,
+			target: "/api/user/urls/abc/workspace",
+			body:   `{"workspace_id": "ws1"}`,
+			mockExec: func() {
+				moved := models.Event{ShortURL: "http://localhost/abc", OriginalURL: "https://practicum.yandex.ru/"}
+				moved.WorkspaceID = "ws1"
+				mockService.EXPECT().SetLinkWorkspace(gomock.Any(), "abc", models.RequestLinkWorkspace{WorkspaceID: "ws1"}).
+					Return(moved, nil)
+			},
+			expCode: http.StatusOK,
+			expBody: `"workspace_id":"ws1"`,
+		},
+		{
+			name:   "MoveForeignLink",
+			method: http.MethodPut,
+			target: "/api/user/urls/abc/workspace",
+			body:   `{"workspace_id": "ws1"}`,
+			mockExec: func() {
+				mockService.EXPECT().SetLinkWorkspace(gomock.Any(), "abc", gomock.Any()).Return(models.Event{}, service.ErrNotOwner)
+			},
+			expCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tc := range tests {
+		testcase := tc
+		t.Run(testcase.name, func(t *testing.T) {
+			testcase.mockExec()
+
+			req := httptest.NewRequest(testcase.method, testcase.target, strings.NewReader(testcase.body))
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, req)
+
+			assert.Equal(t, testcase.expCode, recorder.Code)
+			if testcase.expBody != "" {
+				assert.Contains(t, recorder.Body.String(), testcase.expBody)
+			}
+		})
+	}
+}
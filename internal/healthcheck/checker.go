@@ -0,0 +1,249 @@
+package healthcheck
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/patrick-devel/shorturl/internal/models"
+	"github.com/patrick-devel/shorturl/internal/netguard"
+)
+
+const (
+	defaultConcurrency    = 4
+	defaultHostDelay      = time.Second
+	defaultRequestTimeout = 10 * time.Second
+	userAgent             = "shorturl-healthcheck/1.0"
+)
+
+type store interface {
+	ReadActiveEvents(ctx context.Context) ([]models.Event, error)
+	WriteLinkHealth(ctx context.Context, ref models.LinkRef, health models.LinkHealth) error
+}
+
+type Checker struct {
+	storage     store
+	client      *http.Client
+	interval    time.Duration
+	concurrency int
+	hostDelay   time.Duration
+
+	// hosts - когда можно идти к хосту в следующий раз, чтобы не долбить его чаще hostDelay
+	mu    sync.Mutex
+	hosts map[string]time.Time
+}
+
+type Option func(c *Checker)
+
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Checker) {
+		c.client = client
+	}
+}
+
+func WithConcurrency(n int) Option {
+	return func(c *Checker) {
+		if n > 0 {
+			c.concurrency = n
+		}
+	}
+}
+
+func WithHostDelay(d time.Duration) Option {
+	return func(c *Checker) {
+		c.hostDelay = d
+	}
+}
+
+func New(storage store, interval time.Duration, opts ...Option) *Checker {
+	c := &Checker{
+		storage:     storage,
+		client:      netguard.NewClient(defaultRequestTimeout),
+		interval:    interval,
+		concurrency: defaultConcurrency,
+		hostDelay:   defaultHostDelay,
+		hosts:       map[string]time.Time{},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+func (c *Checker) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := c.CheckAll(ctx); err != nil {
+			logrus.Errorf("health check failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CheckAll проверяет все активные ссылки один раз.
+func (c *Checker) CheckAll(ctx context.Context) error {
+	events, err := c.storage.ReadActiveEvents(ctx)
+	if err != nil {
+		return fmt.Errorf("read events failed: %w", err)
+	}
+	defer c.forgetHosts()
+
+	sem := make(chan struct{}, c.concurrency)
+	var wg sync.WaitGroup
+
+	for _, e := range events {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return ctx.Err()
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(event models.Event) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			health := c.checkEvent(ctx, event)
+			if err := c.storage.WriteLinkHealth(ctx, event.Ref(), health); err != nil {
+				logrus.Errorf("write health for %s failed: %v", event.Ref(), err)
+			}
+		}(e)
+	}
+
+	wg.Wait()
+	return nil
+}
+
+// checkEvent проверяет все адреса ссылки: основной, из правил таргетинга и из вариантов.
+// Результат - первый неоткрывшийся адрес, а если открываются все - основной.
+func (c *Checker) checkEvent(ctx context.Context, event models.Event) models.LinkHealth {
+	var health models.LinkHealth
+	for i, destination := range destinations(event) {
+		h := c.Check(ctx, destination)
+		if i == 0 {
+			health = h
+		}
+		if h.Broken() {
+			return h
+		}
+	}
+
+	return health
+}
+
+// destinations - адреса ссылки без повторов, основной первым.
+func destinations(event models.Event) []string {
+	urls := []string{event.OriginalURL}
+	seen := map[string]bool{event.OriginalURL: true}
+	add := func(u string) {
+		if !seen[u] {
+			seen[u] = true
+			urls = append(urls, u)
+		}
+	}
+	for _, rule := range event.TargetingRules {
+		add(rule.URL)
+	}
+	for _, variant := range event.Variants {
+		add(variant.URL)
+	}
+
+	return urls
+}
+
+func (c *Checker) Check(ctx context.Context, destination string) models.LinkHealth {
+	u, err := url.Parse(destination)
+	if err != nil {
+		return models.LinkHealth{URL: destination, CheckedAt: time.Now().UTC(), Error: err.Error()}
+	}
+
+	// ждем без блокировки: медленный хост не должен держать остальные проверки
+	if wait := c.reserve(u.Host); wait > 0 {
+		select {
+		case <-ctx.Done():
+			return models.LinkHealth{URL: destination, CheckedAt: time.Now().UTC(), Error: ctx.Err().Error()}
+		case <-time.After(wait):
+		}
+	}
+
+	start := time.Now()
+	code, err := c.request(ctx, http.MethodHead, destination)
+	if err == nil && (code == http.StatusMethodNotAllowed || code == http.StatusNotImplemented) {
+		// не все серверы умеют HEAD, повторяем обычным GET
+		code, err = c.request(ctx, http.MethodGet, destination)
+	}
+
+	health := models.LinkHealth{
+		URL:        destination,
+		StatusCode: code,
+		LatencyMS:  time.Since(start).Milliseconds(),
+		CheckedAt:  time.Now().UTC(),
+	}
+	if err != nil {
+		health.Error = err.Error()
+	}
+
+	return health
+}
+
+func (c *Checker) request(ctx context.Context, method, destination string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, destination, http.NoBody)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	return resp.StatusCode, nil
+}
+
+// reserve занимает для запроса к host ближайшее время не раньше hostDelay после предыдущего
+// и возвращает, сколько до него ждать.
+func (c *Checker) reserve(host string) time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	next := now
+	if last, ok := c.hosts[host]; ok && last.Add(c.hostDelay).After(now) {
+		next = last.Add(c.hostDelay)
+	}
+	c.hosts[host] = next
+
+	return next.Sub(now)
+}
+
+// forgetHosts убирает хосты, к которым уже можно идти без ожидания, иначе карта растет с каждым новым доменом.
+func (c *Checker) forgetHosts() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for host, last := range c.hosts {
+		if !last.Add(c.hostDelay).After(now) {
+			delete(c.hosts, host)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package healthcheck_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/patrick-devel/shorturl/internal/healthcheck"
+	"github.com/patrick-devel/shorturl/internal/models"
+)
+
+type fakeStore struct {
+	mu     sync.Mutex
+	events []models.Event
+	health map[string]models.LinkHealth
+}
+
+func (s *fakeStore) ReadActiveEvents(_ context.Context) ([]models.Event, error) {
+	return s.events, nil
+}
+
+func (s *fakeStore) WriteLinkHealth(_ context.Context, ref models.LinkRef, health models.LinkHealth) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.health[ref.Code] = health
+	return nil
+}
+
+func TestCheckAll(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/gone", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	mux.HandleFunc("/nohead", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	store := &fakeStore{
+		events: []models.Event{
+			{Code: "ok", OriginalURL: server.URL + "/ok"},
+			{Code: "gone", OriginalURL: server.URL + "/gone"},
+			{Code: "nohead", OriginalURL: server.URL + "/nohead"},
+			{Code: "down", OriginalURL: closed.URL},
+		},
+		health: map[string]models.LinkHealth{},
+	}
+
+	checker := healthcheck.New(store, time.Hour,
+		healthcheck.WithHTTPClient(server.Client()),
+		healthcheck.WithHostDelay(0),
+	)
+	require.NoError(t, checker.CheckAll(context.Background()))
+
+	require.Len(t, store.health, 4)
+
+	assert.Equal(t, http.StatusOK, store.health["ok"].StatusCode)
+	assert.False(t, store.health["ok"].Broken())
+	assert.False(t, store.health["ok"].CheckedAt.IsZero())
+
+	assert.Equal(t, http.StatusNotFound, store.health["gone"].StatusCode)
+	assert.True(t, store.health["gone"].Broken())
+
+	assert.Equal(t, http.StatusOK, store.health["nohead"].StatusCode)
+
+	assert.Equal(t, 0, store.health["down"].StatusCode)
+	assert.NotEmpty(t, store.health["down"].Error)
+	assert.True(t, store.health["down"].Broken())
+}
+
+func TestCheckHostPoliteness(t *testing.T) {
+	var inFlight, maxInFlight int32
+	var mu sync.Mutex
+	var times []time.Time
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			m := atomic.LoadInt32(&maxInFlight)
+			if n <= m || atomic.CompareAndSwapInt32(&maxInFlight, m, n) {
+				break
+			}
+		}
+
+		mu.Lock()
+		times = append(times, time.Now())
+		mu.Unlock()
+	}))
+	defer server.Close()
+
+	store := &fakeStore{health: map[string]models.LinkHealth{}}
+	for _, short := range []string{"a", "b", "c"} {
+		store.events = append(store.events, models.Event{Code: short, OriginalURL: server.URL + "/" + short})
+	}
+
+	delay := 50 * time.Millisecond
+	checker := healthcheck.New(store, time.Hour,
+		healthcheck.WithHTTPClient(server.Client()),
+		healthcheck.WithConcurrency(3),
+		healthcheck.WithHostDelay(delay),
+	)
+	require.NoError(t, checker.CheckAll(context.Background()))
+
+	assert.EqualValues(t, 1, maxInFlight)
+	require.Len(t, times, 3)
+	// интервал отсчитывается от отправки запроса, до сервера запросы доходят с разной задержкой:
+	// первый, например, еще открывает соединение
+	const jitter = 10 * time.Millisecond
+	for i := 1; i < len(times); i++ {
+		assert.GreaterOrEqual(t, times[i].Sub(times[i-1]), delay-jitter)
+	}
+}
+
+func TestCheckAllDestinations(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/gone", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	store := &fakeStore{
+		events: []models.Event{
+			{Code: "fine", OriginalURL: server.URL + "/ok", LinkOptions: models.LinkOptions{
+				TargetingRules: []models.TargetingRule{{Device: models.DeviceIOS, URL: server.URL + "/ok?ios"}},
+				Variants:       []models.Variant{{Name: "A", URL: server.URL + "/ok", Weight: 1}},
+			}},
+			{Code: "rule", OriginalURL: server.URL + "/ok", LinkOptions: models.LinkOptions{
+				TargetingRules: []models.TargetingRule{{Device: models.DeviceIOS, URL: server.URL + "/gone"}},
+			}},
+			{Code: "variant", OriginalURL: server.URL + "/ok", LinkOptions: models.LinkOptions{
+				Variants: []models.Variant{{Name: "A", URL: server.URL + "/ok", Weight: 1}, {Name: "B", URL: server.URL + "/gone", Weight: 1}},
+			}},
+		},
+		health: map[string]models.LinkHealth{},
+	}
+
+	checker := healthcheck.New(store, time.Hour,
+		healthcheck.WithHTTPClient(server.Client()),
+		healthcheck.WithHostDelay(0),
+	)
+	require.NoError(t, checker.CheckAll(context.Background()))
+
+	assert.False(t, store.health["fine"].Broken())
+	assert.Equal(t, server.URL+"/ok", store.health["fine"].URL)
+
+	for _, code := range []string{"rule", "variant"} {
+		assert.True(t, store.health[code].Broken(), code)
+		assert.Equal(t, http.StatusNotFound, store.health[code].StatusCode, code)
+		assert.Equal(t, server.URL+"/gone", store.health[code].URL, code)
+	}
+}
+
+func TestCheckSlowHost(t *testing.T) {
+	const latency = 100 * time.Millisecond
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(latency)
+	}))
+	defer server.Close()
+
+	store := &fakeStore{health: map[string]models.LinkHealth{}}
+	for _, short := range []string{"a", "b", "c"} {
+		store.events = append(store.events, models.Event{Code: short, OriginalURL: server.URL + "/" + short})
+	}
+
+	checker := healthcheck.New(store, time.Hour,
+		healthcheck.WithHTTPClient(server.Client()),
+		healthcheck.WithConcurrency(3),
+		healthcheck.WithHostDelay(20*time.Millisecond),
+	)
+	start := time.Now()
+	require.NoError(t, checker.CheckAll(context.Background()))
+
+	// запросы разнесены на hostDelay, но не ждут ответа на предыдущий
+	assert.Less(t, time.Since(start), 3*latency)
+	require.Len(t, store.health, 3)
+}
+
+func TestDefaultClientRefusesPrivateAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	checker := healthcheck.New(&fakeStore{}, time.Hour, healthcheck.WithHostDelay(0))
+	health := checker.Check(context.Background(), server.URL)
+
+	assert.Equal(t, 0, health.StatusCode)
+	assert.Contains(t, health.Error, "private address")
+}
@@ -0,0 +1,80 @@
+package middlewares
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"github.com/patrick-devel/shorturl/internal/models"
+	"github.com/patrick-devel/shorturl/internal/problem"
+	"github.com/patrick-devel/shorturl/internal/storage"
+)
+
+// APIKeyHeader - заголовок с API-ключом для интеграций между сервисами.
+const APIKeyHeader = "X-API-Key"
+
+const contextAPIKey = "APIKey"
+
+type apiKeyVerifier interface {
+	VerifyAPIKey(ctx context.Context, raw string) (models.APIKey, error)
+}
+
+// APIKeyMiddleware пускает по ключу из X-API-Key и ставится перед AuthMiddleware.
+// Без заголовка запрос уходит дальше как есть, с неверным ключом - отклоняется,
+// а не получает новый анонимный токен.
+func APIKeyMiddleware(keys apiKeyVerifier, logger *logrus.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		raw := c.GetHeader(APIKeyHeader)
+		if raw == "" {
+			c.Next()
+
+			return
+		}
+
+		key, err := keys.VerifyAPIKey(c.Request.Context(), raw)
+		if errors.Is(err, storage.ErrAPIKeyNotFound) {
+			problem.Abort(c, problem.New(http.StatusUnauthorized, problem.CodeUnauthorized, "api key is invalid"))
+
+			return
+		}
+		if err != nil {
+			logger.Error(err)
+			problem.Abort(c, problem.Internal())
+
+			return
+		}
+
+		c.Set(string(ContextUserID), key.CreatorID)
+		c.Set(contextAPIKey, key)
+		logger.WithFields(logrus.Fields{"uid": key.CreatorID, "key": key.ID}).Info("api key")
+		c.Next()
+	}
+}
+
+// RequireScopes проверяет права ключа. Запросы с токеном пользователя ограничений не имеют.
+func RequireScopes(scopes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, ok := c.Get(contextAPIKey)
+		if !ok {
+			c.Next()
+
+			return
+		}
+
+		key, _ := value.(models.APIKey)
+		for _, s := range scopes {
+			if !key.Allows(s) {
+				problem.Abort(c, problem.New(http.StatusForbidden, problem.CodeForbidden,
+					fmt.Sprintf("api key has no %q scope", s)))
+
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
@@ -1,13 +1,16 @@
 package middlewares
 
 import (
-	"fmt"
+	"errors"
 	"net/http"
+	"time"
 
-	"github.com/dgrijalva/jwt-go"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
+
+	"github.com/patrick-devel/shorturl/internal/problem"
+	"github.com/patrick-devel/shorturl/internal/tokens"
 )
 
 type keyUserID string
@@ -16,77 +19,109 @@ const (
 	ContextUserID keyUserID = "ID"
 )
 
-func AuthMiddleware(jwtSecret string, logger *logrus.Logger) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		claims := &jwt.StandardClaims{}
+const (
+	accessCookie  = "user_id_signed"
+	refreshCookie = "user_id_refresh"
+	// RefreshHeader - заголовок с refresh-токеном, рядом с Authorization.
+	RefreshHeader = "X-Refresh-Token"
+	// RefreshPath - единственный путь, куда браузер отправляет refresh cookie.
+	RefreshPath = "/api/auth/refresh"
+)
 
-		var authToken string
+// contextNewUser отмечает пользователя, которого AuthMiddleware только что завел для запроса без токена.
+const contextNewUser = "NewUser"
 
-		authHeader := c.GetHeader("Authorization")
-		cookie, err := c.Cookie("user_id_signed")
-		if err != nil {
-			logrus.WithError(err).Warning("cookie not found")
-		}
+func AuthMiddleware(issuer *tokens.Issuer, logger *logrus.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// пользователь уже определен по API-ключу
+		if _, ok := c.Get(contextAPIKey); ok {
+			c.Next()
 
-		switch {
-		case authHeader != "":
-			authToken = authHeader
-		case cookie != "":
-			authToken = cookie
+			return
 		}
 
-		logger.WithFields(logrus.Fields{"Authorization": authHeader, "cookie": cookie}).Info("check authorization")
+		authToken := accessToken(c)
+		logger.WithField("token", authToken != "").Info("check authorization")
 
 		if authToken == "" {
 			if c.Request.Method == http.MethodPost {
-				authKey, err := setToken(uuid.NewString(), jwtSecret, claims)
+				uid := uuid.NewString()
+				pair, err := issuer.Issue(uid)
 				if err != nil {
 					logger.Error(err)
-					c.AbortWithStatus(http.StatusInternalServerError)
+					problem.Abort(c, problem.Internal())
 
 					return
 				}
-				c.Header("Authorization", authKey)
-				c.SetCookie("user_id_signed", authKey, 60*60*24, "/", "", false, true)
-				c.Set(string(ContextUserID), claims.Id)
+				SetTokens(c, pair, issuer.RefreshTTL())
+				c.Set(string(ContextUserID), uid)
+				c.Set(contextNewUser, true)
 				c.Next()
 
 				return
 			}
 		}
 
-		if err := validToken(authToken, jwtSecret, claims); err != nil {
+		uid, err := issuer.UserID(authToken)
+		if err != nil {
 			logger.Error(err)
-			c.AbortWithStatus(http.StatusUnauthorized)
+			AbortWithTokenError(c, err)
 
 			return
 		}
 
-		c.Set(string(ContextUserID), claims.Id)
-		logger.WithField("claims", claims).Info("Info CLAIMS")
+		c.Set(string(ContextUserID), uid)
+		logger.WithField("uid", uid).Info("Info CLAIMS")
+		c.Next()
+	}
+}
+
+// OptionalAuthMiddleware определяет пользователя по токену, если он есть и действителен, но запрос не отклоняет
+// и новый токен не выдает. Нужен там, где пользователь может быть, а может и не быть, как при входе.
+func OptionalAuthMiddleware(issuer *tokens.Issuer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if uid, err := issuer.UserID(accessToken(c)); err == nil {
+			c.Set(string(ContextUserID), uid)
+		}
+
 		c.Next()
 	}
 }
 
-func setToken(uid, jwtSecret string, claims *jwt.StandardClaims) (string, error) {
-	claims.Id = uid
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	ss, err := token.SignedString([]byte(jwtSecret))
-	if err != nil {
-		return "", fmt.Errorf("failed to create token for user %s", uid)
+// accessToken - токен из заголовка Authorization, а без него из cookie.
+func accessToken(c *gin.Context) string {
+	if token := c.GetHeader("Authorization"); token != "" {
+		return token
 	}
+	token, _ := c.Cookie(accessCookie)
+
+	return token
+}
+
+// SetTokens отдает пару и в заголовках, и в cookie. Cookie с access-токеном живет столько же,
+// сколько refresh: по истекшему токену клиент получает token_expired и знает, что пора обновить пару.
+func SetTokens(c *gin.Context, pair tokens.Pair, refreshTTL time.Duration) {
+	maxAge := int(refreshTTL.Seconds())
+
+	c.Header("Authorization", pair.Access)
+	c.Header(RefreshHeader, pair.Refresh)
+	c.SetCookie(accessCookie, pair.Access, maxAge, "/", "", false, true)
+	c.SetCookie(refreshCookie, pair.Refresh, maxAge, RefreshPath, "", false, true)
+}
+
+// RefreshToken - refresh-токен из cookie, если клиент не передал его явно.
+func RefreshToken(c *gin.Context) string {
+	token, _ := c.Cookie(refreshCookie)
 
-	return ss, nil
+	return token
 }
 
-func validToken(userToken, jwtSecret string, claims *jwt.StandardClaims) error {
-	token, err := jwt.ParseWithClaims(userToken, claims, func(token *jwt.Token) (interface{}, error) {
-		return []byte(jwtSecret), nil
-	})
+func AbortWithTokenError(c *gin.Context, err error) {
+	if errors.Is(err, tokens.ErrExpired) {
+		problem.Abort(c, problem.New(http.StatusUnauthorized, problem.CodeTokenExpired, ""))
 
-	if err != nil || !token.Valid {
-		return fmt.Errorf("token not valid")
+		return
 	}
 
-	return nil
+	problem.Abort(c, problem.New(http.StatusUnauthorized, problem.CodeUnauthorized, ""))
 }
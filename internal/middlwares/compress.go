@@ -20,7 +20,9 @@ func (c *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
 	return c.w.Hijack()
 }
 
+// Flush сбрасывает и буфер gzip, иначе потоковые ответы (SSE) застревают в компрессоре.
 func (c *compressWriter) Flush() {
+	_ = c.zw.Flush()
 	c.w.Flush()
 }
 
@@ -37,7 +39,7 @@ func (c *compressWriter) Size() int {
 }
 
 func (c *compressWriter) WriteString(s string) (int, error) {
-	return c.w.WriteString(s)
+	return c.zw.Write([]byte(s))
 }
 
 func (c *compressWriter) Written() bool {
@@ -67,8 +69,9 @@ func (c *compressWriter) Write(p []byte) (int, error) {
 	return c.zw.Write(p)
 }
 
+// WriteHeader помечает сжатым любой ответ с телом: ошибки тоже проходят через gzip.
 func (c *compressWriter) WriteHeader(statusCode int) {
-	if statusCode < 300 {
+	if statusCode != http.StatusNoContent && statusCode != http.StatusNotModified {
 		c.w.Header().Set("Content-Encoding", "gzip")
 	}
 	c.w.WriteHeader(statusCode)
@@ -0,0 +1,32 @@
+package middlewares
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+type keyDomain string
+
+// ContextDomain - короткий домен, к которому относится код ссылки из запроса.
+const ContextDomain keyDomain = "domain"
+
+type domainResolver interface {
+	ResolveDomain(host string) string
+}
+
+// HostDomainMiddleware определяет домен ссылки по заголовку Host: один и тот же код
+// на разных доменах - разные ссылки.
+func HostDomainMiddleware(resolver domainResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(string(ContextDomain), resolver.ResolveDomain(c.Request.Host))
+		c.Next()
+	}
+}
+
+// QueryDomainMiddleware берет домен ссылки из параметра domain: API работает на основном домене,
+// а ссылки пользователя могут быть на любом. Без параметра - основной домен.
+func QueryDomainMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(string(ContextDomain), c.Query("domain"))
+		c.Next()
+	}
+}
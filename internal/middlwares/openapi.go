@@ -0,0 +1,83 @@
+package middlewares
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"github.com/patrick-devel/shorturl/internal/problem"
+)
+
+// OpenAPIMiddleware проверяет запросы по спецификации. Маршруты, которых в ней нет, пропускаются как есть,
+// токен проверяет AuthMiddleware.
+func OpenAPIMiddleware(doc *openapi3.T, logger *logrus.Logger) (gin.HandlerFunc, error) {
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build openapi router: %w", err)
+	}
+
+	options := &openapi3filter.Options{AuthenticationFunc: openapi3filter.NoopAuthenticationFunc}
+	withoutBody := &openapi3filter.Options{AuthenticationFunc: openapi3filter.NoopAuthenticationFunc, ExcludeRequestBody: true}
+
+	return func(c *gin.Context) {
+		route, pathParams, err := router.FindRoute(c.Request)
+		if err != nil {
+			c.Next()
+
+			return
+		}
+
+		input := &openapi3filter.RequestValidationInput{
+			Request:    c.Request,
+			PathParams: pathParams,
+			Route:      route,
+			Options:    options,
+		}
+		if opaqueBody(route.Operation) {
+			input.Options = withoutBody
+		}
+		if err := openapi3filter.ValidateRequest(c.Request.Context(), input); err != nil {
+			logger.WithError(err).Info("request does not match openapi spec")
+			problem.Abort(c, problem.New(http.StatusBadRequest, problem.CodeInvalidRequest, validationMessage(err)))
+
+			return
+		}
+
+		c.Next()
+	}, nil
+}
+
+// opaqueBody - тело принимается с любым Content-Type (POST / с URL текстом), его разбирает сам обработчик.
+func opaqueBody(op *openapi3.Operation) bool {
+	if op.RequestBody == nil || op.RequestBody.Value == nil {
+		return false
+	}
+
+	return op.RequestBody.Value.Content["*/*"] != nil
+}
+
+// validationMessage укорачивает ошибку kin-openapi: без дампа схемы, только путь до поля и причина.
+func validationMessage(err error) string {
+	var schemaErr *openapi3.SchemaError
+	if errors.As(err, &schemaErr) {
+		if path := schemaErr.JSONPointer(); len(path) > 0 {
+			return fmt.Sprintf("%s: %s", strings.Join(path, "."), schemaErr.Reason)
+		}
+
+		return schemaErr.Reason
+	}
+
+	var reqErr *openapi3filter.RequestError
+	if errors.As(err, &reqErr) && reqErr.Err == nil {
+		return reqErr.Error()
+	}
+
+	return err.Error()
+}
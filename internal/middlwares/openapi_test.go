@@ -0,0 +1,152 @@
+package middlewares_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/patrick-devel/shorturl/api"
+	middlewares "github.com/patrick-devel/shorturl/internal/middlwares"
+	"github.com/patrick-devel/shorturl/internal/problem"
+)
+
+func TestOpenAPIMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	doc, err := api.Load()
+	require.NoError(t, err)
+	validator, err := middlewares.OpenAPIMiddleware(doc, logrus.New())
+	require.NoError(t, err)
+
+	ok := func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	}
+
+	router := gin.New()
+	router.Use(validator)
+	router.POST("/", ok)
+	router.POST("/api/shorten", ok)
+	router.POST("/api/shorten/batch", ok)
+	router.PUT("/api/user/urls/:id/targeting", ok)
+	router.GET("/:id/*rest", ok)
+
+	tests := []struct {
+		name        string
+		method      string
+		target      string
+		contentType string
+		body        string
+		expCode     int
+		expBody     string
+	}{
+		{
+			name:        "Valid",
+			method:      http.MethodPost,
+			target:      "/api/shorten",
+			contentType: "application/json",
+			body:        `{"url": "https://practicum.yandex.ru/", "redirect_code": 301}`,
+			expCode:     http.StatusOK,
+		},
+		{
+			name:        "MissingURL",
+			method:      http.MethodPost,
+			target:      "/api/shorten",
+			contentType: "application/json",
+			body:        `{"title": "Курсы"}`,
+			expCode:     http.StatusBadRequest,
+			expBody:     `property \"url\" is missing`,
+		},
+		{
+			name:        "WrongType",
+			method:      http.MethodPost,
+			target:      "/api/shorten",
+			contentType: "application/json",
+			body:        `{"url": 42}`,
+			expCode:     http.StatusBadRequest,
+			expBody:     `url: value must be a string`,
+		},
+		{
+			name:        "UnsupportedRedirectCode",
+			method:      http.MethodPost,
+			target:      "/api/shorten",
+			contentType: "application/json",
+			body:        `{"url": "https://practicum.yandex.ru/", "redirect_code": 300}`,
+			expCode:     http.StatusBadRequest,
+			expBody:     `redirect_code`,
+		},
+		{
+			name:        "WrongContentType",
+			method:      http.MethodPost,
+			target:      "/api/shorten",
+			contentType: "text/plain",
+			body:        `{"url": "https://practicum.yandex.ru/"}`,
+			expCode:     http.StatusBadRequest,
+			expBody:     `Content-Type`,
+		},
+		{
+			name:        "EmptyBatch",
+			method:      http.MethodPost,
+			target:      "/api/shorten/batch",
+			contentType: "application/json",
+			body:        `[]`,
+			expCode:     http.StatusBadRequest,
+		},
+		{
+			name:        "UnknownDevice",
+			method:      http.MethodPut,
+			target:      "/api/user/urls/abc/targeting",
+			contentType: "application/json",
+			body:        `[{"device": "fridge", "url": "https://example.com"}]`,
+			expCode:     http.StatusBadRequest,
+			expBody:     `0.device`,
+		},
+		{
+			name:        "PlainBodyAnyContentType",
+			method:      http.MethodPost,
+			target:      "/",
+			contentType: "application/x-gzip",
+			body:        "https://practicum.yandex.ru/",
+			expCode:     http.StatusOK,
+		},
+		{
+			name:        "PlainBodyForm",
+			method:      http.MethodPost,
+			target:      "/",
+			contentType: "application/x-www-form-urlencoded",
+			body:        "https://practicum.yandex.ru/",
+			expCode:     http.StatusOK,
+		},
+		{
+			name:    "RouteNotInSpec",
+			method:  http.MethodGet,
+			target:  "/abc/docs/intro",
+			expCode: http.StatusOK,
+		},
+	}
+
+	for _, tc := range tests {
+		testcase := tc
+		t.Run(testcase.name, func(t *testing.T) {
+			req := httptest.NewRequest(testcase.method, testcase.target, strings.NewReader(testcase.body))
+			if testcase.contentType != "" {
+				req.Header.Set("Content-Type", testcase.contentType)
+			}
+			resp := httptest.NewRecorder()
+
+			router.ServeHTTP(resp, req)
+
+			assert.Equal(t, testcase.expCode, resp.Code)
+			assert.Contains(t, resp.Body.String(), testcase.expBody)
+			if testcase.expCode == http.StatusBadRequest {
+				assert.Equal(t, problem.ContentType, resp.Header().Get("Content-Type"))
+				assert.Contains(t, resp.Body.String(), `"code":"invalid_request"`)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package middlewares
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"github.com/patrick-devel/shorturl/internal/problem"
+	"github.com/patrick-devel/shorturl/internal/ratelimit"
+)
+
+type rateLimitStore interface {
+	Take(ctx context.Context, keys []string, limit ratelimit.Limit) (ratelimit.Result, error)
+}
+
+// RateLimitMiddleware ограничивает частоту запросов к группе маршрутов route: одна корзина на IP клиента
+// и еще одна на пользователя, если он уже известен. Поэтому ставится после AuthMiddleware. Пользователь,
+// заведенный для запроса без токена, новый на каждом запросе, такой запрос ограничиваем только по IP.
+// Токен тратится, только если его дают обе корзины, а заголовки RateLimit-* отдаются по самой пустой из них.
+func RateLimitMiddleware(store rateLimitStore, route string, limit ratelimit.Limit, logger *logrus.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !limit.Enabled() {
+			c.Next()
+
+			return
+		}
+
+		keys := []string{route + ":ip:" + c.ClientIP()}
+		if uid := c.GetString(string(ContextUserID)); uid != "" && !c.GetBool(contextNewUser) {
+			keys = append(keys, route+":user:"+uid)
+		}
+
+		result, err := store.Take(c.Request.Context(), keys, limit)
+		if err != nil {
+			// недоступное хранилище лимитов не должно останавливать сервис
+			logger.WithError(err).Error("rate limit store failed")
+			c.Next()
+
+			return
+		}
+
+		c.Header("RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		c.Header("RateLimit-Reset", seconds(result.Reset))
+		if !result.Allowed {
+			c.Header("Retry-After", seconds(result.RetryAfter))
+			problem.Abort(c, problem.New(http.StatusTooManyRequests, problem.CodeRateLimited,
+				"rate limit of "+strconv.Itoa(limit.Requests)+" requests per "+limit.Period.String()+" exceeded"))
+
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// seconds округляет вверх: клиент, подождавший указанное время, уже не получит 429.
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,140 @@
+package middlewares_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	middlewares "github.com/patrick-devel/shorturl/internal/middlwares"
+	"github.com/patrick-devel/shorturl/internal/problem"
+	"github.com/patrick-devel/shorturl/internal/ratelimit"
+)
+
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, []string, ratelimit.Limit) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("redis is down")
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	limit := ratelimit.Limit{Requests: 2, Period: 10 * time.Second}
+
+	type request struct {
+		ip      string
+		user    string
+		expCode int
+	}
+
+	tests := []struct {
+		name     string
+		store    func() ratelimit.Store
+		limit    ratelimit.Limit
+		requests []request
+	}{
+		{
+			name:  "PerIP",
+			limit: limit,
+			requests: []request{
+				{ip: "10.0.0.1", expCode: http.StatusOK},
+				{ip: "10.0.0.1", expCode: http.StatusOK},
+				{ip: "10.0.0.1", expCode: http.StatusTooManyRequests},
+				{ip: "10.0.0.2", expCode: http.StatusOK},
+			},
+		},
+		{
+			name:  "PerUser",
+			limit: limit,
+			requests: []request{
+				{ip: "10.0.0.1", user: "u1", expCode: http.StatusOK},
+				{ip: "10.0.0.2", user: "u1", expCode: http.StatusOK},
+				{ip: "10.0.0.3", user: "u1", expCode: http.StatusTooManyRequests},
+				{ip: "10.0.0.3", user: "u2", expCode: http.StatusOK},
+			},
+		},
+		{
+			// отказ по корзине пользователя не тратит корзину IP
+			name:  "UserDeniedKeepsIP",
+			limit: limit,
+			requests: []request{
+				{ip: "10.0.0.1", user: "u1", expCode: http.StatusOK},
+				{ip: "10.0.0.2", user: "u1", expCode: http.StatusOK},
+				{ip: "10.0.0.1", user: "u1", expCode: http.StatusTooManyRequests},
+				{ip: "10.0.0.1", expCode: http.StatusOK},
+				{ip: "10.0.0.1", expCode: http.StatusTooManyRequests},
+			},
+		},
+		{
+			name: "Disabled",
+			requests: []request{
+				{ip: "10.0.0.1", expCode: http.StatusOK},
+				{ip: "10.0.0.1", expCode: http.StatusOK},
+				{ip: "10.0.0.1", expCode: http.StatusOK},
+			},
+		},
+		{
+			name:  "StoreUnavailable",
+			store: func() ratelimit.Store { return failingStore{} },
+			limit: limit,
+			requests: []request{
+				{ip: "10.0.0.1", expCode: http.StatusOK},
+				{ip: "10.0.0.1", expCode: http.StatusOK},
+				{ip: "10.0.0.1", expCode: http.StatusOK},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		testcase := tc
+		t.Run(testcase.name, func(t *testing.T) {
+			var store ratelimit.Store = ratelimit.NewMemoryStore(ratelimit.WithClock(clock))
+			if testcase.store != nil {
+				store = testcase.store()
+			}
+
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				if user := c.GetHeader("X-Test-User"); user != "" {
+					c.Set(string(middlewares.ContextUserID), user)
+				}
+			})
+			router.POST("/api/shorten", middlewares.RateLimitMiddleware(store, "create", testcase.limit, logrus.New()),
+				func(c *gin.Context) {
+					c.Status(http.StatusOK)
+				})
+
+			for _, r := range testcase.requests {
+				req := httptest.NewRequest(http.MethodPost, "/api/shorten", nil)
+				req.RemoteAddr = r.ip + ":5000"
+				if r.user != "" {
+					req.Header.Set("X-Test-User", r.user)
+				}
+				w := httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+
+				require.Equal(t, r.expCode, w.Code, r)
+				if r.expCode == http.StatusTooManyRequests {
+					assert.Equal(t, "5", w.Header().Get("Retry-After"))
+					assert.Equal(t, "2", w.Header().Get("RateLimit-Limit"))
+					assert.Equal(t, "0", w.Header().Get("RateLimit-Remaining"))
+					assert.Equal(t, "10", w.Header().Get("RateLimit-Reset"))
+					assert.Contains(t, w.Body.String(), `"code":"`+problem.CodeRateLimited+`"`)
+				}
+				if !testcase.limit.Enabled() || testcase.store != nil {
+					assert.Empty(t, w.Header().Get("RateLimit-Limit"))
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+const (
+	ScopeShorten = "shorten"
+	ScopeRead    = "read"
+	ScopeDelete  = "delete"
+)
+
+var APIKeyScopes = []string{ScopeShorten, ScopeRead, ScopeDelete}
+
+// APIKey - ключ для интеграций между сервисами. Хранится только хэш, сам ключ отдается один раз при создании.
+type APIKey struct {
+	ID         string     `json:"id"`
+	CreatorID  string     `json:"-"`
+	Name       string     `json:"name,omitempty"`
+	Prefix     string     `json:"prefix"`
+	Hash       string     `json:"-"`
+	Key        string     `json:"key,omitempty"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+}
+
+// Allows - пустой список прав означает полный доступ.
+func (k APIKey) Allows(scope string) bool {
+	return len(k.Scopes) == 0 || slices.Contains(k.Scopes, scope)
+}
+
+type RequestAPIKey struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// Identity связывает учетную запись у внешнего OIDC-провайдера с пользователем сервиса.
+// Провайдер определяется issuer, пользователь в нем - subject; email хранится только подтвержденный.
+type Identity struct {
+	Issuer    string    `json:"issuer"`
+	Subject   string    `json:"subject"`
+	UserID    string    `json:"-"`
+	Email     string    `json:"email,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
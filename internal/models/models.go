@@ -3,15 +3,33 @@ package models
 import (
 	"encoding/json"
 	"net/url"
+	"time"
 )
 
+type LinkOptions struct {
+	RedirectCode   int             `json:"redirect_code,omitempty"`
+	TargetingRules []TargetingRule `json:"targeting_rules,omitempty"`
+	Variants       []Variant       `json:"variants,omitempty"`
+	StickyVariants bool            `json:"sticky_variants,omitempty"`
+	Passthrough    string          `json:"passthrough,omitempty"`
+	UTM            *UTM            `json:"utm,omitempty"`
+	Title          string          `json:"title,omitempty"`
+	OpenGraph      *OpenGraph      `json:"open_graph,omitempty"`
+	// WorkspaceID - пространство, которому принадлежит ссылка; пусто - ссылка личная.
+	WorkspaceID string `json:"workspace_id,omitempty"`
+	// Domain - короткий домен ссылки; пусто - основной домен из BASE_URL.
+	Domain string `json:"domain,omitempty"`
+}
+
 type Request struct {
 	URL url.URL `json:"url"`
+	LinkOptions
 }
 
 type RequestBulk struct {
 	OriginalURL   url.URL `json:"original_url"`
 	CorrelationID string  `json:"correlation_id"`
+	LinkOptions
 }
 
 func (r *RequestBulk) UnmarshalJSON(data []byte) error {
@@ -36,6 +54,7 @@ func (r *RequestBulk) UnmarshalJSON(data []byte) error {
 
 	r.OriginalURL = *uri
 	r.CorrelationID = aliasValue.CorrelationID
+	r.LinkOptions = aliasValue.LinkOptions
 
 	return nil
 }
@@ -45,6 +64,8 @@ type ListRequestBulk []RequestBulk
 type ResponseBulk struct {
 	ShortURL      string `json:"short_url"`
 	CorrelationID string `json:"correlation_id"`
+	// Duplicate - адрес уже был сокращен: отдана существующая ссылка, настройки из запроса не применены.
+	Duplicate bool `json:"duplicate,omitempty"`
 }
 
 type ListResponseBulk []ResponseBulk
@@ -69,6 +90,7 @@ func (r *Request) UnmarshalJSON(data []byte) error {
 	}
 
 	r.URL = *uri
+	r.LinkOptions = aliasValue.LinkOptions
 
 	return nil
 }
@@ -78,13 +100,83 @@ type Response struct {
 }
 
 type Event struct {
-	UUID        string `json:"uuid"`
-	CreatorID   string `json:"creator_id"`
+	UUID      string `json:"uuid"`
+	CreatorID string `json:"creator_id"`
+	// Code - код ссылки, уникален в пределах домена. Хранится только он.
+	Code string `json:"code"`
+	// ShortURL собирается из домена и кода при ответе по текущему BASE_URL, в хранилище не пишется.
+	ShortURL    string    `json:"short_url,omitempty"`
+	OriginalURL string    `json:"original_url"`
+	CreatedAt   time.Time `json:"created_at"`
+	// Duplicate отмечает в ответе на пакет адрес, сокращенный раньше. Не хранится.
+	Duplicate bool `json:"-"`
+	LinkOptions
+}
+
+func (e Event) Ref() LinkRef {
+	return LinkRef{Domain: e.Domain, Code: e.Code}
+}
+
+// LinkRef - ключ ссылки в хранилище. Domain пустой для основного домена.
+type LinkRef struct {
+	Domain string
+	Code   string
+}
+
+func (r LinkRef) String() string {
+	if r.Domain == "" {
+		return r.Code
+	}
+
+	return r.Domain + "/" + r.Code
+}
+
+type Redirect struct {
+	URL        string
+	StatusCode int
+	Variant    string
+	Sticky     bool
+}
+
+type Visitor struct {
+	UserAgent      string
+	AcceptLanguage string
+	Query          url.Values
+	// Path - хвост пути после кода ссылки, например /docs для /abc/docs.
+	Path string
+	// Variant - вариант, выбранный посетителю раньше (из cookie).
+	Variant string
+}
+
+type ResponseGetURLs struct {
 	ShortURL    string `json:"short_url"`
 	OriginalURL string `json:"original_url"`
+	UTM         *UTM   `json:"utm,omitempty"`
+	WorkspaceID string `json:"workspace_id,omitempty"`
 }
 
-type ResponseGetURLs struct {
+type LinkFilter struct {
+	Campaign string
+	// Workspace - только ссылки этого пространства.
+	Workspace string
+}
+
+type LinkHealth struct {
+	// URL - проверенный адрес. У ссылки с таргетингом или вариантами это первый неоткрывшийся из них.
+	URL        string    `json:"url,omitempty"`
+	StatusCode int       `json:"status_code"`
+	LatencyMS  int64     `json:"latency_ms"`
+	CheckedAt  time.Time `json:"checked_at"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Broken - ссылка не открывается: ошибка соединения или ответ 4xx/5xx.
+func (h LinkHealth) Broken() bool {
+	return h.Error != "" || h.StatusCode >= 400
+}
+
+type ResponseBrokenURL struct {
 	ShortURL    string `json:"short_url"`
 	OriginalURL string `json:"original_url"`
+	LinkHealth
 }
@@ -0,0 +1,22 @@
+package models
+
+type OpenGraph struct {
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	Image       string `json:"image,omitempty"`
+}
+
+func (og OpenGraph) IsZero() bool {
+	return og == OpenGraph{}
+}
+
+type LinkMeta struct {
+	Title     string     `json:"title"`
+	OpenGraph *OpenGraph `json:"open_graph,omitempty"`
+}
+
+type Unfurl struct {
+	ShortURL    string
+	OriginalURL string
+	OpenGraph   OpenGraph
+}
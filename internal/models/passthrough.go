@@ -0,0 +1,9 @@
+package models
+
+// Режимы передачи параметров запроса и хвоста пути в целевой URL.
+const (
+	PassthroughNone  = ""
+	PassthroughQuery = "query"
+	PassthroughPath  = "path"
+	PassthroughAll   = "all"
+)
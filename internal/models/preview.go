@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+type Preview struct {
+	ShortURL    string     `json:"short_url"`
+	OriginalURL string     `json:"original_url"`
+	Title       string     `json:"title,omitempty"`
+	CreatedAt   *time.Time `json:"created_at,omitempty"`
+	Clicks      int        `json:"clicks"`
+}
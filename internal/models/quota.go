@@ -0,0 +1,15 @@
+package models
+
+// Quota - ограничения тарифа пользователя, 0 - без ограничения.
+type Quota struct {
+	// Links - сколько всего ссылок может быть у пользователя.
+	Links int `json:"links"`
+	// BatchSize - сколько ссылок можно создать одним пакетом.
+	BatchSize int `json:"batch_size"`
+}
+
+// QuotaUsage - квота пользователя и сколько ссылок у него уже есть.
+type QuotaUsage struct {
+	Quota
+	LinksUsed int `json:"links_used"`
+}
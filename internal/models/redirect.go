@@ -0,0 +1,14 @@
+package models
+
+import "net/http"
+
+// ValidRedirectCode сообщает, можно ли использовать код для редиректа по короткой ссылке.
+func ValidRedirectCode(code int) bool {
+	switch code {
+	case http.StatusMovedPermanently, http.StatusFound,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+
+	return false
+}
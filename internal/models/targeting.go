@@ -0,0 +1,17 @@
+package models
+
+const (
+	DeviceIOS     = "ios"
+	DeviceAndroid = "android"
+	DeviceMobile  = "mobile"
+	DeviceDesktop = "desktop"
+)
+
+// TargetingRule отправляет посетителя на URL, если совпали все заданные условия.
+// Правила проверяются по порядку, срабатывает первое подходящее.
+type TargetingRule struct {
+	Device   string            `json:"device,omitempty"`
+	Language string            `json:"language,omitempty"`
+	Query    map[string]string `json:"query,omitempty"`
+	URL      string            `json:"url"`
+}
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// User - зарегистрированный пользователь. ID совпадает с тем, что кладется в токен,
+// поэтому ссылки, webhooks и ключи привязываются к нему так же, как к анонимному UUID.
+type User struct {
+	ID           string    `json:"id"`
+	Email        string    `json:"email"`
+	PasswordHash string    `json:"-"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type Credentials struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
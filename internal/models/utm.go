@@ -0,0 +1,32 @@
+package models
+
+type UTM struct {
+	Source   string `json:"source"`
+	Medium   string `json:"medium,omitempty"`
+	Campaign string `json:"campaign,omitempty"`
+	Term     string `json:"term,omitempty"`
+	Content  string `json:"content,omitempty"`
+}
+
+// Params возвращает метки в виде параметров запроса utm_*.
+func (u UTM) Params() map[string]string {
+	params := map[string]string{
+		"utm_source":   u.Source,
+		"utm_medium":   u.Medium,
+		"utm_campaign": u.Campaign,
+		"utm_term":     u.Term,
+		"utm_content":  u.Content,
+	}
+
+	for k, v := range params {
+		if v == "" {
+			delete(params, k)
+		}
+	}
+
+	return params
+}
+
+func (u UTM) IsZero() bool {
+	return u == UTM{}
+}
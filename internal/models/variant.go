@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+type Variant struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Weight int    `json:"weight"`
+}
+
+type RequestVariants struct {
+	Variants []Variant `json:"variants"`
+	Sticky   bool      `json:"sticky"`
+}
+
+type Click struct {
+	Link      LinkRef
+	Variant   string
+	CreatedAt time.Time
+}
+
+type ClickStats struct {
+	ShortURL string         `json:"short_url"`
+	Clicks   int            `json:"clicks"`
+	Variants map[string]int `json:"variants,omitempty"`
+}
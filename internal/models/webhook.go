@@ -0,0 +1,68 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	NotificationLinkCreated = "link.created"
+	NotificationLinkUpdated = "link.updated"
+	NotificationLinkDeleted = "link.deleted"
+	NotificationLinkClicked = "link.clicked"
+)
+
+var NotificationTypes = []string{NotificationLinkCreated, NotificationLinkUpdated, NotificationLinkDeleted, NotificationLinkClicked}
+
+// Notification - событие жизненного цикла ссылки, уходит в вебхуки и в поток SSE.
+type Notification struct {
+	ID          string    `json:"id"`
+	Type        string    `json:"type"`
+	CreatorID   string    `json:"-"`
+	ShortURL    string    `json:"short_url"`
+	OriginalURL string    `json:"original_url,omitempty"`
+	Variant     string    `json:"variant,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type Webhook struct {
+	ID        string    `json:"id"`
+	CreatorID string    `json:"-"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Subscribed - пустой список событий означает подписку на все.
+func (w Webhook) Subscribed(eventType string) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+
+	for _, e := range w.Events {
+		if e == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+type RequestWebhook struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+}
+
+// WebhookDelivery - одна попытка доставки. Dead выставляется, когда попытки кончились.
+type WebhookDelivery struct {
+	ID         string          `json:"id"`
+	WebhookID  string          `json:"webhook_id"`
+	Event      string          `json:"event"`
+	Payload    json.RawMessage `json:"payload"`
+	Attempt    int             `json:"attempt"`
+	StatusCode int             `json:"status_code"`
+	Error      string          `json:"error,omitempty"`
+	Dead       bool            `json:"dead"`
+	CreatedAt  time.Time       `json:"created_at"`
+}
@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+const (
+	RoleOwner  = "owner"
+	RoleEditor = "editor"
+	RoleViewer = "viewer"
+)
+
+// roleLevels - каждая роль может все, что роли ниже нее.
+var roleLevels = map[string]int{RoleViewer: 1, RoleEditor: 2, RoleOwner: 3}
+
+// Workspace - пространство команды. Ссылки в нем принадлежат пространству, а не создателю,
+// поэтому остаются управляемыми, когда создатель уходит из команды.
+type Workspace struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	// Role - роль текущего пользователя, заполняется в списке его пространств.
+	Role string `json:"role,omitempty"`
+}
+
+type WorkspaceMember struct {
+	WorkspaceID string    `json:"-"`
+	UserID      string    `json:"user_id"`
+	Role        string    `json:"role"`
+	AddedAt     time.Time `json:"added_at"`
+}
+
+type RequestWorkspace struct {
+	Name string `json:"name"`
+}
+
+type RequestWorkspaceMember struct {
+	Role string `json:"role"`
+}
+
+type RequestLinkWorkspace struct {
+	// WorkspaceID - пустое значение возвращает ссылку в личные ссылки пользователя.
+	WorkspaceID string `json:"workspace_id"`
+}
+
+func ValidWorkspaceRole(role string) bool {
+	_, ok := roleLevels[role]
+
+	return ok
+}
+
+// RoleAllows - роль не ниже требуемой.
+func RoleAllows(role, required string) bool {
+	return ValidWorkspaceRole(role) && roleLevels[role] >= roleLevels[required]
+}
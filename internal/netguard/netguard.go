@@ -0,0 +1,48 @@
+// Package netguard содержит HTTP-клиент для запросов на адреса, которые задают пользователи.
+package netguard
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+var ErrPrivateAddress = errors.New("destination resolves to a private address")
+
+// NewClient не ходит во внутреннюю сеть: проверка адреса делается после резолва,
+// поэтому DNS-имя, указывающее на 127.0.0.1 или 10.0.0.0/8, тоже не пройдет.
+func NewClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: func(_, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+
+			if !Public(net.ParseIP(host)) {
+				return ErrPrivateAddress
+			}
+
+			return nil
+		},
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			// через прокси проверялся бы адрес прокси, а не адрес назначения
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConnsPerHost: 1,
+		},
+	}
+}
+
+func Public(ip net.IP) bool {
+	return ip != nil && !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsUnspecified() &&
+		!ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast()
+}
@@ -0,0 +1,71 @@
+package passwords
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Параметры argon2id из рекомендаций OWASP: 19 МиБ памяти, 2 прохода, 1 поток.
+const (
+	memoryKiB = 19 * 1024
+	passes    = 2
+	threads   = 1
+	keyLen    = 32
+	saltLen   = 16
+)
+
+var ErrMalformedHash = errors.New("password hash is malformed")
+
+var b64 = base64.RawStdEncoding
+
+// Hash возвращает хэш в формате PHC: $argon2id$v=19$m=...,t=...,p=...$соль$хэш.
+// Параметры хранятся в самой строке, поэтому их можно поменять без миграции старых хэшей.
+func Hash(password string) (string, error) {
+	salt := make([]byte, saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("generate salt failed: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, passes, memoryKiB, threads, keyLen)
+
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, memoryKiB, passes, threads, b64.EncodeToString(salt), b64.EncodeToString(key)), nil
+}
+
+// Verify сравнивает пароль с хэшем за постоянное время.
+func Verify(password, encoded string) (bool, error) {
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return false, ErrMalformedHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false, ErrMalformedHash
+	}
+
+	var m, t uint32
+	var p uint8
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &m, &t, &p); err != nil {
+		return false, ErrMalformedHash
+	}
+
+	salt, err := b64.DecodeString(parts[4])
+	if err != nil {
+		return false, ErrMalformedHash
+	}
+	want, err := b64.DecodeString(parts[5])
+	if err != nil || len(want) == 0 {
+		return false, ErrMalformedHash
+	}
+
+	got := argon2.IDKey([]byte(password), salt, t, m, p, uint32(len(want)))
+
+	return subtle.ConstantTimeCompare(got, want) == 1, nil
+}
@@ -0,0 +1,54 @@
+package passwords_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/patrick-devel/shorturl/internal/passwords"
+)
+
+func TestHashAndVerify(t *testing.T) {
+	hash, err := passwords.Hash("correct horse battery staple")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(hash, "$argon2id$v=19$m=19456,t=2,p=1$"))
+
+	ok, err := passwords.Verify("correct horse battery staple", hash)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = passwords.Verify("Correct horse battery staple", hash)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// соль случайная, одинаковые пароли дают разные хэши
+	again, err := passwords.Hash("correct horse battery staple")
+	require.NoError(t, err)
+	assert.NotEqual(t, hash, again)
+}
+
+func TestVerifyOtherParameters(t *testing.T) {
+	// хэш с другими параметрами проверяется по параметрам из строки
+	const hash = "$argon2id$v=19$m=8192,t=1,p=2$c29tZXNhbHRzb21lc2FsdA$uxIE8SI7P041/gi+d7ewgXDY5xJkVijfcwDHtShQK5U"
+
+	ok, err := passwords.Verify("password", hash)
+	require.NoError(t, err)
+	assert.True(t, ok)
+}
+
+func TestVerifyMalformed(t *testing.T) {
+	for _, hash := range []string{
+		"",
+		"plain-text",
+		"$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy",
+		"$argon2id$v=18$m=8192,t=1,p=2$c29tZXNhbHQ$aGFzaA",
+		"$argon2id$v=19$m=x,t=1,p=2$c29tZXNhbHQ$aGFzaA",
+		"$argon2id$v=19$m=8192,t=1,p=2$!!!$aGFzaA",
+		"$argon2id$v=19$m=8192,t=1,p=2$c29tZXNhbHQ$",
+	} {
+		_, err := passwords.Verify("password", hash)
+		assert.ErrorIs(t, err, passwords.ErrMalformedHash, hash)
+	}
+}
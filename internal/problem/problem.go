@@ -0,0 +1,75 @@
+package problem
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const ContentType = "application/problem+json"
+
+// Коды ошибок. Клиенты разбирают ответы по ним, поэтому существующие коды не меняются.
+const (
+	CodeInvalidURL     = "invalid_url"
+	CodeInvalidRequest = "invalid_request"
+	CodeDuplicate      = "duplicate"
+	CodeEmailTaken     = "email_taken"
+	CodeNotFound       = "not_found"
+	CodeGone           = "gone"
+	CodeUnauthorized   = "unauthorized"
+	CodeTokenExpired   = "token_expired"
+	CodeForbidden      = "forbidden"
+	CodePolicyBlocked  = "policy_blocked"
+	CodeRateLimited    = "rate_limited"
+	CodeQuotaExceeded  = "quota_exceeded"
+	CodeSSOUnavailable = "sso_unavailable"
+	CodeInternal       = "internal"
+)
+
+var titles = map[string]string{
+	CodeInvalidURL:     "URL is invalid",
+	CodeInvalidRequest: "Request is invalid",
+	CodeDuplicate:      "URL is already shortened",
+	CodeEmailTaken:     "Email is already registered",
+	CodeNotFound:       "Not found",
+	CodeGone:           "Link was deleted",
+	CodeUnauthorized:   "Authorization required",
+	CodeTokenExpired:   "Access token expired, refresh it",
+	CodeForbidden:      "Access denied",
+	CodePolicyBlocked:  "Destination is not allowed",
+	CodeRateLimited:    "Too many requests",
+	CodeQuotaExceeded:  "Plan quota exceeded",
+	CodeSSOUnavailable: "Identity provider is unavailable, try again later",
+	CodeInternal:       "Internal error",
+}
+
+// Problem - тело ошибки по RFC 7807.
+type Problem struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	Code   string `json:"code"`
+	// Result - существующая короткая ссылка для duplicate, то же поле, что в ответе /api/shorten.
+	Result string `json:"result,omitempty"`
+}
+
+func New(status int, code, detail string) Problem {
+	return Problem{
+		Type:   "urn:shorturl:problem:" + code,
+		Title:  titles[code],
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+func Internal() Problem {
+	return New(http.StatusInternalServerError, CodeInternal, "")
+}
+
+// Abort пишет ошибку и прерывает цепочку обработчиков.
+func Abort(c *gin.Context, p Problem) {
+	c.Header("Content-Type", ContentType)
+	c.AbortWithStatusJSON(p.Status, p)
+}
@@ -0,0 +1,221 @@
+package qr
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"strconv"
+	"strings"
+
+	"github.com/skip2/go-qrcode"
+)
+
+const (
+	FormatPNG = "png"
+	FormatSVG = "svg"
+
+	DefaultSize   = 256
+	MinSize       = 64
+	MaxSize       = 2048
+	DefaultMargin = 4
+	MaxMargin     = 16
+)
+
+var ErrInvalidOptions = errors.New("invalid qr options")
+
+type Options struct {
+	Format     string
+	Size       int
+	ECC        string
+	Margin     int
+	Foreground color.RGBA
+	Background color.RGBA
+}
+
+func DefaultOptions() Options {
+	return Options{
+		Format:     FormatPNG,
+		Size:       DefaultSize,
+		ECC:        "M",
+		Margin:     DefaultMargin,
+		Foreground: color.RGBA{A: 0xff},
+		Background: color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+	}
+}
+
+// ParseOptions разбирает параметры запроса, незаданные заполняет значениями по умолчанию.
+func ParseOptions(get func(key string) string) (Options, error) {
+	opts := DefaultOptions()
+
+	if v := get("format"); v != "" {
+		v = strings.ToLower(v)
+		if v != FormatPNG && v != FormatSVG {
+			return opts, fmt.Errorf("%w: unknown format %q", ErrInvalidOptions, v)
+		}
+		opts.Format = v
+	}
+
+	if v := get("size"); v != "" {
+		size, err := strconv.Atoi(v)
+		if err != nil || size < MinSize || size > MaxSize {
+			return opts, fmt.Errorf("%w: size must be between %d and %d", ErrInvalidOptions, MinSize, MaxSize)
+		}
+		opts.Size = size
+	}
+
+	if v := get("ecc"); v != "" {
+		v = strings.ToUpper(v)
+		if _, err := recoveryLevel(v); err != nil {
+			return opts, err
+		}
+		opts.ECC = v
+	}
+
+	if v := get("margin"); v != "" {
+		margin, err := strconv.Atoi(v)
+		if err != nil || margin < 0 || margin > MaxMargin {
+			return opts, fmt.Errorf("%w: margin must be between 0 and %d", ErrInvalidOptions, MaxMargin)
+		}
+		opts.Margin = margin
+	}
+
+	var err error
+	if v := get("fg"); v != "" {
+		if opts.Foreground, err = parseColor(v); err != nil {
+			return opts, err
+		}
+	}
+
+	if v := get("bg"); v != "" {
+		if opts.Background, err = parseColor(v); err != nil {
+			return opts, err
+		}
+	}
+
+	return opts, nil
+}
+
+// Key однозначно описывает картинку, используется для ETag и имен файлов.
+func (o Options) Key() string {
+	return fmt.Sprintf("%s-%d-%s-%d-%s-%s", o.Format, o.Size, o.ECC, o.Margin, hexColor(o.Foreground), hexColor(o.Background))
+}
+
+func (o Options) ContentType() string {
+	if o.Format == FormatSVG {
+		return "image/svg+xml"
+	}
+
+	return "image/png"
+}
+
+func Render(content string, opts Options) ([]byte, error) {
+	level, err := recoveryLevel(opts.ECC)
+	if err != nil {
+		return nil, err
+	}
+
+	code, err := qrcode.New(content, level)
+	if err != nil {
+		return nil, fmt.Errorf("encode qr failed: %w", err)
+	}
+	code.DisableBorder = true
+
+	modules := withMargin(code.Bitmap(), opts.Margin)
+
+	if opts.Format == FormatSVG {
+		return renderSVG(modules, opts), nil
+	}
+
+	return renderPNG(modules, opts)
+}
+
+func withMargin(bitmap [][]bool, margin int) [][]bool {
+	size := len(bitmap) + 2*margin
+	modules := make([][]bool, size)
+	for y := range modules {
+		modules[y] = make([]bool, size)
+		if y < margin || y >= margin+len(bitmap) {
+			continue
+		}
+		copy(modules[y][margin:], bitmap[y-margin])
+	}
+
+	return modules
+}
+
+func renderPNG(modules [][]bool, opts Options) ([]byte, error) {
+	img := image.NewPaletted(image.Rect(0, 0, opts.Size, opts.Size), color.Palette{opts.Background, opts.Foreground})
+
+	count := len(modules)
+	for y := 0; y < opts.Size; y++ {
+		my := y * count / opts.Size
+		for x := 0; x < opts.Size; x++ {
+			if modules[my][x*count/opts.Size] {
+				img.SetColorIndex(x, y, 1)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("encode png failed: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func renderSVG(modules [][]bool, opts Options) []byte {
+	count := len(modules)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		opts.Size, opts.Size, count, count)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="#%s"/>`, count, count, hexColor(opts.Background))
+	fmt.Fprintf(&buf, `<path fill="#%s" d="`, hexColor(opts.Foreground))
+	for y, row := range modules {
+		for x, dark := range row {
+			if dark {
+				fmt.Fprintf(&buf, "M%d %dh1v1h-1z", x, y)
+			}
+		}
+	}
+	buf.WriteString(`"/></svg>`)
+
+	return buf.Bytes()
+}
+
+func recoveryLevel(ecc string) (qrcode.RecoveryLevel, error) {
+	switch ecc {
+	case "L":
+		return qrcode.Low, nil
+	case "M":
+		return qrcode.Medium, nil
+	case "Q":
+		return qrcode.High, nil
+	case "H":
+		return qrcode.Highest, nil
+	}
+
+	return 0, fmt.Errorf("%w: ecc must be one of L, M, Q, H", ErrInvalidOptions)
+}
+
+func parseColor(v string) (color.RGBA, error) {
+	v = strings.TrimPrefix(v, "#")
+	if len(v) != 6 {
+		return color.RGBA{}, fmt.Errorf("%w: color must be in form RRGGBB", ErrInvalidOptions)
+	}
+
+	rgb, err := strconv.ParseUint(v, 16, 32)
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("%w: color must be in form RRGGBB", ErrInvalidOptions)
+	}
+
+	return color.RGBA{R: uint8(rgb >> 16), G: uint8(rgb >> 8), B: uint8(rgb), A: 0xff}, nil
+}
+
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("%02x%02x%02x", c.R, c.G, c.B)
+}
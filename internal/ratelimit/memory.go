@@ -0,0 +1,107 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	// full - момент, когда корзина наполнится, после него ее можно забыть
+	full time.Time
+}
+
+// MemoryStore держит корзины в памяти процесса. Полные корзины периодически удаляются:
+// новая корзина для того же ключа ничем от них не отличается.
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+type MemoryOption func(s *MemoryStore)
+
+// WithClock подменяет часы, для тестов.
+func WithClock(now func() time.Time) MemoryOption {
+	return func(s *MemoryStore) {
+		s.now = now
+	}
+}
+
+func NewMemoryStore(opts ...MemoryOption) *MemoryStore {
+	s := &MemoryStore{buckets: map[string]*bucket{}, now: time.Now}
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+func (s *MemoryStore) Take(_ context.Context, keys []string, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	capacity := float64(limit.Requests)
+	// время на один токен
+	interval := float64(limit.Period) / capacity
+
+	result := Result{Allowed: true, Limit: limit.Requests, Remaining: limit.Requests}
+	buckets := make([]*bucket, 0, len(keys))
+	for _, key := range keys {
+		b, ok := s.buckets[key]
+		if !ok {
+			b = &bucket{tokens: capacity, updated: now}
+			s.buckets[key] = b
+		}
+		b.tokens = math.Min(capacity, b.tokens+float64(now.Sub(b.updated))/interval)
+		b.updated = now
+		if b.tokens < 1 {
+			result.Allowed = false
+		}
+		buckets = append(buckets, b)
+	}
+
+	var emptiest *bucket
+	for _, b := range buckets {
+		if result.Allowed {
+			b.tokens--
+		}
+		b.full = now.Add(time.Duration(math.Ceil((capacity - b.tokens) * interval)))
+		if emptiest == nil || b.tokens < emptiest.tokens {
+			emptiest = b
+		}
+	}
+	if emptiest == nil {
+		return result, nil
+	}
+
+	if !result.Allowed {
+		result.RetryAfter = time.Duration(math.Ceil((1 - emptiest.tokens) * interval))
+	}
+	result.Remaining = int(emptiest.tokens)
+	result.Reset = emptiest.full.Sub(now)
+
+	return result, nil
+}
+
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		if !now.Before(b.full) {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+// Package ratelimit ограничивает частоту запросов корзинами токенов.
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrInvalidLimit = errors.New("rate limit is invalid")
+
+// Limit - корзина на Requests запросов, которая наполняется целиком за Period.
+// Нулевой лимит ничего не ограничивает.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+func (l Limit) Enabled() bool {
+	return l.Requests > 0 && l.Period > 0
+}
+
+// ParseLimit разбирает лимит вида 100/1m. Пустая строка и off выключают ограничение.
+func ParseLimit(value string) (Limit, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "off" {
+		return Limit{}, nil
+	}
+
+	requests, period, ok := strings.Cut(value, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("%w: %q, expected requests/period, e.g. 100/1m", ErrInvalidLimit, value)
+	}
+
+	n, err := strconv.Atoi(requests)
+	if err != nil || n <= 0 {
+		return Limit{}, fmt.Errorf("%w: %q: requests must be a positive number", ErrInvalidLimit, value)
+	}
+
+	d, err := time.ParseDuration(period)
+	if err != nil || d <= 0 {
+		return Limit{}, fmt.Errorf("%w: %q: period must be a positive duration", ErrInvalidLimit, value)
+	}
+
+	return Limit{Requests: n, Period: d}, nil
+}
+
+// Limits - лимиты групп маршрутов.
+type Limits struct {
+	Create   Limit
+	Batch    Limit
+	Delete   Limit
+	Redirect Limit
+	// Auth - вход и регистрация по паролю, только по IP: ограничивает перебор паролей.
+	Auth Limit
+}
+
+// Result - состояние корзины после запроса, из него собираются заголовки RateLimit-*.
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// RetryAfter - когда появится следующий токен, только для отклоненного запроса.
+	RetryAfter time.Duration
+	// Reset - когда корзина наполнится целиком.
+	Reset time.Duration
+}
+
+// Store хранит корзины. MemoryStore годится для одного экземпляра сервиса,
+// несколько экземпляров должны делить корзины через общее хранилище.
+// Take берет по токену из каждой корзины keys, только если токен есть во всех: запрос, отклоненный
+// одной корзиной, не тратит остальные. Result описывает самую пустую из корзин.
+type Store interface {
+	Take(ctx context.Context, keys []string, limit Limit) (Result, error)
+}
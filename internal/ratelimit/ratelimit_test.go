@@ -0,0 +1,105 @@
+package ratelimit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/patrick-devel/shorturl/internal/ratelimit"
+)
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := ratelimit.NewMemoryStore(ratelimit.WithClock(func() time.Time { return now }))
+	limit := ratelimit.Limit{Requests: 3, Period: 3 * time.Second}
+	ctx := context.Background()
+
+	for remaining := 2; remaining >= 0; remaining-- {
+		result, err := store.Take(ctx, []string{"ip:1"}, limit)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 3, result.Limit)
+		assert.Equal(t, remaining, result.Remaining)
+	}
+
+	result, err := store.Take(ctx, []string{"ip:1"}, limit)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, time.Second, result.RetryAfter)
+	assert.Equal(t, 3*time.Second, result.Reset)
+
+	// у другого ключа своя корзина
+	result, err = store.Take(ctx, []string{"ip:2"}, limit)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+
+	// за секунду появляется один токен, не больше
+	now = now.Add(time.Second)
+	result, err = store.Take(ctx, []string{"ip:1"}, limit)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+	result, err = store.Take(ctx, []string{"ip:1"}, limit)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+
+	// корзина не наполняется сверх емкости
+	now = now.Add(time.Hour)
+	result, err = store.Take(ctx, []string{"ip:1"}, limit)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 2, result.Remaining)
+	assert.Equal(t, time.Second, result.Reset)
+
+	// токен берется из обеих корзин или ни из одной
+	result, err = store.Take(ctx, []string{"ip:3", "user:1"}, limit)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	for i := 0; i < 2; i++ {
+		_, err = store.Take(ctx, []string{"user:1"}, limit)
+		require.NoError(t, err)
+	}
+	result, err = store.Take(ctx, []string{"ip:3", "user:1"}, limit)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+	result, err = store.Take(ctx, []string{"ip:3"}, limit)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 1, result.Remaining)
+}
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		value    string
+		expLimit ratelimit.Limit
+		expErr   bool
+	}{
+		{value: "100/1m", expLimit: ratelimit.Limit{Requests: 100, Period: time.Minute}},
+		{value: " 5/10s ", expLimit: ratelimit.Limit{Requests: 5, Period: 10 * time.Second}},
+		{value: ""},
+		{value: "off"},
+		{value: "100", expErr: true},
+		{value: "0/1m", expErr: true},
+		{value: "x/1m", expErr: true},
+		{value: "10/0s", expErr: true},
+		{value: "10/minute", expErr: true},
+	}
+
+	for _, tc := range tests {
+		testcase := tc
+		t.Run(testcase.value, func(t *testing.T) {
+			limit, err := ratelimit.ParseLimit(testcase.value)
+			if testcase.expErr {
+				assert.ErrorIs(t, err, ratelimit.ErrInvalidLimit)
+
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testcase.expLimit, limit)
+		})
+	}
+}
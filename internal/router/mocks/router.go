@@ -0,0 +1,595 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: internal/router/router.go
+
+// Package mock_router is a generated GoMock package.
+package mock_router
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/patrick-devel/shorturl/internal/models"
+	sso "github.com/patrick-devel/shorturl/internal/sso"
+)
+
+// MocklinkService is a mock of linkService interface.
+type MocklinkService struct {
+	ctrl     *gomock.Controller
+	recorder *MocklinkServiceMockRecorder
+}
+
+// MocklinkServiceMockRecorder is the mock recorder for MocklinkService.
+type MocklinkServiceMockRecorder struct {
+	mock *MocklinkService
+}
+
+// NewMocklinkService creates a new mock instance.
+func NewMocklinkService(ctrl *gomock.Controller) *MocklinkService {
+	mock := &MocklinkService{ctrl: ctrl}
+	mock.recorder = &MocklinkServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MocklinkService) EXPECT() *MocklinkServiceMockRecorder {
+	return m.recorder
+}
+
+// APIKeys mocks base method.
+func (m *MocklinkService) APIKeys(ctx context.Context) ([]models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "APIKeys", ctx)
+	ret0, _ := ret[0].([]models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// APIKeys indicates an expected call of APIKeys.
+func (mr *MocklinkServiceMockRecorder) APIKeys(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "APIKeys", reflect.TypeOf((*MocklinkService)(nil).APIKeys), ctx)
+}
+
+// BrokenLinks mocks base method.
+func (m *MocklinkService) BrokenLinks(ctx context.Context) ([]models.ResponseBrokenURL, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BrokenLinks", ctx)
+	ret0, _ := ret[0].([]models.ResponseBrokenURL)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BrokenLinks indicates an expected call of BrokenLinks.
+func (mr *MocklinkServiceMockRecorder) BrokenLinks(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BrokenLinks", reflect.TypeOf((*MocklinkService)(nil).BrokenLinks), ctx)
+}
+
+// ClickStats mocks base method.
+func (m *MocklinkService) ClickStats(ctx context.Context, hash string) (models.ClickStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClickStats", ctx, hash)
+	ret0, _ := ret[0].(models.ClickStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClickStats indicates an expected call of ClickStats.
+func (mr *MocklinkServiceMockRecorder) ClickStats(ctx, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClickStats", reflect.TypeOf((*MocklinkService)(nil).ClickStats), ctx, hash)
+}
+
+// CreateAPIKey mocks base method.
+func (m *MocklinkService) CreateAPIKey(ctx context.Context, request models.RequestAPIKey) (models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAPIKey", ctx, request)
+	ret0, _ := ret[0].(models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAPIKey indicates an expected call of CreateAPIKey.
+func (mr *MocklinkServiceMockRecorder) CreateAPIKey(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAPIKey", reflect.TypeOf((*MocklinkService)(nil).CreateAPIKey), ctx, request)
+}
+
+// CreateWebhook mocks base method.
+func (m *MocklinkService) CreateWebhook(ctx context.Context, request models.RequestWebhook) (models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhook", ctx, request)
+	ret0, _ := ret[0].(models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhook indicates an expected call of CreateWebhook.
+func (mr *MocklinkServiceMockRecorder) CreateWebhook(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhook", reflect.TypeOf((*MocklinkService)(nil).CreateWebhook), ctx, request)
+}
+
+// CreateWorkspace mocks base method.
+func (m *MocklinkService) CreateWorkspace(ctx context.Context, request models.RequestWorkspace) (models.Workspace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWorkspace", ctx, request)
+	ret0, _ := ret[0].(models.Workspace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWorkspace indicates an expected call of CreateWorkspace.
+func (mr *MocklinkServiceMockRecorder) CreateWorkspace(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWorkspace", reflect.TypeOf((*MocklinkService)(nil).CreateWorkspace), ctx, request)
+}
+
+// DeleteAPIKey mocks base method.
+func (m *MocklinkService) DeleteAPIKey(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteAPIKey", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteAPIKey indicates an expected call of DeleteAPIKey.
+func (mr *MocklinkServiceMockRecorder) DeleteAPIKey(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteAPIKey", reflect.TypeOf((*MocklinkService)(nil).DeleteAPIKey), ctx, id)
+}
+
+// DeleteShortURL mocks base method.
+func (m *MocklinkService) DeleteShortURL(ctx context.Context, shortUrls []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteShortURL", ctx, shortUrls)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteShortURL indicates an expected call of DeleteShortURL.
+func (mr *MocklinkServiceMockRecorder) DeleteShortURL(ctx, shortUrls interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteShortURL", reflect.TypeOf((*MocklinkService)(nil).DeleteShortURL), ctx, shortUrls)
+}
+
+// DeleteWebhook mocks base method.
+func (m *MocklinkService) DeleteWebhook(ctx context.Context, id string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhook", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook.
+func (mr *MocklinkServiceMockRecorder) DeleteWebhook(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MocklinkService)(nil).DeleteWebhook), ctx, id)
+}
+
+// Domains mocks base method.
+func (m *MocklinkService) Domains() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Domains")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// Domains indicates an expected call of Domains.
+func (mr *MocklinkServiceMockRecorder) Domains() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Domains", reflect.TypeOf((*MocklinkService)(nil).Domains))
+}
+
+// GetOriginalURL mocks base method.
+func (m *MocklinkService) GetOriginalURL(ctx context.Context, hash string, visitor models.Visitor) (models.Redirect, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOriginalURL", ctx, hash, visitor)
+	ret0, _ := ret[0].(models.Redirect)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOriginalURL indicates an expected call of GetOriginalURL.
+func (mr *MocklinkServiceMockRecorder) GetOriginalURL(ctx, hash, visitor interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOriginalURL", reflect.TypeOf((*MocklinkService)(nil).GetOriginalURL), ctx, hash, visitor)
+}
+
+// GetShortLink mocks base method.
+func (m *MocklinkService) GetShortLink(ctx context.Context, hash string) (models.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetShortLink", ctx, hash)
+	ret0, _ := ret[0].(models.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetShortLink indicates an expected call of GetShortLink.
+func (mr *MocklinkServiceMockRecorder) GetShortLink(ctx, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetShortLink", reflect.TypeOf((*MocklinkService)(nil).GetShortLink), ctx, hash)
+}
+
+// GetUnfurl mocks base method.
+func (m *MocklinkService) GetUnfurl(ctx context.Context, hash string) (models.Unfurl, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUnfurl", ctx, hash)
+	ret0, _ := ret[0].(models.Unfurl)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetUnfurl indicates an expected call of GetUnfurl.
+func (mr *MocklinkServiceMockRecorder) GetUnfurl(ctx, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUnfurl", reflect.TypeOf((*MocklinkService)(nil).GetUnfurl), ctx, hash)
+}
+
+// LinksByCreatorID mocks base method.
+func (m *MocklinkService) LinksByCreatorID(ctx context.Context, filter models.LinkFilter) ([]models.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LinksByCreatorID", ctx, filter)
+	ret0, _ := ret[0].([]models.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LinksByCreatorID indicates an expected call of LinksByCreatorID.
+func (mr *MocklinkServiceMockRecorder) LinksByCreatorID(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LinksByCreatorID", reflect.TypeOf((*MocklinkService)(nil).LinksByCreatorID), ctx, filter)
+}
+
+// Login mocks base method.
+func (m *MocklinkService) Login(ctx context.Context, request models.Credentials) (models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Login", ctx, request)
+	ret0, _ := ret[0].(models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Login indicates an expected call of Login.
+func (mr *MocklinkServiceMockRecorder) Login(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Login", reflect.TypeOf((*MocklinkService)(nil).Login), ctx, request)
+}
+
+// LoginWithIdentity mocks base method.
+func (m *MocklinkService) LoginWithIdentity(ctx context.Context, identity models.Identity) (models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoginWithIdentity", ctx, identity)
+	ret0, _ := ret[0].(models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LoginWithIdentity indicates an expected call of LoginWithIdentity.
+func (mr *MocklinkServiceMockRecorder) LoginWithIdentity(ctx, identity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoginWithIdentity", reflect.TypeOf((*MocklinkService)(nil).LoginWithIdentity), ctx, identity)
+}
+
+// MakeShortURL mocks base method.
+func (m *MocklinkService) MakeShortURL(ctx context.Context, originalURL, uid string, opts models.LinkOptions) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MakeShortURL", ctx, originalURL, uid, opts)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MakeShortURL indicates an expected call of MakeShortURL.
+func (mr *MocklinkServiceMockRecorder) MakeShortURL(ctx, originalURL, uid, opts interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MakeShortURL", reflect.TypeOf((*MocklinkService)(nil).MakeShortURL), ctx, originalURL, uid, opts)
+}
+
+// MakeShortURLs mocks base method.
+func (m *MocklinkService) MakeShortURLs(ctx context.Context, bulk models.ListRequestBulk) ([]models.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MakeShortURLs", ctx, bulk)
+	ret0, _ := ret[0].([]models.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MakeShortURLs indicates an expected call of MakeShortURLs.
+func (mr *MocklinkServiceMockRecorder) MakeShortURLs(ctx, bulk interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MakeShortURLs", reflect.TypeOf((*MocklinkService)(nil).MakeShortURLs), ctx, bulk)
+}
+
+// PreviewLink mocks base method.
+func (m *MocklinkService) PreviewLink(ctx context.Context, hash string) (models.Preview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PreviewLink", ctx, hash)
+	ret0, _ := ret[0].(models.Preview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PreviewLink indicates an expected call of PreviewLink.
+func (mr *MocklinkServiceMockRecorder) PreviewLink(ctx, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PreviewLink", reflect.TypeOf((*MocklinkService)(nil).PreviewLink), ctx, hash)
+}
+
+// Quota mocks base method.
+func (m *MocklinkService) Quota(ctx context.Context) (models.QuotaUsage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Quota", ctx)
+	ret0, _ := ret[0].(models.QuotaUsage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Quota indicates an expected call of Quota.
+func (mr *MocklinkServiceMockRecorder) Quota(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Quota", reflect.TypeOf((*MocklinkService)(nil).Quota), ctx)
+}
+
+// Register mocks base method.
+func (m *MocklinkService) Register(ctx context.Context, request models.Credentials) (models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Register", ctx, request)
+	ret0, _ := ret[0].(models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Register indicates an expected call of Register.
+func (mr *MocklinkServiceMockRecorder) Register(ctx, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Register", reflect.TypeOf((*MocklinkService)(nil).Register), ctx, request)
+}
+
+// RemoveWorkspaceMember mocks base method.
+func (m *MocklinkService) RemoveWorkspaceMember(ctx context.Context, workspaceID, userID string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveWorkspaceMember", ctx, workspaceID, userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveWorkspaceMember indicates an expected call of RemoveWorkspaceMember.
+func (mr *MocklinkServiceMockRecorder) RemoveWorkspaceMember(ctx, workspaceID, userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveWorkspaceMember", reflect.TypeOf((*MocklinkService)(nil).RemoveWorkspaceMember), ctx, workspaceID, userID)
+}
+
+// ResolveDomain mocks base method.
+func (m *MocklinkService) ResolveDomain(host string) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResolveDomain", host)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// ResolveDomain indicates an expected call of ResolveDomain.
+func (mr *MocklinkServiceMockRecorder) ResolveDomain(host interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResolveDomain", reflect.TypeOf((*MocklinkService)(nil).ResolveDomain), host)
+}
+
+// SetLinkMeta mocks base method.
+func (m *MocklinkService) SetLinkMeta(ctx context.Context, hash string, meta models.LinkMeta) (models.LinkMeta, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetLinkMeta", ctx, hash, meta)
+	ret0, _ := ret[0].(models.LinkMeta)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetLinkMeta indicates an expected call of SetLinkMeta.
+func (mr *MocklinkServiceMockRecorder) SetLinkMeta(ctx, hash, meta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLinkMeta", reflect.TypeOf((*MocklinkService)(nil).SetLinkMeta), ctx, hash, meta)
+}
+
+// SetLinkWorkspace mocks base method.
+func (m *MocklinkService) SetLinkWorkspace(ctx context.Context, hash string, request models.RequestLinkWorkspace) (models.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetLinkWorkspace", ctx, hash, request)
+	ret0, _ := ret[0].(models.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetLinkWorkspace indicates an expected call of SetLinkWorkspace.
+func (mr *MocklinkServiceMockRecorder) SetLinkWorkspace(ctx, hash, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLinkWorkspace", reflect.TypeOf((*MocklinkService)(nil).SetLinkWorkspace), ctx, hash, request)
+}
+
+// SetTargetingRules mocks base method.
+func (m *MocklinkService) SetTargetingRules(ctx context.Context, hash string, rules []models.TargetingRule) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetTargetingRules", ctx, hash, rules)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTargetingRules indicates an expected call of SetTargetingRules.
+func (mr *MocklinkServiceMockRecorder) SetTargetingRules(ctx, hash, rules interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTargetingRules", reflect.TypeOf((*MocklinkService)(nil).SetTargetingRules), ctx, hash, rules)
+}
+
+// SetVariants mocks base method.
+func (m *MocklinkService) SetVariants(ctx context.Context, hash string, variants []models.Variant, sticky bool) ([]models.Variant, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetVariants", ctx, hash, variants, sticky)
+	ret0, _ := ret[0].([]models.Variant)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetVariants indicates an expected call of SetVariants.
+func (mr *MocklinkServiceMockRecorder) SetVariants(ctx, hash, variants, sticky interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetVariants", reflect.TypeOf((*MocklinkService)(nil).SetVariants), ctx, hash, variants, sticky)
+}
+
+// SetWorkspaceMember mocks base method.
+func (m *MocklinkService) SetWorkspaceMember(ctx context.Context, workspaceID, userID string, request models.RequestWorkspaceMember) (models.WorkspaceMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetWorkspaceMember", ctx, workspaceID, userID, request)
+	ret0, _ := ret[0].(models.WorkspaceMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetWorkspaceMember indicates an expected call of SetWorkspaceMember.
+func (mr *MocklinkServiceMockRecorder) SetWorkspaceMember(ctx, workspaceID, userID, request interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWorkspaceMember", reflect.TypeOf((*MocklinkService)(nil).SetWorkspaceMember), ctx, workspaceID, userID, request)
+}
+
+// TargetingRules mocks base method.
+func (m *MocklinkService) TargetingRules(ctx context.Context, hash string) ([]models.TargetingRule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TargetingRules", ctx, hash)
+	ret0, _ := ret[0].([]models.TargetingRule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TargetingRules indicates an expected call of TargetingRules.
+func (mr *MocklinkServiceMockRecorder) TargetingRules(ctx, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TargetingRules", reflect.TypeOf((*MocklinkService)(nil).TargetingRules), ctx, hash)
+}
+
+// VerifyAPIKey mocks base method.
+func (m *MocklinkService) VerifyAPIKey(ctx context.Context, raw string) (models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyAPIKey", ctx, raw)
+	ret0, _ := ret[0].(models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyAPIKey indicates an expected call of VerifyAPIKey.
+func (mr *MocklinkServiceMockRecorder) VerifyAPIKey(ctx, raw interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyAPIKey", reflect.TypeOf((*MocklinkService)(nil).VerifyAPIKey), ctx, raw)
+}
+
+// WebhookDeliveries mocks base method.
+func (m *MocklinkService) WebhookDeliveries(ctx context.Context, id string, deadOnly bool) ([]models.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WebhookDeliveries", ctx, id, deadOnly)
+	ret0, _ := ret[0].([]models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WebhookDeliveries indicates an expected call of WebhookDeliveries.
+func (mr *MocklinkServiceMockRecorder) WebhookDeliveries(ctx, id, deadOnly interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WebhookDeliveries", reflect.TypeOf((*MocklinkService)(nil).WebhookDeliveries), ctx, id, deadOnly)
+}
+
+// Webhooks mocks base method.
+func (m *MocklinkService) Webhooks(ctx context.Context) ([]models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Webhooks", ctx)
+	ret0, _ := ret[0].([]models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Webhooks indicates an expected call of Webhooks.
+func (mr *MocklinkServiceMockRecorder) Webhooks(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Webhooks", reflect.TypeOf((*MocklinkService)(nil).Webhooks), ctx)
+}
+
+// WorkspaceMembers mocks base method.
+func (m *MocklinkService) WorkspaceMembers(ctx context.Context, workspaceID string) ([]models.WorkspaceMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WorkspaceMembers", ctx, workspaceID)
+	ret0, _ := ret[0].([]models.WorkspaceMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WorkspaceMembers indicates an expected call of WorkspaceMembers.
+func (mr *MocklinkServiceMockRecorder) WorkspaceMembers(ctx, workspaceID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WorkspaceMembers", reflect.TypeOf((*MocklinkService)(nil).WorkspaceMembers), ctx, workspaceID)
+}
+
+// Workspaces mocks base method.
+func (m *MocklinkService) Workspaces(ctx context.Context) ([]models.Workspace, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Workspaces", ctx)
+	ret0, _ := ret[0].([]models.Workspace)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Workspaces indicates an expected call of Workspaces.
+func (mr *MocklinkServiceMockRecorder) Workspaces(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Workspaces", reflect.TypeOf((*MocklinkService)(nil).Workspaces), ctx)
+}
+
+// MockoidcProvider is a mock of oidcProvider interface.
+type MockoidcProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockoidcProviderMockRecorder
+}
+
+// MockoidcProviderMockRecorder is the mock recorder for MockoidcProvider.
+type MockoidcProviderMockRecorder struct {
+	mock *MockoidcProvider
+}
+
+// NewMockoidcProvider creates a new mock instance.
+func NewMockoidcProvider(ctrl *gomock.Controller) *MockoidcProvider {
+	mock := &MockoidcProvider{ctrl: ctrl}
+	mock.recorder = &MockoidcProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockoidcProvider) EXPECT() *MockoidcProviderMockRecorder {
+	return m.recorder
+}
+
+// Begin mocks base method.
+func (m *MockoidcProvider) Begin(ctx context.Context) (string, sso.Flow, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Begin", ctx)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(sso.Flow)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Begin indicates an expected call of Begin.
+func (mr *MockoidcProviderMockRecorder) Begin(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Begin", reflect.TypeOf((*MockoidcProvider)(nil).Begin), ctx)
+}
+
+// Finish mocks base method.
+func (m *MockoidcProvider) Finish(ctx context.Context, flow sso.Flow, code string) (models.Identity, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Finish", ctx, flow, code)
+	ret0, _ := ret[0].(models.Identity)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Finish indicates an expected call of Finish.
+func (mr *MockoidcProviderMockRecorder) Finish(ctx, flow, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Finish", reflect.TypeOf((*MockoidcProvider)(nil).Finish), ctx, flow, code)
+}
@@ -0,0 +1,219 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+
+	"github.com/patrick-devel/shorturl/api"
+	"github.com/patrick-devel/shorturl/internal/handlers"
+	middlewares "github.com/patrick-devel/shorturl/internal/middlwares"
+	"github.com/patrick-devel/shorturl/internal/models"
+	"github.com/patrick-devel/shorturl/internal/problem"
+	"github.com/patrick-devel/shorturl/internal/ratelimit"
+	"github.com/patrick-devel/shorturl/internal/sso"
+	"github.com/patrick-devel/shorturl/internal/stream"
+	"github.com/patrick-devel/shorturl/internal/tokens"
+)
+
+const streamHeartbeat = 15 * time.Second
+
+// linkService - все, что HTTP API нужно от сервиса ссылок.
+type linkService interface {
+	MakeShortURL(ctx context.Context, originalURL, uid string, opts models.LinkOptions) (string, error)
+	MakeShortURLs(ctx context.Context, bulk models.ListRequestBulk) ([]models.Event, error)
+	GetOriginalURL(ctx context.Context, hash string, visitor models.Visitor) (models.Redirect, error)
+	GetUnfurl(ctx context.Context, hash string) (models.Unfurl, bool, error)
+	GetShortLink(ctx context.Context, hash string) (models.Event, error)
+	PreviewLink(ctx context.Context, hash string) (models.Preview, error)
+	LinksByCreatorID(ctx context.Context, filter models.LinkFilter) ([]models.Event, error)
+	DeleteShortURL(ctx context.Context, shortUrls []string) error
+	BrokenLinks(ctx context.Context) ([]models.ResponseBrokenURL, error)
+	TargetingRules(ctx context.Context, hash string) ([]models.TargetingRule, error)
+	SetTargetingRules(ctx context.Context, hash string, rules []models.TargetingRule) error
+	SetVariants(ctx context.Context, hash string, variants []models.Variant, sticky bool) ([]models.Variant, error)
+	ClickStats(ctx context.Context, hash string) (models.ClickStats, error)
+	SetLinkMeta(ctx context.Context, hash string, meta models.LinkMeta) (models.LinkMeta, error)
+	CreateWebhook(ctx context.Context, request models.RequestWebhook) (models.Webhook, error)
+	Webhooks(ctx context.Context) ([]models.Webhook, error)
+	DeleteWebhook(ctx context.Context, id string) error
+	WebhookDeliveries(ctx context.Context, id string, deadOnly bool) ([]models.WebhookDelivery, error)
+	CreateAPIKey(ctx context.Context, request models.RequestAPIKey) (models.APIKey, error)
+	APIKeys(ctx context.Context) ([]models.APIKey, error)
+	DeleteAPIKey(ctx context.Context, id string) error
+	VerifyAPIKey(ctx context.Context, raw string) (models.APIKey, error)
+	Register(ctx context.Context, request models.Credentials) (models.User, error)
+	Login(ctx context.Context, request models.Credentials) (models.User, error)
+	LoginWithIdentity(ctx context.Context, identity models.Identity) (models.User, error)
+	CreateWorkspace(ctx context.Context, request models.RequestWorkspace) (models.Workspace, error)
+	Workspaces(ctx context.Context) ([]models.Workspace, error)
+	WorkspaceMembers(ctx context.Context, workspaceID string) ([]models.WorkspaceMember, error)
+	SetWorkspaceMember(ctx context.Context, workspaceID, userID string, request models.RequestWorkspaceMember) (models.WorkspaceMember, error)
+	RemoveWorkspaceMember(ctx context.Context, workspaceID, userID string) error
+	SetLinkWorkspace(ctx context.Context, hash string, request models.RequestLinkWorkspace) (models.Event, error)
+	Quota(ctx context.Context) (models.QuotaUsage, error)
+	Domains() []string
+	ResolveDomain(host string) string
+}
+
+type oidcProvider interface {
+	Begin(ctx context.Context) (string, sso.Flow, error)
+	Finish(ctx context.Context, flow sso.Flow, code string) (models.Identity, error)
+}
+
+type router struct {
+	basePath       string
+	ping           func() error
+	oidc           oidcProvider
+	rateStore      ratelimit.Store
+	rateLimits     ratelimit.Limits
+	trustedProxies []string
+}
+
+type Option func(r *router)
+
+// WithBasePath - путь из BASE_URL, под ним живут короткие ссылки.
+func WithBasePath(path string) Option {
+	return func(r *router) {
+		r.basePath = path
+	}
+}
+
+// WithPing - проверка для /ping, например db.Ping.
+func WithPing(ping func() error) Option {
+	return func(r *router) {
+		r.ping = ping
+	}
+}
+
+// WithOIDC включает вход через OIDC-провайдера.
+func WithOIDC(provider oidcProvider) Option {
+	return func(r *router) {
+		r.oidc = provider
+	}
+}
+
+// WithRateLimits включает ограничение частоты запросов на создание, удаление и переход по ссылкам,
+// а также на вход и регистрацию.
+func WithRateLimits(store ratelimit.Store, limits ratelimit.Limits) Option {
+	return func(r *router) {
+		r.rateStore = store
+		r.rateLimits = limits
+	}
+}
+
+// WithTrustedProxies - прокси, которым можно верить в X-Forwarded-For. По умолчанию не верим никому
+// и берем адрес соединения, иначе IP клиента для лимитов легко подделать заголовком.
+func WithTrustedProxies(proxies []string) Option {
+	return func(r *router) {
+		r.trustedProxies = proxies
+	}
+}
+
+// New собирает HTTP API целиком: middleware, маршруты и проверку запросов по спецификации.
+func New(shortService linkService, hub *stream.Hub, issuer *tokens.Issuer, logger *logrus.Logger,
+	opts ...Option) (*gin.Engine, error) {
+	r := &router{}
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	apiSpec, err := api.Load()
+	if err != nil {
+		return nil, err
+	}
+	openAPIMdlwr, err := middlewares.OpenAPIMiddleware(apiSpec, logger)
+	if err != nil {
+		return nil, err
+	}
+	authMidlwr := middlewares.AuthMiddleware(issuer, logger)
+	apiKeyMidlwr := middlewares.APIKeyMiddleware(shortService, logger)
+
+	mux := gin.New()
+	// без списка gin верит X-Forwarded-For от кого угодно, nil отключает это доверие
+	if err := mux.SetTrustedProxies(r.trustedProxies); err != nil {
+		return nil, err
+	}
+	mux.Use(middlewares.LoggingMiddleware(logger))
+	mux.Use(middlewares.GzipMiddleware())
+	mux.Use(openAPIMdlwr)
+
+	// группы различаются правами, которые нужны API-ключу; с токеном пользователя доступно все
+	queryDomain := middlewares.QueryDomainMiddleware()
+	shorten := mux.Group("", apiKeyMidlwr, authMidlwr, middlewares.RequireScopes(models.ScopeShorten), queryDomain)
+	read := mux.Group("", apiKeyMidlwr, authMidlwr, middlewares.RequireScopes(models.ScopeRead), queryDomain)
+	remove := mux.Group("", apiKeyMidlwr, authMidlwr, middlewares.RequireScopes(models.ScopeDelete), queryDomain)
+	full := mux.Group("", apiKeyMidlwr, authMidlwr, middlewares.RequireScopes(models.APIKeyScopes...), queryDomain)
+	limit := func(route string, l ratelimit.Limit) gin.HandlerFunc {
+		return middlewares.RateLimitMiddleware(r.rateStore, route, l, logger)
+	}
+	createLimit := limit("create", r.rateLimits.Create)
+	// по коротким ссылкам домен определяется по Host
+	links := mux.Group("", middlewares.HostDomainMiddleware(shortService), limit("redirect", r.rateLimits.Redirect))
+
+	shorten.POST("/", createLimit, handlers.MakeShortLinkHandler(shortService))
+	redirectHandler := handlers.RedirectShortLinkHandler(shortService)
+	links.GET(fmt.Sprintf("%s/:id", r.basePath), handlers.WithPreview(handlers.PreviewHandler(shortService), redirectHandler))
+	links.GET(fmt.Sprintf("%s/:id/*rest", r.basePath), handlers.SubpathHandler(redirectHandler, map[string]gin.HandlerFunc{
+		"/qr": handlers.QRCodeHandler(shortService),
+	}))
+	shorten.POST("/api/shorten", createLimit, handlers.MakeShortURLJSONHandler(shortService))
+	shorten.POST("/api/shorten/batch", limit("batch", r.rateLimits.Batch), handlers.MakeShortURLBulk(shortService))
+	mux.POST(middlewares.RefreshPath, handlers.RefreshTokens(issuer))
+	optionalAuth := middlewares.OptionalAuthMiddleware(issuer)
+	// лимит до optionalAuth: перебор паролей ограничиваем по IP, токен анонима тут ничего не меняет
+	authLimit := limit("auth", r.rateLimits.Auth)
+	mux.POST("/api/auth/register", authLimit, optionalAuth, handlers.Register(shortService, issuer))
+	mux.POST("/api/auth/login", authLimit, optionalAuth, handlers.Login(shortService, issuer))
+	if r.oidc != nil {
+		mux.GET("/api/auth/oidc/login", handlers.OIDCLogin(r.oidc))
+		mux.GET("/api/auth/oidc/callback", optionalAuth, handlers.OIDCCallback(r.oidc, shortService, issuer))
+	}
+	read.GET("/api/user/quota", handlers.GetQuota(shortService))
+	read.GET("/api/user/urls", handlers.GetURLsByCreatorID(shortService))
+	remove.DELETE("/api/user/urls", limit("delete", r.rateLimits.Delete), handlers.DeleteShortUrls(shortService))
+	read.GET("/api/user/urls/broken", handlers.GetBrokenURLs(shortService))
+	read.GET("/api/user/urls/stream", handlers.StreamEvents(hub, streamHeartbeat))
+	read.GET("/api/user/urls/qr.zip", handlers.QRCodeBatchHandler(shortService))
+	read.GET("/api/user/urls/:id/targeting", handlers.GetTargetingRules(shortService))
+	shorten.PUT("/api/user/urls/:id/targeting", handlers.SetTargetingRules(shortService))
+	shorten.PUT("/api/user/urls/:id/variants", handlers.SetVariants(shortService))
+	read.GET("/api/user/urls/:id/stats", handlers.GetClickStats(shortService))
+	shorten.PUT("/api/user/urls/:id/meta", handlers.SetLinkMeta(shortService))
+	shorten.PUT("/api/user/urls/:id/workspace", handlers.SetLinkWorkspace(shortService))
+	full.POST("/api/user/workspaces", handlers.CreateWorkspace(shortService))
+	read.GET("/api/user/workspaces", handlers.GetWorkspaces(shortService))
+	read.GET("/api/user/workspaces/:id/members", handlers.GetWorkspaceMembers(shortService))
+	full.PUT("/api/user/workspaces/:id/members/:user_id", handlers.SetWorkspaceMember(shortService))
+	full.DELETE("/api/user/workspaces/:id/members/:user_id", handlers.DeleteWorkspaceMember(shortService))
+	full.POST("/api/user/webhooks", handlers.CreateWebhook(shortService))
+	read.GET("/api/user/webhooks", handlers.GetWebhooks(shortService))
+	full.DELETE("/api/user/webhooks/:id", handlers.DeleteWebhook(shortService))
+	read.GET("/api/user/webhooks/:id/deliveries", handlers.GetWebhookDeliveries(shortService))
+	full.POST("/api/user/keys", handlers.CreateAPIKey(shortService))
+	read.GET("/api/user/keys", handlers.GetAPIKeys(shortService))
+	full.DELETE("/api/user/keys/:id", handlers.DeleteAPIKey(shortService))
+
+	mux.GET("/api/domains", handlers.GetDomains(shortService))
+	mux.GET("/api/openapi.json", handlers.OpenAPISpec(api.Spec()))
+
+	mux.GET("/ping", func(c *gin.Context) {
+		if r.ping != nil {
+			if err := r.ping(); err != nil {
+				problem.Abort(c, problem.Internal())
+
+				return
+			}
+		}
+		c.JSON(http.StatusOK, gin.H{
+			"message": "pong",
+		})
+	})
+	mux.HandleMethodNotAllowed = true
+
+	return mux, nil
+}
@@ -0,0 +1,606 @@
+package router_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/patrick-devel/shorturl/internal/domains"
+	middlewares "github.com/patrick-devel/shorturl/internal/middlwares"
+	"github.com/patrick-devel/shorturl/internal/models"
+	"github.com/patrick-devel/shorturl/internal/problem"
+	"github.com/patrick-devel/shorturl/internal/ratelimit"
+	"github.com/patrick-devel/shorturl/internal/router"
+	"github.com/patrick-devel/shorturl/internal/service"
+	"github.com/patrick-devel/shorturl/internal/sso"
+	"github.com/patrick-devel/shorturl/internal/sso/ssotest"
+	"github.com/patrick-devel/shorturl/internal/storage"
+	"github.com/patrick-devel/shorturl/internal/stream"
+	"github.com/patrick-devel/shorturl/internal/tokens"
+)
+
+type session struct {
+	User        models.User `json:"user"`
+	AccessToken string      `json:"access_token"`
+}
+
+// browser ходит по редиректам и хранит cookie, как настоящий браузер.
+func browser(t *testing.T) *http.Client {
+	t.Helper()
+
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+
+	return &http.Client{Jar: jar}
+}
+
+func shorten(t *testing.T, client *http.Client, serverURL, originalURL string) string {
+	t.Helper()
+
+	resp, err := client.Post(serverURL+"/api/shorten", "application/json",
+		strings.NewReader(`{"url": "`+originalURL+`"}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	return resp.Header.Get("Authorization")
+}
+
+func oidcLogin(t *testing.T, client *http.Client, serverURL string) (*http.Response, session) {
+	t.Helper()
+
+	resp, err := client.Get(serverURL + "/api/auth/oidc/login")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var s session
+	if resp.StatusCode == http.StatusOK {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&s))
+	}
+
+	return resp, s
+}
+
+func userURLs(t *testing.T, client *http.Client, serverURL string) []models.Event {
+	t.Helper()
+
+	resp, err := client.Get(serverURL + "/api/user/urls")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var events []models.Event
+	if resp.StatusCode == http.StatusOK {
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&events))
+	}
+
+	return events
+}
+
+func TestOIDCLogin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	idp := ssotest.New(t)
+	srv := httptest.NewUnstartedServer(nil)
+	baseURL := &url.URL{Scheme: "http", Host: srv.Listener.Addr().String()}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	shortService := service.New(baseURL, storage.NewMemoryStorage(map[models.LinkRef]models.Event{}), ctx)
+	issuer, err := tokens.New([]tokens.Key{tokens.NewKey("test-secret")})
+	require.NoError(t, err)
+	provider := sso.New(sso.Config{
+		IssuerURL:    idp.URL,
+		ClientID:     ssotest.ClientID,
+		ClientSecret: ssotest.ClientSecret,
+		RedirectURL:  baseURL.String() + "/api/auth/oidc/callback",
+	})
+	mux, err := router.New(shortService, stream.NewHub(0), issuer, logger, router.WithOIDC(provider))
+	require.NoError(t, err)
+	srv.Config.Handler = mux
+	srv.Start()
+	t.Cleanup(srv.Close)
+
+	// первый вход с анонимным токеном оставляет пользователю его UUID и ссылки
+	laptop := browser(t)
+	anonymous, err := issuer.UserID(shorten(t, laptop, srv.URL, "https://practicum.yandex.ru/"))
+	require.NoError(t, err)
+
+	resp, first := oidcLogin(t, laptop, srv.URL)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, anonymous, first.User.ID)
+	assert.Equal(t, "user@example.com", first.User.Email)
+	uid, err := issuer.UserID(first.AccessToken)
+	require.NoError(t, err)
+	assert.Equal(t, anonymous, uid)
+	assert.Len(t, userURLs(t, laptop, srv.URL), 1)
+
+	// на другом устройстве тот же subject попадает к тому же пользователю и забирает анонимные ссылки
+	desktop := browser(t)
+	shorten(t, desktop, srv.URL, "https://go.dev/")
+	resp, second := oidcLogin(t, desktop, srv.URL)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, first.User.ID, second.User.ID)
+	assert.Len(t, userURLs(t, desktop, srv.URL), 2)
+
+	// токен вошедшего пользователя не отдает его ссылки другому subject
+	idp.SignIn("subject-2", "other@example.com")
+	resp, other := oidcLogin(t, desktop, srv.URL)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.NotEqual(t, first.User.ID, other.User.ID)
+	assert.Empty(t, userURLs(t, desktop, srv.URL))
+	assert.Len(t, userURLs(t, laptop, srv.URL), 2)
+
+	idp.Deny(true)
+	resp, _ = oidcLogin(t, browser(t), srv.URL)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	// callback без начатого входа не принимается
+	resp, err = browser(t).Get(srv.URL + "/api/auth/oidc/callback?state=s&code=c")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestOIDCDisabled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	baseURL := &url.URL{Scheme: "http", Host: "localhost:8080"}
+	shortService := service.New(baseURL, storage.NewMemoryStorage(map[models.LinkRef]models.Event{}), context.Background())
+	issuer, err := tokens.New([]tokens.Key{tokens.NewKey("test-secret")})
+	require.NoError(t, err)
+	mux, err := router.New(shortService, stream.NewHub(0), issuer, logger)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/auth/oidc/login", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// call выполняет запрос от имени пользователя с токеном token, пустой token - анонимный запрос.
+func call(mux http.Handler, token, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	return w
+}
+
+// deletionStorage запоминает удаленные ссылки: MemoryStorage сама флаг удаления не хранит.
+type deletionStorage struct {
+	*storage.MemoryStorage
+
+	mu      sync.Mutex
+	deleted []models.LinkRef
+}
+
+func (s *deletionStorage) SetDeleted(refs []models.LinkRef) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deleted = append(s.deleted, refs...)
+
+	return nil
+}
+
+func (s *deletionStorage) Deleted() []models.LinkRef {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.deleted)
+}
+
+func TestWorkspaces(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	baseURL := &url.URL{Scheme: "http", Host: "localhost:8080"}
+	store := &deletionStorage{MemoryStorage: storage.NewMemoryStorage(map[models.LinkRef]models.Event{})}
+	shortService := service.New(baseURL, store, ctx)
+	issuer, err := tokens.New([]tokens.Key{tokens.NewKey("test-secret")})
+	require.NoError(t, err)
+	mux, err := router.New(shortService, stream.NewHub(0), issuer, logger)
+	require.NoError(t, err)
+
+	users := map[string]string{}
+	token := func(role string) string {
+		if _, ok := users[role]; !ok {
+			users[role] = uuid.NewString()
+		}
+		pair, err := issuer.Issue(users[role])
+		require.NoError(t, err)
+
+		return pair.Access
+	}
+	owner, editor, viewer, stranger := token("owner"), token("editor"), token("viewer"), token("stranger")
+
+	w := call(mux, owner, http.MethodPost, "/api/user/workspaces", `{"name": "Маркетинг"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var workspace models.Workspace
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &workspace))
+	assert.Equal(t, models.RoleOwner, workspace.Role)
+	members := "/api/user/workspaces/" + workspace.ID + "/members/"
+
+	assert.Equal(t, http.StatusOK, call(mux, owner, http.MethodPut, members+users["editor"], `{"role": "editor"}`).Code)
+	assert.Equal(t, http.StatusOK, call(mux, owner, http.MethodPut, members+users["viewer"], `{"role": "viewer"}`).Code)
+	// управлять участниками может только владелец, чужим пространство не видно
+	assert.Equal(t, http.StatusForbidden, call(mux, editor, http.MethodPut, members+users["stranger"], `{"role": "viewer"}`).Code)
+	assert.Equal(t, http.StatusNotFound, call(mux, stranger, http.MethodGet, "/api/user/workspaces/"+workspace.ID+"/members", "").Code)
+
+	shortenIn := func(token, originalURL string) *httptest.ResponseRecorder {
+		return call(mux, token, http.MethodPost, "/api/shorten",
+			`{"url": "`+originalURL+`", "workspace_id": "`+workspace.ID+`"}`)
+	}
+	codeOf := func(w *httptest.ResponseRecorder) string {
+		var resp models.Response
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+
+		return path.Base(resp.Result)
+	}
+	w = shortenIn(editor, "https://practicum.yandex.ru/")
+	require.Equal(t, http.StatusCreated, w.Code)
+	kept := codeOf(w)
+	w = shortenIn(editor, "https://go.dev/")
+	require.Equal(t, http.StatusCreated, w.Code)
+	removed := codeOf(w)
+	assert.Equal(t, http.StatusForbidden, shortenIn(viewer, "https://pkg.go.dev/").Code)
+	assert.Equal(t, http.StatusNotFound, shortenIn(stranger, "https://pkg.go.dev/").Code)
+
+	// ссылки пространства видны всем участникам, но менять их может только редактор или владелец
+	for _, member := range []string{owner, editor, viewer} {
+		w = call(mux, member, http.MethodGet, "/api/user/urls?workspace="+workspace.ID, "")
+		require.Equal(t, http.StatusOK, w.Code)
+		var links []models.ResponseGetURLs
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &links))
+		assert.Len(t, links, 2)
+		assert.Equal(t, workspace.ID, links[0].WorkspaceID)
+	}
+	assert.Equal(t, http.StatusNoContent, call(mux, stranger, http.MethodGet, "/api/user/urls", "").Code)
+	assert.Equal(t, http.StatusForbidden, call(mux, viewer, http.MethodPut, "/api/user/urls/"+kept+"/meta", `{"title": "Курсы"}`).Code)
+	assert.Equal(t, http.StatusForbidden, call(mux, stranger, http.MethodPut, "/api/user/urls/"+kept+"/meta", `{"title": "Курсы"}`).Code)
+	assert.Equal(t, http.StatusOK, call(mux, owner, http.MethodPut, "/api/user/urls/"+kept+"/meta", `{"title": "Курсы"}`).Code)
+
+	// удаление асинхронное, поэтому попытку читателя делаем напрямую: к возврату ссылки уже в очереди
+	viewerCtx := context.WithValue(ctx, string(middlewares.ContextUserID), users["viewer"])
+	require.NoError(t, shortService.DeleteShortURL(viewerCtx, []string{kept}))
+	assert.Equal(t, http.StatusAccepted, call(mux, editor, http.MethodDelete, "/api/user/urls", `["`+removed+`"]`).Code)
+	require.Eventually(t, func() bool {
+		return len(store.Deleted()) > 0
+	}, 3*time.Second, 50*time.Millisecond)
+	assert.Equal(t, []models.LinkRef{{Code: removed}}, store.Deleted())
+
+	// исключенный участник теряет доступ, а ссылки остаются в пространстве
+	assert.Equal(t, http.StatusNoContent, call(mux, owner, http.MethodDelete, members+users["editor"], "").Code)
+	assert.Equal(t, http.StatusNoContent, call(mux, editor, http.MethodGet, "/api/user/urls", "").Code)
+	assert.Equal(t, http.StatusForbidden, call(mux, editor, http.MethodPut, "/api/user/urls/"+kept+"/meta", `{"title": "Курсы"}`).Code)
+	assert.Equal(t, http.StatusOK, call(mux, viewer, http.MethodGet, "/api/user/urls", "").Code)
+
+	// читатель может выйти сам, а последний владелец - нет
+	assert.Equal(t, http.StatusNoContent, call(mux, viewer, http.MethodDelete, members+users["viewer"], "").Code)
+	assert.Equal(t, http.StatusBadRequest, call(mux, owner, http.MethodDelete, members+users["owner"], "").Code)
+	assert.Equal(t, http.StatusBadRequest, call(mux, owner, http.MethodPut, members+users["owner"], `{"role": "editor"}`).Code)
+
+	// вынесенная из пространства ссылка становится личной ссылкой того, кто ее вынес
+	w = call(mux, owner, http.MethodPut, "/api/user/urls/"+kept+"/workspace", `{"workspace_id": ""}`)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "workspace_id")
+	w = call(mux, owner, http.MethodGet, "/api/user/urls?workspace="+workspace.ID, "")
+	assert.NotContains(t, w.Body.String(), kept)
+	assert.Equal(t, http.StatusOK, call(mux, owner, http.MethodGet, "/api/user/urls", "").Code)
+}
+
+func TestShortDomains(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	baseURL := &url.URL{Scheme: "http", Host: "localhost:8080"}
+	registry, err := domains.New(baseURL, "go.example.com")
+	require.NoError(t, err)
+	shortService := service.New(baseURL, storage.NewMemoryStorage(map[models.LinkRef]models.Event{}), ctx, service.WithDomains(registry))
+	issuer, err := tokens.New([]tokens.Key{tokens.NewKey("test-secret")})
+	require.NoError(t, err)
+	mux, err := router.New(shortService, stream.NewHub(0), issuer, logger)
+	require.NoError(t, err)
+
+	pair, err := issuer.Issue(uuid.NewString())
+	require.NoError(t, err)
+	user := pair.Access
+
+	w := call(mux, "", http.MethodGet, "/api/domains", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `["localhost:8080", "go.example.com"]`, w.Body.String())
+
+	shortenOn := func(domain, originalURL string) *httptest.ResponseRecorder {
+		return call(mux, user, http.MethodPost, "/api/shorten", `{"url": "`+originalURL+`", "domain": "`+domain+`"}`)
+	}
+	w = shortenOn("go.example.com", "https://practicum.yandex.ru/")
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created models.Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Regexp(t, `^http://go\.example\.com/\w+$`, created.Result)
+	code := path.Base(created.Result)
+
+	// тот же адрес на основном домене - отдельная ссылка с тем же кодом
+	w = shortenOn("", "https://practicum.yandex.ru/")
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, baseURL.String()+"/"+code, created.Result)
+
+	assert.Equal(t, http.StatusBadRequest, shortenOn("evil.example.com", "https://go.dev/").Code)
+	assert.Equal(t, http.StatusUnprocessableEntity, shortenOn("", "http://go.example.com/abc").Code)
+
+	// код ищется на домене из Host, незнакомый Host - основной домен
+	w = shortenOn("go.example.com", "https://go.dev/")
+	require.Equal(t, http.StatusCreated, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	branded := path.Base(created.Result)
+	redirect := func(host, code string) int {
+		req := httptest.NewRequest(http.MethodGet, "/"+code, nil)
+		req.Host = host
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		return w.Code
+	}
+	assert.Equal(t, http.StatusTemporaryRedirect, redirect("go.example.com", branded))
+	assert.Equal(t, http.StatusNotFound, redirect("localhost:8080", branded))
+	assert.Equal(t, http.StatusNotFound, redirect("10.0.0.1:8080", branded))
+	assert.Equal(t, http.StatusTemporaryRedirect, redirect("10.0.0.1:8080", code))
+
+	// в API домен ссылки передается параметром domain
+	rules := `[{"language": "ru", "url": "https://practicum.yandex.ru/ru"}]`
+	assert.Equal(t, http.StatusNotFound, call(mux, user, http.MethodPut, "/api/user/urls/"+branded+"/targeting", rules).Code)
+	assert.Equal(t, http.StatusOK,
+		call(mux, user, http.MethodPut, "/api/user/urls/"+branded+"/targeting?domain=go.example.com", rules).Code)
+	assert.Equal(t, http.StatusBadRequest,
+		call(mux, user, http.MethodGet, "/api/user/urls/"+branded+"/targeting?domain=evil.example.com", "").Code)
+}
+
+func TestBaseURLChange(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	// запись из файла до перехода на коды: в ней полный адрес со старым BASE_URL
+	userID := uuid.NewString()
+	legacy, err := json.Marshal(map[string]string{
+		"uuid":         uuid.NewString(),
+		"creator_id":   userID,
+		"short_url":    "http://localhost:8080/legacy",
+		"original_url": "https://practicum.yandex.ru/",
+	})
+	require.NoError(t, err)
+	file := filepath.Join(t.TempDir(), "urls.json")
+	require.NoError(t, os.WriteFile(file, append(legacy, '\n'), 0o600))
+
+	store, err := storage.NewFileStorage(file)
+	require.NoError(t, err)
+	baseURL := &url.URL{Scheme: "https", Host: "sho.rt"}
+	shortService := service.New(baseURL, store, ctx)
+	issuer, err := tokens.New([]tokens.Key{tokens.NewKey("test-secret")})
+	require.NoError(t, err)
+	mux, err := router.New(shortService, stream.NewHub(0), issuer, logger)
+	require.NoError(t, err)
+
+	pair, err := issuer.Issue(userID)
+	require.NoError(t, err)
+
+	w := call(mux, "", http.MethodGet, "/legacy", "")
+	require.Equal(t, http.StatusTemporaryRedirect, w.Code)
+	assert.Equal(t, "https://practicum.yandex.ru/", w.Header().Get("Location"))
+
+	w = call(mux, pair.Access, http.MethodPost, "/api/shorten", `{"url": "https://go.dev/"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	var created models.Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Regexp(t, `^https://sho\.rt/\w+$`, created.Result)
+
+	// адреса собираются из текущего BASE_URL, в файл пишется только код
+	w = call(mux, pair.Access, http.MethodGet, "/api/user/urls", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var links []models.ResponseGetURLs
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &links))
+	shorts := make([]string, 0, len(links))
+	for _, l := range links {
+		shorts = append(shorts, l.ShortURL)
+	}
+	assert.ElementsMatch(t, []string{"https://sho.rt/legacy", created.Result}, shorts)
+
+	data, err := os.ReadFile(file)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+	assert.NotContains(t, lines[1], "short_url")
+	assert.Contains(t, lines[1], `"code":"`+path.Base(created.Result)+`"`)
+}
+
+func TestRateLimits(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	baseURL := &url.URL{Scheme: "http", Host: "localhost:8080"}
+	shortService := service.New(baseURL, storage.NewMemoryStorage(map[models.LinkRef]models.Event{}), ctx)
+	issuer, err := tokens.New([]tokens.Key{tokens.NewKey("test-secret")})
+	require.NoError(t, err)
+	limits := ratelimit.Limits{
+		Batch:    ratelimit.Limit{Requests: 1, Period: time.Minute},
+		Redirect: ratelimit.Limit{Requests: 2, Period: time.Minute},
+		Auth:     ratelimit.Limit{Requests: 1, Period: time.Minute},
+	}
+	mux, err := router.New(shortService, stream.NewHub(0), issuer, logger,
+		router.WithRateLimits(ratelimit.NewMemoryStore(), limits))
+	require.NoError(t, err)
+
+	pair, err := issuer.Issue(uuid.NewString())
+	require.NoError(t, err)
+
+	batch := `[{"correlation_id": "1", "original_url": "https://practicum.yandex.ru/"}]`
+	require.Equal(t, http.StatusCreated, call(mux, pair.Access, http.MethodPost, "/api/shorten/batch", batch).Code)
+	w := call(mux, pair.Access, http.MethodPost, "/api/shorten/batch", batch)
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "60", w.Header().Get("Retry-After"))
+
+	// у каждой группы маршрутов свои корзины, создание без лимита
+	w = call(mux, pair.Access, http.MethodPost, "/api/shorten", `{"url": "https://go.dev/"}`)
+	require.Equal(t, http.StatusCreated, w.Code)
+	assert.Empty(t, w.Header().Get("RateLimit-Limit"))
+	var created models.Response
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+
+	code := "/" + path.Base(created.Result)
+	assert.Equal(t, http.StatusTemporaryRedirect, call(mux, "", http.MethodGet, code, "").Code)
+	assert.Equal(t, http.StatusTemporaryRedirect, call(mux, "", http.MethodGet, code, "").Code)
+	assert.Equal(t, http.StatusTooManyRequests, call(mux, "", http.MethodGet, code, "").Code)
+
+	// вход и регистрация делят корзину IP, новый токен ее не обходит
+	credentials := `{"email": "user@example.com", "password": "wrong password"}`
+	assert.Equal(t, http.StatusUnauthorized, call(mux, "", http.MethodPost, "/api/auth/login", credentials).Code)
+	other, err := issuer.Issue(uuid.NewString())
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, call(mux, other.Access, http.MethodPost, "/api/auth/login", credentials).Code)
+	assert.Equal(t, http.StatusTooManyRequests, call(mux, "", http.MethodPost, "/api/auth/register", credentials).Code)
+}
+
+func TestQuotas(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	free, pro := uuid.NewString(), uuid.NewString()
+	baseURL := &url.URL{Scheme: "http", Host: "localhost:8080"}
+	shortService := service.New(baseURL, storage.NewMemoryStorage(map[models.LinkRef]models.Event{}), ctx,
+		service.WithQuotas(models.Quota{Links: 2, BatchSize: 2}, map[string]models.Quota{pro: {Links: 10, BatchSize: 5}}))
+	issuer, err := tokens.New([]tokens.Key{tokens.NewKey("test-secret")})
+	require.NoError(t, err)
+	mux, err := router.New(shortService, stream.NewHub(0), issuer, logger)
+	require.NoError(t, err)
+
+	freePair, err := issuer.Issue(free)
+	require.NoError(t, err)
+	proPair, err := issuer.Issue(pro)
+	require.NoError(t, err)
+
+	batch := `[{"correlation_id": "1", "original_url": "https://go.dev/1"},
+		{"correlation_id": "2", "original_url": "https://go.dev/2"},
+		{"correlation_id": "3", "original_url": "https://go.dev/3"}]`
+	w := call(mux, freePair.Access, http.MethodPost, "/api/shorten/batch", batch)
+	require.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"`+problem.CodeQuotaExceeded+`"`)
+	require.Equal(t, http.StatusCreated, call(mux, proPair.Access, http.MethodPost, "/api/shorten/batch", batch).Code)
+
+	require.Equal(t, http.StatusCreated,
+		call(mux, freePair.Access, http.MethodPost, "/api/shorten", `{"url": "https://go.dev/a"}`).Code)
+	require.Equal(t, http.StatusCreated,
+		call(mux, freePair.Access, http.MethodPost, "/api/shorten", `{"url": "https://go.dev/b"}`).Code)
+	w = call(mux, freePair.Access, http.MethodPost, "/api/shorten", `{"url": "https://go.dev/c"}`)
+	require.Equal(t, http.StatusForbidden, w.Code)
+	assert.Contains(t, w.Body.String(), "2 of 2 links used")
+
+	w = call(mux, freePair.Access, http.MethodGet, "/api/user/quota", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	var usage models.QuotaUsage
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &usage))
+	assert.Equal(t, models.QuotaUsage{Quota: models.Quota{Links: 2, BatchSize: 2}, LinksUsed: 2}, usage)
+}
+
+func TestRateLimitForwardedFor(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	issuer, err := tokens.New([]tokens.Key{tokens.NewKey("test-secret")})
+	require.NoError(t, err)
+	limits := ratelimit.Limits{Create: ratelimit.Limit{Requests: 1, Period: time.Minute}}
+
+	shorten := func(mux http.Handler, forwardedFor, target string) int {
+		req := httptest.NewRequest(http.MethodPost, "/api/shorten", strings.NewReader(`{"url": "`+target+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Forwarded-For", forwardedFor)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+
+		return w.Code
+	}
+
+	tests := []struct {
+		name    string
+		proxies []string
+		expCode int
+	}{
+		// анонимный клиент без доверенных прокси не получает новую корзину ни за счет
+		// подделанного X-Forwarded-For, ни за счет нового пользователя на каждом запросе
+		{name: "Forged", expCode: http.StatusTooManyRequests},
+		// httptest присылает запросы с 192.0.2.1
+		{name: "TrustedProxy", proxies: []string{"192.0.2.1"}, expCode: http.StatusCreated},
+	}
+
+	for _, tc := range tests {
+		testcase := tc
+		t.Run(testcase.name, func(t *testing.T) {
+			baseURL := &url.URL{Scheme: "http", Host: "localhost:8080"}
+			shortService := service.New(baseURL, storage.NewMemoryStorage(map[models.LinkRef]models.Event{}), ctx)
+			mux, err := router.New(shortService, stream.NewHub(0), issuer, logger,
+				router.WithRateLimits(ratelimit.NewMemoryStore(), limits), router.WithTrustedProxies(testcase.proxies))
+			require.NoError(t, err)
+
+			require.Equal(t, http.StatusCreated, shorten(mux, "203.0.113.1", "https://go.dev/1"))
+			assert.Equal(t, testcase.expCode, shorten(mux, "203.0.113.2", "https://go.dev/2"))
+		})
+	}
+}
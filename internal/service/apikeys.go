@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/patrick-devel/shorturl/internal/ctxaux"
+	"github.com/patrick-devel/shorturl/internal/models"
+	"github.com/patrick-devel/shorturl/internal/storage"
+)
+
+const (
+	apiKeyPrefix    = "sk_"
+	apiKeyShownSize = 10
+	maxAPIKeyName   = 128
+	// apiKeyTouchEvery - время последнего использования пишется не чаще, чтобы не нагружать базу на каждом запросе.
+	apiKeyTouchEvery = time.Minute
+)
+
+var (
+	ErrInvalidAPIKeyRequest = errors.New("api key request is invalid")
+	ErrAPIKeyNotFound       = errors.New("api key not found")
+)
+
+// CreateAPIKey выпускает ключ. Сам ключ отдается только в ответе на создание, хранится его хэш.
+func (sh *ShortLinkService) CreateAPIKey(ctx context.Context, request models.RequestAPIKey) (models.APIKey, error) {
+	userID := ctxaux.GetUserIDFromContext(ctx)
+	if userID == "" {
+		return models.APIKey{}, ErrUnauthorized
+	}
+
+	if len(request.Name) > maxAPIKeyName {
+		return models.APIKey{}, fmt.Errorf("%w: name is longer than %d", ErrInvalidAPIKeyRequest, maxAPIKeyName)
+	}
+	scopes := []string{}
+	for _, s := range request.Scopes {
+		if !slices.Contains(models.APIKeyScopes, s) {
+			return models.APIKey{}, fmt.Errorf("%w: unknown scope %q", ErrInvalidAPIKeyRequest, s)
+		}
+		if !slices.Contains(scopes, s) {
+			scopes = append(scopes, s)
+		}
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return models.APIKey{}, fmt.Errorf("generate api key failed: %w", err)
+	}
+	raw := apiKeyPrefix + hex.EncodeToString(secret)
+
+	key := models.APIKey{
+		ID:        uuid.NewString(),
+		CreatorID: userID,
+		Name:      request.Name,
+		Prefix:    raw[:apiKeyShownSize],
+		Hash:      hashAPIKey(raw),
+		Scopes:    scopes,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := sh.storage.WriteAPIKey(ctx, key); err != nil {
+		return models.APIKey{}, fmt.Errorf("save api key failed: %w", err)
+	}
+
+	key.Key = raw
+
+	return key, nil
+}
+
+func (sh *ShortLinkService) APIKeys(ctx context.Context) ([]models.APIKey, error) {
+	keys, err := sh.storage.ReadAPIKeysByCreatorID(ctx, ctxaux.GetUserIDFromContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("read api keys failed: %w", err)
+	}
+
+	return keys, nil
+}
+
+func (sh *ShortLinkService) DeleteAPIKey(ctx context.Context, id string) error {
+	err := sh.storage.DeleteAPIKey(ctx, ctxaux.GetUserIDFromContext(ctx), id)
+	if errors.Is(err, storage.ErrAPIKeyNotFound) {
+		return ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("delete api key failed: %w", err)
+	}
+
+	return nil
+}
+
+// VerifyAPIKey находит ключ по значению из заголовка и отмечает его использование.
+// Неизвестный ключ - storage.ErrAPIKeyNotFound.
+func (sh *ShortLinkService) VerifyAPIKey(ctx context.Context, raw string) (models.APIKey, error) {
+	if !strings.HasPrefix(raw, apiKeyPrefix) {
+		return models.APIKey{}, storage.ErrAPIKeyNotFound
+	}
+
+	key, err := sh.storage.ReadAPIKeyByHash(ctx, hashAPIKey(raw))
+	if errors.Is(err, storage.ErrAPIKeyNotFound) {
+		return models.APIKey{}, err
+	}
+	if err != nil {
+		return models.APIKey{}, fmt.Errorf("read api key failed: %w", err)
+	}
+
+	now := time.Now().UTC()
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchEvery {
+		// ошибка отметки не должна отказывать в доступе
+		if err := sh.storage.TouchAPIKey(ctx, key.ID, now); err == nil {
+			key.LastUsedAt = &now
+		}
+	}
+
+	return key, nil
+}
+
+// hashAPIKey - у ключа 256 бит случайности, медленный хэш для него не нужен, а поиск по sha256 работает через индекс.
+func hashAPIKey(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/patrick-devel/shorturl/internal/models"
+)
+
+// BrokenLinks возвращает ссылки текущего пользователя, которые при последней проверке не открылись.
+func (sh *ShortLinkService) BrokenLinks(ctx context.Context) ([]models.ResponseBrokenURL, error) {
+	events, err := sh.LinksByCreatorID(ctx, models.LinkFilter{})
+	if err != nil {
+		return nil, err
+	}
+
+	refs := make([]models.LinkRef, 0, len(events))
+	for _, e := range events {
+		refs = append(refs, e.Ref())
+	}
+
+	health, err := sh.storage.ReadLinkHealth(ctx, refs)
+	if err != nil {
+		return nil, fmt.Errorf("read link health failed: %w", err)
+	}
+
+	broken := []models.ResponseBrokenURL{}
+	for _, e := range events {
+		h, ok := health[e.Ref()]
+		if !ok || !h.Broken() {
+			continue
+		}
+		broken = append(broken, models.ResponseBrokenURL{ShortURL: e.ShortURL, OriginalURL: e.OriginalURL, LinkHealth: h})
+	}
+
+	return broken, nil
+}
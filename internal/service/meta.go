@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/patrick-devel/shorturl/internal/models"
+)
+
+const maxDescriptionLength = 1024
+
+var ErrInvalidOpenGraph = errors.New("open graph meta is invalid")
+
+func (sh *ShortLinkService) SetLinkMeta(ctx context.Context, hash string, meta models.LinkMeta) (models.LinkMeta, error) {
+	opts, err := normalizeMeta(models.LinkOptions{Title: meta.Title, OpenGraph: meta.OpenGraph})
+	if err != nil {
+		return models.LinkMeta{}, err
+	}
+
+	event, err := sh.ownedEvent(ctx, hash, models.RoleEditor)
+	if err != nil {
+		return models.LinkMeta{}, err
+	}
+
+	event.Title = opts.Title
+	event.OpenGraph = opts.OpenGraph
+	if err := sh.storage.UpdateEvent(ctx, event); err != nil {
+		return models.LinkMeta{}, fmt.Errorf("update link meta failed: %w", err)
+	}
+	sh.notify(models.NotificationLinkUpdated, event, "")
+
+	return models.LinkMeta{Title: event.Title, OpenGraph: event.OpenGraph}, nil
+}
+
+// GetUnfurl отдает данные для превью в мессенджерах. Если владелец ничего не задал, ok=false,
+// и бот должен пройти по обычному редиректу и взять теги со страницы назначения.
+func (sh *ShortLinkService) GetUnfurl(ctx context.Context, hash string) (models.Unfurl, bool, error) {
+	event, err := sh.GetShortLink(ctx, hash)
+	if err != nil {
+		return models.Unfurl{}, false, err
+	}
+
+	if event.OpenGraph == nil && event.Title == "" {
+		return models.Unfurl{}, false, nil
+	}
+
+	unfurl := models.Unfurl{ShortURL: event.ShortURL, OriginalURL: event.OriginalURL}
+	if event.OpenGraph != nil {
+		unfurl.OpenGraph = *event.OpenGraph
+	}
+	if unfurl.OpenGraph.Title == "" {
+		unfurl.OpenGraph.Title = event.Title
+	}
+
+	return unfurl, true, nil
+}
+
+func normalizeMeta(opts models.LinkOptions) (models.LinkOptions, error) {
+	opts.Title = strings.TrimSpace(opts.Title)
+	if utf8.RuneCountInString(opts.Title) > maxTitleLength {
+		return opts, fmt.Errorf("%w: max %d characters", ErrInvalidTitle, maxTitleLength)
+	}
+
+	if opts.OpenGraph == nil {
+		return opts, nil
+	}
+
+	og := models.OpenGraph{
+		Title:       strings.TrimSpace(opts.OpenGraph.Title),
+		Description: strings.TrimSpace(opts.OpenGraph.Description),
+		Image:       strings.TrimSpace(opts.OpenGraph.Image),
+	}
+
+	if utf8.RuneCountInString(og.Title) > maxTitleLength {
+		return opts, fmt.Errorf("%w: title max %d characters", ErrInvalidOpenGraph, maxTitleLength)
+	}
+
+	if utf8.RuneCountInString(og.Description) > maxDescriptionLength {
+		return opts, fmt.Errorf("%w: description max %d characters", ErrInvalidOpenGraph, maxDescriptionLength)
+	}
+
+	if og.Image != "" {
+		u, err := url.ParseRequestURI(og.Image)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+			return opts, fmt.Errorf("%w: image must be an http(s) url", ErrInvalidOpenGraph)
+		}
+	}
+
+	opts.OpenGraph = nil
+	if !og.IsZero() {
+		opts.OpenGraph = &og
+	}
+
+	return opts, nil
+}
@@ -0,0 +1,179 @@
+package service_test
+
+import (
+	"context"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/patrick-devel/shorturl/internal/models"
+	"github.com/patrick-devel/shorturl/internal/service"
+	"github.com/patrick-devel/shorturl/internal/storage"
+)
+
+// deletedStorage ведет себя как база для удаленной ссылки: MemoryStorage флаг удаления не хранит.
+type deletedStorage struct {
+	*storage.MemoryStorage
+}
+
+func (deletedStorage) ReadEvent(context.Context, models.LinkRef) (models.Event, error) {
+	return models.Event{}, storage.ErrEventDeleted
+}
+
+func TestSetLinkMeta(t *testing.T) {
+	// кириллица проверяет, что длина считается в символах, а не в байтах
+	title256 := strings.Repeat("я", 256)
+
+	tests := []struct {
+		name    string
+		meta    models.LinkMeta
+		expMeta models.LinkMeta
+		expErr  error
+	}{
+		{
+			name: "Trimmed",
+			meta: models.LinkMeta{
+				Title:     "  Go  ",
+				OpenGraph: &models.OpenGraph{Title: " Go ", Description: "\tdocs\n", Image: " https://go.dev/og.png "},
+			},
+			expMeta: models.LinkMeta{
+				Title:     "Go",
+				OpenGraph: &models.OpenGraph{Title: "Go", Description: "docs", Image: "https://go.dev/og.png"},
+			},
+		},
+		{
+			name:    "BlankOpenGraphDropped",
+			meta:    models.LinkMeta{Title: "Go", OpenGraph: &models.OpenGraph{Title: " ", Description: " "}},
+			expMeta: models.LinkMeta{Title: "Go"},
+		},
+		{
+			name:    "MaxLengths",
+			meta:    models.LinkMeta{Title: title256, OpenGraph: &models.OpenGraph{Title: title256, Description: strings.Repeat("я", 1024)}},
+			expMeta: models.LinkMeta{Title: title256, OpenGraph: &models.OpenGraph{Title: title256, Description: strings.Repeat("я", 1024)}},
+		},
+		{
+			name:   "TitleTooLong",
+			meta:   models.LinkMeta{Title: title256 + "я"},
+			expErr: service.ErrInvalidTitle,
+		},
+		{
+			name:   "OpenGraphTitleTooLong",
+			meta:   models.LinkMeta{OpenGraph: &models.OpenGraph{Title: title256 + "я"}},
+			expErr: service.ErrInvalidOpenGraph,
+		},
+		{
+			name:   "DescriptionTooLong",
+			meta:   models.LinkMeta{OpenGraph: &models.OpenGraph{Description: strings.Repeat("я", 1025)}},
+			expErr: service.ErrInvalidOpenGraph,
+		},
+		{
+			name:   "ImageNotHTTP",
+			meta:   models.LinkMeta{OpenGraph: &models.OpenGraph{Image: "ftp://go.dev/og.png"}},
+			expErr: service.ErrInvalidOpenGraph,
+		},
+		{
+			name:   "ImageNotURL",
+			meta:   models.LinkMeta{OpenGraph: &models.OpenGraph{Image: "og.png"}},
+			expErr: service.ErrInvalidOpenGraph,
+		},
+	}
+
+	for _, tc := range tests {
+		testcase := tc
+		t.Run(testcase.name, func(t *testing.T) {
+			sh := newService(t, nil)
+			ctx := userContext("owner")
+			shortURL, err := sh.MakeShortURL(ctx, "https://go.dev/", "", models.LinkOptions{})
+			require.NoError(t, err)
+
+			meta, err := sh.SetLinkMeta(ctx, path.Base(shortURL), testcase.meta)
+			if testcase.expErr != nil {
+				assert.ErrorIs(t, err, testcase.expErr)
+
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testcase.expMeta, meta)
+		})
+	}
+}
+
+func TestGetUnfurl(t *testing.T) {
+	ctx := context.Background()
+	ref := models.LinkRef{Code: "abc"}
+
+	tests := []struct {
+		name      string
+		opts      models.LinkOptions
+		expOK     bool
+		expUnfurl models.Unfurl
+	}{
+		{
+			name: "NoMeta",
+		},
+		{
+			name:  "TitleFallback",
+			opts:  models.LinkOptions{Title: "Go", OpenGraph: &models.OpenGraph{Description: "docs"}},
+			expOK: true,
+			expUnfurl: models.Unfurl{
+				ShortURL:    "http://localhost:8080/abc",
+				OriginalURL: "https://go.dev/",
+				OpenGraph:   models.OpenGraph{Title: "Go", Description: "docs"},
+			},
+		},
+		{
+			name:  "TitleOnly",
+			opts:  models.LinkOptions{Title: "Go"},
+			expOK: true,
+			expUnfurl: models.Unfurl{
+				ShortURL:    "http://localhost:8080/abc",
+				OriginalURL: "https://go.dev/",
+				OpenGraph:   models.OpenGraph{Title: "Go"},
+			},
+		},
+		{
+			name:  "OpenGraphTitleWins",
+			opts:  models.LinkOptions{Title: "Go", OpenGraph: &models.OpenGraph{Title: "The Go language", Image: "https://go.dev/og.png"}},
+			expOK: true,
+			expUnfurl: models.Unfurl{
+				ShortURL:    "http://localhost:8080/abc",
+				OriginalURL: "https://go.dev/",
+				OpenGraph:   models.OpenGraph{Title: "The Go language", Image: "https://go.dev/og.png"},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		testcase := tc
+		t.Run(testcase.name, func(t *testing.T) {
+			sh := newService(t, map[models.LinkRef]models.Event{
+				ref: {Code: ref.Code, OriginalURL: "https://go.dev/", LinkOptions: testcase.opts},
+			})
+
+			unfurl, ok, err := sh.GetUnfurl(ctx, ref.Code)
+			require.NoError(t, err)
+			assert.Equal(t, testcase.expOK, ok)
+			assert.Equal(t, testcase.expUnfurl, unfurl)
+		})
+	}
+
+	t.Run("NotFound", func(t *testing.T) {
+		_, ok, err := newService(t, nil).GetUnfurl(ctx, "missing")
+		assert.ErrorIs(t, err, storage.ErrNotFound)
+		assert.False(t, ok)
+	})
+
+	t.Run("Deleted", func(t *testing.T) {
+		cancelCtx, cancel := context.WithCancel(ctx)
+		t.Cleanup(cancel)
+		store := deletedStorage{storage.NewMemoryStorage(map[models.LinkRef]models.Event{})}
+		sh := service.New(baseURL, store, cancelCtx)
+
+		_, ok, err := sh.GetUnfurl(ctx, ref.Code)
+		assert.ErrorIs(t, err, storage.ErrEventDeleted)
+		assert.False(t, ok)
+	})
+}
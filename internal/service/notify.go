@@ -0,0 +1,33 @@
+package service
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/patrick-devel/shorturl/internal/models"
+)
+
+// publisher получает события по ссылкам: вебхуки, поток SSE.
+type publisher interface {
+	Publish(n models.Notification)
+}
+
+func (sh *ShortLinkService) notify(eventType string, event models.Event, variant string) {
+	if event.CreatorID == "" || len(sh.publishers) == 0 {
+		return
+	}
+
+	n := models.Notification{
+		ID:          uuid.NewString(),
+		Type:        eventType,
+		CreatorID:   event.CreatorID,
+		ShortURL:    event.ShortURL,
+		OriginalURL: event.OriginalURL,
+		Variant:     variant,
+		CreatedAt:   time.Now().UTC(),
+	}
+	for _, p := range sh.publishers {
+		p.Publish(n)
+	}
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/patrick-devel/shorturl/internal/models"
+	"github.com/patrick-devel/shorturl/internal/storage"
+)
+
+var (
+	ErrInvalidPassthrough = errors.New("passthrough mode is not supported")
+	// для посетителя это та же ссылка, которой нет
+	errPathNotAllowed = fmt.Errorf("link does not accept trailing path: %w", storage.ErrNotFound)
+)
+
+func validatePassthrough(mode string) error {
+	switch mode {
+	case models.PassthroughNone, models.PassthroughQuery, models.PassthroughPath, models.PassthroughAll:
+		return nil
+	}
+
+	return fmt.Errorf("%w: %q", ErrInvalidPassthrough, mode)
+}
+
+// applyPassthrough переносит в целевой URL параметры и хвост пути из запроса посетителя.
+// Параметры, заданные в самой ссылке, имеют приоритет над пришедшими в запросе и остаются как были.
+func applyPassthrough(destination, mode string, visitor models.Visitor) (string, error) {
+	passPath := mode == models.PassthroughPath || mode == models.PassthroughAll
+	passQuery := mode == models.PassthroughQuery || mode == models.PassthroughAll
+
+	if visitor.Path != "" && !passPath {
+		return "", errPathNotAllowed
+	}
+	// JoinPath схлопывает .., и хвост увел бы посетителя выше пути ссылки
+	if slices.Contains(strings.Split(visitor.Path, "/"), "..") {
+		return "", errPathNotAllowed
+	}
+
+	if (!passQuery || len(visitor.Query) == 0) && visitor.Path == "" {
+		return destination, nil
+	}
+
+	u, err := url.Parse(destination)
+	if err != nil {
+		return "", fmt.Errorf("parse destination failed: %w", err)
+	}
+
+	if visitor.Path != "" {
+		u = u.JoinPath(visitor.Path)
+	}
+
+	if passQuery && len(visitor.Query) != 0 {
+		u.RawQuery = mergeRawQuery(u.RawQuery, visitor.Query, false)
+	}
+
+	return u.String(), nil
+}
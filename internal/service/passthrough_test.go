@@ -0,0 +1,163 @@
+package service_test
+
+import (
+	"context"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/patrick-devel/shorturl/internal/models"
+	"github.com/patrick-devel/shorturl/internal/service"
+	"github.com/patrick-devel/shorturl/internal/storage"
+)
+
+func TestPassthrough(t *testing.T) {
+	tests := []struct {
+		name        string
+		destination string
+		mode        string
+		visitor     models.Visitor
+		expURL      string
+		expNotFound bool
+	}{
+		{
+			name:        "NoneIgnoresQuery",
+			destination: "https://go.dev/doc",
+			visitor:     models.Visitor{Query: url.Values{"ref": {"mail"}}},
+			expURL:      "https://go.dev/doc",
+		},
+		{
+			name:        "NoneRejectsPath",
+			destination: "https://go.dev/doc",
+			visitor:     models.Visitor{Path: "/install"},
+			expNotFound: true,
+		},
+		{
+			name:        "QueryRejectsPath",
+			destination: "https://go.dev/doc",
+			mode:        models.PassthroughQuery,
+			visitor:     models.Visitor{Path: "/install"},
+			expNotFound: true,
+		},
+		{
+			name:        "Query",
+			destination: "https://go.dev/doc",
+			mode:        models.PassthroughQuery,
+			visitor:     models.Visitor{Query: url.Values{"ref": {"mail"}, "tag": {"a", "b"}}},
+			expURL:      "https://go.dev/doc?ref=mail&tag=a&tag=b",
+		},
+		{
+			// параметр ссылки важнее параметра посетителя, остальные добавляются
+			name:        "LinkQueryWins",
+			destination: "https://go.dev/doc?ref=site&lang=en",
+			mode:        models.PassthroughQuery,
+			visitor:     models.Visitor{Query: url.Values{"ref": {"mail"}, "id": {"7"}}},
+			expURL:      "https://go.dev/doc?ref=site&lang=en&id=7",
+		},
+		{
+			name:        "QueryWithoutVisitorQuery",
+			destination: "https://go.dev/doc?b=2&a=1",
+			mode:        models.PassthroughQuery,
+			expURL:      "https://go.dev/doc?b=2&a=1",
+		},
+		{
+			name:        "PathIgnoresQuery",
+			destination: "https://go.dev/doc",
+			mode:        models.PassthroughPath,
+			visitor:     models.Visitor{Path: "/install", Query: url.Values{"ref": {"mail"}}},
+			expURL:      "https://go.dev/doc/install",
+		},
+		{
+			name:        "PathJoinsSlashes",
+			destination: "https://go.dev/doc/",
+			mode:        models.PassthroughPath,
+			visitor:     models.Visitor{Path: "/install/linux"},
+			expURL:      "https://go.dev/doc/install/linux",
+		},
+		{
+			name:        "PathKeepsLinkQuery",
+			destination: "https://go.dev/doc?lang=en",
+			mode:        models.PassthroughPath,
+			visitor:     models.Visitor{Path: "/install"},
+			expURL:      "https://go.dev/doc/install?lang=en",
+		},
+		{
+			name:        "PathCannotEscape",
+			destination: "https://go.dev/doc",
+			mode:        models.PassthroughPath,
+			visitor:     models.Visitor{Path: "/../../admin"},
+			expNotFound: true,
+		},
+		{
+			name:        "DotsInsideSegment",
+			destination: "https://go.dev/doc",
+			mode:        models.PassthroughPath,
+			visitor:     models.Visitor{Path: "/go1..22"},
+			expURL:      "https://go.dev/doc/go1..22",
+		},
+		{
+			name:        "All",
+			destination: "https://go.dev/doc?ref=site",
+			mode:        models.PassthroughAll,
+			visitor:     models.Visitor{Path: "/install", Query: url.Values{"ref": {"mail"}, "os": {"linux"}}},
+			expURL:      "https://go.dev/doc/install?ref=site&os=linux",
+		},
+		{
+			// подписанная ссылка: исходная строка запроса не пересобирается
+			name:        "SignedQuery",
+			destination: "https://cdn.example/file?X-Expires=60&a=%2Fx+y&empty=&X-Signature=abc%3D",
+			mode:        models.PassthroughQuery,
+			visitor:     models.Visitor{Query: url.Values{"ref": {"mail"}, "empty": {"x"}}},
+			expURL:      "https://cdn.example/file?X-Expires=60&a=%2Fx+y&empty=&X-Signature=abc%3D&ref=mail",
+		},
+	}
+
+	for _, tc := range tests {
+		testcase := tc
+		t.Run(testcase.name, func(t *testing.T) {
+			ref := models.LinkRef{Code: "abc"}
+			sh := newService(t, map[models.LinkRef]models.Event{ref: {
+				Code:        ref.Code,
+				OriginalURL: testcase.destination,
+				LinkOptions: models.LinkOptions{Passthrough: testcase.mode},
+			}})
+
+			redirect, err := sh.GetOriginalURL(context.Background(), ref.Code, testcase.visitor)
+			if testcase.expNotFound {
+				// хендлер отвечает на это 404, как на несуществующую ссылку
+				assert.ErrorIs(t, err, storage.ErrNotFound)
+
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testcase.expURL, redirect.URL)
+		})
+	}
+}
+
+func TestPassthroughWithTargeting(t *testing.T) {
+	ref := models.LinkRef{Code: "abc"}
+	sh := newService(t, map[models.LinkRef]models.Event{ref: {
+		Code:        ref.Code,
+		OriginalURL: "https://go.dev/doc",
+		LinkOptions: models.LinkOptions{
+			Passthrough:    models.PassthroughAll,
+			TargetingRules: []models.TargetingRule{{Device: models.DeviceIOS, URL: "https://apps.apple.com/app?id=1"}},
+		},
+	}})
+
+	// хвост и параметры переносятся и в адрес, выбранный правилом
+	redirect, err := sh.GetOriginalURL(context.Background(), ref.Code,
+		models.Visitor{UserAgent: uaIPhone, Path: "/reviews", Query: url.Values{"id": {"2"}, "ref": {"mail"}}})
+	require.NoError(t, err)
+	assert.Equal(t, "https://apps.apple.com/app/reviews?id=1&ref=mail", redirect.URL)
+}
+
+func TestInvalidPassthrough(t *testing.T) {
+	sh := newService(t, nil)
+
+	_, err := sh.MakeShortURL(context.Background(), "https://go.dev/", "", models.LinkOptions{Passthrough: "fragment"})
+	assert.ErrorIs(t, err, service.ErrInvalidPassthrough)
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/patrick-devel/shorturl/internal/domains"
+	"github.com/patrick-devel/shorturl/internal/models"
+)
+
+var (
+	ErrInvalidURL    = errors.New("url is invalid")
+	ErrPolicyBlocked = errors.New("destination is blocked by policy")
+)
+
+// DestinationPolicy решает, можно ли вести короткую ссылку на адрес. Отказ - ошибка с ErrPolicyBlocked.
+type DestinationPolicy func(destination *url.URL) error
+
+func WithDestinationPolicy(p DestinationPolicy) Option {
+	return func(sh *ShortLinkService) {
+		sh.policies = append(sh.policies, p)
+	}
+}
+
+// selfLinkPolicy запрещает ссылки на сам сокращатель: такая ссылка ведет по кругу
+// или прячет настоящий адрес за двумя редиректами.
+func selfLinkPolicy(registry *domains.Registry) DestinationPolicy {
+	self := map[string]bool{}
+	for _, host := range registry.Hosts() {
+		domain, _ := registry.Lookup(host)
+		self[hostKey(registry.URL(domain))] = true
+	}
+
+	return func(destination *url.URL) error {
+		if self[hostKey(destination)] {
+			return fmt.Errorf("%w: %s points to the shortener itself", ErrPolicyBlocked, destination.Host)
+		}
+
+		return nil
+	}
+}
+
+func hostKey(u *url.URL) string {
+	port := u.Port()
+	if port == "" {
+		switch strings.ToLower(u.Scheme) {
+		case "https":
+			port = "443"
+		default:
+			port = "80"
+		}
+	}
+
+	return net.JoinHostPort(strings.ToLower(strings.TrimSuffix(u.Hostname(), ".")), port)
+}
+
+// checkDestinations проверяет все адреса, куда может увести ссылка: основной, из правил таргетинга и вариантов.
+func (sh *ShortLinkService) checkDestinations(originalURL string, opts models.LinkOptions) error {
+	destinations := []string{originalURL}
+	for _, r := range opts.TargetingRules {
+		destinations = append(destinations, r.URL)
+	}
+	for _, v := range opts.Variants {
+		destinations = append(destinations, v.URL)
+	}
+
+	for _, raw := range destinations {
+		if err := sh.checkDestination(raw); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (sh *ShortLinkService) checkDestination(raw string) error {
+	destination, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidURL, err)
+	}
+
+	// относительные адреса политикам проверять нечем
+	if destination.Host == "" {
+		return nil
+	}
+
+	for _, policy := range sh.policies {
+		if err := policy(destination); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
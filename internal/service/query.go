@@ -0,0 +1,46 @@
+package service
+
+import (
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// mergeRawQuery дописывает параметры params в строку запроса raw, не перекодируя ее: порядок и запись
+// чужих параметров важны, например, для подписанных ссылок. Если параметр уже есть в raw, replace
+// решает, заменить его или оставить как есть.
+func mergeRawQuery(raw string, params url.Values, replace bool) string {
+	present := map[string]bool{}
+	pairs := make([]string, 0, len(params))
+	for _, pair := range strings.Split(raw, "&") {
+		if pair == "" {
+			continue
+		}
+		key, _, _ := strings.Cut(pair, "=")
+		if unescaped, err := url.QueryUnescape(key); err == nil {
+			key = unescaped
+		}
+		if params.Has(key) {
+			present[key] = true
+			if replace {
+				continue
+			}
+		}
+		pairs = append(pairs, pair)
+	}
+
+	keys := make([]string, 0, len(params))
+	for k := range params {
+		if replace || !present[k] {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+	for _, k := range keys {
+		for _, v := range params[k] {
+			pairs = append(pairs, url.QueryEscape(k)+"="+url.QueryEscape(v))
+		}
+	}
+
+	return strings.Join(pairs, "&")
+}
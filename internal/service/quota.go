@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"github.com/patrick-devel/shorturl/internal/ctxaux"
+	"github.com/patrick-devel/shorturl/internal/models"
+)
+
+var (
+	ErrLinkQuotaExceeded = errors.New("link quota exceeded")
+	ErrBatchTooLarge     = errors.New("batch is larger than quota allows")
+	ErrInvalidQuota      = errors.New("quota is invalid")
+)
+
+// WithQuotas - квота по умолчанию и квоты отдельных пользователей, например на платном тарифе.
+// Без опции ограничений нет.
+func WithQuotas(defaults models.Quota, overrides map[string]models.Quota) Option {
+	return func(sh *ShortLinkService) {
+		sh.quota = defaults
+		sh.quotaOverrides = overrides
+	}
+}
+
+// ParseQuotaOverrides разбирает квоты пользователей вида uuid=10000:1000,uuid=0:500,
+// где первое число - ссылки, второе - размер пакета.
+func ParseQuotaOverrides(value string) (map[string]models.Quota, error) {
+	overrides := map[string]models.Quota{}
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		userID, limits, ok := strings.Cut(item, "=")
+		links, batch, ok2 := strings.Cut(limits, ":")
+		if !ok || !ok2 {
+			return nil, fmt.Errorf("%w: %q, expected user=links:batch", ErrInvalidQuota, item)
+		}
+
+		userID = strings.TrimSpace(userID)
+		if _, err := uuid.Parse(userID); err != nil {
+			return nil, fmt.Errorf("%w: %q: user must be a UUID", ErrInvalidQuota, item)
+		}
+		if _, ok := overrides[userID]; ok {
+			return nil, fmt.Errorf("%w: %q: user is listed twice", ErrInvalidQuota, item)
+		}
+
+		var quota models.Quota
+		var err error
+		if quota.Links, err = strconv.Atoi(links); err != nil || quota.Links < 0 {
+			return nil, fmt.Errorf("%w: %q: links must be a non-negative number", ErrInvalidQuota, item)
+		}
+		if quota.BatchSize, err = strconv.Atoi(batch); err != nil || quota.BatchSize < 0 {
+			return nil, fmt.Errorf("%w: %q: batch must be a non-negative number", ErrInvalidQuota, item)
+		}
+		overrides[userID] = quota
+	}
+
+	return overrides, nil
+}
+
+func (sh *ShortLinkService) quotaFor(userID string) models.Quota {
+	if quota, ok := sh.quotaOverrides[userID]; ok {
+		return quota
+	}
+
+	return sh.quota
+}
+
+// Quota - квота текущего пользователя и сколько ссылок у него уже есть.
+func (sh *ShortLinkService) Quota(ctx context.Context) (models.QuotaUsage, error) {
+	userID := ctxaux.GetUserIDFromContext(ctx)
+	if userID == "" {
+		return models.QuotaUsage{}, ErrUnauthorized
+	}
+
+	used, err := sh.storage.CountEventsByCreatorID(ctx, userID)
+	if err != nil {
+		return models.QuotaUsage{}, fmt.Errorf("count links failed: %w", err)
+	}
+
+	return models.QuotaUsage{Quota: sh.quotaFor(userID), LinksUsed: used}, nil
+}
+
+func (sh *ShortLinkService) checkBatchQuota(ctx context.Context, size int) error {
+	quota := sh.quotaFor(ctxaux.GetUserIDFromContext(ctx))
+	if quota.BatchSize > 0 && size > quota.BatchSize {
+		return fmt.Errorf("%w: %d links in batch, at most %d allowed", ErrBatchTooLarge, size, quota.BatchSize)
+	}
+
+	return nil
+}
+
+// checkLinkQuota проверяет, что пользователь может создать еще n ссылок. Счетчик читается без блокировки,
+// параллельные запросы могут немного превысить квоту - для тарифов это допустимо.
+func (sh *ShortLinkService) checkLinkQuota(ctx context.Context, n int) error {
+	userID := ctxaux.GetUserIDFromContext(ctx)
+	quota := sh.quotaFor(userID)
+	if quota.Links == 0 || userID == "" {
+		return nil
+	}
+
+	used, err := sh.storage.CountEventsByCreatorID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("count links failed: %w", err)
+	}
+	if used+n > quota.Links {
+		return fmt.Errorf("%w: %d of %d links used", ErrLinkQuotaExceeded, used, quota.Links)
+	}
+
+	return nil
+}
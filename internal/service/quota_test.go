@@ -0,0 +1,90 @@
+package service_test
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/patrick-devel/shorturl/internal/models"
+	"github.com/patrick-devel/shorturl/internal/service"
+	"github.com/patrick-devel/shorturl/internal/storage"
+)
+
+func TestParseQuotaOverrides(t *testing.T) {
+	const (
+		first  = "6f1c1b0e-3b5a-4d3e-9a57-1f0b6d1c2a10"
+		second = "0b7e9d4c-2f61-4a8b-8c3d-5e9f1a2b3c4d"
+	)
+
+	tests := []struct {
+		name         string
+		value        string
+		expOverrides map[string]models.Quota
+		expErr       bool
+	}{
+		{name: "Empty", value: "", expOverrides: map[string]models.Quota{}},
+		{
+			name:  "Several",
+			value: first + "=10000:1000, " + second + "=0:500,",
+			expOverrides: map[string]models.Quota{
+				first:  {Links: 10000, BatchSize: 1000},
+				second: {Links: 0, BatchSize: 500},
+			},
+		},
+		{name: "NoEquals", value: first + ":10000:1000", expErr: true},
+		{name: "NoBatch", value: first + "=10000", expErr: true},
+		{name: "NoUser", value: "=10000:1000", expErr: true},
+		{name: "NotUUID", value: "alice=10000:1000", expErr: true},
+		{name: "NotNumber", value: first + "=many:1000", expErr: true},
+		{name: "NegativeLinks", value: first + "=-1:1000", expErr: true},
+		{name: "NegativeBatch", value: first + "=10000:-5", expErr: true},
+		{name: "DuplicateUser", value: first + "=10000:1000," + first + "=5:5", expErr: true},
+	}
+
+	for _, tc := range tests {
+		testcase := tc
+		t.Run(testcase.name, func(t *testing.T) {
+			overrides, err := service.ParseQuotaOverrides(testcase.value)
+			if testcase.expErr {
+				assert.ErrorIs(t, err, service.ErrInvalidQuota)
+
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testcase.expOverrides, overrides)
+		})
+	}
+}
+
+func TestQuotaDuplicates(t *testing.T) {
+	sh := newService(t, nil, service.WithQuotas(models.Quota{Links: 1, BatchSize: 2}, nil))
+	ctx := userContext("user")
+
+	shortURL, err := sh.MakeShortURL(ctx, "https://go.dev/", "", models.LinkOptions{})
+	require.NoError(t, err)
+
+	// на пределе квоты повтор отдает существующую ссылку, а не ошибку квоты
+	again, err := sh.MakeShortURL(ctx, "https://go.dev/", "", models.LinkOptions{})
+	require.ErrorIs(t, err, storage.ErrDuplicateURL)
+	assert.Equal(t, shortURL, again)
+
+	_, err = sh.MakeShortURL(ctx, "https://go.dev/blog", "", models.LinkOptions{})
+	require.ErrorIs(t, err, service.ErrLinkQuotaExceeded)
+
+	existing, err := url.Parse("https://go.dev/")
+	require.NoError(t, err)
+	events, err := sh.MakeShortURLs(ctx, models.ListRequestBulk{{OriginalURL: *existing, CorrelationID: "1"}})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.Equal(t, shortURL, events[0].ShortURL)
+
+	fresh, err := url.Parse("https://go.dev/doc")
+	require.NoError(t, err)
+	_, err = sh.MakeShortURLs(ctx, models.ListRequestBulk{
+		{OriginalURL: *existing, CorrelationID: "1"},
+		{OriginalURL: *fresh, CorrelationID: "2"},
+	})
+	require.ErrorIs(t, err, service.ErrLinkQuotaExceeded)
+}
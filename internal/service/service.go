@@ -2,10 +2,13 @@ package service
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/binary"
 	"errors"
 	"fmt"
-	"math/big"
+	"net/http"
 	"net/url"
+	"strconv"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,40 +16,146 @@ import (
 	"github.com/sqids/sqids-go"
 
 	"github.com/patrick-devel/shorturl/internal/ctxaux"
+	"github.com/patrick-devel/shorturl/internal/domains"
 	"github.com/patrick-devel/shorturl/internal/models"
 	"github.com/patrick-devel/shorturl/internal/storage"
 )
 
 const minLength = 6
+const codeAttempts = 5
 const batchDelete = 10
+const maxTitleLength = 256
+
+var (
+	ErrInvalidRedirectCode = errors.New("redirect code is not supported")
+	ErrNotOwner            = errors.New("link belongs to another user")
+	ErrInvalidTitle        = errors.New("title is too long")
+	ErrUnauthorized        = errors.New("no user is currently logged in")
+)
 
 type ShortLinkService struct {
-	baseURL *url.URL
-	storage store
+	domains      *domains.Registry
+	storage      store
+	redirectCode int
+	publishers   []publisher
+	policies     []DestinationPolicy
+
+	quota          models.Quota
+	quotaOverrides map[string]models.Quota
 
-	urlsCh chan string
+	urlsCh chan models.Event
 	ctx    context.Context
 }
 
-func New(baseURL *url.URL, storage store, ctx context.Context) *ShortLinkService {
-	urlsCh := make(chan string)
-	sh := &ShortLinkService{baseURL: baseURL, storage: storage, urlsCh: urlsCh, ctx: ctx}
+type Option func(sh *ShortLinkService)
+
+func WithRedirectCode(code int) Option {
+	return func(sh *ShortLinkService) {
+		sh.redirectCode = code
+	}
+}
+
+// WithDomains - короткие домены, на которых можно создавать ссылки. По умолчанию только домен из baseURL.
+func WithDomains(registry *domains.Registry) Option {
+	return func(sh *ShortLinkService) {
+		sh.domains = registry
+	}
+}
+
+func WithPublisher(p publisher) Option {
+	return func(sh *ShortLinkService) {
+		sh.publishers = append(sh.publishers, p)
+	}
+}
+
+func New(baseURL *url.URL, storage store, ctx context.Context, opts ...Option) *ShortLinkService {
+	urlsCh := make(chan models.Event)
+	// без дополнительных доменов реестр собирается без ошибок
+	registry, _ := domains.New(baseURL)
+	sh := &ShortLinkService{
+		domains:      registry,
+		storage:      storage,
+		redirectCode: http.StatusTemporaryRedirect,
+		urlsCh:       urlsCh,
+		ctx:          ctx,
+	}
+	for _, opt := range opts {
+		opt(sh)
+	}
+	sh.policies = append([]DestinationPolicy{selfLinkPolicy(sh.domains)}, sh.policies...)
+
 	go sh.runDelete()
 	return sh
 }
 
 type store interface {
-	ReadEvent(ctx context.Context, hash string) (string, error)
+	ReadEvent(ctx context.Context, ref models.LinkRef) (models.Event, error)
+	ReadEventByOriginalURL(ctx context.Context, domain, originalURL string) (models.Event, error)
 	WriteEvent(ctx context.Context, event models.Event) error
 	WriteEvents(_ context.Context, events []models.Event) error
 	ReadEventsByCreatorID(ctx context.Context, userID string) ([]models.Event, error)
-	SetDeleteByShortURL(shorts []string) error
+	CountEventsByCreatorID(ctx context.Context, userID string) (int, error)
+	ReadEventsByWorkspaceIDs(ctx context.Context, workspaceIDs []string) ([]models.Event, error)
+	UpdateEvent(ctx context.Context, event models.Event) error
+	SetDeleted(refs []models.LinkRef) error
+	WriteClick(ctx context.Context, click models.Click) error
+	ReadClickStats(ctx context.Context, ref models.LinkRef) (models.ClickStats, error)
+	ReadLinkHealth(ctx context.Context, refs []models.LinkRef) (map[models.LinkRef]models.LinkHealth, error)
+	WriteWebhook(ctx context.Context, webhook models.Webhook) error
+	ReadWebhooksByCreatorID(ctx context.Context, creatorID string) ([]models.Webhook, error)
+	DeleteWebhook(ctx context.Context, creatorID, id string) error
+	ReadWebhookDeliveries(ctx context.Context, webhookID string, deadOnly bool) ([]models.WebhookDelivery, error)
+	WriteAPIKey(ctx context.Context, key models.APIKey) error
+	ReadAPIKeysByCreatorID(ctx context.Context, creatorID string) ([]models.APIKey, error)
+	ReadAPIKeyByHash(ctx context.Context, hash string) (models.APIKey, error)
+	DeleteAPIKey(ctx context.Context, creatorID, id string) error
+	TouchAPIKey(ctx context.Context, id string, usedAt time.Time) error
+	WriteUser(ctx context.Context, user models.User) error
+	ReadUserByEmail(ctx context.Context, email string) (models.User, error)
+	ReadUserByID(ctx context.Context, id string) (models.User, error)
+	WriteIdentity(ctx context.Context, identity models.Identity) error
+	ReadIdentity(ctx context.Context, issuer, subject string) (models.Identity, error)
+	ReadIdentitiesByUserID(ctx context.Context, userID string) ([]models.Identity, error)
+	WriteWorkspace(ctx context.Context, workspace models.Workspace, owner models.WorkspaceMember) error
+	ReadWorkspacesByUserID(ctx context.Context, userID string) ([]models.Workspace, error)
+	ReadWorkspaceMember(ctx context.Context, workspaceID, userID string) (models.WorkspaceMember, error)
+	ReadWorkspaceMembers(ctx context.Context, workspaceID string) ([]models.WorkspaceMember, error)
+	WriteWorkspaceMember(ctx context.Context, member models.WorkspaceMember) error
+	DeleteWorkspaceMember(ctx context.Context, workspaceID, userID string) error
+	ReassignCreator(ctx context.Context, from, to string) error
 }
 
-func (sh *ShortLinkService) MakeShortURL(ctx context.Context, originalURL, uid string) (string, error) {
-	hash, err := sh.generateHash(originalURL)
+func (sh *ShortLinkService) MakeShortURL(ctx context.Context, originalURL, uid string, opts models.LinkOptions) (string, error) {
+	opts, err := normalizeOptions(opts)
+	if err != nil {
+		return "", err
+	}
+
+	originalURL, err = applyUTM(originalURL, opts.UTM)
+	if err != nil {
+		return "", err
+	}
+
+	if err := sh.checkDestinations(originalURL, opts); err != nil {
+		return "", err
+	}
+
+	if err := sh.checkLinkWorkspace(ctx, opts.WorkspaceID); err != nil {
+		return "", err
+	}
+
+	opts.Domain, err = sh.domains.Lookup(opts.Domain)
 	if err != nil {
-		return "", fmt.Errorf("generate hash failed: %w", err)
+		return "", err
+	}
+
+	if shortURL, err := sh.existingLink(ctx, opts.Domain, originalURL); !errors.Is(err, storage.ErrNotFound) {
+		return shortURL, err
+	}
+
+	// повтор уже сокращенного адреса квоту не тратит, поэтому проверяем ее после поиска дубликата
+	if err := sh.checkLinkQuota(ctx, 1); err != nil {
+		return "", err
 	}
 
 	if uid == "" {
@@ -56,33 +165,235 @@ func (sh *ShortLinkService) MakeShortURL(ctx context.Context, originalURL, uid s
 	event := models.Event{
 		UUID:        uid,
 		CreatorID:   ctxaux.GetUserIDFromContext(ctx),
-		ShortURL:    sh.baseURL.String() + "/" + hash,
 		OriginalURL: originalURL,
+		CreatedAt:   time.Now().UTC(),
+		LinkOptions: opts,
 	}
 
-	err = sh.storage.WriteEvent(ctx, event)
+	// код мог занять параллельный запрос между проверкой и записью, тогда подбираем следующий
+	for attempt := 0; ; attempt++ {
+		event.Code, err = sh.freeCode(ctx, opts.Domain, originalURL)
+		if err != nil {
+			return "", err
+		}
+
+		err = sh.storage.WriteEvent(ctx, event)
+		if !errors.Is(err, storage.ErrCodeTaken) || attempt == codeAttempts-1 {
+			break
+		}
+	}
 	if err != nil {
 		if errors.Is(err, storage.ErrDuplicateURL) {
-			return event.ShortURL, err
+			return sh.existingLink(ctx, opts.Domain, originalURL)
 		}
 
 		return "", fmt.Errorf("save event failed: %w", err)
 	}
+
+	event = sh.render(event)
+
+	sh.notify(models.NotificationLinkCreated, event, "")
 	return event.ShortURL, nil
 }
 
-func (sh *ShortLinkService) GetOriginalURL(ctx context.Context, hash string) (string, error) {
-	short := sh.baseURL.String() + hash
-	originalURL, err := sh.storage.ReadEvent(ctx, short)
+func (sh *ShortLinkService) GetOriginalURL(ctx context.Context, hash string, visitor models.Visitor) (models.Redirect, error) {
+	event, err := sh.GetShortLink(ctx, hash)
+	if err != nil {
+		return models.Redirect{}, err
+	}
+
+	redirect := models.Redirect{URL: event.OriginalURL, StatusCode: sh.RedirectCode(event)}
+	if target, ok := matchTargeting(event.TargetingRules, visitor); ok {
+		redirect.URL = target
+	} else if variant, ok := pickVariant(event.Variants, event.StickyVariants, visitor.Variant); ok {
+		redirect.URL = variant.URL
+		redirect.Variant = variant.Name
+		redirect.Sticky = event.StickyVariants
+	}
+
+	redirect.URL, err = applyPassthrough(redirect.URL, event.Passthrough, visitor)
+	if err != nil {
+		return models.Redirect{}, err
+	}
+
+	click := models.Click{Link: event.Ref(), Variant: redirect.Variant, CreatedAt: time.Now()}
+	if err := sh.storage.WriteClick(ctx, click); err != nil {
+		// из-за статистики редирект не ломаем
+		logrus.Errorf("write click failed: %v", err)
+	}
+	sh.notify(models.NotificationLinkClicked, event, redirect.Variant)
+
+	return redirect, nil
+}
+
+// RedirectCode - код редиректа ссылки, без своего кода - код по умолчанию для сервиса.
+func (sh *ShortLinkService) RedirectCode(event models.Event) int {
+	if event.RedirectCode != 0 {
+		return event.RedirectCode
+	}
+
+	return sh.redirectCode
+}
+
+// GetShortLink ищет ссылку так же, как редирект, но не считает переход.
+func (sh *ShortLinkService) GetShortLink(ctx context.Context, hash string) (models.Event, error) {
+	domain, err := sh.domains.Lookup(ctxaux.GetDomainFromContext(ctx))
+	if err != nil {
+		return models.Event{}, err
+	}
+
+	event, err := sh.storage.ReadEvent(ctx, models.LinkRef{Domain: domain, Code: hash})
+	if err != nil {
+		return models.Event{}, fmt.Errorf("fetch url failed or not found: %w", err)
+	}
+
+	return sh.render(event), nil
+}
+
+func (sh *ShortLinkService) PreviewLink(ctx context.Context, hash string) (models.Preview, error) {
+	event, err := sh.GetShortLink(ctx, hash)
 	if err != nil {
-		return "", fmt.Errorf("fetch url failed or not found: %w", err)
+		return models.Preview{}, err
 	}
 
-	return originalURL, nil
+	stats, err := sh.storage.ReadClickStats(ctx, event.Ref())
+	if err != nil {
+		return models.Preview{}, fmt.Errorf("read click stats failed: %w", err)
+	}
+
+	preview := models.Preview{
+		ShortURL:    event.ShortURL,
+		OriginalURL: event.OriginalURL,
+		Title:       event.Title,
+		Clicks:      stats.Clicks,
+	}
+	if !event.CreatedAt.IsZero() {
+		preview.CreatedAt = &event.CreatedAt
+	}
+
+	return preview, nil
 }
 
-func (sh *ShortLinkService) generateHash(url string) (string, error) {
-	generatedNumber := new(big.Int).SetBytes([]byte(url)).Uint64()
+func (sh *ShortLinkService) shortURL(domain, hash string) string {
+	return sh.domains.URL(domain).String() + "/" + hash
+}
+
+// render собирает адрес ссылки из домена и кода по текущей конфигурации: после смены BASE_URL ссылки отдаются уже с новым адресом.
+func (sh *ShortLinkService) render(event models.Event) models.Event {
+	event.ShortURL = sh.shortURL(event.Domain, event.Code)
+
+	return event
+}
+
+// ResolveDomain - домен ссылок для заголовка Host.
+func (sh *ShortLinkService) ResolveDomain(host string) string {
+	return sh.domains.Resolve(host)
+}
+
+// Domains - хосты, на которых можно создавать ссылки, основной первым.
+func (sh *ShortLinkService) Domains() []string {
+	return sh.domains.Hosts()
+}
+
+// ownedEvent достает ссылку и проверяет, что она доступна текущему пользователю: личная ссылка - только создателю,
+// ссылка пространства - участникам с ролью не ниже required.
+func (sh *ShortLinkService) ownedEvent(ctx context.Context, hash, required string) (models.Event, error) {
+	userID := ctxaux.GetUserIDFromContext(ctx)
+	if userID == "" {
+		return models.Event{}, ErrUnauthorized
+	}
+
+	event, err := sh.GetShortLink(ctx, hash)
+	if err != nil {
+		return models.Event{}, err
+	}
+
+	if event.WorkspaceID == "" {
+		if event.CreatorID != userID {
+			return models.Event{}, ErrNotOwner
+		}
+
+		return event, nil
+	}
+
+	_, err = sh.requireWorkspaceRole(ctx, event.WorkspaceID, required)
+	if errors.Is(err, ErrWorkspaceNotFound) {
+		return models.Event{}, ErrNotOwner
+	}
+	if err != nil {
+		return models.Event{}, err
+	}
+
+	return event, nil
+}
+
+func normalizeOptions(opts models.LinkOptions) (models.LinkOptions, error) {
+	if opts.RedirectCode != 0 && !models.ValidRedirectCode(opts.RedirectCode) {
+		return opts, fmt.Errorf("%w: %d", ErrInvalidRedirectCode, opts.RedirectCode)
+	}
+
+	if err := validateTargetingRules(opts.TargetingRules); err != nil {
+		return opts, err
+	}
+
+	if err := validatePassthrough(opts.Passthrough); err != nil {
+		return opts, err
+	}
+
+	utm, err := normalizeUTM(opts.UTM)
+	if err != nil {
+		return opts, err
+	}
+	opts.UTM = utm
+
+	opts, err = normalizeMeta(opts)
+	if err != nil {
+		return opts, err
+	}
+
+	variants, err := normalizeVariants(opts.Variants)
+	if err != nil {
+		return opts, err
+	}
+	opts.Variants = variants
+
+	return opts, nil
+}
+
+// existingLink возвращает уже сокращенный на домене адрес вместе с ErrDuplicateURL.
+func (sh *ShortLinkService) existingLink(ctx context.Context, domain, originalURL string) (string, error) {
+	existing, err := sh.storage.ReadEventByOriginalURL(ctx, domain, originalURL)
+	if err != nil {
+		return "", fmt.Errorf("check duplicate failed: %w", err)
+	}
+
+	return sh.render(existing).ShortURL, storage.ErrDuplicateURL
+}
+
+// freeCode подбирает для адреса код, не занятый на домене. Удаленные ссылки свои коды не освобождают.
+func (sh *ShortLinkService) freeCode(ctx context.Context, domain, originalURL string) (string, error) {
+	for attempt := 0; attempt < codeAttempts; attempt++ {
+		code, err := sh.generateHash(originalURL, attempt)
+		if err != nil {
+			return "", fmt.Errorf("generate hash failed: %w", err)
+		}
+
+		_, err = sh.storage.ReadEvent(ctx, models.LinkRef{Domain: domain, Code: code})
+		if errors.Is(err, storage.ErrNotFound) {
+			return code, nil
+		}
+		if err != nil && !errors.Is(err, storage.ErrEventDeleted) {
+			return "", fmt.Errorf("check code failed: %w", err)
+		}
+	}
+
+	return "", fmt.Errorf("generate hash failed: %w", storage.ErrCodeTaken)
+}
+
+// generateHash выводит код из дайджеста всего адреса. attempt дает следующий кандидат, если код уже занят.
+func (sh *ShortLinkService) generateHash(url string, attempt int) (string, error) {
+	digest := sha256.Sum256([]byte(url + "#" + strconv.Itoa(attempt)))
+	generatedNumber := binary.BigEndian.Uint64(digest[:8])
 	s, err := sqids.New(sqids.Options{MinLength: minLength})
 	if err != nil {
 		return "", err
@@ -98,47 +409,132 @@ func (sh *ShortLinkService) generateHash(url string) (string, error) {
 
 func (sh *ShortLinkService) MakeShortURLs(ctx context.Context, bulk models.ListRequestBulk) ([]models.Event, error) {
 	events := make([]models.Event, 0, len(bulk))
+	if err := sh.checkBatchQuota(ctx, len(bulk)); err != nil {
+		return events, err
+	}
+
 	for _, r := range bulk {
-		hash, err := sh.generateHash(r.OriginalURL.String())
+		opts, err := normalizeOptions(r.LinkOptions)
 		if err != nil {
-			return events, fmt.Errorf("genarate hash failed: %w", err)
+			return events, err
+		}
+
+		originalURL, err := applyUTM(r.OriginalURL.String(), opts.UTM)
+		if err != nil {
+			return events, err
+		}
+
+		if err := sh.checkDestinations(originalURL, opts); err != nil {
+			return events, err
+		}
+
+		if err := sh.checkLinkWorkspace(ctx, opts.WorkspaceID); err != nil {
+			return events, err
+		}
+
+		opts.Domain, err = sh.domains.Lookup(opts.Domain)
+		if err != nil {
+			return events, err
 		}
 
 		event := models.Event{
 			UUID:        r.CorrelationID,
 			CreatorID:   ctxaux.GetUserIDFromContext(ctx),
-			ShortURL:    sh.baseURL.String() + "/" + hash,
-			OriginalURL: r.OriginalURL.String(),
+			OriginalURL: originalURL,
+			CreatedAt:   time.Now().UTC(),
+			LinkOptions: opts,
 		}
 		events = append(events, event)
 	}
 
-	err := sh.storage.WriteEvents(ctx, events)
+	var err error
+	for attempt := 0; ; attempt++ {
+		var fresh []models.Event
+		if fresh, err = sh.assignCodes(ctx, events); err != nil {
+			return events, err
+		}
+
+		if err := sh.checkLinkQuota(ctx, len(fresh)); err != nil {
+			return events, err
+		}
+
+		err = sh.storage.WriteEvents(ctx, fresh)
+		if !errors.Is(err, storage.ErrCodeTaken) || attempt == codeAttempts-1 {
+			break
+		}
+	}
 	if err != nil {
 		return events, fmt.Errorf("save events failed: %w", err)
 	}
 
+	for i := range events {
+		events[i] = sh.render(events[i])
+		if !events[i].Duplicate {
+			sh.notify(models.NotificationLinkCreated, events[i], "")
+		}
+	}
+
 	return events, nil
 }
 
-func (sh *ShortLinkService) LinksByCreatorID(ctx context.Context) ([]models.Event, error) {
-	var events []models.Event
+// assignCodes дает ссылкам пачки коды и возвращает новые. Уже сокращенный адрес, как и повтор
+// внутри пачки, получает существующий код и отметку Duplicate, его настройки не применяются.
+func (sh *ShortLinkService) assignCodes(ctx context.Context, events []models.Event) ([]models.Event, error) {
+	type destination struct{ domain, url string }
+
+	fresh := make([]models.Event, 0, len(events))
+	seen := make(map[destination]string, len(events))
+	for i, e := range events {
+		key := destination{domain: e.Domain, url: e.OriginalURL}
+		if code, ok := seen[key]; ok {
+			events[i].Code, events[i].Duplicate = code, true
+
+			continue
+		}
+
+		existing, err := sh.storage.ReadEventByOriginalURL(ctx, e.Domain, e.OriginalURL)
+		switch {
+		case err == nil:
+			events[i].Code, events[i].Duplicate = existing.Code, true
+		case errors.Is(err, storage.ErrNotFound):
+			events[i].Code, err = sh.freeCode(ctx, e.Domain, e.OriginalURL)
+			if err != nil {
+				return nil, err
+			}
+			events[i].Duplicate = false
+			fresh = append(fresh, events[i])
+		default:
+			return nil, fmt.Errorf("check duplicate failed: %w", err)
+		}
+		seen[key] = events[i].Code
+	}
+
+	return fresh, nil
+}
 
-	events, err := sh.storage.ReadEventsByCreatorID(ctx, ctxaux.GetUserIDFromContext(ctx))
+// LinksByCreatorID - личные ссылки пользователя и ссылки пространств, где он состоит.
+func (sh *ShortLinkService) LinksByCreatorID(ctx context.Context, filter models.LinkFilter) ([]models.Event, error) {
+	events, err := sh.accessibleLinks(ctx, models.RoleViewer)
 	if err != nil {
-		return events, fmt.Errorf("failed get links for current user")
+		return events, fmt.Errorf("failed get links for current user: %w", err)
 	}
 
-	return events, nil
+	return filterEvents(events, filter), nil
 }
 
 func (sh *ShortLinkService) DeleteShortURL(ctx context.Context, shortUrls []string) error {
 	if ctxaux.GetUserIDFromContext(ctx) == "" {
-		return errors.New("no user is currently logged in")
+		return ErrUnauthorized
+	}
+
+	domain, err := sh.domains.Lookup(ctxaux.GetDomainFromContext(ctx))
+	if err != nil {
+		return err
 	}
 
-	chUrls := sh.urlDeleteGenerator(ctx, shortUrls)
-	linksByUser, err := sh.LinksByCreatorID(ctx)
+	chUrls := sh.urlDeleteGenerator(ctx, domain, shortUrls)
+	// удалять ссылки пространства могут редакторы и владельцы, читатели только видят их в списке
+	linksByUser, err := sh.accessibleLinks(ctx, models.RoleEditor)
 	if err != nil {
 		return fmt.Errorf("get links by creator id failed: %w", err)
 	}
@@ -155,14 +551,14 @@ func (sh *ShortLinkService) DeleteShortURL(ctx context.Context, shortUrls []stri
 	return nil
 }
 
-func (sh *ShortLinkService) urlDeleteGenerator(ctx context.Context, shortUrls []string) chan string {
-	checkCh := make(chan string)
+func (sh *ShortLinkService) urlDeleteGenerator(ctx context.Context, domain string, shortUrls []string) chan models.LinkRef {
+	checkCh := make(chan models.LinkRef)
 	go func() {
 		defer close(checkCh)
 
 		for _, u := range shortUrls {
 			select {
-			case checkCh <- sh.baseURL.String() + "/" + u:
+			case checkCh <- models.LinkRef{Domain: domain, Code: u}:
 			case <-ctx.Done():
 				return
 			}
@@ -172,17 +568,20 @@ func (sh *ShortLinkService) urlDeleteGenerator(ctx context.Context, shortUrls []
 	return checkCh
 }
 
-func (sh *ShortLinkService) sendDeleteByUser(ctx context.Context, urls chan string, urlsByUser []models.Event) chan string {
-	resURL := make(chan string)
+func (sh *ShortLinkService) sendDeleteByUser(ctx context.Context, urls chan models.LinkRef, urlsByUser []models.Event) chan models.Event {
+	resURL := make(chan models.Event)
 	go func() {
 		defer close(resURL)
 
 		for {
 			select {
-			case data := <-urls:
+			case data, ok := <-urls:
+				if !ok {
+					return
+				}
 				for _, u := range urlsByUser {
-					if u.ShortURL == data {
-						resURL <- data
+					if u.Ref() == data {
+						resURL <- u
 					}
 				}
 			case <-ctx.Done():
@@ -195,16 +594,24 @@ func (sh *ShortLinkService) sendDeleteByUser(ctx context.Context, urls chan stri
 }
 
 func (sh *ShortLinkService) runDelete() {
-	urlsBatch := make([]string, 0, batchDelete*2)
+	urlsBatch := make([]models.Event, 0, batchDelete*2)
 	tiker := time.NewTicker(1 * time.Second)
 
 	funcDelete := func() {
 		if len(urlsBatch) != 0 {
-			err := sh.storage.SetDeleteByShortURL(urlsBatch)
+			refs := make([]models.LinkRef, 0, len(urlsBatch))
+			for _, e := range urlsBatch {
+				refs = append(refs, e.Ref())
+			}
+
+			err := sh.storage.SetDeleted(refs)
 			if err != nil {
 				logrus.Errorf("set delete batch failed: %v", err)
 			} else {
-				urlsBatch = []string{}
+				for _, e := range urlsBatch {
+					sh.notify(models.NotificationLinkDeleted, e, "")
+				}
+				urlsBatch = []models.Event{}
 			}
 		}
 	}
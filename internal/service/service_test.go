@@ -0,0 +1,133 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/patrick-devel/shorturl/internal/models"
+	"github.com/patrick-devel/shorturl/internal/service"
+	"github.com/patrick-devel/shorturl/internal/storage"
+)
+
+var baseURL = &url.URL{Scheme: "http", Host: "localhost:8080"}
+
+func newService(t *testing.T, cache map[models.LinkRef]models.Event, opts ...service.Option) *service.ShortLinkService {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	if cache == nil {
+		cache = map[models.LinkRef]models.Event{}
+	}
+
+	return service.New(baseURL, storage.NewMemoryStorage(cache), ctx, opts...)
+}
+
+func TestMakeShortURLCodes(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("SameUTMDifferentDestinations", func(t *testing.T) {
+		sh := newService(t, nil)
+		utm := &models.UTM{Source: "newsletter"}
+
+		first, err := sh.MakeShortURL(ctx, "https://go.dev/doc", "", models.LinkOptions{UTM: utm})
+		require.NoError(t, err)
+		second, err := sh.MakeShortURL(ctx, "https://go.dev/blog", "", models.LinkOptions{UTM: utm})
+		require.NoError(t, err)
+		assert.NotEqual(t, first, second)
+
+		for shortURL, original := range map[string]string{
+			first:  "https://go.dev/doc?utm_source=newsletter",
+			second: "https://go.dev/blog?utm_source=newsletter",
+		} {
+			redirect, err := sh.GetOriginalURL(ctx, path.Base(shortURL), models.Visitor{})
+			require.NoError(t, err)
+			assert.Equal(t, original, redirect.URL)
+		}
+	})
+
+	t.Run("Duplicate", func(t *testing.T) {
+		sh := newService(t, nil)
+
+		first, err := sh.MakeShortURL(ctx, "https://go.dev/", "", models.LinkOptions{})
+		require.NoError(t, err)
+		second, err := sh.MakeShortURL(ctx, "https://go.dev/", "", models.LinkOptions{})
+		require.ErrorIs(t, err, storage.ErrDuplicateURL)
+		assert.Equal(t, first, second)
+	})
+
+	t.Run("CodeTaken", func(t *testing.T) {
+		// код, который получил бы адрес, уже занят другой ссылкой
+		shortURL, err := newService(t, nil).MakeShortURL(ctx, "https://go.dev/", "", models.LinkOptions{})
+		require.NoError(t, err)
+		taken := models.LinkRef{Code: path.Base(shortURL)}
+		cache := map[models.LinkRef]models.Event{
+			taken: {Code: taken.Code, OriginalURL: "https://example.com/"},
+		}
+		sh := newService(t, cache)
+
+		shortURL, err = sh.MakeShortURL(ctx, "https://go.dev/", "", models.LinkOptions{})
+		require.NoError(t, err)
+		assert.NotEqual(t, taken.Code, path.Base(shortURL))
+
+		redirect, err := sh.GetOriginalURL(ctx, taken.Code, models.Visitor{})
+		require.NoError(t, err)
+		assert.Equal(t, "https://example.com/", redirect.URL)
+		redirect, err = sh.GetOriginalURL(ctx, path.Base(shortURL), models.Visitor{})
+		require.NoError(t, err)
+		assert.Equal(t, "https://go.dev/", redirect.URL)
+
+		// повтор находит ссылку и под вторым кодом
+		again, err := sh.MakeShortURL(ctx, "https://go.dev/", "", models.LinkOptions{})
+		require.ErrorIs(t, err, storage.ErrDuplicateURL)
+		assert.Equal(t, shortURL, again)
+	})
+}
+
+func TestMakeShortURLsDuplicates(t *testing.T) {
+	sh := newService(t, nil)
+	ctx := userContext("owner")
+
+	existing, err := sh.MakeShortURL(ctx, "https://go.dev/", "", models.LinkOptions{RedirectCode: http.StatusFound})
+	require.NoError(t, err)
+
+	goDev, err := url.Parse("https://go.dev/")
+	require.NoError(t, err)
+	blog, err := url.Parse("https://go.dev/blog")
+	require.NoError(t, err)
+	permanent := models.LinkOptions{RedirectCode: http.StatusMovedPermanently}
+
+	events, err := sh.MakeShortURLs(ctx, models.ListRequestBulk{
+		{CorrelationID: "1", OriginalURL: *goDev, LinkOptions: permanent},
+		{CorrelationID: "2", OriginalURL: *blog, LinkOptions: permanent},
+		{CorrelationID: "3", OriginalURL: *blog},
+	})
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+
+	// уже сокращенный адрес отдается как повтор, его настройки не меняются
+	assert.True(t, events[0].Duplicate)
+	assert.Equal(t, existing, events[0].ShortURL)
+	redirect, err := sh.GetOriginalURL(ctx, path.Base(existing), models.Visitor{})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusFound, redirect.StatusCode)
+
+	// повтор внутри пачки получает ссылку первого вхождения
+	assert.False(t, events[1].Duplicate)
+	assert.True(t, events[2].Duplicate)
+	assert.Equal(t, events[1].ShortURL, events[2].ShortURL)
+	redirect, err = sh.GetOriginalURL(ctx, path.Base(events[1].ShortURL), models.Visitor{})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusMovedPermanently, redirect.StatusCode)
+
+	links, err := sh.LinksByCreatorID(ctx, models.LinkFilter{})
+	require.NoError(t, err)
+	assert.Len(t, links, 2)
+}
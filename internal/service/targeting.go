@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/patrick-devel/shorturl/internal/models"
+)
+
+var ErrInvalidTargetingRule = errors.New("targeting rule is invalid")
+
+func (sh *ShortLinkService) TargetingRules(ctx context.Context, hash string) ([]models.TargetingRule, error) {
+	event, err := sh.ownedEvent(ctx, hash, models.RoleViewer)
+	if err != nil {
+		return nil, err
+	}
+
+	return event.TargetingRules, nil
+}
+
+func (sh *ShortLinkService) SetTargetingRules(ctx context.Context, hash string, rules []models.TargetingRule) error {
+	if err := validateTargetingRules(rules); err != nil {
+		return err
+	}
+
+	for _, r := range rules {
+		if err := sh.checkDestination(r.URL); err != nil {
+			return err
+		}
+	}
+
+	event, err := sh.ownedEvent(ctx, hash, models.RoleEditor)
+	if err != nil {
+		return err
+	}
+
+	event.TargetingRules = rules
+	if err := sh.storage.UpdateEvent(ctx, event); err != nil {
+		return fmt.Errorf("update targeting rules failed: %w", err)
+	}
+	sh.notify(models.NotificationLinkUpdated, event, "")
+
+	return nil
+}
+
+func validateTargetingRules(rules []models.TargetingRule) error {
+	for i, r := range rules {
+		if _, err := url.ParseRequestURI(r.URL); err != nil {
+			return fmt.Errorf("%w: rule %d: bad url: %v", ErrInvalidTargetingRule, i, err)
+		}
+
+		if r.Device == "" && r.Language == "" && len(r.Query) == 0 {
+			return fmt.Errorf("%w: rule %d has no conditions", ErrInvalidTargetingRule, i)
+		}
+
+		switch r.Device {
+		case "", models.DeviceIOS, models.DeviceAndroid, models.DeviceMobile, models.DeviceDesktop:
+		default:
+			return fmt.Errorf("%w: rule %d: unknown device %q", ErrInvalidTargetingRule, i, r.Device)
+		}
+	}
+
+	return nil
+}
+
+func matchTargeting(rules []models.TargetingRule, visitor models.Visitor) (string, bool) {
+	if len(rules) == 0 {
+		return "", false
+	}
+
+	device := deviceFamily(visitor.UserAgent)
+	language := preferredLanguage(visitor.AcceptLanguage)
+
+	for _, r := range rules {
+		if r.Device != "" && !matchDevice(r.Device, device) {
+			continue
+		}
+		if r.Language != "" && !matchLanguage(r.Language, language) {
+			continue
+		}
+		if !matchQuery(r.Query, visitor.Query) {
+			continue
+		}
+
+		return r.URL, true
+	}
+
+	return "", false
+}
+
+func deviceFamily(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+
+	switch {
+	case strings.Contains(ua, "iphone"), strings.Contains(ua, "ipad"), strings.Contains(ua, "ipod"):
+		return models.DeviceIOS
+	case strings.Contains(ua, "android"):
+		return models.DeviceAndroid
+	case strings.Contains(ua, "mobile"):
+		return models.DeviceMobile
+	default:
+		return models.DeviceDesktop
+	}
+}
+
+func matchDevice(rule, device string) bool {
+	if rule == models.DeviceMobile {
+		return device != models.DeviceDesktop
+	}
+
+	return rule == device
+}
+
+// preferredLanguage возвращает язык с наибольшим весом из заголовка Accept-Language.
+func preferredLanguage(header string) string {
+	type langQ struct {
+		tag string
+		q   float64
+	}
+
+	var langs []langQ
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if tag == "" || tag == "*" {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		langs = append(langs, langQ{tag: strings.ToLower(tag), q: q})
+	}
+
+	if len(langs) == 0 {
+		return ""
+	}
+
+	sort.SliceStable(langs, func(i, j int) bool { return langs[i].q > langs[j].q })
+	return langs[0].tag
+}
+
+func matchLanguage(rule, language string) bool {
+	rule = strings.ToLower(rule)
+	if strings.Contains(rule, "-") {
+		return rule == language
+	}
+
+	primary, _, _ := strings.Cut(language, "-")
+	return rule == primary
+}
+
+func matchQuery(rule map[string]string, query url.Values) bool {
+	for k, v := range rule {
+		if !query.Has(k) {
+			return false
+		}
+		if v != "" && query.Get(k) != v {
+			return false
+		}
+	}
+
+	return true
+}
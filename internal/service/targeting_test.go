@@ -0,0 +1,232 @@
+package service_test
+
+import (
+	"context"
+	"net/url"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/patrick-devel/shorturl/internal/models"
+	"github.com/patrick-devel/shorturl/internal/service"
+)
+
+const (
+	uaIPhone       = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1"
+	uaAndroid      = "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Mobile Safari/537.36"
+	uaFeaturePhone = "Mozilla/5.0 (Mobile; rv:48.0) Gecko/48.0 Firefox/48.0 KAIOS/2.5"
+	uaDesktop      = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36"
+)
+
+func TestTargeting(t *testing.T) {
+	const original = "https://go.dev/"
+
+	tests := []struct {
+		name    string
+		rules   []models.TargetingRule
+		visitor models.Visitor
+		expURL  string
+	}{
+		{
+			name:    "NoRules",
+			visitor: models.Visitor{UserAgent: uaIPhone},
+			expURL:  original,
+		},
+		{
+			name:    "IPhone",
+			rules:   []models.TargetingRule{{Device: models.DeviceIOS, URL: "https://apps.apple.com/"}},
+			visitor: models.Visitor{UserAgent: uaIPhone},
+			expURL:  "https://apps.apple.com/",
+		},
+		{
+			name:    "AndroidIsNotIOS",
+			rules:   []models.TargetingRule{{Device: models.DeviceIOS, URL: "https://apps.apple.com/"}},
+			visitor: models.Visitor{UserAgent: uaAndroid},
+			expURL:  original,
+		},
+		{
+			name:    "Android",
+			rules:   []models.TargetingRule{{Device: models.DeviceAndroid, URL: "https://play.google.com/"}},
+			visitor: models.Visitor{UserAgent: uaAndroid},
+			expURL:  "https://play.google.com/",
+		},
+		{
+			name:    "MobileMatchesAndroid",
+			rules:   []models.TargetingRule{{Device: models.DeviceMobile, URL: "https://m.go.dev/"}},
+			visitor: models.Visitor{UserAgent: uaAndroid},
+			expURL:  "https://m.go.dev/",
+		},
+		{
+			name:    "OtherMobile",
+			rules:   []models.TargetingRule{{Device: models.DeviceMobile, URL: "https://m.go.dev/"}},
+			visitor: models.Visitor{UserAgent: uaFeaturePhone},
+			expURL:  "https://m.go.dev/",
+		},
+		{
+			name:    "MobileSkipsDesktop",
+			rules:   []models.TargetingRule{{Device: models.DeviceMobile, URL: "https://m.go.dev/"}},
+			visitor: models.Visitor{UserAgent: uaDesktop},
+			expURL:  original,
+		},
+		{
+			name:    "Desktop",
+			rules:   []models.TargetingRule{{Device: models.DeviceDesktop, URL: "https://go.dev/dl/"}},
+			visitor: models.Visitor{UserAgent: uaDesktop},
+			expURL:  "https://go.dev/dl/",
+		},
+		{
+			name:    "EmptyUserAgentIsDesktop",
+			rules:   []models.TargetingRule{{Device: models.DeviceDesktop, URL: "https://go.dev/dl/"}},
+			visitor: models.Visitor{},
+			expURL:  "https://go.dev/dl/",
+		},
+		{
+			name:    "PrimaryLanguage",
+			rules:   []models.TargetingRule{{Language: "de", URL: "https://go.dev/de/"}},
+			visitor: models.Visitor{AcceptLanguage: "de-DE,en;q=0.8"},
+			expURL:  "https://go.dev/de/",
+		},
+		{
+			name:    "RegionalLanguage",
+			rules:   []models.TargetingRule{{Language: "de-DE", URL: "https://go.dev/de/"}},
+			visitor: models.Visitor{AcceptLanguage: "de-DE,en;q=0.8"},
+			expURL:  "https://go.dev/de/",
+		},
+		{
+			name:    "OtherRegion",
+			rules:   []models.TargetingRule{{Language: "de-AT", URL: "https://go.dev/at/"}},
+			visitor: models.Visitor{AcceptLanguage: "de-DE,en;q=0.8"},
+			expURL:  original,
+		},
+		{
+			name:    "LowerQualityIgnored",
+			rules:   []models.TargetingRule{{Language: "en", URL: "https://go.dev/en/"}},
+			visitor: models.Visitor{AcceptLanguage: "de-DE,en;q=0.8"},
+			expURL:  original,
+		},
+		{
+			// без q вес 1, поэтому fr важнее en, хотя стоит вторым
+			name: "QualityOrder",
+			rules: []models.TargetingRule{
+				{Language: "en", URL: "https://go.dev/en/"},
+				{Language: "fr", URL: "https://go.dev/fr/"},
+			},
+			visitor: models.Visitor{AcceptLanguage: "en;q=0.1,fr"},
+			expURL:  "https://go.dev/fr/",
+		},
+		{
+			name:    "LanguageCaseInsensitive",
+			rules:   []models.TargetingRule{{Language: "PT-br", URL: "https://go.dev/br/"}},
+			visitor: models.Visitor{AcceptLanguage: "pt-BR"},
+			expURL:  "https://go.dev/br/",
+		},
+		{
+			name:    "QueryValue",
+			rules:   []models.TargetingRule{{Query: map[string]string{"ref": "ads"}, URL: "https://go.dev/ads/"}},
+			visitor: models.Visitor{Query: url.Values{"ref": {"ads"}}},
+			expURL:  "https://go.dev/ads/",
+		},
+		{
+			name:    "QueryOtherValue",
+			rules:   []models.TargetingRule{{Query: map[string]string{"ref": "ads"}, URL: "https://go.dev/ads/"}},
+			visitor: models.Visitor{Query: url.Values{"ref": {"mail"}}},
+			expURL:  original,
+		},
+		{
+			name:    "QueryPresence",
+			rules:   []models.TargetingRule{{Query: map[string]string{"beta": ""}, URL: "https://go.dev/beta/"}},
+			visitor: models.Visitor{Query: url.Values{"beta": {"1"}}},
+			expURL:  "https://go.dev/beta/",
+		},
+		{
+			name:    "QueryMissing",
+			rules:   []models.TargetingRule{{Query: map[string]string{"beta": ""}, URL: "https://go.dev/beta/"}},
+			visitor: models.Visitor{},
+			expURL:  original,
+		},
+		{
+			name: "AllConditions",
+			rules: []models.TargetingRule{
+				{Device: models.DeviceIOS, Language: "de", Query: map[string]string{"ref": "ads"}, URL: "https://go.dev/all/"},
+			},
+			visitor: models.Visitor{UserAgent: uaIPhone, AcceptLanguage: "de", Query: url.Values{"ref": {"mail"}}},
+			expURL:  original,
+		},
+		{
+			name: "FirstMatchWins",
+			rules: []models.TargetingRule{
+				{Language: "fr", URL: "https://go.dev/fr/"},
+				{Device: models.DeviceIOS, URL: "https://apps.apple.com/"},
+				{Device: models.DeviceMobile, URL: "https://m.go.dev/"},
+			},
+			visitor: models.Visitor{UserAgent: uaIPhone, AcceptLanguage: "de"},
+			expURL:  "https://apps.apple.com/",
+		},
+	}
+
+	for _, tc := range tests {
+		testcase := tc
+		t.Run(testcase.name, func(t *testing.T) {
+			ref := models.LinkRef{Code: "abc"}
+			sh := newService(t, map[models.LinkRef]models.Event{ref: {
+				Code:        ref.Code,
+				OriginalURL: original,
+				LinkOptions: models.LinkOptions{TargetingRules: testcase.rules},
+			}})
+
+			redirect, err := sh.GetOriginalURL(context.Background(), ref.Code, testcase.visitor)
+			require.NoError(t, err)
+			assert.Equal(t, testcase.expURL, redirect.URL)
+		})
+	}
+}
+
+func TestSetTargetingRules(t *testing.T) {
+	tests := []struct {
+		name   string
+		rules  []models.TargetingRule
+		expErr error
+	}{
+		{name: "Valid", rules: []models.TargetingRule{{Device: models.DeviceIOS, URL: "https://apps.apple.com/"}}},
+		{name: "Clear"},
+		{
+			name:   "NoConditions",
+			rules:  []models.TargetingRule{{URL: "https://apps.apple.com/"}},
+			expErr: service.ErrInvalidTargetingRule,
+		},
+		{
+			name:   "UnknownDevice",
+			rules:  []models.TargetingRule{{Device: "tv", URL: "https://apps.apple.com/"}},
+			expErr: service.ErrInvalidTargetingRule,
+		},
+		{
+			name:   "BadURL",
+			rules:  []models.TargetingRule{{Device: models.DeviceIOS, URL: "apps.apple.com"}},
+			expErr: service.ErrInvalidTargetingRule,
+		},
+	}
+
+	for _, tc := range tests {
+		testcase := tc
+		t.Run(testcase.name, func(t *testing.T) {
+			sh := newService(t, nil)
+			ctx := userContext("owner")
+			shortURL, err := sh.MakeShortURL(ctx, "https://go.dev/", "", models.LinkOptions{})
+			require.NoError(t, err)
+
+			err = sh.SetTargetingRules(ctx, path.Base(shortURL), testcase.rules)
+			if testcase.expErr != nil {
+				assert.ErrorIs(t, err, testcase.expErr)
+
+				return
+			}
+			require.NoError(t, err)
+
+			rules, err := sh.TargetingRules(ctx, path.Base(shortURL))
+			require.NoError(t, err)
+			assert.Equal(t, len(testcase.rules), len(rules))
+		})
+	}
+}
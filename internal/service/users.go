@@ -0,0 +1,185 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/patrick-devel/shorturl/internal/ctxaux"
+	"github.com/patrick-devel/shorturl/internal/models"
+	"github.com/patrick-devel/shorturl/internal/passwords"
+	"github.com/patrick-devel/shorturl/internal/storage"
+)
+
+const (
+	minPasswordLength = 8
+	maxPasswordLength = 128
+)
+
+var (
+	ErrInvalidAccount     = errors.New("account is invalid")
+	ErrEmailTaken         = errors.New("email is already registered")
+	ErrInvalidCredentials = errors.New("email or password is wrong")
+)
+
+// dummyHash сверяется с паролем, когда email не найден: так по времени ответа не узнать, есть ли пользователь.
+var dummyHash = sync.OnceValue(func() string {
+	hash, _ := passwords.Hash("dummy password")
+
+	return hash
+})
+
+// Register заводит пользователя. Если запрос пришел с анонимным токеном, аккаунт получает его UUID
+// и все ссылки, созданные до регистрации, остаются за пользователем.
+func (sh *ShortLinkService) Register(ctx context.Context, request models.Credentials) (models.User, error) {
+	email, err := normalizeCredentials(request)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	hash, err := passwords.Hash(request.Password)
+	if err != nil {
+		return models.User{}, fmt.Errorf("hash password failed: %w", err)
+	}
+
+	id, err := sh.anonymousUserID(ctx)
+	if err != nil {
+		return models.User{}, err
+	}
+	if id == "" {
+		id = uuid.NewString()
+	}
+
+	user := models.User{ID: id, Email: email, PasswordHash: hash, CreatedAt: time.Now().UTC()}
+	err = sh.storage.WriteUser(ctx, user)
+	if errors.Is(err, storage.ErrUserExists) {
+		return models.User{}, ErrEmailTaken
+	}
+	if err != nil {
+		return models.User{}, fmt.Errorf("save user failed: %w", err)
+	}
+
+	return user, nil
+}
+
+// Login проверяет пароль. Ссылки анонимного пользователя, от имени которого пришел запрос, переходят к аккаунту.
+func (sh *ShortLinkService) Login(ctx context.Context, request models.Credentials) (models.User, error) {
+	email := strings.ToLower(strings.TrimSpace(request.Email))
+
+	user, err := sh.storage.ReadUserByEmail(ctx, email)
+	if errors.Is(err, storage.ErrUserNotFound) {
+		_, _ = passwords.Verify(request.Password, dummyHash())
+
+		return models.User{}, ErrInvalidCredentials
+	}
+	if err != nil {
+		return models.User{}, fmt.Errorf("read user failed: %w", err)
+	}
+
+	ok, err := passwords.Verify(request.Password, user.PasswordHash)
+	if err != nil {
+		return models.User{}, fmt.Errorf("verify password failed: %w", err)
+	}
+	if !ok {
+		return models.User{}, ErrInvalidCredentials
+	}
+
+	anonymous, err := sh.anonymousUserID(ctx)
+	if err != nil {
+		return models.User{}, err
+	}
+	if anonymous != "" && anonymous != user.ID {
+		if err := sh.storage.ReassignCreator(ctx, anonymous, user.ID); err != nil {
+			return models.User{}, fmt.Errorf("claim links failed: %w", err)
+		}
+	}
+
+	return user, nil
+}
+
+// LoginWithIdentity входит по учетной записи OIDC-провайдера, подпись которой уже проверена.
+// При первом входе учетная запись получает анонимный UUID запроса или новый, дальше вход идет как Login.
+func (sh *ShortLinkService) LoginWithIdentity(ctx context.Context, identity models.Identity) (models.User, error) {
+	anonymous, err := sh.anonymousUserID(ctx)
+	if err != nil {
+		return models.User{}, err
+	}
+
+	known, err := sh.storage.ReadIdentity(ctx, identity.Issuer, identity.Subject)
+	if errors.Is(err, storage.ErrIdentityNotFound) {
+		identity.UserID = anonymous
+		if identity.UserID == "" {
+			identity.UserID = uuid.NewString()
+		}
+		identity.CreatedAt = time.Now().UTC()
+
+		err = sh.storage.WriteIdentity(ctx, identity)
+		if err == nil {
+			return models.User{ID: identity.UserID, Email: identity.Email, CreatedAt: identity.CreatedAt}, nil
+		}
+		if !errors.Is(err, storage.ErrIdentityExists) {
+			return models.User{}, fmt.Errorf("save identity failed: %w", err)
+		}
+		// параллельный первый вход успел раньше, входим в созданную им запись
+		known, err = sh.storage.ReadIdentity(ctx, identity.Issuer, identity.Subject)
+	}
+	if err != nil {
+		return models.User{}, fmt.Errorf("read identity failed: %w", err)
+	}
+
+	if anonymous != "" && anonymous != known.UserID {
+		if err := sh.storage.ReassignCreator(ctx, anonymous, known.UserID); err != nil {
+			return models.User{}, fmt.Errorf("claim links failed: %w", err)
+		}
+	}
+
+	return models.User{ID: known.UserID, Email: identity.Email, CreatedAt: known.CreatedAt}, nil
+}
+
+// anonymousUserID - UUID из токена запроса, если он еще не принадлежит аккаунту или учетной записи провайдера.
+// Чужой аккаунт так не присвоить: его ссылки остаются за ним.
+func (sh *ShortLinkService) anonymousUserID(ctx context.Context) (string, error) {
+	uid := ctxaux.GetUserIDFromContext(ctx)
+	if uid == "" {
+		return "", nil
+	}
+
+	_, err := sh.storage.ReadUserByID(ctx, uid)
+	switch {
+	case errors.Is(err, storage.ErrUserNotFound):
+	case err != nil:
+		return "", fmt.Errorf("read user failed: %w", err)
+	default:
+		return "", nil
+	}
+
+	identities, err := sh.storage.ReadIdentitiesByUserID(ctx, uid)
+	if err != nil {
+		return "", fmt.Errorf("read identities failed: %w", err)
+	}
+	if len(identities) != 0 {
+		return "", nil
+	}
+
+	return uid, nil
+}
+
+func normalizeCredentials(request models.Credentials) (string, error) {
+	email := strings.ToLower(strings.TrimSpace(request.Email))
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		return "", fmt.Errorf("%w: email is not valid", ErrInvalidAccount)
+	}
+
+	if len(request.Password) < minPasswordLength || len(request.Password) > maxPasswordLength {
+		return "", fmt.Errorf("%w: password must be %d to %d characters", ErrInvalidAccount, minPasswordLength, maxPasswordLength)
+	}
+
+	return email, nil
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/patrick-devel/shorturl/internal/models"
+)
+
+var ErrInvalidUTM = errors.New("utm parameters are invalid")
+
+func normalizeUTM(utm *models.UTM) (*models.UTM, error) {
+	if utm == nil {
+		return nil, nil
+	}
+
+	normalized := models.UTM{
+		Source:   strings.TrimSpace(utm.Source),
+		Medium:   strings.TrimSpace(utm.Medium),
+		Campaign: strings.TrimSpace(utm.Campaign),
+		Term:     strings.TrimSpace(utm.Term),
+		Content:  strings.TrimSpace(utm.Content),
+	}
+
+	if normalized.IsZero() {
+		return nil, nil
+	}
+
+	if normalized.Source == "" {
+		return nil, fmt.Errorf("%w: source is required", ErrInvalidUTM)
+	}
+
+	return &normalized, nil
+}
+
+// applyUTM дописывает utm-метки в целевой URL. Одноименные метки, уже стоящие в URL, заменяются,
+// остальные параметры и фрагмент сохраняются без изменений.
+func applyUTM(destination string, utm *models.UTM) (string, error) {
+	if utm == nil {
+		return destination, nil
+	}
+
+	u, err := url.Parse(destination)
+	if err != nil {
+		return "", fmt.Errorf("%w: bad url: %v", ErrInvalidUTM, err)
+	}
+
+	params := url.Values{}
+	for k, v := range utm.Params() {
+		params.Set(k, v)
+	}
+	u.RawQuery = mergeRawQuery(u.RawQuery, params, true)
+
+	return u.String(), nil
+}
+
+func filterEvents(events []models.Event, filter models.LinkFilter) []models.Event {
+	if filter == (models.LinkFilter{}) {
+		return events
+	}
+
+	filtered := make([]models.Event, 0, len(events))
+	for _, e := range events {
+		if filter.Campaign != "" && (e.UTM == nil || e.UTM.Campaign != filter.Campaign) {
+			continue
+		}
+		if filter.Workspace != "" && e.WorkspaceID != filter.Workspace {
+			continue
+		}
+		filtered = append(filtered, e)
+	}
+
+	return filtered
+}
@@ -0,0 +1,56 @@
+package service_test
+
+import (
+	"context"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/patrick-devel/shorturl/internal/models"
+)
+
+func TestApplyUTM(t *testing.T) {
+	tests := []struct {
+		name        string
+		destination string
+		utm         models.UTM
+		expURL      string
+	}{
+		{
+			name:        "Append",
+			destination: "https://go.dev/doc",
+			utm:         models.UTM{Source: "newsletter", Campaign: "spring"},
+			expURL:      "https://go.dev/doc?utm_campaign=spring&utm_source=newsletter",
+		},
+		{
+			// подпись считается по исходной строке запроса: порядок, запись и пустые параметры сохраняются
+			name:        "SignedQuery",
+			destination: "https://cdn.example/file?X-Expires=60&a=%2Fx+y&empty=&X-Signature=abc%3D#top",
+			utm:         models.UTM{Source: "mail"},
+			expURL:      "https://cdn.example/file?X-Expires=60&a=%2Fx+y&empty=&X-Signature=abc%3D&utm_source=mail#top",
+		},
+		{
+			name:        "ReplaceUTM",
+			destination: "https://go.dev/doc?z=1&utm_source=old&a=2",
+			utm:         models.UTM{Source: "new"},
+			expURL:      "https://go.dev/doc?z=1&a=2&utm_source=new",
+		},
+	}
+
+	for _, tc := range tests {
+		testcase := tc
+		t.Run(testcase.name, func(t *testing.T) {
+			ctx := context.Background()
+			sh := newService(t, nil)
+
+			shortURL, err := sh.MakeShortURL(ctx, testcase.destination, "", models.LinkOptions{UTM: &testcase.utm})
+			require.NoError(t, err)
+
+			redirect, err := sh.GetOriginalURL(ctx, path.Base(shortURL), models.Visitor{})
+			require.NoError(t, err)
+			assert.Equal(t, testcase.expURL, redirect.URL)
+		})
+	}
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/url"
+
+	"github.com/patrick-devel/shorturl/internal/models"
+)
+
+const (
+	// maxVariants - сколько вариантов можно назвать буквами A-Z.
+	maxVariants = 26
+	// maxVariantWeight ограничивает сумму весов, чтобы она не переполнилась.
+	maxVariantWeight = 1000
+)
+
+var ErrInvalidVariants = errors.New("variants are invalid")
+
+func (sh *ShortLinkService) SetVariants(ctx context.Context, hash string, variants []models.Variant, sticky bool) ([]models.Variant, error) {
+	variants, err := normalizeVariants(variants)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, v := range variants {
+		if err := sh.checkDestination(v.URL); err != nil {
+			return nil, err
+		}
+	}
+
+	event, err := sh.ownedEvent(ctx, hash, models.RoleEditor)
+	if err != nil {
+		return nil, err
+	}
+
+	event.Variants = variants
+	event.StickyVariants = sticky
+	if err := sh.storage.UpdateEvent(ctx, event); err != nil {
+		return nil, fmt.Errorf("update variants failed: %w", err)
+	}
+	sh.notify(models.NotificationLinkUpdated, event, "")
+
+	return variants, nil
+}
+
+func (sh *ShortLinkService) ClickStats(ctx context.Context, hash string) (models.ClickStats, error) {
+	event, err := sh.ownedEvent(ctx, hash, models.RoleViewer)
+	if err != nil {
+		return models.ClickStats{}, err
+	}
+
+	stats, err := sh.storage.ReadClickStats(ctx, event.Ref())
+	if err != nil {
+		return models.ClickStats{}, fmt.Errorf("read click stats failed: %w", err)
+	}
+	stats.ShortURL = event.ShortURL
+
+	return stats, nil
+}
+
+// normalizeVariants проверяет варианты и проставляет имена тем, у кого их нет: A, B, C...
+// Имена, заданные пользователем, автоматические пропускают.
+func normalizeVariants(variants []models.Variant) ([]models.Variant, error) {
+	if len(variants) == 0 {
+		return nil, nil
+	}
+
+	if len(variants) < 2 {
+		return nil, fmt.Errorf("%w: need at least two variants", ErrInvalidVariants)
+	}
+	if len(variants) > maxVariants {
+		return nil, fmt.Errorf("%w: at most %d variants allowed", ErrInvalidVariants, maxVariants)
+	}
+
+	names := make(map[string]struct{}, len(variants))
+	normalized := make([]models.Variant, 0, len(variants))
+	for i, v := range variants {
+		if _, err := url.ParseRequestURI(v.URL); err != nil {
+			return nil, fmt.Errorf("%w: variant %d: bad url: %v", ErrInvalidVariants, i, err)
+		}
+
+		if v.Weight < 1 || v.Weight > maxVariantWeight {
+			return nil, fmt.Errorf("%w: variant %d: weight must be between 1 and %d", ErrInvalidVariants, i, maxVariantWeight)
+		}
+
+		if v.Name != "" {
+			if _, ok := names[v.Name]; ok {
+				return nil, fmt.Errorf("%w: duplicate variant name %q", ErrInvalidVariants, v.Name)
+			}
+			names[v.Name] = struct{}{}
+		}
+
+		normalized = append(normalized, v)
+	}
+
+	// вариантов не больше maxVariants, поэтому свободная буква всегда найдется
+	for i := range normalized {
+		if normalized[i].Name != "" {
+			continue
+		}
+		for next := i; ; next++ {
+			name := string(rune('A' + next%maxVariants))
+			if _, ok := names[name]; !ok {
+				normalized[i].Name = name
+				names[name] = struct{}{}
+
+				break
+			}
+		}
+	}
+
+	return normalized, nil
+}
+
+func pickVariant(variants []models.Variant, sticky bool, previous string) (models.Variant, bool) {
+	if len(variants) == 0 {
+		return models.Variant{}, false
+	}
+
+	if sticky && previous != "" {
+		for _, v := range variants {
+			if v.Name == previous {
+				return v, true
+			}
+		}
+	}
+
+	total := 0
+	for _, v := range variants {
+		total += v.Weight
+	}
+
+	// веса проверяются при сохранении, но ссылка могла быть сохранена раньше
+	if total <= 0 {
+		return variants[0], true
+	}
+
+	n := rand.IntN(total)
+	for _, v := range variants {
+		if n < v.Weight {
+			return v, true
+		}
+		n -= v.Weight
+	}
+
+	return variants[len(variants)-1], true
+}
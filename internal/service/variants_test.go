@@ -0,0 +1,223 @@
+package service_test
+
+import (
+	"context"
+	"math"
+	"path"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	middlewares "github.com/patrick-devel/shorturl/internal/middlwares"
+	"github.com/patrick-devel/shorturl/internal/models"
+	"github.com/patrick-devel/shorturl/internal/service"
+)
+
+func userContext(userID string) context.Context {
+	return context.WithValue(context.Background(), string(middlewares.ContextUserID), userID)
+}
+
+func TestSetVariants(t *testing.T) {
+	many := func(n int) []models.Variant {
+		variants := make([]models.Variant, 0, n)
+		for i := 0; i < n; i++ {
+			variants = append(variants, models.Variant{URL: "https://go.dev/" + strconv.Itoa(i), Weight: 1})
+		}
+
+		return variants
+	}
+
+	tests := []struct {
+		name     string
+		variants []models.Variant
+		expNames []string
+		expErr   error
+	}{
+		{
+			name:     "AutoNames",
+			variants: []models.Variant{{URL: "https://go.dev/a", Weight: 1}, {Name: "blue", URL: "https://go.dev/b", Weight: 3}},
+			expNames: []string{"A", "blue"},
+		},
+		{
+			name:     "MaxVariants",
+			variants: many(26),
+			expNames: []string{"A", "B", "C", "D", "E", "F", "G", "H", "I", "J", "K", "L", "M",
+				"N", "O", "P", "Q", "R", "S", "T", "U", "V", "W", "X", "Y", "Z"},
+		},
+		{
+			name:     "MaxWeight",
+			variants: []models.Variant{{URL: "https://go.dev/a", Weight: 1000}, {URL: "https://go.dev/b", Weight: 1}},
+			expNames: []string{"A", "B"},
+		},
+		{
+			name: "Clear",
+		},
+		{
+			name:     "Single",
+			variants: []models.Variant{{URL: "https://go.dev/a", Weight: 1}},
+			expErr:   service.ErrInvalidVariants,
+		},
+		{
+			name:     "TooMany",
+			variants: many(27),
+			expErr:   service.ErrInvalidVariants,
+		},
+		{
+			name:     "ZeroWeight",
+			variants: []models.Variant{{URL: "https://go.dev/a", Weight: 1}, {URL: "https://go.dev/b"}},
+			expErr:   service.ErrInvalidVariants,
+		},
+		{
+			name:     "WeightTooLarge",
+			variants: []models.Variant{{URL: "https://go.dev/a", Weight: 1001}, {URL: "https://go.dev/b", Weight: 1}},
+			expErr:   service.ErrInvalidVariants,
+		},
+		{
+			name:     "Overflow",
+			variants: []models.Variant{{URL: "https://go.dev/a", Weight: math.MaxInt}, {URL: "https://go.dev/b", Weight: 1}},
+			expErr:   service.ErrInvalidVariants,
+		},
+		{
+			name:     "BadURL",
+			variants: []models.Variant{{URL: "go.dev", Weight: 1}, {URL: "https://go.dev/b", Weight: 1}},
+			expErr:   service.ErrInvalidVariants,
+		},
+		{
+			// автоматическое имя не совпадает с заданным пользователем
+			name: "AutoNameSkipsTaken",
+			variants: []models.Variant{{URL: "https://go.dev/a", Weight: 1}, {URL: "https://go.dev/b", Weight: 1},
+				{Name: "B", URL: "https://go.dev/c", Weight: 1}},
+			expNames: []string{"A", "C", "B"},
+		},
+		{
+			name:     "AutoNameWraps",
+			variants: append([]models.Variant{{Name: "Z", URL: "https://go.dev/z", Weight: 1}}, many(25)...),
+			expNames: []string{"Z", "B", "C", "D", "E", "F", "G", "H", "I", "J", "K", "L", "M",
+				"N", "O", "P", "Q", "R", "S", "T", "U", "V", "W", "X", "Y", "A"},
+		},
+		{
+			name:     "DuplicateName",
+			variants: []models.Variant{{Name: "B", URL: "https://go.dev/a", Weight: 1}, {Name: "B", URL: "https://go.dev/b", Weight: 1}},
+			expErr:   service.ErrInvalidVariants,
+		},
+	}
+
+	for _, tc := range tests {
+		testcase := tc
+		t.Run(testcase.name, func(t *testing.T) {
+			sh := newService(t, nil)
+			ctx := userContext("owner")
+			shortURL, err := sh.MakeShortURL(ctx, "https://go.dev/", "", models.LinkOptions{})
+			require.NoError(t, err)
+
+			variants, err := sh.SetVariants(ctx, path.Base(shortURL), testcase.variants, false)
+			if testcase.expErr != nil {
+				assert.ErrorIs(t, err, testcase.expErr)
+
+				return
+			}
+			require.NoError(t, err)
+
+			names := make([]string, 0, len(variants))
+			for _, v := range variants {
+				names = append(names, v.Name)
+			}
+			if testcase.expNames == nil {
+				assert.Empty(t, names)
+			} else {
+				assert.Equal(t, testcase.expNames, names)
+			}
+		})
+	}
+
+	t.Run("NotOwner", func(t *testing.T) {
+		sh := newService(t, nil)
+		shortURL, err := sh.MakeShortURL(userContext("owner"), "https://go.dev/", "", models.LinkOptions{})
+		require.NoError(t, err)
+
+		_, err = sh.SetVariants(userContext("stranger"), path.Base(shortURL),
+			[]models.Variant{{URL: "https://go.dev/a", Weight: 1}, {URL: "https://go.dev/b", Weight: 1}}, false)
+		assert.ErrorIs(t, err, service.ErrNotOwner)
+	})
+}
+
+func TestPickVariant(t *testing.T) {
+	variants := []models.Variant{
+		{Name: "A", URL: "https://go.dev/a", Weight: 1},
+		{Name: "B", URL: "https://go.dev/b", Weight: 1000},
+	}
+
+	tests := []struct {
+		name     string
+		variants []models.Variant
+		sticky   bool
+		previous string
+		expPicks map[string]bool
+		// expMostly - вариант, который при таких весах должен выпадать почти всегда
+		expMostly string
+	}{
+		{
+			name:      "Weighted",
+			variants:  variants,
+			expPicks:  map[string]bool{"A": true, "B": true},
+			expMostly: "B",
+		},
+		{
+			name:     "Sticky",
+			variants: variants,
+			sticky:   true,
+			previous: "A",
+			expPicks: map[string]bool{"A": true},
+		},
+		{
+			name:      "NotSticky",
+			variants:  variants,
+			previous:  "A",
+			expPicks:  map[string]bool{"A": true, "B": true},
+			expMostly: "B",
+		},
+		{
+			name:      "StickyUnknownPrevious",
+			variants:  variants,
+			sticky:    true,
+			previous:  "Z",
+			expPicks:  map[string]bool{"A": true, "B": true},
+			expMostly: "B",
+		},
+		{
+			// веса, сохраненные до проверки, в сумме переполняются
+			name: "OverflowedWeights",
+			variants: []models.Variant{
+				{Name: "A", URL: "https://go.dev/a", Weight: math.MaxInt},
+				{Name: "B", URL: "https://go.dev/b", Weight: 1},
+			},
+			expPicks: map[string]bool{"A": true},
+		},
+	}
+
+	for _, tc := range tests {
+		testcase := tc
+		t.Run(testcase.name, func(t *testing.T) {
+			ref := models.LinkRef{Code: "abc"}
+			sh := newService(t, map[models.LinkRef]models.Event{ref: {
+				Code:        ref.Code,
+				OriginalURL: "https://go.dev/",
+				LinkOptions: models.LinkOptions{Variants: testcase.variants, StickyVariants: testcase.sticky},
+			}})
+
+			picks := map[string]int{}
+			for i := 0; i < 200; i++ {
+				redirect, err := sh.GetOriginalURL(context.Background(), ref.Code, models.Visitor{Variant: testcase.previous})
+				require.NoError(t, err)
+				require.True(t, testcase.expPicks[redirect.Variant], redirect.Variant)
+				assert.Equal(t, "https://go.dev/"+map[string]string{"A": "a", "B": "b"}[redirect.Variant], redirect.URL)
+				picks[redirect.Variant]++
+			}
+			if testcase.expMostly != "" {
+				assert.Greater(t, picks[testcase.expMostly], 150)
+			}
+		})
+	}
+}
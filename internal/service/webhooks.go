@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/patrick-devel/shorturl/internal/ctxaux"
+	"github.com/patrick-devel/shorturl/internal/models"
+	"github.com/patrick-devel/shorturl/internal/storage"
+)
+
+var (
+	ErrInvalidWebhook  = errors.New("webhook is invalid")
+	ErrWebhookNotFound = errors.New("webhook not found")
+)
+
+// CreateWebhook регистрирует вебхук. Секрет для проверки подписи отдается только в ответе на создание.
+func (sh *ShortLinkService) CreateWebhook(ctx context.Context, request models.RequestWebhook) (models.Webhook, error) {
+	userID := ctxaux.GetUserIDFromContext(ctx)
+	if userID == "" {
+		return models.Webhook{}, ErrUnauthorized
+	}
+
+	u, err := url.ParseRequestURI(request.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return models.Webhook{}, fmt.Errorf("%w: url must be absolute http(s) url", ErrInvalidWebhook)
+	}
+
+	for _, e := range request.Events {
+		if !slices.Contains(models.NotificationTypes, e) {
+			return models.Webhook{}, fmt.Errorf("%w: unknown event %q", ErrInvalidWebhook, e)
+		}
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return models.Webhook{}, fmt.Errorf("generate secret failed: %w", err)
+	}
+
+	webhook := models.Webhook{
+		ID:        uuid.NewString(),
+		CreatorID: userID,
+		URL:       u.String(),
+		Events:    request.Events,
+		Secret:    hex.EncodeToString(secret),
+		CreatedAt: time.Now().UTC(),
+	}
+	if webhook.Events == nil {
+		webhook.Events = []string{}
+	}
+
+	if err := sh.storage.WriteWebhook(ctx, webhook); err != nil {
+		return models.Webhook{}, fmt.Errorf("save webhook failed: %w", err)
+	}
+
+	return webhook, nil
+}
+
+func (sh *ShortLinkService) Webhooks(ctx context.Context) ([]models.Webhook, error) {
+	webhooks, err := sh.storage.ReadWebhooksByCreatorID(ctx, ctxaux.GetUserIDFromContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("read webhooks failed: %w", err)
+	}
+
+	for i := range webhooks {
+		webhooks[i].Secret = ""
+	}
+
+	return webhooks, nil
+}
+
+func (sh *ShortLinkService) DeleteWebhook(ctx context.Context, id string) error {
+	err := sh.storage.DeleteWebhook(ctx, ctxaux.GetUserIDFromContext(ctx), id)
+	if errors.Is(err, storage.ErrWebhookNotFound) {
+		return ErrWebhookNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("delete webhook failed: %w", err)
+	}
+
+	return nil
+}
+
+// WebhookDeliveries отдает журнал доставок, deadOnly - только доставки, на которых кончились попытки.
+func (sh *ShortLinkService) WebhookDeliveries(ctx context.Context, id string, deadOnly bool) ([]models.WebhookDelivery, error) {
+	webhooks, err := sh.storage.ReadWebhooksByCreatorID(ctx, ctxaux.GetUserIDFromContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("read webhooks failed: %w", err)
+	}
+
+	if !slices.ContainsFunc(webhooks, func(w models.Webhook) bool { return w.ID == id }) {
+		return nil, ErrWebhookNotFound
+	}
+
+	deliveries, err := sh.storage.ReadWebhookDeliveries(ctx, id, deadOnly)
+	if err != nil {
+		return nil, fmt.Errorf("read webhook deliveries failed: %w", err)
+	}
+
+	return deliveries, nil
+}
@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/patrick-devel/shorturl/internal/ctxaux"
+	"github.com/patrick-devel/shorturl/internal/models"
+	"github.com/patrick-devel/shorturl/internal/storage"
+)
+
+const maxWorkspaceNameLength = 128
+
+var (
+	ErrInvalidWorkspace  = errors.New("workspace request is invalid")
+	ErrWorkspaceNotFound = errors.New("workspace not found")
+	ErrWorkspaceRole     = errors.New("workspace role does not allow this")
+)
+
+// CreateWorkspace заводит пространство, создатель становится его владельцем.
+func (sh *ShortLinkService) CreateWorkspace(ctx context.Context, request models.RequestWorkspace) (models.Workspace, error) {
+	userID := ctxaux.GetUserIDFromContext(ctx)
+	if userID == "" {
+		return models.Workspace{}, ErrUnauthorized
+	}
+
+	name := strings.TrimSpace(request.Name)
+	if name == "" || utf8.RuneCountInString(name) > maxWorkspaceNameLength {
+		return models.Workspace{}, fmt.Errorf("%w: name must be 1 to %d characters", ErrInvalidWorkspace, maxWorkspaceNameLength)
+	}
+
+	now := time.Now().UTC()
+	workspace := models.Workspace{ID: uuid.NewString(), Name: name, CreatedAt: now, Role: models.RoleOwner}
+	owner := models.WorkspaceMember{WorkspaceID: workspace.ID, UserID: userID, Role: models.RoleOwner, AddedAt: now}
+	if err := sh.storage.WriteWorkspace(ctx, workspace, owner); err != nil {
+		return models.Workspace{}, fmt.Errorf("save workspace failed: %w", err)
+	}
+
+	return workspace, nil
+}
+
+// Workspaces - пространства, где состоит текущий пользователь, с его ролью в каждом.
+func (sh *ShortLinkService) Workspaces(ctx context.Context) ([]models.Workspace, error) {
+	workspaces, err := sh.storage.ReadWorkspacesByUserID(ctx, ctxaux.GetUserIDFromContext(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("read workspaces failed: %w", err)
+	}
+
+	return workspaces, nil
+}
+
+func (sh *ShortLinkService) WorkspaceMembers(ctx context.Context, workspaceID string) ([]models.WorkspaceMember, error) {
+	if _, err := sh.requireWorkspaceRole(ctx, workspaceID, models.RoleViewer); err != nil {
+		return nil, err
+	}
+
+	members, err := sh.storage.ReadWorkspaceMembers(ctx, workspaceID)
+	if err != nil {
+		return nil, fmt.Errorf("read workspace members failed: %w", err)
+	}
+
+	return members, nil
+}
+
+// SetWorkspaceMember добавляет участника или меняет его роль, это может только владелец.
+func (sh *ShortLinkService) SetWorkspaceMember(ctx context.Context, workspaceID, userID string,
+	request models.RequestWorkspaceMember) (models.WorkspaceMember, error) {
+	if !models.ValidWorkspaceRole(request.Role) {
+		return models.WorkspaceMember{}, fmt.Errorf("%w: unknown role %q", ErrInvalidWorkspace, request.Role)
+	}
+
+	if _, err := sh.requireWorkspaceRole(ctx, workspaceID, models.RoleOwner); err != nil {
+		return models.WorkspaceMember{}, err
+	}
+
+	if request.Role != models.RoleOwner {
+		if err := sh.keepOwner(ctx, workspaceID, userID); err != nil {
+			return models.WorkspaceMember{}, err
+		}
+	}
+
+	member := models.WorkspaceMember{WorkspaceID: workspaceID, UserID: userID, Role: request.Role, AddedAt: time.Now().UTC()}
+	if err := sh.storage.WriteWorkspaceMember(ctx, member); err != nil {
+		return models.WorkspaceMember{}, fmt.Errorf("save workspace member failed: %w", err)
+	}
+
+	// у существующего участника дата добавления прежняя
+	member, err := sh.storage.ReadWorkspaceMember(ctx, workspaceID, userID)
+	if err != nil {
+		return models.WorkspaceMember{}, fmt.Errorf("read workspace member failed: %w", err)
+	}
+
+	return member, nil
+}
+
+// RemoveWorkspaceMember исключает участника. Владелец исключает любого, остальные могут только выйти сами.
+// Ссылки, созданные участником, остаются в пространстве.
+func (sh *ShortLinkService) RemoveWorkspaceMember(ctx context.Context, workspaceID, userID string) error {
+	required := models.RoleOwner
+	if userID == ctxaux.GetUserIDFromContext(ctx) {
+		required = models.RoleViewer
+	}
+	if _, err := sh.requireWorkspaceRole(ctx, workspaceID, required); err != nil {
+		return err
+	}
+
+	if err := sh.keepOwner(ctx, workspaceID, userID); err != nil {
+		return err
+	}
+
+	if err := sh.storage.DeleteWorkspaceMember(ctx, workspaceID, userID); err != nil {
+		return fmt.Errorf("delete workspace member failed: %w", err)
+	}
+
+	return nil
+}
+
+// SetLinkWorkspace переносит ссылку в пространство или обратно в личные.
+// Ссылка, вынесенная из пространства, переходит к тому, кто ее вынес.
+func (sh *ShortLinkService) SetLinkWorkspace(ctx context.Context, hash string, request models.RequestLinkWorkspace) (models.Event, error) {
+	event, err := sh.ownedEvent(ctx, hash, models.RoleEditor)
+	if err != nil {
+		return models.Event{}, err
+	}
+
+	if err := sh.checkLinkWorkspace(ctx, request.WorkspaceID); err != nil {
+		return models.Event{}, err
+	}
+
+	event.WorkspaceID = request.WorkspaceID
+	if event.WorkspaceID == "" {
+		event.CreatorID = ctxaux.GetUserIDFromContext(ctx)
+	}
+	if err := sh.storage.UpdateEvent(ctx, event); err != nil {
+		return models.Event{}, fmt.Errorf("update link workspace failed: %w", err)
+	}
+	sh.notify(models.NotificationLinkUpdated, event, "")
+
+	return event, nil
+}
+
+// checkLinkWorkspace - создавать и переносить ссылки в пространство могут редакторы и владельцы.
+func (sh *ShortLinkService) checkLinkWorkspace(ctx context.Context, workspaceID string) error {
+	if workspaceID == "" {
+		return nil
+	}
+
+	_, err := sh.requireWorkspaceRole(ctx, workspaceID, models.RoleEditor)
+
+	return err
+}
+
+// requireWorkspaceRole проверяет, что роль текущего пользователя в пространстве не ниже required.
+// Тем, кто в пространстве не состоит, оно не видно.
+func (sh *ShortLinkService) requireWorkspaceRole(ctx context.Context, workspaceID, required string) (models.WorkspaceMember, error) {
+	userID := ctxaux.GetUserIDFromContext(ctx)
+	if userID == "" {
+		return models.WorkspaceMember{}, ErrUnauthorized
+	}
+
+	member, err := sh.storage.ReadWorkspaceMember(ctx, workspaceID, userID)
+	if errors.Is(err, storage.ErrWorkspaceMemberNotFound) {
+		return models.WorkspaceMember{}, ErrWorkspaceNotFound
+	}
+	if err != nil {
+		return models.WorkspaceMember{}, fmt.Errorf("read workspace member failed: %w", err)
+	}
+
+	if !models.RoleAllows(member.Role, required) {
+		return models.WorkspaceMember{}, fmt.Errorf("%w: %s role is required", ErrWorkspaceRole, required)
+	}
+
+	return member, nil
+}
+
+// keepOwner не дает лишить пространство последнего владельца: управлять участниками стало бы некому.
+func (sh *ShortLinkService) keepOwner(ctx context.Context, workspaceID, userID string) error {
+	members, err := sh.storage.ReadWorkspaceMembers(ctx, workspaceID)
+	if err != nil {
+		return fmt.Errorf("read workspace members failed: %w", err)
+	}
+
+	owners, leaving := 0, false
+	for _, m := range members {
+		if m.Role == models.RoleOwner {
+			owners++
+			leaving = leaving || m.UserID == userID
+		}
+	}
+	if leaving && owners == 1 {
+		return fmt.Errorf("%w: workspace must keep an owner", ErrInvalidWorkspace)
+	}
+
+	return nil
+}
+
+// accessibleLinks - личные ссылки пользователя и ссылки пространств, где его роль не ниже required.
+// Ссылки, созданные пользователем в пространстве, видны ему, только пока он там состоит.
+func (sh *ShortLinkService) accessibleLinks(ctx context.Context, required string) ([]models.Event, error) {
+	userID := ctxaux.GetUserIDFromContext(ctx)
+
+	events, err := sh.storage.ReadEventsByCreatorID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("read links failed: %w", err)
+	}
+
+	links := make([]models.Event, 0, len(events))
+	for _, e := range events {
+		if e.WorkspaceID == "" {
+			links = append(links, e)
+		}
+	}
+
+	workspaces, err := sh.storage.ReadWorkspacesByUserID(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("read workspaces failed: %w", err)
+	}
+
+	var workspaceIDs []string
+	for _, w := range workspaces {
+		if models.RoleAllows(w.Role, required) {
+			workspaceIDs = append(workspaceIDs, w.ID)
+		}
+	}
+	if len(workspaceIDs) > 0 {
+		shared, err := sh.storage.ReadEventsByWorkspaceIDs(ctx, workspaceIDs)
+		if err != nil {
+			return nil, fmt.Errorf("read workspace links failed: %w", err)
+		}
+		links = append(links, shared...)
+	}
+
+	for i := range links {
+		links[i] = sh.render(links[i])
+	}
+
+	return links, nil
+}
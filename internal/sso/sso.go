@@ -0,0 +1,201 @@
+package sso
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/coreos/go-oidc/v3/oidc"
+	"golang.org/x/oauth2"
+
+	"github.com/patrick-devel/shorturl/internal/models"
+)
+
+var defaultScopes = []string{oidc.ScopeOpenID, "email"}
+
+var (
+	ErrInvalidConfig = errors.New("oidc config is invalid")
+	ErrInvalidFlow   = errors.New("oidc login flow is invalid")
+	// ErrRejected - провайдер не подтвердил вход: отказал в обмене кода или выдал негодный ID-токен.
+	ErrRejected = errors.New("identity provider rejected login")
+	// ErrUnavailable - провайдер не ответил, вход стоит повторить позже.
+	ErrUnavailable = errors.New("identity provider is unavailable")
+)
+
+// Config - клиент, зарегистрированный у провайдера. Пустой IssuerURL выключает вход через OIDC.
+type Config struct {
+	// IssuerURL - адрес провайдера, метаданные читаются из /.well-known/openid-configuration.
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	// RedirectURL - адрес /api/auth/oidc/callback, как его видит браузер.
+	RedirectURL string
+	Scopes      []string
+}
+
+func (c Config) Enabled() bool {
+	return c.IssuerURL != ""
+}
+
+func (c Config) Validate() error {
+	if !c.Enabled() {
+		return nil
+	}
+	if c.ClientID == "" || c.RedirectURL == "" {
+		return fmt.Errorf("%w: client id and redirect url are required", ErrInvalidConfig)
+	}
+
+	return nil
+}
+
+// Flow - состояние одного входа между редиректом к провайдеру и возвратом на callback.
+// State защищает от подмены ответа, nonce привязывает ID-токен к входу, verifier - PKCE.
+type Flow struct {
+	State    string
+	Nonce    string
+	Verifier string
+}
+
+// Encode упаковывает flow в значение cookie. Все части в base64url, точек в них не бывает.
+func (f Flow) Encode() string {
+	return strings.Join([]string{f.State, f.Nonce, f.Verifier}, ".")
+}
+
+func DecodeFlow(value string) (Flow, error) {
+	parts := strings.Split(value, ".")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+		return Flow{}, ErrInvalidFlow
+	}
+
+	return Flow{State: parts[0], Nonce: parts[1], Verifier: parts[2]}, nil
+}
+
+type Provider struct {
+	cfg    Config
+	client *http.Client
+
+	mu       sync.Mutex
+	oauth    *oauth2.Config
+	verifier *oidc.IDTokenVerifier
+}
+
+type Option func(p *Provider)
+
+// WithHTTPClient - клиент для запросов к провайдеру: discovery, JWKS и обмен кода.
+func WithHTTPClient(client *http.Client) Option {
+	return func(p *Provider) {
+		p.client = client
+	}
+}
+
+func New(cfg Config, opts ...Option) *Provider {
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = defaultScopes
+	}
+	p := &Provider{cfg: cfg, client: http.DefaultClient}
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+// Begin начинает вход: возвращает адрес провайдера для редиректа и flow, который нужно сохранить до callback.
+func (p *Provider) Begin(ctx context.Context) (string, Flow, error) {
+	oauth, _, err := p.discover(ctx)
+	if err != nil {
+		return "", Flow{}, err
+	}
+
+	flow := Flow{State: randomString(), Nonce: randomString(), Verifier: oauth2.GenerateVerifier()}
+	authURL := oauth.AuthCodeURL(flow.State, oidc.Nonce(flow.Nonce), oauth2.S256ChallengeOption(flow.Verifier))
+
+	return authURL, flow, nil
+}
+
+// Finish меняет код на токены и проверяет ID-токен: подпись по JWKS провайдера, issuer, audience, срок и nonce.
+func (p *Provider) Finish(ctx context.Context, flow Flow, code string) (models.Identity, error) {
+	oauth, verifier, err := p.discover(ctx)
+	if err != nil {
+		return models.Identity{}, err
+	}
+
+	ctx = oidc.ClientContext(ctx, p.client)
+	token, err := oauth.Exchange(ctx, code, oauth2.VerifierOption(flow.Verifier))
+	if err != nil {
+		var retrieveErr *oauth2.RetrieveError
+		if errors.As(err, &retrieveErr) {
+			return models.Identity{}, fmt.Errorf("%w: exchange code: %s", ErrRejected, retrieveErr.ErrorCode)
+		}
+
+		return models.Identity{}, fmt.Errorf("%w: exchange code: %w", ErrUnavailable, err)
+	}
+
+	rawIDToken, ok := token.Extra("id_token").(string)
+	if !ok {
+		return models.Identity{}, fmt.Errorf("%w: no id_token in response", ErrRejected)
+	}
+	idToken, err := verifier.Verify(ctx, rawIDToken)
+	if err != nil {
+		return models.Identity{}, fmt.Errorf("%w: %w", ErrRejected, err)
+	}
+	if idToken.Nonce != flow.Nonce {
+		return models.Identity{}, fmt.Errorf("%w: nonce mismatch", ErrRejected)
+	}
+
+	var claims struct {
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+	}
+	if err := idToken.Claims(&claims); err != nil {
+		return models.Identity{}, fmt.Errorf("%w: %w", ErrRejected, err)
+	}
+
+	identity := models.Identity{Issuer: idToken.Issuer, Subject: idToken.Subject}
+	// неподтвержденный адрес мог указать кто угодно
+	if claims.EmailVerified {
+		identity.Email = strings.ToLower(claims.Email)
+	}
+
+	return identity, nil
+}
+
+// discover читает метаданные провайдера при первом входе. Неудача не запоминается:
+// сервер запускается и работает, пока провайдер недоступен, а вход заработает, когда он вернется.
+func (p *Provider) discover(ctx context.Context) (*oauth2.Config, *oidc.IDTokenVerifier, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.oauth != nil {
+		return p.oauth, p.verifier, nil
+	}
+
+	// контекст запроса годится и для ключей: go-oidc берет из него только HTTP-клиент
+	provider, err := oidc.NewProvider(oidc.ClientContext(ctx, p.client), p.cfg.IssuerURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: discovery: %w", ErrUnavailable, err)
+	}
+
+	p.oauth = &oauth2.Config{
+		ClientID:     p.cfg.ClientID,
+		ClientSecret: p.cfg.ClientSecret,
+		RedirectURL:  p.cfg.RedirectURL,
+		Endpoint:     provider.Endpoint(),
+		Scopes:       p.cfg.Scopes,
+	}
+	p.verifier = provider.Verifier(&oidc.Config{ClientID: p.cfg.ClientID})
+
+	return p.oauth, p.verifier, nil
+}
+
+func randomString() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,178 @@
+package sso_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/patrick-devel/shorturl/internal/models"
+	"github.com/patrick-devel/shorturl/internal/sso"
+	"github.com/patrick-devel/shorturl/internal/sso/ssotest"
+)
+
+const redirectURL = "http://localhost:8080/api/auth/oidc/callback"
+
+func newProvider(issuerURL string) *sso.Provider {
+	return sso.New(sso.Config{
+		IssuerURL:    issuerURL,
+		ClientID:     ssotest.ClientID,
+		ClientSecret: ssotest.ClientSecret,
+		RedirectURL:  redirectURL,
+	})
+}
+
+// authorize проходит редирект к провайдеру, как браузер, и возвращает параметры callback.
+func authorize(t *testing.T, authURL string) url.Values {
+	t.Helper()
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(authURL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusFound, resp.StatusCode)
+
+	location, err := resp.Location()
+	require.NoError(t, err)
+	require.Equal(t, redirectURL, location.Scheme+"://"+location.Host+location.Path)
+
+	return location.Query()
+}
+
+func TestProvider(t *testing.T) {
+	tests := []struct {
+		name        string
+		prepare     func(idp *ssotest.IdP)
+		tamper      func(flow *sso.Flow)
+		expIdentity func(idp *ssotest.IdP) models.Identity
+		expErr      error
+	}{
+		{
+			name: "OK",
+			prepare: func(idp *ssotest.IdP) {
+				idp.SignIn("subject-1", "User@Example.com")
+			},
+			expIdentity: func(idp *ssotest.IdP) models.Identity {
+				return models.Identity{Issuer: idp.URL, Subject: "subject-1", Email: "user@example.com"}
+			},
+		},
+		{
+			name: "UnverifiedEmail",
+			prepare: func(idp *ssotest.IdP) {
+				idp.OverrideClaims(map[string]any{"email_verified": false})
+			},
+			expIdentity: func(idp *ssotest.IdP) models.Identity {
+				return models.Identity{Issuer: idp.URL, Subject: "subject-1"}
+			},
+		},
+		{
+			name: "WrongAudience",
+			prepare: func(idp *ssotest.IdP) {
+				idp.OverrideClaims(map[string]any{"aud": "another-client"})
+			},
+			expErr: sso.ErrRejected,
+		},
+		{
+			name: "WrongIssuer",
+			prepare: func(idp *ssotest.IdP) {
+				idp.OverrideClaims(map[string]any{"iss": "https://evil.example.com"})
+			},
+			expErr: sso.ErrRejected,
+		},
+		{
+			name: "Expired",
+			prepare: func(idp *ssotest.IdP) {
+				idp.OverrideClaims(map[string]any{"exp": time.Now().Add(-time.Hour).Unix()})
+			},
+			expErr: sso.ErrRejected,
+		},
+		{
+			name: "UnknownSigningKey",
+			prepare: func(idp *ssotest.IdP) {
+				idp.SignWithUnknownKey()
+			},
+			expErr: sso.ErrRejected,
+		},
+		{
+			name: "NonceMismatch",
+			tamper: func(flow *sso.Flow) {
+				flow.Nonce = "another-nonce"
+			},
+			expErr: sso.ErrRejected,
+		},
+		{
+			name: "WrongVerifier",
+			tamper: func(flow *sso.Flow) {
+				flow.Verifier = "another-verifier-another-verifier-another-verifier"
+			},
+			expErr: sso.ErrRejected,
+		},
+	}
+
+	for _, tc := range tests {
+		testcase := tc
+		t.Run(testcase.name, func(t *testing.T) {
+			idp := ssotest.New(t)
+			if testcase.prepare != nil {
+				testcase.prepare(idp)
+			}
+			provider := newProvider(idp.URL)
+
+			authURL, flow, err := provider.Begin(context.Background())
+			require.NoError(t, err)
+			callback := authorize(t, authURL)
+			assert.Equal(t, flow.State, callback.Get("state"))
+
+			if testcase.tamper != nil {
+				testcase.tamper(&flow)
+			}
+			identity, err := provider.Finish(context.Background(), flow, callback.Get("code"))
+			if testcase.expErr != nil {
+				assert.ErrorIs(t, err, testcase.expErr)
+
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, testcase.expIdentity(idp), identity)
+		})
+	}
+}
+
+func TestProviderUnavailable(t *testing.T) {
+	idp := ssotest.New(t)
+	provider := newProvider(idp.URL + "/missing")
+
+	_, _, err := provider.Begin(context.Background())
+	assert.ErrorIs(t, err, sso.ErrUnavailable)
+
+	// неудачный discovery не запоминается
+	provider = newProvider(idp.URL)
+	_, _, err = provider.Begin(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestFlowEncoding(t *testing.T) {
+	flow := sso.Flow{State: "state", Nonce: "nonce", Verifier: "verifier"}
+
+	decoded, err := sso.DecodeFlow(flow.Encode())
+	require.NoError(t, err)
+	assert.Equal(t, flow, decoded)
+
+	for _, value := range []string{"", "state.nonce", "state..verifier", "a.b.c.d"} {
+		_, err := sso.DecodeFlow(value)
+		assert.ErrorIs(t, err, sso.ErrInvalidFlow, value)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	assert.NoError(t, sso.Config{}.Validate())
+	assert.NoError(t, sso.Config{IssuerURL: "https://idp", ClientID: "id", RedirectURL: redirectURL}.Validate())
+	assert.ErrorIs(t, sso.Config{IssuerURL: "https://idp", ClientID: "id"}.Validate(), sso.ErrInvalidConfig)
+	assert.ErrorIs(t, sso.Config{IssuerURL: "https://idp", RedirectURL: redirectURL}.Validate(), sso.ErrInvalidConfig)
+}
@@ -0,0 +1,242 @@
+// Package ssotest - OIDC-провайдер для тестов: discovery, JWKS, authorize с PKCE и token с ID-токеном RS256.
+package ssotest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+)
+
+const (
+	ClientID     = "shorturl"
+	ClientSecret = "shorturl-secret"
+
+	keyID = "test"
+)
+
+type grant struct {
+	clientID    string
+	redirectURI string
+	nonce       string
+	challenge   string
+}
+
+// IdP пускает без логина: authorize сразу возвращает код для пользователя из SignIn.
+type IdP struct {
+	URL string
+
+	mu      sync.Mutex
+	subject string
+	email   string
+	deny    bool
+	claims  map[string]any
+	key     *rsa.PrivateKey
+	signer  *rsa.PrivateKey
+	grants  map[string]grant
+}
+
+func New(t testing.TB) *IdP {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp := &IdP{subject: "subject-1", email: "user@example.com", key: key, signer: key, grants: map[string]grant{}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", idp.discovery)
+	mux.HandleFunc("GET /jwks", idp.jwks)
+	mux.HandleFunc("GET /authorize", idp.authorize)
+	mux.HandleFunc("POST /token", idp.token)
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	idp.URL = server.URL
+
+	return idp
+}
+
+// SignIn задает пользователя, который войдет при следующем authorize.
+func (idp *IdP) SignIn(subject, email string) {
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+
+	idp.subject, idp.email = subject, email
+}
+
+// Deny - пользователь отказывается от входа, authorize возвращает error=access_denied.
+func (idp *IdP) Deny(deny bool) {
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+
+	idp.deny = deny
+}
+
+// OverrideClaims подменяет поля следующих ID-токенов, чтобы проверить, что клиент их отвергает.
+func (idp *IdP) OverrideClaims(claims map[string]any) {
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+
+	idp.claims = claims
+}
+
+// SignWithUnknownKey подписывает ID-токены ключом, которого нет в JWKS.
+func (idp *IdP) SignWithUnknownKey() {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+
+	idp.signer = key
+}
+
+func (idp *IdP) discovery(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"issuer":                                idp.URL,
+		"authorization_endpoint":                idp.URL + "/authorize",
+		"token_endpoint":                        idp.URL + "/token",
+		"jwks_uri":                              idp.URL + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+func (idp *IdP) jwks(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{
+		Key:       &idp.key.PublicKey,
+		KeyID:     keyID,
+		Algorithm: string(jose.RS256),
+		Use:       "sig",
+	}}})
+}
+
+func (idp *IdP) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	redirectURI, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || query.Get("client_id") != ClientID || query.Get("response_type") != "code" ||
+		query.Get("code_challenge_method") != "S256" || query.Get("code_challenge") == "" {
+		http.Error(w, "invalid authorization request", http.StatusBadRequest)
+
+		return
+	}
+
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+
+	callback := url.Values{"state": {query.Get("state")}}
+	if idp.deny {
+		callback.Set("error", "access_denied")
+	} else {
+		code := randomCode()
+		idp.grants[code] = grant{
+			clientID:    ClientID,
+			redirectURI: redirectURI.String(),
+			nonce:       query.Get("nonce"),
+			challenge:   query.Get("code_challenge"),
+		}
+		callback.Set("code", code)
+	}
+	redirectURI.RawQuery = callback.Encode()
+
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+func (idp *IdP) token(w http.ResponseWriter, r *http.Request) {
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientID, clientSecret = r.PostFormValue("client_id"), r.PostFormValue("client_secret")
+	}
+	if clientID != ClientID || clientSecret != ClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+
+		return
+	}
+
+	idp.mu.Lock()
+	defer idp.mu.Unlock()
+
+	code := r.PostFormValue("code")
+	g, ok := idp.grants[code]
+	// код одноразовый, даже если обмен не удался
+	delete(idp.grants, code)
+	challenge := sha256.Sum256([]byte(r.PostFormValue("code_verifier")))
+	if !ok || r.PostFormValue("grant_type") != "authorization_code" || r.PostFormValue("redirect_uri") != g.redirectURI ||
+		base64.RawURLEncoding.EncodeToString(challenge[:]) != g.challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+
+		return
+	}
+
+	now := time.Now()
+	claims := map[string]any{
+		"iss":            idp.URL,
+		"sub":            idp.subject,
+		"aud":            g.clientID,
+		"iat":            now.Unix(),
+		"exp":            now.Add(time.Minute).Unix(),
+		"nonce":          g.nonce,
+		"email":          idp.email,
+		"email_verified": true,
+	}
+	for k, v := range idp.claims {
+		claims[k] = v
+	}
+
+	idToken, err := sign(idp.signer, claims)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{
+		"access_token": randomCode(),
+		"token_type":   "Bearer",
+		"expires_in":   60,
+		"id_token":     idToken,
+	})
+}
+
+func sign(key *rsa.PrivateKey, claims map[string]any) (string, error) {
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: key},
+		(&jose.SignerOptions{}).WithType("JWT").WithHeader("kid", keyID))
+	if err != nil {
+		return "", err
+	}
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	signed, err := signer.Sign(payload)
+	if err != nil {
+		return "", err
+	}
+
+	return signed.CompactSerialize()
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func randomCode() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/patrick-devel/shorturl/internal/models"
+)
+
+// apiKeyRegistry хранит API-ключи в памяти процесса, используется хранилищами без базы данных.
+type apiKeyRegistry struct {
+	apiKeyMu sync.RWMutex
+	apiKeys  map[string]models.APIKey
+}
+
+func (kr *apiKeyRegistry) WriteAPIKey(_ context.Context, key models.APIKey) error {
+	kr.apiKeyMu.Lock()
+	defer kr.apiKeyMu.Unlock()
+
+	if kr.apiKeys == nil {
+		kr.apiKeys = map[string]models.APIKey{}
+	}
+	kr.apiKeys[key.ID] = key
+
+	return nil
+}
+
+func (kr *apiKeyRegistry) ReadAPIKeysByCreatorID(_ context.Context, creatorID string) ([]models.APIKey, error) {
+	kr.apiKeyMu.RLock()
+	defer kr.apiKeyMu.RUnlock()
+
+	keys := []models.APIKey{}
+	for _, k := range kr.apiKeys {
+		if k.CreatorID == creatorID {
+			keys = append(keys, k)
+		}
+	}
+
+	return keys, nil
+}
+
+func (kr *apiKeyRegistry) ReadAPIKeyByHash(_ context.Context, hash string) (models.APIKey, error) {
+	kr.apiKeyMu.RLock()
+	defer kr.apiKeyMu.RUnlock()
+
+	for _, k := range kr.apiKeys {
+		if k.Hash == hash {
+			return k, nil
+		}
+	}
+
+	return models.APIKey{}, ErrAPIKeyNotFound
+}
+
+func (kr *apiKeyRegistry) DeleteAPIKey(_ context.Context, creatorID, id string) error {
+	kr.apiKeyMu.Lock()
+	defer kr.apiKeyMu.Unlock()
+
+	k, ok := kr.apiKeys[id]
+	if !ok || k.CreatorID != creatorID {
+		return ErrAPIKeyNotFound
+	}
+	delete(kr.apiKeys, id)
+
+	return nil
+}
+
+func (kr *apiKeyRegistry) TouchAPIKey(_ context.Context, id string, usedAt time.Time) error {
+	kr.apiKeyMu.Lock()
+	defer kr.apiKeyMu.Unlock()
+
+	k, ok := kr.apiKeys[id]
+	if !ok {
+		return ErrAPIKeyNotFound
+	}
+	k.LastUsedAt = &usedAt
+	kr.apiKeys[id] = k
+
+	return nil
+}
+
+func (kr *apiKeyRegistry) reassignAPIKeys(from, to string) {
+	kr.apiKeyMu.Lock()
+	defer kr.apiKeyMu.Unlock()
+
+	for id, k := range kr.apiKeys {
+		if k.CreatorID == from {
+			k.CreatorID = to
+			kr.apiKeys[id] = k
+		}
+	}
+}
+
+func (kr *apiKeyRegistry) snapshotAPIKeys() []models.APIKey {
+	kr.apiKeyMu.RLock()
+	defer kr.apiKeyMu.RUnlock()
+
+	keys := make([]models.APIKey, 0, len(kr.apiKeys))
+	for _, k := range kr.apiKeys {
+		keys = append(keys, k)
+	}
+
+	return keys
+}
+
+func (kr *apiKeyRegistry) restoreAPIKeys(keys []models.APIKey) {
+	kr.apiKeyMu.Lock()
+	defer kr.apiKeyMu.Unlock()
+
+	kr.apiKeys = make(map[string]models.APIKey, len(keys))
+	for _, k := range keys {
+		kr.apiKeys[k.ID] = k
+	}
+}
@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"context"
+	"sync"
+
+	"github.com/patrick-devel/shorturl/internal/models"
+)
+
+// clickCounter хранит статистику переходов в памяти процесса,
+// используется хранилищами без базы данных.
+type clickCounter struct {
+	mu     sync.Mutex
+	clicks map[models.LinkRef]map[string]int
+}
+
+func (cc *clickCounter) WriteClick(_ context.Context, click models.Click) error {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	if cc.clicks == nil {
+		cc.clicks = map[models.LinkRef]map[string]int{}
+	}
+	if cc.clicks[click.Link] == nil {
+		cc.clicks[click.Link] = map[string]int{}
+	}
+	cc.clicks[click.Link][click.Variant]++
+
+	return nil
+}
+
+func (cc *clickCounter) ReadClickStats(_ context.Context, ref models.LinkRef) (models.ClickStats, error) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	var stats models.ClickStats
+	for variant, count := range cc.clicks[ref] {
+		stats.Clicks += count
+		if variant == "" {
+			continue
+		}
+		if stats.Variants == nil {
+			stats.Variants = map[string]int{}
+		}
+		stats.Variants[variant] = count
+	}
+
+	return stats, nil
+}
@@ -3,6 +3,7 @@ package storage
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
@@ -16,6 +17,10 @@ import (
 
 var ErrEventDeleted = errors.New("event deleted")
 
+const eventColumns = "uuid, creator_id, code, original_url, redirect_code, targeting_rules, variants, sticky_variants, passthrough, " +
+	"utm_source, utm_medium, utm_campaign, utm_term, utm_content, created_at, title, " +
+	"og_title, og_description, og_image, workspace_id, domain"
+
 type DBStorage struct {
 	db *sql.DB
 
@@ -26,41 +31,158 @@ func NewDBStorage(db *sql.DB, timeout time.Duration) *DBStorage {
 	return &DBStorage{db: db, queryTimeout: timeout}
 }
 
-func (s *DBStorage) ReadEvent(ctx context.Context, shortURL string) (string, error) {
-	row := s.db.QueryRowContext(ctx, "SELECT original_url, is_deleted FROM urls WHERE short_url=$1;", shortURL)
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanEvent(row rowScanner, extra ...any) (models.Event, error) {
+	var event models.Event
+	var rules, variants []byte
+	var utm models.UTM
+	var og models.OpenGraph
+
+	dest := append([]any{
+		&event.UUID, &event.CreatorID, &event.Code, &event.OriginalURL, &event.RedirectCode, &rules,
+		&variants, &event.StickyVariants, &event.Passthrough,
+		&utm.Source, &utm.Medium, &utm.Campaign, &utm.Term, &utm.Content, &event.CreatedAt, &event.Title,
+		&og.Title, &og.Description, &og.Image, &event.WorkspaceID, &event.Domain,
+	}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return models.Event{}, err
+	}
+
+	if !utm.IsZero() {
+		event.UTM = &utm
+	}
+
+	if !og.IsZero() {
+		event.OpenGraph = &og
+	}
+
+	if err := json.Unmarshal(rules, &event.TargetingRules); err != nil {
+		return models.Event{}, fmt.Errorf("error decode targeting rules: %w", err)
+	}
+
+	if err := json.Unmarshal(variants, &event.Variants); err != nil {
+		return models.Event{}, fmt.Errorf("error decode variants: %w", err)
+	}
+
+	return event, nil
+}
+
+// jsonList кодирует список для jsonb-колонки, пустой список пишем как [], а не null.
+func jsonList[T any](items []T) ([]byte, error) {
+	if items == nil {
+		items = []T{}
+	}
+
+	data, err := json.Marshal(items)
+	if err != nil {
+		return nil, fmt.Errorf("error encode %T: %w", items, err)
+	}
+
+	return data, nil
+}
+
+func eventArgs(event models.Event) ([]any, error) {
+	rules, err := jsonList(event.TargetingRules)
+	if err != nil {
+		return nil, err
+	}
+
+	variants, err := jsonList(event.Variants)
+	if err != nil {
+		return nil, err
+	}
+
+	var utm models.UTM
+	if event.UTM != nil {
+		utm = *event.UTM
+	}
+
+	var og models.OpenGraph
+	if event.OpenGraph != nil {
+		og = *event.OpenGraph
+	}
+
+	return []any{
+		event.UUID, event.CreatorID, event.Code, event.OriginalURL, event.RedirectCode, rules,
+		variants, event.StickyVariants, event.Passthrough,
+		utm.Source, utm.Medium, utm.Campaign, utm.Term, utm.Content, event.CreatedAt, event.Title,
+		og.Title, og.Description, og.Image, event.WorkspaceID, event.Domain,
+	}, nil
+}
+
+func (s *DBStorage) ReadEvent(ctx context.Context, ref models.LinkRef) (models.Event, error) {
+	row := s.db.QueryRowContext(ctx,
+		"SELECT "+eventColumns+", is_deleted FROM urls WHERE domain=$1 AND code=$2;",
+		ref.Domain, ref.Code)
 
-	var OriginalURL string
 	var isDeleted bool
 
-	err := row.Scan(&OriginalURL, &isDeleted)
+	event, err := scanEvent(row, &isDeleted)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Event{}, fmt.Errorf("error fetch event from db: %w", ErrNotFound)
+	}
 	if err != nil {
-		return "", fmt.Errorf("error fetch event from db: %w", err)
+		return models.Event{}, fmt.Errorf("error fetch event from db: %w", err)
 	}
 
 	if isDeleted {
-		return "", ErrEventDeleted
+		return models.Event{}, ErrEventDeleted
+	}
+
+	return event, nil
+}
+
+// ReadEventByOriginalURL ищет ссылку и среди удаленных: уникальный индекс по адресу их тоже учитывает.
+func (s *DBStorage) ReadEventByOriginalURL(ctx context.Context, domain, originalURL string) (models.Event, error) {
+	row := s.db.QueryRowContext(ctx,
+		"SELECT "+eventColumns+", is_deleted FROM urls WHERE domain=$1 AND original_url=$2;", domain, originalURL)
+
+	var isDeleted bool
+
+	event, err := scanEvent(row, &isDeleted)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Event{}, fmt.Errorf("error fetch event from db: %w", ErrNotFound)
+	}
+	if err != nil {
+		return models.Event{}, fmt.Errorf("error fetch event from db: %w", err)
 	}
 
-	return OriginalURL, nil
+	return event, nil
 }
 
 func (s *DBStorage) WriteEvent(ctx context.Context, event models.Event) error {
-	sqlStatement := `INSERT INTO urls (uuid, creator_id, short_url, original_url) VALUES ($1, $2, $3, $4);`
-	_, err := s.db.ExecContext(ctx, sqlStatement, event.UUID, event.CreatorID, event.ShortURL, event.OriginalURL)
+	args, err := eventArgs(event)
 	if err != nil {
-		var pgErr *pq.Error
-		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
-			err = ErrDuplicateURL
-		}
+		return err
+	}
 
-		return fmt.Errorf("error write event to db: %w", err)
+	sqlStatement := `INSERT INTO urls (` + eventColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21);`
+	_, err = s.db.ExecContext(ctx, sqlStatement, args...)
+	if err != nil {
+		return fmt.Errorf("error write event to db: %w", uniqueViolation(err))
 	}
 
 	return nil
 }
 
+// uniqueViolation различает, какой из уникальных индексов urls нарушен: код или адрес.
+func uniqueViolation(err error) error {
+	var pgErr *pq.Error
+	if !errors.As(err, &pgErr) || pgErr.Code != pgerrcode.UniqueViolation {
+		return err
+	}
+	if pgErr.Constraint == "urls_domain_code_idx" {
+		return ErrCodeTaken
+	}
+
+	return ErrDuplicateURL
+}
+
 func (s *DBStorage) WriteEvents(ctx context.Context, events []models.Event) error {
-	sqlStatement := `INSERT INTO urls (uuid, creator_id, short_url, original_url) VALUES ($1, $2, $3, $4) ON CONFLICT (original_url) DO UPDATE SET uuid = EXCLUDED.uuid;`
+	sqlStatement := `INSERT INTO urls (` + eventColumns + `) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21);`
 
 	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{})
 	if err != nil {
@@ -68,13 +190,16 @@ func (s *DBStorage) WriteEvents(ctx context.Context, events []models.Event) erro
 	}
 
 	for _, e := range events {
-		_, err = tx.ExecContext(ctx, sqlStatement, e.UUID, e.CreatorID, e.ShortURL, e.OriginalURL)
+		args, err := eventArgs(e)
+		if err == nil {
+			_, err = tx.ExecContext(ctx, sqlStatement, args...)
+		}
 		if err != nil {
 			if rbError := tx.Rollback(); rbError != nil {
 				logrus.Errorf("insert failed, unable to rollback %v", rbError)
 			}
 
-			return fmt.Errorf("error write event to db: %w", err)
+			return fmt.Errorf("error write event to db: %w", uniqueViolation(err))
 		}
 	}
 
@@ -85,8 +210,38 @@ func (s *DBStorage) WriteEvents(ctx context.Context, events []models.Event) erro
 	return nil
 }
 
+func (s *DBStorage) UpdateEvent(ctx context.Context, event models.Event) error {
+	rules, err := jsonList(event.TargetingRules)
+	if err != nil {
+		return err
+	}
+
+	variants, err := jsonList(event.Variants)
+	if err != nil {
+		return err
+	}
+
+	var og models.OpenGraph
+	if event.OpenGraph != nil {
+		og = *event.OpenGraph
+	}
+
+	// uuid, домен и код не меняются, обновляем настройки ссылки и владельца: ее могут перенести в пространство и обратно
+	_, err = s.db.ExecContext(ctx,
+		`UPDATE urls SET redirect_code=$2, targeting_rules=$3, variants=$4, sticky_variants=$5, passthrough=$6, title=$7,
+		og_title=$8, og_description=$9, og_image=$10, creator_id=$11, workspace_id=$12
+		WHERE code=$1 AND domain=$13 AND is_deleted=false;`,
+		event.Code, event.RedirectCode, rules, variants, event.StickyVariants, event.Passthrough, event.Title,
+		og.Title, og.Description, og.Image, event.CreatorID, event.WorkspaceID, event.Domain)
+	if err != nil {
+		return fmt.Errorf("error update event in db: %w", err)
+	}
+
+	return nil
+}
+
 func (s *DBStorage) ReadEventsByCreatorID(ctx context.Context, userID string) ([]models.Event, error) {
-	rows, err := s.db.QueryContext(ctx, "SELECT uuid, creator_id, short_url, original_url FROM urls WHERE creator_id=$1 and is_deleted = false;", userID)
+	rows, err := s.db.QueryContext(ctx, "SELECT "+eventColumns+" FROM urls WHERE creator_id=$1 and is_deleted = false;", userID)
 	if err != nil {
 		return []models.Event{}, fmt.Errorf("error fetch events from db: %w", err)
 	}
@@ -96,12 +251,49 @@ func (s *DBStorage) ReadEventsByCreatorID(ctx context.Context, userID string) ([
 	var events []models.Event
 
 	for rows.Next() {
-		event := new(models.Event)
-		if err := rows.Scan(&event.UUID, &event.CreatorID, &event.ShortURL, &event.OriginalURL); err != nil {
+		event, err := scanEvent(rows)
+		if err != nil {
 			return events, fmt.Errorf("error decode events from db: %w", err)
 		}
-		events = append(events, *event)
+		events = append(events, event)
+
+	}
+
+	if rows.Err() != nil {
+		return events, fmt.Errorf("error scan rows: %w", rows.Err())
+	}
+
+	return events, nil
+}
+
+func (s *DBStorage) CountEventsByCreatorID(ctx context.Context, userID string) (int, error) {
+	var count int
+	err := s.db.QueryRowContext(ctx,
+		"SELECT count(*) FROM urls WHERE creator_id=$1 and is_deleted = false;", userID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("error count events in db: %w", err)
+	}
+
+	return count, nil
+}
+
+func (s *DBStorage) ReadEventsByWorkspaceIDs(ctx context.Context, workspaceIDs []string) ([]models.Event, error) {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT "+eventColumns+" FROM urls WHERE workspace_id = any($1) and is_deleted = false;", pq.Array(workspaceIDs))
+	if err != nil {
+		return []models.Event{}, fmt.Errorf("error fetch events from db: %w", err)
+	}
+
+	defer rows.Close()
+
+	var events []models.Event
 
+	for rows.Next() {
+		event, err := scanEvent(rows)
+		if err != nil {
+			return events, fmt.Errorf("error decode events from db: %w", err)
+		}
+		events = append(events, event)
 	}
 
 	if rows.Err() != nil {
@@ -111,17 +303,30 @@ func (s *DBStorage) ReadEventsByCreatorID(ctx context.Context, userID string) ([
 	return events, nil
 }
 
-func (s *DBStorage) SetDeleteByShortURL(shorts []string) error {
+// refArrays раскладывает ключи ссылок на массивы доменов и кодов для unnest.
+func refArrays(refs []models.LinkRef) (any, any) {
+	domains := make([]string, 0, len(refs))
+	codes := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		domains = append(domains, ref.Domain)
+		codes = append(codes, ref.Code)
+	}
+
+	return pq.Array(domains), pq.Array(codes)
+}
+
+func (s *DBStorage) SetDeleted(refs []models.LinkRef) error {
 	ctx, cancel := context.WithTimeout(context.Background(), s.queryTimeout)
 	defer cancel()
 
-	if len(shorts) == 0 {
+	if len(refs) == 0 {
 		return nil
 	}
 
+	domains, codes := refArrays(refs)
 	rows, err := s.db.ExecContext(ctx,
-		"UPDATE urls SET is_deleted=true WHERE short_url = any($1);",
-		pq.Array(shorts))
+		"UPDATE urls SET is_deleted=true WHERE (domain, code) IN (SELECT unnest($1::text[]), unnest($2::text[]));",
+		domains, codes)
 	if err != nil {
 		return fmt.Errorf("error update event to db: %w", err)
 	}
@@ -134,3 +339,587 @@ func (s *DBStorage) SetDeleteByShortURL(shorts []string) error {
 	logrus.Infof("update cpunt %d", count)
 	return nil
 }
+
+func (s *DBStorage) WriteClick(ctx context.Context, click models.Click) error {
+	_, err := s.db.ExecContext(ctx,
+		"INSERT INTO clicks (domain, code, variant, created_at) VALUES ($1, $2, $3, $4);",
+		click.Link.Domain, click.Link.Code, click.Variant, click.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("error write click to db: %w", err)
+	}
+
+	return nil
+}
+
+func (s *DBStorage) ReadClickStats(ctx context.Context, ref models.LinkRef) (models.ClickStats, error) {
+	var stats models.ClickStats
+
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT variant, count(*) FROM clicks WHERE domain=$1 AND code=$2 GROUP BY variant;", ref.Domain, ref.Code)
+	if err != nil {
+		return stats, fmt.Errorf("error fetch clicks from db: %w", err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var variant string
+		var count int
+		if err := rows.Scan(&variant, &count); err != nil {
+			return stats, fmt.Errorf("error decode clicks from db: %w", err)
+		}
+
+		stats.Clicks += count
+		if variant == "" {
+			continue
+		}
+		if stats.Variants == nil {
+			stats.Variants = map[string]int{}
+		}
+		stats.Variants[variant] = count
+	}
+
+	if rows.Err() != nil {
+		return stats, fmt.Errorf("error scan rows: %w", rows.Err())
+	}
+
+	return stats, nil
+}
+
+func (s *DBStorage) ReadActiveEvents(ctx context.Context) ([]models.Event, error) {
+	rows, err := s.db.QueryContext(ctx, "SELECT "+eventColumns+" FROM urls WHERE is_deleted = false;")
+	if err != nil {
+		return []models.Event{}, fmt.Errorf("error fetch events from db: %w", err)
+	}
+
+	defer rows.Close()
+
+	var events []models.Event
+
+	for rows.Next() {
+		event, err := scanEvent(rows)
+		if err != nil {
+			return events, fmt.Errorf("error decode events from db: %w", err)
+		}
+		events = append(events, event)
+	}
+
+	if rows.Err() != nil {
+		return events, fmt.Errorf("error scan rows: %w", rows.Err())
+	}
+
+	return events, nil
+}
+
+func (s *DBStorage) WriteLinkHealth(ctx context.Context, ref models.LinkRef, health models.LinkHealth) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO link_health (domain, code, url, status_code, latency_ms, error, checked_at) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (domain, code) DO UPDATE SET url = EXCLUDED.url, status_code = EXCLUDED.status_code,
+		latency_ms = EXCLUDED.latency_ms, error = EXCLUDED.error, checked_at = EXCLUDED.checked_at;`,
+		ref.Domain, ref.Code, health.URL, health.StatusCode, health.LatencyMS, health.Error, health.CheckedAt)
+	if err != nil {
+		return fmt.Errorf("error write link health to db: %w", err)
+	}
+
+	return nil
+}
+
+func (s *DBStorage) ReadLinkHealth(ctx context.Context, refs []models.LinkRef) (map[models.LinkRef]models.LinkHealth, error) {
+	result := map[models.LinkRef]models.LinkHealth{}
+	if len(refs) == 0 {
+		return result, nil
+	}
+
+	domains, codes := refArrays(refs)
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT domain, code, url, status_code, latency_ms, error, checked_at FROM link_health
+		WHERE (domain, code) IN (SELECT unnest($1::text[]), unnest($2::text[]));`,
+		domains, codes)
+	if err != nil {
+		return result, fmt.Errorf("error fetch link health from db: %w", err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var ref models.LinkRef
+		var health models.LinkHealth
+		if err := rows.Scan(&ref.Domain, &ref.Code, &health.URL, &health.StatusCode, &health.LatencyMS, &health.Error, &health.CheckedAt); err != nil {
+			return result, fmt.Errorf("error decode link health from db: %w", err)
+		}
+		result[ref] = health
+	}
+
+	if rows.Err() != nil {
+		return result, fmt.Errorf("error scan rows: %w", rows.Err())
+	}
+
+	return result, nil
+}
+
+func (s *DBStorage) WriteWebhook(ctx context.Context, webhook models.Webhook) error {
+	events, err := jsonList(webhook.Events)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx,
+		"INSERT INTO webhooks (id, creator_id, url, events, secret, created_at) VALUES ($1, $2, $3, $4, $5, $6);",
+		webhook.ID, webhook.CreatorID, webhook.URL, events, webhook.Secret, webhook.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("error write webhook to db: %w", err)
+	}
+
+	return nil
+}
+
+func (s *DBStorage) ReadWebhooksByCreatorID(ctx context.Context, creatorID string) ([]models.Webhook, error) {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT id, creator_id, url, events, secret, created_at FROM webhooks WHERE creator_id=$1 ORDER BY created_at;", creatorID)
+	if err != nil {
+		return []models.Webhook{}, fmt.Errorf("error fetch webhooks from db: %w", err)
+	}
+
+	defer rows.Close()
+
+	webhooks := []models.Webhook{}
+	for rows.Next() {
+		var w models.Webhook
+		var events []byte
+		if err := rows.Scan(&w.ID, &w.CreatorID, &w.URL, &events, &w.Secret, &w.CreatedAt); err != nil {
+			return webhooks, fmt.Errorf("error decode webhooks from db: %w", err)
+		}
+		if err := json.Unmarshal(events, &w.Events); err != nil {
+			return webhooks, fmt.Errorf("error decode webhook events: %w", err)
+		}
+		webhooks = append(webhooks, w)
+	}
+
+	if rows.Err() != nil {
+		return webhooks, fmt.Errorf("error scan rows: %w", rows.Err())
+	}
+
+	return webhooks, nil
+}
+
+func (s *DBStorage) DeleteWebhook(ctx context.Context, creatorID, id string) error {
+	res, err := s.db.ExecContext(ctx, "DELETE FROM webhooks WHERE id=$1 AND creator_id=$2;", id, creatorID)
+	if err != nil {
+		return fmt.Errorf("error delete webhook from db: %w", err)
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error delete webhook from db: %w", err)
+	}
+	if count == 0 {
+		return ErrWebhookNotFound
+	}
+
+	return nil
+}
+
+func (s *DBStorage) WriteWebhookDelivery(ctx context.Context, delivery models.WebhookDelivery) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO webhook_deliveries (delivery_id, webhook_id, event, payload, attempt, status_code, error, dead, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);`,
+		delivery.ID, delivery.WebhookID, delivery.Event, []byte(delivery.Payload), delivery.Attempt,
+		delivery.StatusCode, delivery.Error, delivery.Dead, delivery.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("error write webhook delivery to db: %w", err)
+	}
+
+	return nil
+}
+
+func (s *DBStorage) ReadWebhookDeliveries(ctx context.Context, webhookID string, deadOnly bool) ([]models.WebhookDelivery, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT delivery_id, webhook_id, event, payload, attempt, status_code, error, dead, created_at
+		FROM webhook_deliveries WHERE webhook_id=$1 AND (dead OR NOT $2) ORDER BY id DESC LIMIT 100;`,
+		webhookID, deadOnly)
+	if err != nil {
+		return []models.WebhookDelivery{}, fmt.Errorf("error fetch webhook deliveries from db: %w", err)
+	}
+
+	defer rows.Close()
+
+	deliveries := []models.WebhookDelivery{}
+	for rows.Next() {
+		var d models.WebhookDelivery
+		var payload []byte
+		if err := rows.Scan(&d.ID, &d.WebhookID, &d.Event, &payload, &d.Attempt,
+			&d.StatusCode, &d.Error, &d.Dead, &d.CreatedAt); err != nil {
+			return deliveries, fmt.Errorf("error decode webhook deliveries from db: %w", err)
+		}
+		d.Payload = payload
+		deliveries = append(deliveries, d)
+	}
+
+	if rows.Err() != nil {
+		return deliveries, fmt.Errorf("error scan rows: %w", rows.Err())
+	}
+
+	return deliveries, nil
+}
+
+const apiKeyColumns = "id, creator_id, name, prefix, hash, scopes, created_at, last_used_at"
+
+func (s *DBStorage) WriteAPIKey(ctx context.Context, key models.APIKey) error {
+	scopes, err := jsonList(key.Scopes)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.ExecContext(ctx,
+		"INSERT INTO api_keys (id, creator_id, name, prefix, hash, scopes, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7);",
+		key.ID, key.CreatorID, key.Name, key.Prefix, key.Hash, scopes, key.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("error write api key to db: %w", err)
+	}
+
+	return nil
+}
+
+func (s *DBStorage) ReadAPIKeysByCreatorID(ctx context.Context, creatorID string) ([]models.APIKey, error) {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT "+apiKeyColumns+" FROM api_keys WHERE creator_id=$1 ORDER BY created_at;", creatorID)
+	if err != nil {
+		return []models.APIKey{}, fmt.Errorf("error fetch api keys from db: %w", err)
+	}
+
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return keys, err
+		}
+		keys = append(keys, k)
+	}
+
+	if rows.Err() != nil {
+		return keys, fmt.Errorf("error scan rows: %w", rows.Err())
+	}
+
+	return keys, nil
+}
+
+func (s *DBStorage) ReadAPIKeyByHash(ctx context.Context, hash string) (models.APIKey, error) {
+	row := s.db.QueryRowContext(ctx, "SELECT "+apiKeyColumns+" FROM api_keys WHERE hash=$1;", hash)
+
+	k, err := scanAPIKey(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.APIKey{}, ErrAPIKeyNotFound
+	}
+
+	return k, err
+}
+
+func (s *DBStorage) DeleteAPIKey(ctx context.Context, creatorID, id string) error {
+	res, err := s.db.ExecContext(ctx, "DELETE FROM api_keys WHERE id=$1 AND creator_id=$2;", id, creatorID)
+	if err != nil {
+		return fmt.Errorf("error delete api key from db: %w", err)
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error delete api key from db: %w", err)
+	}
+	if count == 0 {
+		return ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+func (s *DBStorage) TouchAPIKey(ctx context.Context, id string, usedAt time.Time) error {
+	_, err := s.db.ExecContext(ctx, "UPDATE api_keys SET last_used_at=$2 WHERE id=$1;", id, usedAt)
+	if err != nil {
+		return fmt.Errorf("error update api key in db: %w", err)
+	}
+
+	return nil
+}
+
+func scanAPIKey(row interface{ Scan(dest ...any) error }) (models.APIKey, error) {
+	var k models.APIKey
+	var scopes []byte
+	var lastUsed sql.NullTime
+	err := row.Scan(&k.ID, &k.CreatorID, &k.Name, &k.Prefix, &k.Hash, &scopes, &k.CreatedAt, &lastUsed)
+	if errors.Is(err, sql.ErrNoRows) {
+		return k, err
+	}
+	if err != nil {
+		return k, fmt.Errorf("error decode api key from db: %w", err)
+	}
+	if err := json.Unmarshal(scopes, &k.Scopes); err != nil {
+		return k, fmt.Errorf("error decode api key scopes: %w", err)
+	}
+	if lastUsed.Valid {
+		k.LastUsedAt = &lastUsed.Time
+	}
+
+	return k, nil
+}
+
+func (s *DBStorage) WriteUser(ctx context.Context, user models.User) error {
+	_, err := s.db.ExecContext(ctx,
+		"INSERT INTO users (id, email, password_hash, created_at) VALUES ($1, $2, $3, $4);",
+		user.ID, user.Email, user.PasswordHash, user.CreatedAt)
+	if err != nil {
+		var pgErr *pq.Error
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+			err = ErrUserExists
+		}
+
+		return fmt.Errorf("error write user to db: %w", err)
+	}
+
+	return nil
+}
+
+func (s *DBStorage) ReadUserByEmail(ctx context.Context, email string) (models.User, error) {
+	return s.readUser(ctx, "SELECT id, email, password_hash, created_at FROM users WHERE email=$1;", email)
+}
+
+func (s *DBStorage) ReadUserByID(ctx context.Context, id string) (models.User, error) {
+	return s.readUser(ctx, "SELECT id, email, password_hash, created_at FROM users WHERE id=$1;", id)
+}
+
+func (s *DBStorage) readUser(ctx context.Context, query, arg string) (models.User, error) {
+	var u models.User
+	err := s.db.QueryRowContext(ctx, query, arg).Scan(&u.ID, &u.Email, &u.PasswordHash, &u.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.User{}, ErrUserNotFound
+	}
+	if err != nil {
+		return models.User{}, fmt.Errorf("error fetch user from db: %w", err)
+	}
+
+	return u, nil
+}
+
+func (s *DBStorage) WriteIdentity(ctx context.Context, identity models.Identity) error {
+	_, err := s.db.ExecContext(ctx,
+		"INSERT INTO identities (issuer, subject, user_id, email, created_at) VALUES ($1, $2, $3, $4, $5);",
+		identity.Issuer, identity.Subject, identity.UserID, identity.Email, identity.CreatedAt)
+	if err != nil {
+		var pgErr *pq.Error
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.UniqueViolation {
+			err = ErrIdentityExists
+		}
+
+		return fmt.Errorf("error write identity to db: %w", err)
+	}
+
+	return nil
+}
+
+func (s *DBStorage) ReadIdentity(ctx context.Context, issuer, subject string) (models.Identity, error) {
+	var i models.Identity
+	err := s.db.QueryRowContext(ctx,
+		"SELECT issuer, subject, user_id, email, created_at FROM identities WHERE issuer=$1 AND subject=$2;",
+		issuer, subject).Scan(&i.Issuer, &i.Subject, &i.UserID, &i.Email, &i.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Identity{}, ErrIdentityNotFound
+	}
+	if err != nil {
+		return models.Identity{}, fmt.Errorf("error fetch identity from db: %w", err)
+	}
+
+	return i, nil
+}
+
+func (s *DBStorage) ReadIdentitiesByUserID(ctx context.Context, userID string) ([]models.Identity, error) {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT issuer, subject, user_id, email, created_at FROM identities WHERE user_id=$1;", userID)
+	if err != nil {
+		return nil, fmt.Errorf("error fetch identities from db: %w", err)
+	}
+	defer rows.Close()
+
+	identities := []models.Identity{}
+	for rows.Next() {
+		var i models.Identity
+		if err := rows.Scan(&i.Issuer, &i.Subject, &i.UserID, &i.Email, &i.CreatedAt); err != nil {
+			return nil, fmt.Errorf("error decode identity from db: %w", err)
+		}
+		identities = append(identities, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error fetch identities from db: %w", err)
+	}
+
+	return identities, nil
+}
+
+// ReassignCreator передает ссылки, вебхуки и ключи от одного пользователя другому одной транзакцией.
+func (s *DBStorage) ReassignCreator(ctx context.Context, from, to string) error {
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("tx error: %w", err)
+	}
+
+	statements := []string{
+		"UPDATE urls SET creator_id=$2 WHERE creator_id=$1;",
+		"UPDATE webhooks SET creator_id=$2 WHERE creator_id=$1;",
+		"UPDATE api_keys SET creator_id=$2 WHERE creator_id=$1;",
+		// где состоят оба, остается старшая из ролей
+		`INSERT INTO workspace_members (workspace_id, user_id, role, added_at)
+		SELECT workspace_id, $2, role, added_at FROM workspace_members WHERE user_id=$1
+		ON CONFLICT (workspace_id, user_id) DO UPDATE SET role = CASE
+			WHEN array_position(` + roleRanks + `, EXCLUDED.role) > array_position(` + roleRanks + `, workspace_members.role)
+			THEN EXCLUDED.role ELSE workspace_members.role END;`,
+		"DELETE FROM workspace_members WHERE user_id=$1;",
+	}
+	for _, statement := range statements {
+		if _, err := tx.ExecContext(ctx, statement, from, to); err != nil {
+			if rbError := tx.Rollback(); rbError != nil {
+				logrus.Errorf("reassign failed, unable to rollback %v", rbError)
+			}
+
+			return fmt.Errorf("error reassign creator in db: %w", err)
+		}
+	}
+
+	if cError := tx.Commit(); cError != nil {
+		return fmt.Errorf("commit error: %w", cError)
+	}
+
+	return nil
+}
+
+// roleRanks - роли пространства по возрастанию прав для сравнения в SQL.
+const roleRanks = "ARRAY['" + models.RoleViewer + "', '" + models.RoleEditor + "', '" + models.RoleOwner + "']"
+
+func (s *DBStorage) WriteWorkspace(ctx context.Context, workspace models.Workspace, owner models.WorkspaceMember) error {
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{})
+	if err != nil {
+		return fmt.Errorf("tx error: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, "INSERT INTO workspaces (id, name, created_at) VALUES ($1, $2, $3);",
+		workspace.ID, workspace.Name, workspace.CreatedAt)
+	if err == nil {
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO workspace_members (workspace_id, user_id, role, added_at) VALUES ($1, $2, $3, $4);",
+			workspace.ID, owner.UserID, owner.Role, owner.AddedAt)
+	}
+	if err != nil {
+		if rbError := tx.Rollback(); rbError != nil {
+			logrus.Errorf("insert failed, unable to rollback %v", rbError)
+		}
+
+		return fmt.Errorf("error write workspace to db: %w", err)
+	}
+
+	if cError := tx.Commit(); cError != nil {
+		return fmt.Errorf("commit error: %w", cError)
+	}
+
+	return nil
+}
+
+func (s *DBStorage) ReadWorkspacesByUserID(ctx context.Context, userID string) ([]models.Workspace, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT w.id, w.name, w.created_at, m.role FROM workspaces w
+		JOIN workspace_members m ON m.workspace_id = w.id
+		WHERE m.user_id=$1 ORDER BY w.created_at;`, userID)
+	if err != nil {
+		return []models.Workspace{}, fmt.Errorf("error fetch workspaces from db: %w", err)
+	}
+
+	defer rows.Close()
+
+	workspaces := []models.Workspace{}
+	for rows.Next() {
+		var w models.Workspace
+		if err := rows.Scan(&w.ID, &w.Name, &w.CreatedAt, &w.Role); err != nil {
+			return workspaces, fmt.Errorf("error decode workspaces from db: %w", err)
+		}
+		workspaces = append(workspaces, w)
+	}
+
+	if rows.Err() != nil {
+		return workspaces, fmt.Errorf("error scan rows: %w", rows.Err())
+	}
+
+	return workspaces, nil
+}
+
+func (s *DBStorage) ReadWorkspaceMember(ctx context.Context, workspaceID, userID string) (models.WorkspaceMember, error) {
+	m := models.WorkspaceMember{WorkspaceID: workspaceID, UserID: userID}
+	err := s.db.QueryRowContext(ctx,
+		"SELECT role, added_at FROM workspace_members WHERE workspace_id=$1 AND user_id=$2;",
+		workspaceID, userID).Scan(&m.Role, &m.AddedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.WorkspaceMember{}, ErrWorkspaceMemberNotFound
+	}
+	if err != nil {
+		return models.WorkspaceMember{}, fmt.Errorf("error fetch workspace member from db: %w", err)
+	}
+
+	return m, nil
+}
+
+func (s *DBStorage) ReadWorkspaceMembers(ctx context.Context, workspaceID string) ([]models.WorkspaceMember, error) {
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT user_id, role, added_at FROM workspace_members WHERE workspace_id=$1 ORDER BY added_at;", workspaceID)
+	if err != nil {
+		return []models.WorkspaceMember{}, fmt.Errorf("error fetch workspace members from db: %w", err)
+	}
+
+	defer rows.Close()
+
+	members := []models.WorkspaceMember{}
+	for rows.Next() {
+		m := models.WorkspaceMember{WorkspaceID: workspaceID}
+		if err := rows.Scan(&m.UserID, &m.Role, &m.AddedAt); err != nil {
+			return members, fmt.Errorf("error decode workspace members from db: %w", err)
+		}
+		members = append(members, m)
+	}
+
+	if rows.Err() != nil {
+		return members, fmt.Errorf("error scan rows: %w", rows.Err())
+	}
+
+	return members, nil
+}
+
+// WriteWorkspaceMember добавляет участника или меняет роль существующего.
+func (s *DBStorage) WriteWorkspaceMember(ctx context.Context, member models.WorkspaceMember) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO workspace_members (workspace_id, user_id, role, added_at) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (workspace_id, user_id) DO UPDATE SET role = EXCLUDED.role;`,
+		member.WorkspaceID, member.UserID, member.Role, member.AddedAt)
+	if err != nil {
+		var pgErr *pq.Error
+		if errors.As(err, &pgErr) && pgErr.Code == pgerrcode.ForeignKeyViolation {
+			err = ErrWorkspaceMemberNotFound
+		}
+
+		return fmt.Errorf("error write workspace member to db: %w", err)
+	}
+
+	return nil
+}
+
+func (s *DBStorage) DeleteWorkspaceMember(ctx context.Context, workspaceID, userID string) error {
+	res, err := s.db.ExecContext(ctx,
+		"DELETE FROM workspace_members WHERE workspace_id=$1 AND user_id=$2;", workspaceID, userID)
+	if err != nil {
+		return fmt.Errorf("error delete workspace member from db: %w", err)
+	}
+
+	count, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error delete workspace member from db: %w", err)
+	}
+	if count == 0 {
+		return ErrWorkspaceMemberNotFound
+	}
+
+	return nil
+}
@@ -2,4 +2,15 @@ package storage
 
 import "errors"
 
-var ErrDuplicateURL = errors.New("URL is exists")
+var (
+	ErrDuplicateURL            = errors.New("URL is exists")
+	ErrCodeTaken               = errors.New("short code is taken")
+	ErrNotFound                = errors.New("event not found")
+	ErrWebhookNotFound         = errors.New("webhook not found")
+	ErrAPIKeyNotFound          = errors.New("api key not found")
+	ErrUserNotFound            = errors.New("user not found")
+	ErrUserExists              = errors.New("user already exists")
+	ErrIdentityNotFound        = errors.New("identity not found")
+	ErrIdentityExists          = errors.New("identity already exists")
+	ErrWorkspaceMemberNotFound = errors.New("workspace member not found")
+)
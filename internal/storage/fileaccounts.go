@@ -0,0 +1,156 @@
+package storage
+
+import (
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/patrick-devel/shorturl/internal/models"
+)
+
+// AccountsPath - файл, где FileStorage рядом с журналом ссылок хранит учетные записи и настройки пользователей.
+func AccountsPath(path string) string {
+	return path + ".accounts"
+}
+
+// accountsSnapshot - все, что без базы иначе пропало бы при перезапуске, хотя ссылки пользователей остаются.
+// Записей немного и меняются они редко, поэтому файл переписывается целиком после каждого изменения.
+// Время последнего использования API-ключа файл не переписывает, оно попадет туда со следующим изменением.
+// Формат gob, а не JSON: у моделей секреты и владельцы скрыты из JSON тегами.
+type accountsSnapshot struct {
+	Users      []models.User
+	Identities []models.Identity
+	APIKeys    []models.APIKey
+	Webhooks   []models.Webhook
+	Workspaces []models.Workspace
+	Members    []models.WorkspaceMember
+}
+
+func (fs *FileStorage) loadAccounts() error {
+	file, err := os.Open(fs.accountsPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error open accounts: %w", err)
+	}
+	defer file.Close()
+
+	var snapshot accountsSnapshot
+	if err := gob.NewDecoder(file).Decode(&snapshot); err != nil {
+		return fmt.Errorf("error decode accounts: %w", err)
+	}
+
+	fs.restoreUsers(snapshot.Users, snapshot.Identities)
+	fs.restoreAPIKeys(snapshot.APIKeys)
+	fs.restoreWebhooks(snapshot.Webhooks)
+	fs.restoreWorkspaces(snapshot.Workspaces, snapshot.Members)
+
+	return nil
+}
+
+// saveAccounts пишет снимок во временный файл и подменяет им старый, чтобы сбой не оставил файл недописанным.
+func (fs *FileStorage) saveAccounts() error {
+	fs.accountsMu.Lock()
+	defer fs.accountsMu.Unlock()
+
+	var snapshot accountsSnapshot
+	snapshot.Users, snapshot.Identities = fs.snapshotUsers()
+	snapshot.APIKeys = fs.snapshotAPIKeys()
+	snapshot.Webhooks = fs.snapshotWebhooks()
+	snapshot.Workspaces, snapshot.Members = fs.snapshotWorkspaces()
+
+	file, err := os.CreateTemp(filepath.Dir(fs.accountsPath), filepath.Base(fs.accountsPath)+".*")
+	if err != nil {
+		return fmt.Errorf("error save accounts: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	if err := gob.NewEncoder(file).Encode(&snapshot); err != nil {
+		file.Close()
+		return fmt.Errorf("error save accounts: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("error save accounts: %w", err)
+	}
+	if err := os.Rename(file.Name(), fs.accountsPath); err != nil {
+		return fmt.Errorf("error save accounts: %w", err)
+	}
+
+	return nil
+}
+
+func (fs *FileStorage) WriteUser(ctx context.Context, user models.User) error {
+	if err := fs.userRegistry.WriteUser(ctx, user); err != nil {
+		return err
+	}
+
+	return fs.saveAccounts()
+}
+
+func (fs *FileStorage) WriteIdentity(ctx context.Context, identity models.Identity) error {
+	if err := fs.userRegistry.WriteIdentity(ctx, identity); err != nil {
+		return err
+	}
+
+	return fs.saveAccounts()
+}
+
+func (fs *FileStorage) WriteAPIKey(ctx context.Context, key models.APIKey) error {
+	if err := fs.apiKeyRegistry.WriteAPIKey(ctx, key); err != nil {
+		return err
+	}
+
+	return fs.saveAccounts()
+}
+
+func (fs *FileStorage) DeleteAPIKey(ctx context.Context, creatorID, id string) error {
+	if err := fs.apiKeyRegistry.DeleteAPIKey(ctx, creatorID, id); err != nil {
+		return err
+	}
+
+	return fs.saveAccounts()
+}
+
+func (fs *FileStorage) WriteWebhook(ctx context.Context, webhook models.Webhook) error {
+	if err := fs.webhookRegistry.WriteWebhook(ctx, webhook); err != nil {
+		return err
+	}
+
+	return fs.saveAccounts()
+}
+
+func (fs *FileStorage) DeleteWebhook(ctx context.Context, creatorID, id string) error {
+	if err := fs.webhookRegistry.DeleteWebhook(ctx, creatorID, id); err != nil {
+		return err
+	}
+
+	return fs.saveAccounts()
+}
+
+func (fs *FileStorage) WriteWorkspace(ctx context.Context, workspace models.Workspace, owner models.WorkspaceMember) error {
+	if err := fs.workspaceRegistry.WriteWorkspace(ctx, workspace, owner); err != nil {
+		return err
+	}
+
+	return fs.saveAccounts()
+}
+
+func (fs *FileStorage) WriteWorkspaceMember(ctx context.Context, member models.WorkspaceMember) error {
+	if err := fs.workspaceRegistry.WriteWorkspaceMember(ctx, member); err != nil {
+		return err
+	}
+
+	return fs.saveAccounts()
+}
+
+func (fs *FileStorage) DeleteWorkspaceMember(ctx context.Context, workspaceID, userID string) error {
+	if err := fs.workspaceRegistry.DeleteWorkspaceMember(ctx, workspaceID, userID); err != nil {
+		return err
+	}
+
+	return fs.saveAccounts()
+}
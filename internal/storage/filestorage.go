@@ -2,15 +2,28 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
+	"sync"
 
 	filemanager "github.com/patrick-devel/shorturl/internal/file_manager"
 	"github.com/patrick-devel/shorturl/internal/models"
 )
 
 type FileStorage struct {
+	clickCounter
+	healthRegistry
+	webhookRegistry
+	apiKeyRegistry
+	userRegistry
+	workspaceRegistry
+
 	consumer Consumer
 	producer Producer
+
+	accountsMu   sync.Mutex
+	accountsPath string
 }
 
 func NewFileStorage(path string) (*FileStorage, error) {
@@ -23,12 +36,18 @@ func NewFileStorage(path string) (*FileStorage, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &FileStorage{consumer: consumer, producer: producer}, nil
+	fs := &FileStorage{consumer: consumer, producer: producer, accountsPath: AccountsPath(path)}
+	if err := fs.loadAccounts(); err != nil {
+		return nil, err
+	}
+
+	return fs, nil
 }
 
 type Consumer interface {
-	ReadEvent(hash string) (*models.Event, error)
+	ReadEvent(ref models.LinkRef) (*models.Event, error)
 	ReadEventsByUserID(userID string) ([]models.Event, error)
+	ReadEvents() ([]models.Event, error)
 	Close() error
 }
 
@@ -37,16 +56,38 @@ type Producer interface {
 	Close() error
 }
 
-func (fs *FileStorage) ReadEvent(_ context.Context, shortURL string) (string, error) {
-	event, err := fs.consumer.ReadEvent(shortURL)
+func (fs *FileStorage) ReadEvent(_ context.Context, ref models.LinkRef) (models.Event, error) {
+	event, err := fs.consumer.ReadEvent(ref)
+	if errors.Is(err, filemanager.ErrNotFoundEvent) {
+		return models.Event{}, fmt.Errorf("error read event: %w", ErrNotFound)
+	}
 	if err != nil {
-		return "", fmt.Errorf("error read event: %w", err)
+		return models.Event{}, fmt.Errorf("error read event: %w", err)
 	}
 
-	return event.OriginalURL, nil
+	return *event, nil
+}
+
+func (fs *FileStorage) ReadEventByOriginalURL(_ context.Context, domain, originalURL string) (models.Event, error) {
+	events, err := fs.consumer.ReadEvents()
+	if err != nil {
+		return models.Event{}, fmt.Errorf("error read events: %w", err)
+	}
+
+	for _, e := range events {
+		if e.Domain == domain && e.OriginalURL == originalURL {
+			return e, nil
+		}
+	}
+
+	return models.Event{}, fmt.Errorf("error read event: %w", ErrNotFound)
 }
 
 func (fs *FileStorage) WriteEvent(_ context.Context, event models.Event) error {
+	if err := fs.checkCode(event); err != nil {
+		return err
+	}
+
 	err := fs.producer.WriteEvent(&event)
 	if err != nil {
 		return fmt.Errorf("error write event: %w", err)
@@ -56,6 +97,12 @@ func (fs *FileStorage) WriteEvent(_ context.Context, event models.Event) error {
 }
 
 func (fs *FileStorage) WriteEvents(_ context.Context, events []models.Event) error {
+	for _, e := range events {
+		if err := fs.checkCode(e); err != nil {
+			return err
+		}
+	}
+
 	for _, e := range events {
 		err := fs.producer.WriteEvent(&e)
 		if err != nil {
@@ -66,6 +113,15 @@ func (fs *FileStorage) WriteEvents(_ context.Context, events []models.Event) err
 	return nil
 }
 
+func (fs *FileStorage) UpdateEvent(_ context.Context, event models.Event) error {
+	err := fs.producer.WriteEvent(&event)
+	if err != nil {
+		return fmt.Errorf("error write event: %w", err)
+	}
+
+	return nil
+}
+
 func (fs *FileStorage) ReadEventsByCreatorID(_ context.Context, userID string) ([]models.Event, error) {
 	events, err := fs.consumer.ReadEventsByUserID(userID)
 	if err != nil {
@@ -75,6 +131,72 @@ func (fs *FileStorage) ReadEventsByCreatorID(_ context.Context, userID string) (
 	return events, nil
 }
 
-func (fs *FileStorage) SetDeleteByShortURL(shorts []string) error {
+func (fs *FileStorage) CountEventsByCreatorID(_ context.Context, userID string) (int, error) {
+	events, err := fs.consumer.ReadEventsByUserID(userID)
+	if err != nil {
+		return 0, fmt.Errorf("error read events: %w", err)
+	}
+
+	return len(events), nil
+}
+
+func (fs *FileStorage) ReadEventsByWorkspaceIDs(_ context.Context, workspaceIDs []string) ([]models.Event, error) {
+	events, err := fs.consumer.ReadEvents()
+	if err != nil {
+		return []models.Event{}, fmt.Errorf("error read events: %w", err)
+	}
+
+	return slices.DeleteFunc(events, func(e models.Event) bool {
+		return e.WorkspaceID == "" || !slices.Contains(workspaceIDs, e.WorkspaceID)
+	}), nil
+}
+
+// ReassignCreator дописывает ссылки с новым владельцем, как UpdateEvent.
+func (fs *FileStorage) ReassignCreator(_ context.Context, from, to string) error {
+	events, err := fs.consumer.ReadEventsByUserID(from)
+	if err != nil {
+		return fmt.Errorf("error read events: %w", err)
+	}
+
+	for _, e := range events {
+		e.CreatorID = to
+		if err := fs.producer.WriteEvent(&e); err != nil {
+			return fmt.Errorf("error write event: %w", err)
+		}
+	}
+
+	fs.reassignWebhooks(from, to)
+	fs.reassignAPIKeys(from, to)
+	fs.reassignWorkspaceMembers(from, to)
+
+	return fs.saveAccounts()
+}
+
+func (fs *FileStorage) ReadActiveEvents(_ context.Context) ([]models.Event, error) {
+	events, err := fs.consumer.ReadEvents()
+	if err != nil {
+		return []models.Event{}, fmt.Errorf("error read events: %w", err)
+	}
+
+	return events, nil
+}
+
+func (fs *FileStorage) SetDeleted(refs []models.LinkRef) error {
+	return nil
+}
+
+// checkCode не дает перезаписать чужую ссылку при совпадении кодов: в файле актуальна последняя запись.
+func (fs *FileStorage) checkCode(event models.Event) error {
+	existing, err := fs.consumer.ReadEvent(event.Ref())
+	if errors.Is(err, filemanager.ErrNotFoundEvent) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("error read event: %w", err)
+	}
+	if existing.OriginalURL != event.OriginalURL {
+		return fmt.Errorf("error write event: %w", ErrCodeTaken)
+	}
+
 	return nil
 }
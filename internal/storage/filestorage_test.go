@@ -0,0 +1,73 @@
+package storage_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/patrick-devel/shorturl/internal/models"
+	"github.com/patrick-devel/shorturl/internal/storage"
+)
+
+func TestFileStorageKeepsAccounts(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "urls.json")
+	now := time.Now().UTC().Truncate(time.Second)
+
+	user := models.User{ID: "user", Email: "user@example.com", PasswordHash: "hash", CreatedAt: now}
+	identity := models.Identity{Issuer: "https://idp.example", Subject: "sub", UserID: "user", CreatedAt: now}
+	key := models.APIKey{ID: "key", CreatorID: "user", Prefix: "sk_abc", Hash: "keyhash", Scopes: []string{"links:read"}, CreatedAt: now}
+	webhook := models.Webhook{ID: "hook", CreatorID: "user", URL: "https://hooks.example/", Secret: "secret", CreatedAt: now}
+	workspace := models.Workspace{ID: "team", Name: "Team", CreatedAt: now}
+	owner := models.WorkspaceMember{WorkspaceID: "team", UserID: "user", Role: models.RoleOwner, AddedAt: now}
+	member := models.WorkspaceMember{WorkspaceID: "team", UserID: "other", Role: models.RoleViewer, AddedAt: now}
+
+	fs, err := storage.NewFileStorage(path)
+	require.NoError(t, err)
+	require.NoError(t, fs.WriteUser(ctx, user))
+	require.NoError(t, fs.WriteIdentity(ctx, identity))
+	require.NoError(t, fs.WriteAPIKey(ctx, key))
+	require.NoError(t, fs.WriteWebhook(ctx, webhook))
+	require.NoError(t, fs.WriteWorkspace(ctx, workspace, owner))
+	require.NoError(t, fs.WriteWorkspaceMember(ctx, member))
+
+	// перезапуск: новое хранилище на том же файле
+	fs, err = storage.NewFileStorage(path)
+	require.NoError(t, err)
+
+	gotUser, err := fs.ReadUserByEmail(ctx, user.Email)
+	require.NoError(t, err)
+	assert.Equal(t, user, gotUser)
+
+	gotIdentity, err := fs.ReadIdentity(ctx, identity.Issuer, identity.Subject)
+	require.NoError(t, err)
+	assert.Equal(t, identity, gotIdentity)
+
+	gotKey, err := fs.ReadAPIKeyByHash(ctx, key.Hash)
+	require.NoError(t, err)
+	assert.Equal(t, key, gotKey)
+
+	gotWebhooks, err := fs.ReadWebhooksByCreatorID(ctx, "user")
+	require.NoError(t, err)
+	assert.Equal(t, []models.Webhook{webhook}, gotWebhooks)
+
+	gotMembers, err := fs.ReadWorkspaceMembers(ctx, "team")
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []models.WorkspaceMember{owner, member}, gotMembers)
+
+	gotWorkspaces, err := fs.ReadWorkspacesByUserID(ctx, "other")
+	require.NoError(t, err)
+	require.Len(t, gotWorkspaces, 1)
+	assert.Equal(t, "Team", gotWorkspaces[0].Name)
+
+	// удаление тоже переживает перезапуск
+	require.NoError(t, fs.DeleteAPIKey(ctx, "user", "key"))
+	fs, err = storage.NewFileStorage(path)
+	require.NoError(t, err)
+	_, err = fs.ReadAPIKeyByHash(ctx, key.Hash)
+	assert.Error(t, err)
+}
@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"context"
+	"sync"
+
+	"github.com/patrick-devel/shorturl/internal/models"
+)
+
+// healthRegistry хранит результаты проверки ссылок в памяти процесса,
+// используется хранилищами без базы данных.
+type healthRegistry struct {
+	healthMu sync.RWMutex
+	health   map[models.LinkRef]models.LinkHealth
+}
+
+func (hr *healthRegistry) WriteLinkHealth(_ context.Context, ref models.LinkRef, health models.LinkHealth) error {
+	hr.healthMu.Lock()
+	defer hr.healthMu.Unlock()
+
+	if hr.health == nil {
+		hr.health = map[models.LinkRef]models.LinkHealth{}
+	}
+	hr.health[ref] = health
+
+	return nil
+}
+
+func (hr *healthRegistry) ReadLinkHealth(_ context.Context, refs []models.LinkRef) (map[models.LinkRef]models.LinkHealth, error) {
+	hr.healthMu.RLock()
+	defer hr.healthMu.RUnlock()
+
+	result := map[models.LinkRef]models.LinkHealth{}
+	for _, ref := range refs {
+		if health, ok := hr.health[ref]; ok {
+			result[ref] = health
+		}
+	}
+
+	return result, nil
+}
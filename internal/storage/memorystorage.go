@@ -3,44 +3,174 @@ package storage
 import (
 	"context"
 	"fmt"
+	"slices"
+	"sync"
 
 	"github.com/patrick-devel/shorturl/internal/models"
 )
 
 type MemoryStorage struct {
-	cache map[string]string
+	clickCounter
+	healthRegistry
+	webhookRegistry
+	apiKeyRegistry
+	userRegistry
+	workspaceRegistry
+
+	mu    sync.RWMutex
+	cache map[models.LinkRef]models.Event
 }
 
-func NewMemoryStorage(cache map[string]string) *MemoryStorage {
+func NewMemoryStorage(cache map[models.LinkRef]models.Event) *MemoryStorage {
 	return &MemoryStorage{cache: cache}
 }
 
-func (s *MemoryStorage) ReadEvent(_ context.Context, shortURL string) (string, error) {
-	originalURL, ok := s.cache[shortURL]
+func (s *MemoryStorage) ReadEvent(_ context.Context, ref models.LinkRef) (models.Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	event, ok := s.cache[ref]
 	if !ok {
-		return "", fmt.Errorf("error fetch event from memory")
+		return models.Event{}, fmt.Errorf("error fetch event from memory: %w", ErrNotFound)
+	}
+
+	return event, nil
+}
+
+func (s *MemoryStorage) ReadEventByOriginalURL(_ context.Context, domain, originalURL string) (models.Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, e := range s.cache {
+		if e.Domain == domain && e.OriginalURL == originalURL {
+			return e, nil
+		}
 	}
 
-	return originalURL, nil
+	return models.Event{}, fmt.Errorf("error fetch event from memory: %w", ErrNotFound)
 }
 
 func (s *MemoryStorage) WriteEvent(_ context.Context, event models.Event) error {
-	s.cache[event.ShortURL] = event.OriginalURL
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkCode(event); err != nil {
+		return err
+	}
+	s.cache[event.Ref()] = event
+
 	return nil
 }
 
 func (s *MemoryStorage) WriteEvents(_ context.Context, events []models.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// пачка пишется целиком или никак
+	for _, e := range events {
+		if err := s.checkCode(e); err != nil {
+			return err
+		}
+	}
 	for _, e := range events {
-		s.cache[e.ShortURL] = e.OriginalURL
+		s.cache[e.Ref()] = e
+	}
+
+	return nil
+}
+
+// checkCode не дает перезаписать чужую ссылку при совпадении кодов.
+func (s *MemoryStorage) checkCode(event models.Event) error {
+	if existing, ok := s.cache[event.Ref()]; ok && existing.OriginalURL != event.OriginalURL {
+		return fmt.Errorf("error write event to memory: %w", ErrCodeTaken)
+	}
+
+	return nil
+}
+
+func (s *MemoryStorage) UpdateEvent(_ context.Context, event models.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.cache[event.Ref()]; !ok {
+		return fmt.Errorf("error fetch event from memory: %w", ErrNotFound)
 	}
+	s.cache[event.Ref()] = event
 
 	return nil
 }
 
-func (s *MemoryStorage) ReadEventsByCreatorID(_ context.Context, _ string) ([]models.Event, error) {
-	return []models.Event{}, nil
+func (s *MemoryStorage) ReadEventsByCreatorID(_ context.Context, userID string) ([]models.Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	events := []models.Event{}
+	for _, e := range s.cache {
+		if e.CreatorID == userID {
+			events = append(events, e)
+		}
+	}
+
+	return events, nil
+}
+
+func (s *MemoryStorage) CountEventsByCreatorID(_ context.Context, userID string) (int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	count := 0
+	for _, e := range s.cache {
+		if e.CreatorID == userID {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+func (s *MemoryStorage) ReadEventsByWorkspaceIDs(_ context.Context, workspaceIDs []string) ([]models.Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	events := []models.Event{}
+	for _, e := range s.cache {
+		if e.WorkspaceID != "" && slices.Contains(workspaceIDs, e.WorkspaceID) {
+			events = append(events, e)
+		}
+	}
+
+	return events, nil
+}
+
+func (s *MemoryStorage) ReassignCreator(_ context.Context, from, to string) error {
+	s.mu.Lock()
+	for ref, e := range s.cache {
+		if e.CreatorID == from {
+			e.CreatorID = to
+			s.cache[ref] = e
+		}
+	}
+	s.mu.Unlock()
+
+	s.reassignWebhooks(from, to)
+	s.reassignAPIKeys(from, to)
+	s.reassignWorkspaceMembers(from, to)
+
+	return nil
+}
+
+func (s *MemoryStorage) ReadActiveEvents(_ context.Context) ([]models.Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	events := make([]models.Event, 0, len(s.cache))
+	for _, e := range s.cache {
+		events = append(events, e)
+	}
+
+	return events, nil
 }
 
-func (s *MemoryStorage) SetDeleteByShortURL(shorts []string) error {
+func (s *MemoryStorage) SetDeleted(refs []models.LinkRef) error {
 	return nil
 }
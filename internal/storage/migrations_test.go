@@ -0,0 +1,92 @@
+package storage_test
+
+import (
+	"database/sql"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/golang-migrate/migrate/v4"
+	_ "github.com/golang-migrate/migrate/v4/database/postgres"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const migrationsURL = "file://../../migrations"
+
+// migrateDB - миграции на пустой базе из TEST_DATABASE_DSN. Без нее тест пропускается:
+// база в тесте очищается целиком, поэтому подойдет только отдельная.
+func migrateDB(t *testing.T) (*migrate.Migrate, *sql.DB) {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN is not set")
+	}
+
+	clean, err := migrate.New(migrationsURL, dsn)
+	require.NoError(t, err)
+	require.NoError(t, clean.Drop())
+	_, _ = clean.Close()
+
+	// после Drop таблицы версий нет, ее создает только новый экземпляр
+	m, err := migrate.New(migrationsURL, dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() { _, _ = m.Close() })
+
+	db, err := sql.Open("postgres", dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+
+	return m, db
+}
+
+func TestMigrateDuplicateCodes(t *testing.T) {
+	m, db := migrateDB(t)
+
+	// до 000018 в short_url полный адрес
+	require.NoError(t, m.Migrate(17))
+	insert := `INSERT INTO urls (uuid, short_url, original_url) VALUES ($1, $2, $3);`
+	for _, row := range [][2]string{
+		{"http://localhost:8080/abc", "https://go.dev/"},
+		// совпадение старого хеша
+		{"http://localhost:8080/abc", "https://go.dev/blog"},
+		// тот же код, сохраненный под другим BASE_URL
+		{"https://short.example/abc", "https://go.dev/doc"},
+		{"http://localhost:8080/xyz", "https://go.dev/dl"},
+	} {
+		_, err := db.Exec(insert, uuid.NewString(), row[0], row[1])
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, m.Up())
+	version, dirty, err := m.Version()
+	require.NoError(t, err)
+	assert.False(t, dirty)
+	assert.GreaterOrEqual(t, version, uint(18))
+
+	rows, err := db.Query(`SELECT code, original_url FROM urls ORDER BY uid;`)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	codes := map[string]string{}
+	for rows.Next() {
+		var code, originalURL string
+		require.NoError(t, rows.Scan(&code, &originalURL))
+		assert.NotContains(t, codes, code, "code %s is not unique", code)
+		codes[code] = originalURL
+	}
+	require.NoError(t, rows.Err())
+	require.Len(t, codes, 4)
+
+	// первая ссылка сохраняет код, остальные получают новый на его основе
+	assert.Equal(t, "https://go.dev/", codes["abc"])
+	assert.Equal(t, "https://go.dev/dl", codes["xyz"])
+	for code, originalURL := range codes {
+		if originalURL == "https://go.dev/blog" || originalURL == "https://go.dev/doc" {
+			assert.True(t, strings.HasPrefix(code, "abc-"), code)
+		}
+	}
+}
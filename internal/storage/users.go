@@ -0,0 +1,135 @@
+package storage
+
+import (
+	"context"
+	"sync"
+
+	"github.com/patrick-devel/shorturl/internal/models"
+)
+
+// userRegistry хранит пользователей и их внешние учетные записи в памяти процесса,
+// используется хранилищами без базы данных.
+type userRegistry struct {
+	userMu     sync.RWMutex
+	users      map[string]models.User
+	identities map[identityKey]models.Identity
+}
+
+func (ur *userRegistry) WriteUser(_ context.Context, user models.User) error {
+	ur.userMu.Lock()
+	defer ur.userMu.Unlock()
+
+	if ur.users == nil {
+		ur.users = map[string]models.User{}
+	}
+	for _, u := range ur.users {
+		if u.Email == user.Email || u.ID == user.ID {
+			return ErrUserExists
+		}
+	}
+	ur.users[user.ID] = user
+
+	return nil
+}
+
+func (ur *userRegistry) ReadUserByEmail(_ context.Context, email string) (models.User, error) {
+	ur.userMu.RLock()
+	defer ur.userMu.RUnlock()
+
+	for _, u := range ur.users {
+		if u.Email == email {
+			return u, nil
+		}
+	}
+
+	return models.User{}, ErrUserNotFound
+}
+
+func (ur *userRegistry) ReadUserByID(_ context.Context, id string) (models.User, error) {
+	ur.userMu.RLock()
+	defer ur.userMu.RUnlock()
+
+	u, ok := ur.users[id]
+	if !ok {
+		return models.User{}, ErrUserNotFound
+	}
+
+	return u, nil
+}
+
+type identityKey struct {
+	issuer  string
+	subject string
+}
+
+func (ur *userRegistry) WriteIdentity(_ context.Context, identity models.Identity) error {
+	ur.userMu.Lock()
+	defer ur.userMu.Unlock()
+
+	if ur.identities == nil {
+		ur.identities = map[identityKey]models.Identity{}
+	}
+	key := identityKey{issuer: identity.Issuer, subject: identity.Subject}
+	if _, ok := ur.identities[key]; ok {
+		return ErrIdentityExists
+	}
+	ur.identities[key] = identity
+
+	return nil
+}
+
+func (ur *userRegistry) ReadIdentity(_ context.Context, issuer, subject string) (models.Identity, error) {
+	ur.userMu.RLock()
+	defer ur.userMu.RUnlock()
+
+	identity, ok := ur.identities[identityKey{issuer: issuer, subject: subject}]
+	if !ok {
+		return models.Identity{}, ErrIdentityNotFound
+	}
+
+	return identity, nil
+}
+
+func (ur *userRegistry) ReadIdentitiesByUserID(_ context.Context, userID string) ([]models.Identity, error) {
+	ur.userMu.RLock()
+	defer ur.userMu.RUnlock()
+
+	identities := []models.Identity{}
+	for _, identity := range ur.identities {
+		if identity.UserID == userID {
+			identities = append(identities, identity)
+		}
+	}
+
+	return identities, nil
+}
+
+func (ur *userRegistry) snapshotUsers() ([]models.User, []models.Identity) {
+	ur.userMu.RLock()
+	defer ur.userMu.RUnlock()
+
+	users := make([]models.User, 0, len(ur.users))
+	for _, u := range ur.users {
+		users = append(users, u)
+	}
+	identities := make([]models.Identity, 0, len(ur.identities))
+	for _, identity := range ur.identities {
+		identities = append(identities, identity)
+	}
+
+	return users, identities
+}
+
+func (ur *userRegistry) restoreUsers(users []models.User, identities []models.Identity) {
+	ur.userMu.Lock()
+	defer ur.userMu.Unlock()
+
+	ur.users = make(map[string]models.User, len(users))
+	for _, u := range users {
+		ur.users[u.ID] = u
+	}
+	ur.identities = make(map[identityKey]models.Identity, len(identities))
+	for _, identity := range identities {
+		ur.identities[identityKey{issuer: identity.Issuer, subject: identity.Subject}] = identity
+	}
+}
@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"context"
+	"sync"
+
+	"github.com/patrick-devel/shorturl/internal/models"
+)
+
+// maxMemoryDeliveries ограничивает журнал доставок одного вебхука в памяти.
+const maxMemoryDeliveries = 100
+
+// webhookRegistry хранит вебхуки и журнал доставок в памяти процесса,
+// используется хранилищами без базы данных.
+type webhookRegistry struct {
+	webhookMu  sync.RWMutex
+	webhooks   map[string]models.Webhook
+	deliveries map[string][]models.WebhookDelivery
+}
+
+func (wr *webhookRegistry) WriteWebhook(_ context.Context, webhook models.Webhook) error {
+	wr.webhookMu.Lock()
+	defer wr.webhookMu.Unlock()
+
+	if wr.webhooks == nil {
+		wr.webhooks = map[string]models.Webhook{}
+	}
+	wr.webhooks[webhook.ID] = webhook
+
+	return nil
+}
+
+func (wr *webhookRegistry) ReadWebhooksByCreatorID(_ context.Context, creatorID string) ([]models.Webhook, error) {
+	wr.webhookMu.RLock()
+	defer wr.webhookMu.RUnlock()
+
+	webhooks := []models.Webhook{}
+	for _, w := range wr.webhooks {
+		if w.CreatorID == creatorID {
+			webhooks = append(webhooks, w)
+		}
+	}
+
+	return webhooks, nil
+}
+
+func (wr *webhookRegistry) DeleteWebhook(_ context.Context, creatorID, id string) error {
+	wr.webhookMu.Lock()
+	defer wr.webhookMu.Unlock()
+
+	w, ok := wr.webhooks[id]
+	if !ok || w.CreatorID != creatorID {
+		return ErrWebhookNotFound
+	}
+	delete(wr.webhooks, id)
+	delete(wr.deliveries, id)
+
+	return nil
+}
+
+func (wr *webhookRegistry) WriteWebhookDelivery(_ context.Context, delivery models.WebhookDelivery) error {
+	wr.webhookMu.Lock()
+	defer wr.webhookMu.Unlock()
+
+	if wr.deliveries == nil {
+		wr.deliveries = map[string][]models.WebhookDelivery{}
+	}
+
+	log := append(wr.deliveries[delivery.WebhookID], delivery)
+	if len(log) > maxMemoryDeliveries {
+		log = log[len(log)-maxMemoryDeliveries:]
+	}
+	wr.deliveries[delivery.WebhookID] = log
+
+	return nil
+}
+
+func (wr *webhookRegistry) ReadWebhookDeliveries(_ context.Context, webhookID string, deadOnly bool) ([]models.WebhookDelivery, error) {
+	wr.webhookMu.RLock()
+	defer wr.webhookMu.RUnlock()
+
+	log := wr.deliveries[webhookID]
+	deliveries := make([]models.WebhookDelivery, 0, len(log))
+	for i := len(log) - 1; i >= 0; i-- {
+		if deadOnly && !log[i].Dead {
+			continue
+		}
+		deliveries = append(deliveries, log[i])
+	}
+
+	return deliveries, nil
+}
+
+func (wr *webhookRegistry) reassignWebhooks(from, to string) {
+	wr.webhookMu.Lock()
+	defer wr.webhookMu.Unlock()
+
+	for id, w := range wr.webhooks {
+		if w.CreatorID == from {
+			w.CreatorID = to
+			wr.webhooks[id] = w
+		}
+	}
+}
+
+// snapshotWebhooks отдает только вебхуки: журнал доставок, как и клики, между перезапусками не хранится.
+func (wr *webhookRegistry) snapshotWebhooks() []models.Webhook {
+	wr.webhookMu.RLock()
+	defer wr.webhookMu.RUnlock()
+
+	webhooks := make([]models.Webhook, 0, len(wr.webhooks))
+	for _, w := range wr.webhooks {
+		webhooks = append(webhooks, w)
+	}
+
+	return webhooks
+}
+
+func (wr *webhookRegistry) restoreWebhooks(webhooks []models.Webhook) {
+	wr.webhookMu.Lock()
+	defer wr.webhookMu.Unlock()
+
+	wr.webhooks = make(map[string]models.Webhook, len(webhooks))
+	for _, w := range webhooks {
+		wr.webhooks[w.ID] = w
+	}
+}
@@ -0,0 +1,158 @@
+package storage
+
+import (
+	"context"
+	"slices"
+	"sync"
+
+	"github.com/patrick-devel/shorturl/internal/models"
+)
+
+// workspaceRegistry хранит пространства и их участников в памяти процесса,
+// используется хранилищами без базы данных.
+type workspaceRegistry struct {
+	workspaceMu sync.RWMutex
+	workspaces  map[string]models.Workspace
+	// members - участники по пространствам, внутри по пользователям.
+	members map[string]map[string]models.WorkspaceMember
+}
+
+func (wr *workspaceRegistry) WriteWorkspace(_ context.Context, workspace models.Workspace, owner models.WorkspaceMember) error {
+	wr.workspaceMu.Lock()
+	defer wr.workspaceMu.Unlock()
+
+	if wr.workspaces == nil {
+		wr.workspaces = map[string]models.Workspace{}
+		wr.members = map[string]map[string]models.WorkspaceMember{}
+	}
+	wr.workspaces[workspace.ID] = workspace
+	wr.members[workspace.ID] = map[string]models.WorkspaceMember{owner.UserID: owner}
+
+	return nil
+}
+
+func (wr *workspaceRegistry) ReadWorkspacesByUserID(_ context.Context, userID string) ([]models.Workspace, error) {
+	wr.workspaceMu.RLock()
+	defer wr.workspaceMu.RUnlock()
+
+	workspaces := []models.Workspace{}
+	for id, members := range wr.members {
+		if m, ok := members[userID]; ok {
+			w := wr.workspaces[id]
+			w.Role = m.Role
+			workspaces = append(workspaces, w)
+		}
+	}
+	slices.SortFunc(workspaces, func(a, b models.Workspace) int { return a.CreatedAt.Compare(b.CreatedAt) })
+
+	return workspaces, nil
+}
+
+func (wr *workspaceRegistry) ReadWorkspaceMember(_ context.Context, workspaceID, userID string) (models.WorkspaceMember, error) {
+	wr.workspaceMu.RLock()
+	defer wr.workspaceMu.RUnlock()
+
+	m, ok := wr.members[workspaceID][userID]
+	if !ok {
+		return models.WorkspaceMember{}, ErrWorkspaceMemberNotFound
+	}
+
+	return m, nil
+}
+
+func (wr *workspaceRegistry) ReadWorkspaceMembers(_ context.Context, workspaceID string) ([]models.WorkspaceMember, error) {
+	wr.workspaceMu.RLock()
+	defer wr.workspaceMu.RUnlock()
+
+	members := make([]models.WorkspaceMember, 0, len(wr.members[workspaceID]))
+	for _, m := range wr.members[workspaceID] {
+		members = append(members, m)
+	}
+	slices.SortFunc(members, func(a, b models.WorkspaceMember) int { return a.AddedAt.Compare(b.AddedAt) })
+
+	return members, nil
+}
+
+// WriteWorkspaceMember добавляет участника или меняет роль существующего.
+func (wr *workspaceRegistry) WriteWorkspaceMember(_ context.Context, member models.WorkspaceMember) error {
+	wr.workspaceMu.Lock()
+	defer wr.workspaceMu.Unlock()
+
+	members, ok := wr.members[member.WorkspaceID]
+	if !ok {
+		return ErrWorkspaceMemberNotFound
+	}
+	if existing, ok := members[member.UserID]; ok {
+		member.AddedAt = existing.AddedAt
+	}
+	members[member.UserID] = member
+
+	return nil
+}
+
+func (wr *workspaceRegistry) DeleteWorkspaceMember(_ context.Context, workspaceID, userID string) error {
+	wr.workspaceMu.Lock()
+	defer wr.workspaceMu.Unlock()
+
+	if _, ok := wr.members[workspaceID][userID]; !ok {
+		return ErrWorkspaceMemberNotFound
+	}
+	delete(wr.members[workspaceID], userID)
+
+	return nil
+}
+
+// reassignWorkspaceMembers передает участие в пространствах. Где состоят оба, остается старшая из ролей.
+func (wr *workspaceRegistry) reassignWorkspaceMembers(from, to string) {
+	wr.workspaceMu.Lock()
+	defer wr.workspaceMu.Unlock()
+
+	for _, members := range wr.members {
+		m, ok := members[from]
+		if !ok {
+			continue
+		}
+		delete(members, from)
+		if existing, ok := members[to]; ok && models.RoleAllows(existing.Role, m.Role) {
+			continue
+		}
+		m.UserID = to
+		members[to] = m
+	}
+}
+
+func (wr *workspaceRegistry) snapshotWorkspaces() ([]models.Workspace, []models.WorkspaceMember) {
+	wr.workspaceMu.RLock()
+	defer wr.workspaceMu.RUnlock()
+
+	workspaces := make([]models.Workspace, 0, len(wr.workspaces))
+	for _, w := range wr.workspaces {
+		workspaces = append(workspaces, w)
+	}
+	var members []models.WorkspaceMember
+	for workspaceID, byUser := range wr.members {
+		for _, m := range byUser {
+			m.WorkspaceID = workspaceID
+			members = append(members, m)
+		}
+	}
+
+	return workspaces, members
+}
+
+func (wr *workspaceRegistry) restoreWorkspaces(workspaces []models.Workspace, members []models.WorkspaceMember) {
+	wr.workspaceMu.Lock()
+	defer wr.workspaceMu.Unlock()
+
+	wr.workspaces = make(map[string]models.Workspace, len(workspaces))
+	wr.members = make(map[string]map[string]models.WorkspaceMember, len(workspaces))
+	for _, w := range workspaces {
+		wr.workspaces[w.ID] = w
+		wr.members[w.ID] = map[string]models.WorkspaceMember{}
+	}
+	for _, m := range members {
+		if byUser, ok := wr.members[m.WorkspaceID]; ok {
+			byUser[m.UserID] = m
+		}
+	}
+}
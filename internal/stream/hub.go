@@ -0,0 +1,140 @@
+package stream
+
+import (
+	"sync"
+
+	"github.com/patrick-devel/shorturl/internal/models"
+)
+
+const (
+	defaultBufferSize     = 1024
+	defaultSubscriberSize = 64
+)
+
+type Message struct {
+	ID           uint64
+	Notification models.Notification
+}
+
+// Hub раздает события подписчикам внутри процесса. Последние события хранятся
+// в кольцевом буфере, чтобы клиент мог переподключиться с Last-Event-ID и ничего не потерять.
+type Hub struct {
+	mu          sync.Mutex
+	seq         uint64
+	ring        []Message
+	next        int
+	subscribers map[string]map[*Subscription]struct{}
+}
+
+type Subscription struct {
+	hub    *Hub
+	userID string
+	events chan Message
+	once   sync.Once
+}
+
+func NewHub(bufferSize int) *Hub {
+	if bufferSize <= 0 {
+		bufferSize = defaultBufferSize
+	}
+
+	return &Hub{
+		ring:        make([]Message, 0, bufferSize),
+		subscribers: map[string]map[*Subscription]struct{}{},
+	}
+}
+
+func (h *Hub) Publish(n models.Notification) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.seq++
+	msg := Message{ID: h.seq, Notification: n}
+
+	if len(h.ring) < cap(h.ring) {
+		h.ring = append(h.ring, msg)
+	} else {
+		h.ring[h.next] = msg
+		h.next = (h.next + 1) % cap(h.ring)
+	}
+
+	for sub := range h.subscribers[n.CreatorID] {
+		select {
+		case sub.events <- msg:
+		default:
+			// медленного клиента отключаем: он переподключится с Last-Event-ID и догонит из буфера
+			h.remove(sub)
+		}
+	}
+}
+
+// Subscribe подписывает на события пользователя. Если lastEventID не ноль,
+// сначала в канал попадут события из буфера, пришедшие после него.
+func (h *Hub) Subscribe(userID string, lastEventID uint64) *Subscription {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var backlog []Message
+	if lastEventID > 0 {
+		for i := 0; i < len(h.ring); i++ {
+			msg := h.ring[(h.next+i)%len(h.ring)]
+			if msg.ID > lastEventID && msg.Notification.CreatorID == userID {
+				backlog = append(backlog, msg)
+			}
+		}
+	}
+
+	sub := &Subscription{
+		hub:    h,
+		userID: userID,
+		events: make(chan Message, defaultSubscriberSize+len(backlog)),
+	}
+	for _, msg := range backlog {
+		sub.events <- msg
+	}
+
+	if h.subscribers[userID] == nil {
+		h.subscribers[userID] = map[*Subscription]struct{}{}
+	}
+	h.subscribers[userID][sub] = struct{}{}
+
+	return sub
+}
+
+func (h *Hub) remove(sub *Subscription) {
+	subs := h.subscribers[sub.userID]
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(h.subscribers, sub.userID)
+	}
+	sub.once.Do(func() { close(sub.events) })
+}
+
+// Events закрывается, когда подписку отключили.
+func (s *Subscription) Events() <-chan Message {
+	return s.events
+}
+
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+
+	s.hub.remove(s)
+}
+
+// Subscribers возвращает число открытых подписок.
+func (h *Hub) Subscribers() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	count := 0
+	for _, subs := range h.subscribers {
+		count += len(subs)
+	}
+
+	return count
+}
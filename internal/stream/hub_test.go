@@ -0,0 +1,106 @@
+package stream_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/patrick-devel/shorturl/internal/models"
+	"github.com/patrick-devel/shorturl/internal/stream"
+)
+
+func notification(user, short string) models.Notification {
+	return models.Notification{Type: models.NotificationLinkClicked, CreatorID: user, ShortURL: short}
+}
+
+func drain(sub *stream.Subscription) []string {
+	var shorts []string
+	for {
+		select {
+		case msg, ok := <-sub.Events():
+			if !ok {
+				return shorts
+			}
+			shorts = append(shorts, msg.Notification.ShortURL)
+		default:
+			return shorts
+		}
+	}
+}
+
+func TestHubDeliversOwnEvents(t *testing.T) {
+	hub := stream.NewHub(10)
+
+	sub := hub.Subscribe("user", 0)
+	defer sub.Close()
+
+	hub.Publish(notification("user", "a"))
+	hub.Publish(notification("other", "b"))
+	hub.Publish(notification("user", "c"))
+
+	assert.Equal(t, []string{"a", "c"}, drain(sub))
+}
+
+func TestHubResume(t *testing.T) {
+	tests := []struct {
+		name        string
+		bufferSize  int
+		lastEventID uint64
+		exp         []string
+	}{
+		{name: "FromStart", bufferSize: 10, lastEventID: 0, exp: nil},
+		{name: "AfterFirst", bufferSize: 10, lastEventID: 1, exp: []string{"2", "4"}},
+		{name: "Evicted", bufferSize: 2, lastEventID: 1, exp: []string{"4"}},
+		{name: "UpToDate", bufferSize: 10, lastEventID: 4, exp: nil},
+	}
+
+	for _, tc := range tests {
+		testcase := tc
+		t.Run(testcase.name, func(t *testing.T) {
+			hub := stream.NewHub(testcase.bufferSize)
+			hub.Publish(notification("user", "1"))
+			hub.Publish(notification("user", "2"))
+			hub.Publish(notification("other", "3"))
+			hub.Publish(notification("user", "4"))
+
+			sub := hub.Subscribe("user", testcase.lastEventID)
+			defer sub.Close()
+
+			assert.Equal(t, testcase.exp, drain(sub))
+		})
+	}
+}
+
+func TestHubDropsSlowSubscriber(t *testing.T) {
+	hub := stream.NewHub(1000)
+
+	sub := hub.Subscribe("user", 0)
+	for i := 0; i < 200; i++ {
+		hub.Publish(notification("user", "a"))
+	}
+
+	assert.Equal(t, 0, hub.Subscribers())
+
+	received := 0
+	for range sub.Events() {
+		received++
+	}
+	assert.Less(t, received, 200)
+
+	sub.Close()
+}
+
+func TestHubClose(t *testing.T) {
+	hub := stream.NewHub(10)
+
+	sub := hub.Subscribe("user", 0)
+	require.Equal(t, 1, hub.Subscribers())
+
+	sub.Close()
+	sub.Close()
+
+	assert.Equal(t, 0, hub.Subscribers())
+	_, ok := <-sub.Events()
+	assert.False(t, ok)
+}
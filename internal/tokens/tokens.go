@@ -0,0 +1,207 @@
+package tokens
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	defaultAccessTTL  = 15 * time.Minute
+	defaultRefreshTTL = 30 * 24 * time.Hour
+
+	typeAccess  = "access"
+	typeRefresh = "refresh"
+)
+
+var (
+	ErrNoKeys  = errors.New("jwt signing key is empty")
+	ErrInvalid = errors.New("token not valid")
+	ErrExpired = errors.New("token expired")
+)
+
+// Key - ключ подписи. ID уходит в заголовок kid, по нему при проверке выбирается ключ.
+type Key struct {
+	ID     string
+	Secret []byte
+}
+
+// NewKey выводит kid из самого секрета, поэтому его не нужно настраивать отдельно
+// и один и тот же секрет всегда дает один и тот же kid.
+func NewKey(secret string) Key {
+	sum := sha256.Sum256([]byte(secret))
+
+	return Key{ID: hex.EncodeToString(sum[:4]), Secret: []byte(secret)}
+}
+
+// ParseKeys разбирает список секретов через запятую. Первым подписываются новые токены,
+// остальные только проверяются - так старые токены доживают до конца срока после ротации.
+func ParseKeys(secrets string) ([]Key, error) {
+	var keys []Key
+	for _, s := range strings.Split(secrets, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			keys = append(keys, NewKey(s))
+		}
+	}
+	if len(keys) == 0 {
+		return nil, ErrNoKeys
+	}
+
+	return keys, nil
+}
+
+// Pair - выданные токены. Access подтверждает пользователя, refresh обменивается на новую пару.
+type Pair struct {
+	Access    string
+	Refresh   string
+	ExpiresIn time.Duration
+}
+
+type claims struct {
+	jwt.RegisteredClaims
+	Type string `json:"typ"`
+}
+
+type Issuer struct {
+	keys       []Key
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+	now        func() time.Time
+}
+
+type Option func(i *Issuer)
+
+func WithAccessTTL(ttl time.Duration) Option {
+	return func(i *Issuer) {
+		if ttl > 0 {
+			i.accessTTL = ttl
+		}
+	}
+}
+
+func WithRefreshTTL(ttl time.Duration) Option {
+	return func(i *Issuer) {
+		if ttl > 0 {
+			i.refreshTTL = ttl
+		}
+	}
+}
+
+// WithClock подменяет часы, в тестах так проверяется истечение срока.
+func WithClock(now func() time.Time) Option {
+	return func(i *Issuer) {
+		i.now = now
+	}
+}
+
+func New(keys []Key, opts ...Option) (*Issuer, error) {
+	if len(keys) == 0 {
+		return nil, ErrNoKeys
+	}
+	for _, k := range keys {
+		if len(k.Secret) == 0 {
+			return nil, ErrNoKeys
+		}
+	}
+
+	i := &Issuer{
+		keys:       keys,
+		accessTTL:  defaultAccessTTL,
+		refreshTTL: defaultRefreshTTL,
+		now:        time.Now,
+	}
+	for _, opt := range opts {
+		opt(i)
+	}
+
+	return i, nil
+}
+
+func (i *Issuer) RefreshTTL() time.Duration {
+	return i.refreshTTL
+}
+
+// Issue выдает пару токенов пользователю uid.
+func (i *Issuer) Issue(uid string) (Pair, error) {
+	access, err := i.sign(uid, typeAccess, i.accessTTL)
+	if err != nil {
+		return Pair{}, err
+	}
+	refresh, err := i.sign(uid, typeRefresh, i.refreshTTL)
+	if err != nil {
+		return Pair{}, err
+	}
+
+	return Pair{Access: access, Refresh: refresh, ExpiresIn: i.accessTTL}, nil
+}
+
+// UserID проверяет access-токен и возвращает пользователя.
+func (i *Issuer) UserID(access string) (string, error) {
+	return i.parse(access, typeAccess)
+}
+
+// Refresh меняет refresh-токен на новую пару. Старый refresh-токен не отзывается:
+// хранилища токенов нет, он живет до своего срока.
+func (i *Issuer) Refresh(refresh string) (Pair, error) {
+	uid, err := i.parse(refresh, typeRefresh)
+	if err != nil {
+		return Pair{}, err
+	}
+
+	return i.Issue(uid)
+}
+
+func (i *Issuer) sign(uid, typ string, ttl time.Duration) (string, error) {
+	now := i.now()
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   uid,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+		Type: typ,
+	})
+	token.Header["kid"] = i.keys[0].ID
+
+	ss, err := token.SignedString(i.keys[0].Secret)
+	if err != nil {
+		return "", fmt.Errorf("failed to create token for user %s: %w", uid, err)
+	}
+
+	return ss, nil
+}
+
+func (i *Issuer) parse(raw, typ string) (string, error) {
+	c := &claims{}
+	_, err := jwt.ParseWithClaims(raw, c, i.key,
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(i.now),
+	)
+	switch {
+	case errors.Is(err, jwt.ErrTokenExpired):
+		return "", ErrExpired
+	case err != nil:
+		return "", fmt.Errorf("%w: %v", ErrInvalid, err)
+	case c.Type != typ || c.Subject == "":
+		return "", fmt.Errorf("%w: %s token expected", ErrInvalid, typ)
+	}
+
+	return c.Subject, nil
+}
+
+func (i *Issuer) key(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	for _, k := range i.keys {
+		if k.ID == kid {
+			return k.Secret, nil
+		}
+	}
+
+	return nil, fmt.Errorf("unknown kid %q", kid)
+}
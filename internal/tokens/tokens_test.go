@@ -0,0 +1,170 @@
+package tokens_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/patrick-devel/shorturl/internal/tokens"
+)
+
+func newIssuer(t *testing.T, secrets string, opts ...tokens.Option) *tokens.Issuer {
+	t.Helper()
+
+	keys, err := tokens.ParseKeys(secrets)
+	require.NoError(t, err)
+	issuer, err := tokens.New(keys, opts...)
+	require.NoError(t, err)
+
+	return issuer
+}
+
+func TestIssueAndRefresh(t *testing.T) {
+	issuer := newIssuer(t, "secret", tokens.WithAccessTTL(time.Minute))
+
+	pair, err := issuer.Issue("user-1")
+	require.NoError(t, err)
+	assert.Equal(t, time.Minute, pair.ExpiresIn)
+
+	uid, err := issuer.UserID(pair.Access)
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", uid)
+
+	// токены не взаимозаменяемы
+	_, err = issuer.UserID(pair.Refresh)
+	assert.ErrorIs(t, err, tokens.ErrInvalid)
+	_, err = issuer.Refresh(pair.Access)
+	assert.ErrorIs(t, err, tokens.ErrInvalid)
+
+	next, err := issuer.Refresh(pair.Refresh)
+	require.NoError(t, err)
+	uid, err = issuer.UserID(next.Access)
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", uid)
+}
+
+func TestExpiry(t *testing.T) {
+	now := time.Now()
+	issuer := newIssuer(t, "secret", tokens.WithClock(func() time.Time { return now }),
+		tokens.WithAccessTTL(time.Minute), tokens.WithRefreshTTL(time.Hour))
+
+	pair, err := issuer.Issue("user-1")
+	require.NoError(t, err)
+
+	now = now.Add(2 * time.Minute)
+	_, err = issuer.UserID(pair.Access)
+	assert.ErrorIs(t, err, tokens.ErrExpired)
+
+	// refresh-токен еще жив и выдает новую пару
+	next, err := issuer.Refresh(pair.Refresh)
+	require.NoError(t, err)
+	_, err = issuer.UserID(next.Access)
+	assert.NoError(t, err)
+
+	now = now.Add(2 * time.Hour)
+	_, err = issuer.Refresh(pair.Refresh)
+	assert.ErrorIs(t, err, tokens.ErrExpired)
+}
+
+func TestKeyRotation(t *testing.T) {
+	old := newIssuer(t, "old")
+	pair, err := old.Issue("user-1")
+	require.NoError(t, err)
+
+	rotated := newIssuer(t, "new, old")
+	uid, err := rotated.UserID(pair.Access)
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", uid)
+
+	// новые токены подписаны новым ключом, старый сервис их не примет
+	fresh, err := rotated.Issue("user-1")
+	require.NoError(t, err)
+	_, err = old.UserID(fresh.Access)
+	assert.ErrorIs(t, err, tokens.ErrInvalid)
+
+	// после удаления старого ключа его токены недействительны
+	_, err = newIssuer(t, "new").UserID(pair.Access)
+	assert.ErrorIs(t, err, tokens.ErrInvalid)
+}
+
+func TestRejectsForeignTokens(t *testing.T) {
+	issuer := newIssuer(t, "secret")
+	key := tokens.NewKey("secret")
+	claims := jwt.MapClaims{"sub": "user-1", "typ": "access", "exp": time.Now().Add(time.Hour).Unix()}
+
+	tests := []struct {
+		name  string
+		token func() string
+	}{
+		{
+			name: "AlgNone",
+			token: func() string {
+				token := jwt.NewWithClaims(jwt.SigningMethodNone, claims)
+				token.Header["kid"] = key.ID
+				ss, err := token.SignedString(jwt.UnsafeAllowNoneSignatureType)
+				require.NoError(t, err)
+
+				return ss
+			},
+		},
+		{
+			name: "OtherHMAC",
+			token: func() string {
+				token := jwt.NewWithClaims(jwt.SigningMethodHS512, claims)
+				token.Header["kid"] = key.ID
+				ss, err := token.SignedString(key.Secret)
+				require.NoError(t, err)
+
+				return ss
+			},
+		},
+		{
+			name: "NoExpiry",
+			token: func() string {
+				token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{"sub": "user-1", "typ": "access"})
+				token.Header["kid"] = key.ID
+				ss, err := token.SignedString(key.Secret)
+				require.NoError(t, err)
+
+				return ss
+			},
+		},
+		{
+			name: "NoKid",
+			token: func() string {
+				ss, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(key.Secret)
+				require.NoError(t, err)
+
+				return ss
+			},
+		},
+		{
+			name: "Garbage",
+			token: func() string {
+				return "not-a-jwt"
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		testcase := tc
+		t.Run(testcase.name, func(t *testing.T) {
+			_, err := issuer.UserID(testcase.token())
+			assert.ErrorIs(t, err, tokens.ErrInvalid)
+		})
+	}
+}
+
+func TestNoKeys(t *testing.T) {
+	_, err := tokens.ParseKeys(" , ")
+	assert.ErrorIs(t, err, tokens.ErrNoKeys)
+
+	_, err = tokens.New(nil)
+	assert.ErrorIs(t, err, tokens.ErrNoKeys)
+
+	_, err = tokens.New([]tokens.Key{{ID: "k"}})
+	assert.ErrorIs(t, err, tokens.ErrNoKeys)
+}
@@ -0,0 +1,269 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+
+	"github.com/patrick-devel/shorturl/internal/models"
+	"github.com/patrick-devel/shorturl/internal/netguard"
+)
+
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+
+	defaultWorkers     = 4
+	defaultQueueSize   = 1024
+	defaultMaxAttempts = 5
+	defaultBaseDelay   = time.Second
+	defaultTimeout     = 10 * time.Second
+)
+
+type store interface {
+	ReadWebhooksByCreatorID(ctx context.Context, creatorID string) ([]models.Webhook, error)
+	WriteWebhookDelivery(ctx context.Context, delivery models.WebhookDelivery) error
+}
+
+// job - либо событие, которое надо разослать по вебхукам, либо доставка в один вебхук.
+type job struct {
+	notification models.Notification
+	webhook      *models.Webhook
+	deliveryID   string
+	payload      []byte
+	attempt      int
+}
+
+type Dispatcher struct {
+	storage     store
+	client      *http.Client
+	workers     int
+	maxAttempts int
+	baseDelay   time.Duration
+	queueSize   int
+
+	jobs chan job
+}
+
+type Option func(d *Dispatcher)
+
+func WithHTTPClient(client *http.Client) Option {
+	return func(d *Dispatcher) {
+		d.client = client
+	}
+}
+
+func WithRetry(maxAttempts int, baseDelay time.Duration) Option {
+	return func(d *Dispatcher) {
+		if maxAttempts > 0 {
+			d.maxAttempts = maxAttempts
+		}
+		d.baseDelay = baseDelay
+	}
+}
+
+func WithWorkers(n int) Option {
+	return func(d *Dispatcher) {
+		if n > 0 {
+			d.workers = n
+		}
+	}
+}
+
+// WithQueueSize - сколько событий и повторов ждут свободного воркера.
+func WithQueueSize(n int) Option {
+	return func(d *Dispatcher) {
+		if n > 0 {
+			d.queueSize = n
+		}
+	}
+}
+
+func New(storage store, opts ...Option) *Dispatcher {
+	d := &Dispatcher{
+		storage:     storage,
+		client:      netguard.NewClient(defaultTimeout),
+		workers:     defaultWorkers,
+		maxAttempts: defaultMaxAttempts,
+		baseDelay:   defaultBaseDelay,
+		queueSize:   defaultQueueSize,
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	d.jobs = make(chan job, d.queueSize)
+
+	return d
+}
+
+// Publish не блокирует вызывающего: если очередь переполнена, событие теряется с записью в лог.
+func (d *Dispatcher) Publish(n models.Notification) {
+	select {
+	case d.jobs <- job{notification: n}:
+	default:
+		logrus.Errorf("webhook queue is full, drop %s for %s", n.Type, n.ShortURL)
+	}
+}
+
+func (d *Dispatcher) Run(ctx context.Context) {
+	done := make(chan struct{})
+	for i := 0; i < d.workers; i++ {
+		go func() {
+			defer func() { done <- struct{}{} }()
+			d.work(ctx)
+		}()
+	}
+
+	for i := 0; i < d.workers; i++ {
+		<-done
+	}
+}
+
+func (d *Dispatcher) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case j := <-d.jobs:
+			if j.webhook == nil {
+				d.fanOut(ctx, j.notification)
+				continue
+			}
+			d.deliver(ctx, j)
+		}
+	}
+}
+
+func (d *Dispatcher) fanOut(ctx context.Context, n models.Notification) {
+	hooks, err := d.storage.ReadWebhooksByCreatorID(ctx, n.CreatorID)
+	if err != nil {
+		logrus.Errorf("read webhooks failed: %v", err)
+
+		return
+	}
+
+	payload, err := json.Marshal(n)
+	if err != nil {
+		logrus.Errorf("encode notification failed: %v", err)
+
+		return
+	}
+
+	for i := range hooks {
+		if !hooks[i].Subscribed(n.Type) {
+			continue
+		}
+		d.deliver(ctx, job{notification: n, webhook: &hooks[i], deliveryID: uuid.NewString(), payload: payload, attempt: 1})
+	}
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, j job) {
+	code, err := d.send(ctx, j)
+
+	delivery := models.WebhookDelivery{
+		ID:         j.deliveryID,
+		WebhookID:  j.webhook.ID,
+		Event:      j.notification.Type,
+		Payload:    j.payload,
+		Attempt:    j.attempt,
+		StatusCode: code,
+		CreatedAt:  time.Now().UTC(),
+	}
+	if err != nil {
+		delivery.Error = err.Error()
+	}
+
+	failed := err != nil || code < 200 || code >= 300
+	if failed && j.attempt >= d.maxAttempts {
+		delivery.Dead = true
+	}
+
+	if err := d.storage.WriteWebhookDelivery(ctx, delivery); err != nil {
+		logrus.Errorf("write webhook delivery failed: %v", err)
+	}
+
+	if failed && !delivery.Dead {
+		d.retry(ctx, j)
+	}
+}
+
+// retry откладывает следующую попытку: 1s, 2s, 4s... не занимая воркер на время ожидания.
+// Как и Publish, в полную очередь не ждет: таймеры с повторами копились бы без предела.
+// Такая доставка сразу записывается мертвой, ее видно в журнале вебхука.
+func (d *Dispatcher) retry(ctx context.Context, j job) {
+	j.attempt++
+	delay := d.baseDelay << (j.attempt - 2)
+
+	time.AfterFunc(delay, func() {
+		if ctx.Err() != nil {
+			return
+		}
+
+		select {
+		case d.jobs <- j:
+		default:
+			logrus.Errorf("webhook queue is full, drop delivery %s to %s", j.deliveryID, j.webhook.ID)
+			delivery := models.WebhookDelivery{
+				ID:        j.deliveryID,
+				WebhookID: j.webhook.ID,
+				Event:     j.notification.Type,
+				Payload:   j.payload,
+				Attempt:   j.attempt,
+				Error:     "webhook queue is full",
+				Dead:      true,
+				CreatedAt: time.Now().UTC(),
+			}
+			if err := d.storage.WriteWebhookDelivery(ctx, delivery); err != nil {
+				logrus.Errorf("write webhook delivery failed: %v", err)
+			}
+		}
+	})
+}
+
+func (d *Dispatcher) send(ctx context.Context, j job) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, j.webhook.URL, bytes.NewReader(j.payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, j.notification.Type)
+	req.Header.Set(HeaderDelivery, j.deliveryID)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(j.webhook.Secret, timestamp, j.payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("send webhook failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	return resp.StatusCode, nil
+}
+
+// Sign считает подпись тела запроса. Метка времени входит в подпись,
+// чтобы перехваченный запрос нельзя было повторить позже.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
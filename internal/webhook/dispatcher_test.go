@@ -0,0 +1,204 @@
+package webhook_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/patrick-devel/shorturl/internal/models"
+	"github.com/patrick-devel/shorturl/internal/webhook"
+)
+
+type fakeStore struct {
+	mu         sync.Mutex
+	webhooks   []models.Webhook
+	deliveries []models.WebhookDelivery
+}
+
+func (s *fakeStore) ReadWebhooksByCreatorID(_ context.Context, creatorID string) ([]models.Webhook, error) {
+	var webhooks []models.Webhook
+	for _, w := range s.webhooks {
+		if w.CreatorID == creatorID {
+			webhooks = append(webhooks, w)
+		}
+	}
+
+	return webhooks, nil
+}
+
+func (s *fakeStore) WriteWebhookDelivery(_ context.Context, delivery models.WebhookDelivery) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deliveries = append(s.deliveries, delivery)
+	return nil
+}
+
+func (s *fakeStore) log() []models.WebhookDelivery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]models.WebhookDelivery(nil), s.deliveries...)
+}
+
+func TestDispatcherDelivers(t *testing.T) {
+	type received struct {
+		header http.Header
+		body   []byte
+	}
+	requests := make(chan received, 10)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- received{header: r.Header.Clone(), body: body}
+	}))
+	defer server.Close()
+
+	store := &fakeStore{webhooks: []models.Webhook{
+		{ID: "all", CreatorID: "user", URL: server.URL, Secret: "s3cret"},
+		{ID: "deleted-only", CreatorID: "user", URL: server.URL, Secret: "s3cret", Events: []string{models.NotificationLinkDeleted}},
+		{ID: "other-user", CreatorID: "other", URL: server.URL, Secret: "s3cret"},
+	}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dispatcher := webhook.New(store, webhook.WithHTTPClient(server.Client()))
+	go dispatcher.Run(ctx)
+
+	dispatcher.Publish(models.Notification{
+		ID:        "n1",
+		Type:      models.NotificationLinkCreated,
+		CreatorID: "user",
+		ShortURL:  "http://localhost/abc",
+	})
+
+	var got received
+	select {
+	case got = <-requests:
+	case <-time.After(2 * time.Second):
+		t.Fatal("webhook was not delivered")
+	}
+
+	assert.Equal(t, models.NotificationLinkCreated, got.header.Get(webhook.HeaderEvent))
+	assert.Equal(t, webhook.Sign("s3cret", got.header.Get(webhook.HeaderTimestamp), got.body), got.header.Get(webhook.HeaderSignature))
+
+	var n models.Notification
+	require.NoError(t, json.Unmarshal(got.body, &n))
+	assert.Equal(t, "http://localhost/abc", n.ShortURL)
+
+	select {
+	case <-requests:
+		t.Fatal("unsubscribed webhook received event")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	require.Eventually(t, func() bool { return len(store.log()) == 1 }, time.Second, 10*time.Millisecond)
+	delivery := store.log()[0]
+	assert.Equal(t, "all", delivery.WebhookID)
+	assert.Equal(t, http.StatusOK, delivery.StatusCode)
+	assert.False(t, delivery.Dead)
+}
+
+func TestDispatcherRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		failFirst int32
+		expLog    int
+		expDead   bool
+	}{
+		{name: "RecoversAfterRetry", failFirst: 2, expLog: 3, expDead: false},
+		{name: "DeadLetter", failFirst: 100, expLog: 3, expDead: true},
+	}
+
+	for _, tc := range tests {
+		testcase := tc
+		t.Run(testcase.name, func(t *testing.T) {
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&calls, 1) <= testcase.failFirst {
+					w.WriteHeader(http.StatusServiceUnavailable)
+				}
+			}))
+			defer server.Close()
+
+			store := &fakeStore{webhooks: []models.Webhook{{ID: "w", CreatorID: "user", URL: server.URL, Secret: "s"}}}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			dispatcher := webhook.New(store,
+				webhook.WithHTTPClient(server.Client()),
+				webhook.WithRetry(3, 10*time.Millisecond),
+			)
+			go dispatcher.Run(ctx)
+
+			dispatcher.Publish(models.Notification{Type: models.NotificationLinkClicked, CreatorID: "user"})
+
+			require.Eventually(t, func() bool { return len(store.log()) == testcase.expLog }, 2*time.Second, 10*time.Millisecond)
+
+			log := store.log()
+			for i, d := range log {
+				assert.Equal(t, i+1, d.Attempt)
+				assert.Equal(t, log[0].ID, d.ID)
+			}
+			last := log[len(log)-1]
+			assert.Equal(t, testcase.expDead, last.Dead)
+			assert.NotEmpty(t, last.Payload)
+		})
+	}
+}
+
+func TestDispatcherRetryQueueFull(t *testing.T) {
+	release := make(chan struct{})
+	slowCalled := make(chan struct{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			slowCalled <- struct{}{}
+			<-release
+
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	defer close(release)
+
+	store := &fakeStore{webhooks: []models.Webhook{
+		{ID: "failing", CreatorID: "user", URL: server.URL + "/fail", Secret: "s"},
+		{ID: "slow", CreatorID: "user", URL: server.URL + "/slow", Secret: "s"},
+	}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dispatcher := webhook.New(store,
+		webhook.WithHTTPClient(server.Client()),
+		webhook.WithRetry(3, 200*time.Millisecond),
+		webhook.WithWorkers(1),
+		webhook.WithQueueSize(1),
+	)
+	go dispatcher.Run(ctx)
+
+	dispatcher.Publish(models.Notification{Type: models.NotificationLinkClicked, CreatorID: "user"})
+	// единственный воркер занят медленным вебхуком, очередь занимает следующее событие
+	<-slowCalled
+	dispatcher.Publish(models.Notification{Type: models.NotificationLinkClicked, CreatorID: "other"})
+
+	// повтор не ждет места в очереди, а записывается мертвым
+	require.Eventually(t, func() bool { return len(store.log()) == 2 }, 2*time.Second, 10*time.Millisecond)
+	log := store.log()
+	assert.Equal(t, "failing", log[1].WebhookID)
+	assert.Equal(t, 2, log[1].Attempt)
+	assert.True(t, log[1].Dead)
+	assert.Equal(t, log[0].ID, log[1].ID)
+}
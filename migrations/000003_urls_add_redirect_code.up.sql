@@ -0,0 +1,2 @@
+ALTER TABLE urls
+   ADD COLUMN redirect_code int NOT NULL DEFAULT 0;
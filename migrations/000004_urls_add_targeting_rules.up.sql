@@ -0,0 +1,2 @@
+ALTER TABLE urls
+   ADD COLUMN targeting_rules jsonb NOT NULL DEFAULT '[]';
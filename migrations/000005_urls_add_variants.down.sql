@@ -0,0 +1,3 @@
+ALTER TABLE urls
+    DROP COLUMN variants,
+    DROP COLUMN sticky_variants;
@@ -0,0 +1,3 @@
+ALTER TABLE urls
+   ADD COLUMN variants jsonb NOT NULL DEFAULT '[]',
+   ADD COLUMN sticky_variants bool NOT NULL DEFAULT false;
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS clicks (
+  id bigint generated always as identity primary key,
+  short_url text NOT NULL,
+  variant text NOT NULL DEFAULT '',
+  created_at timestamptz NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS clicks_short_url_idx ON clicks (short_url);
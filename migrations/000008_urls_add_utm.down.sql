@@ -0,0 +1,6 @@
+ALTER TABLE urls
+    DROP COLUMN utm_source,
+    DROP COLUMN utm_medium,
+    DROP COLUMN utm_campaign,
+    DROP COLUMN utm_term,
+    DROP COLUMN utm_content;
@@ -0,0 +1,6 @@
+ALTER TABLE urls
+   ADD COLUMN utm_source text NOT NULL DEFAULT '',
+   ADD COLUMN utm_medium text NOT NULL DEFAULT '',
+   ADD COLUMN utm_campaign text NOT NULL DEFAULT '',
+   ADD COLUMN utm_term text NOT NULL DEFAULT '',
+   ADD COLUMN utm_content text NOT NULL DEFAULT '';
@@ -0,0 +1,3 @@
+ALTER TABLE urls
+    DROP COLUMN created_at,
+    DROP COLUMN title;
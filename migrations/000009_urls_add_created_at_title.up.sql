@@ -0,0 +1,3 @@
+ALTER TABLE urls
+   ADD COLUMN created_at timestamptz NOT NULL DEFAULT now(),
+   ADD COLUMN title text NOT NULL DEFAULT '';
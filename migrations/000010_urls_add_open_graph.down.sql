@@ -0,0 +1,4 @@
+ALTER TABLE urls
+    DROP COLUMN og_title,
+    DROP COLUMN og_description,
+    DROP COLUMN og_image;
@@ -0,0 +1,4 @@
+ALTER TABLE urls
+   ADD COLUMN og_title text NOT NULL DEFAULT '',
+   ADD COLUMN og_description text NOT NULL DEFAULT '',
+   ADD COLUMN og_image text NOT NULL DEFAULT '';
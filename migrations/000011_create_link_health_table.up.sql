@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS link_health (
+  short_url text primary key,
+  status_code integer NOT NULL DEFAULT 0,
+  latency_ms bigint NOT NULL DEFAULT 0,
+  error text NOT NULL DEFAULT '',
+  checked_at timestamptz NOT NULL DEFAULT now()
+);
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS webhooks (
+  id text primary key,
+  creator_id text NOT NULL,
+  url text NOT NULL,
+  events jsonb NOT NULL DEFAULT '[]',
+  secret text NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS webhooks_creator_id_idx ON webhooks (creator_id);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+  id bigint generated always as identity primary key,
+  delivery_id text NOT NULL,
+  webhook_id text NOT NULL REFERENCES webhooks (id) ON DELETE CASCADE,
+  event text NOT NULL,
+  payload jsonb NOT NULL,
+  attempt integer NOT NULL,
+  status_code integer NOT NULL DEFAULT 0,
+  error text NOT NULL DEFAULT '',
+  dead boolean NOT NULL DEFAULT false,
+  created_at timestamptz NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS webhook_deliveries_webhook_id_idx ON webhook_deliveries (webhook_id);
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS api_keys (
+  id text primary key,
+  creator_id text NOT NULL,
+  name text NOT NULL DEFAULT '',
+  prefix text NOT NULL,
+  hash text NOT NULL UNIQUE,
+  scopes jsonb NOT NULL DEFAULT '[]',
+  created_at timestamptz NOT NULL DEFAULT now(),
+  last_used_at timestamptz
+);
+CREATE INDEX IF NOT EXISTS api_keys_creator_id_idx ON api_keys (creator_id);
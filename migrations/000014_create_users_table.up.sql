@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS users (
+  id text primary key,
+  email text NOT NULL UNIQUE,
+  password_hash text NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT now()
+);
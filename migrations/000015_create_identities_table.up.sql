@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS identities (
+  issuer text NOT NULL,
+  subject text NOT NULL,
+  user_id text NOT NULL,
+  email text NOT NULL DEFAULT '',
+  created_at timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY (issuer, subject)
+);
+CREATE INDEX IF NOT EXISTS identities_user_id_idx ON identities (user_id);
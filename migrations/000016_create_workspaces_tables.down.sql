@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS urls_workspace_id_idx;
+ALTER TABLE urls
+   DROP COLUMN workspace_id;
+DROP TABLE IF EXISTS workspace_members;
+DROP TABLE IF EXISTS workspaces;
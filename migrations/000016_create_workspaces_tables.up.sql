@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS workspaces (
+  id text primary key,
+  name text NOT NULL,
+  created_at timestamptz NOT NULL DEFAULT now()
+);
+CREATE TABLE IF NOT EXISTS workspace_members (
+  workspace_id text NOT NULL REFERENCES workspaces (id) ON DELETE CASCADE,
+  user_id text NOT NULL,
+  role text NOT NULL,
+  added_at timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY (workspace_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS workspace_members_user_id_idx ON workspace_members (user_id);
+ALTER TABLE urls
+   ADD COLUMN workspace_id text NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS urls_workspace_id_idx ON urls (workspace_id);
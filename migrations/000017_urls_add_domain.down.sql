@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS urls_domain_original_url_idx;
+ALTER TABLE urls
+   ADD CONSTRAINT urls_original_url_key UNIQUE (original_url);
+ALTER TABLE urls
+   DROP COLUMN domain;
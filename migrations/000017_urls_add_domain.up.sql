@@ -0,0 +1,6 @@
+ALTER TABLE urls
+   ADD COLUMN domain text NOT NULL DEFAULT '';
+-- один и тот же адрес можно сократить на каждом домене
+ALTER TABLE urls
+   DROP CONSTRAINT IF EXISTS urls_original_url_key;
+CREATE UNIQUE INDEX IF NOT EXISTS urls_domain_original_url_idx ON urls (domain, original_url);
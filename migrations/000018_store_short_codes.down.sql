@@ -0,0 +1,22 @@
+-- полный адрес не восстановить: BASE_URL при откате неизвестен, в short_url остаются коды
+ALTER TABLE link_health
+   DROP CONSTRAINT IF EXISTS link_health_pkey;
+DELETE FROM link_health a USING link_health b
+   WHERE a.code = b.code AND (a.checked_at, a.ctid) < (b.checked_at, b.ctid);
+ALTER TABLE link_health
+   RENAME COLUMN code TO short_url;
+ALTER TABLE link_health
+   ADD PRIMARY KEY (short_url);
+ALTER TABLE link_health
+   DROP COLUMN domain;
+
+DROP INDEX IF EXISTS clicks_domain_code_idx;
+ALTER TABLE clicks
+   RENAME COLUMN code TO short_url;
+ALTER TABLE clicks
+   DROP COLUMN domain;
+CREATE INDEX IF NOT EXISTS clicks_short_url_idx ON clicks (short_url);
+
+DROP INDEX IF EXISTS urls_domain_code_idx;
+ALTER TABLE urls
+   RENAME COLUMN code TO short_url;
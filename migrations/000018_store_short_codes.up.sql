@@ -0,0 +1,36 @@
+-- короткий адрес зависит от BASE_URL и собирается при ответе, в таблицах остается только код ссылки
+ALTER TABLE clicks
+   ADD COLUMN domain text NOT NULL DEFAULT '';
+ALTER TABLE link_health
+   ADD COLUMN domain text NOT NULL DEFAULT '';
+UPDATE clicks SET domain = urls.domain FROM urls WHERE urls.short_url = clicks.short_url;
+UPDATE link_health SET domain = urls.domain FROM urls WHERE urls.short_url = link_health.short_url;
+
+UPDATE urls SET short_url = regexp_replace(short_url, '^.*/', '');
+UPDATE clicks SET short_url = regexp_replace(short_url, '^.*/', '');
+UPDATE link_health SET short_url = regexp_replace(short_url, '^.*/', '');
+
+ALTER TABLE urls
+   RENAME COLUMN short_url TO code;
+ALTER TABLE clicks
+   RENAME COLUMN short_url TO code;
+ALTER TABLE link_health
+   RENAME COLUMN short_url TO code;
+
+-- коды из старого хеша могли совпасть у разных адресов, а без BASE_URL совпадают и коды, сохраненные
+-- под разными адресами сервиса. Код остается у первой ссылки, остальные получают новый: дефиса в кодах
+-- sqids нет, а uid уникален, так что новый код ни с чем не совпадет.
+UPDATE urls SET code = urls.code || '-' || urls.uid
+   FROM urls first
+   WHERE first.domain = urls.domain AND first.code = urls.code AND first.uid < urls.uid;
+CREATE UNIQUE INDEX IF NOT EXISTS urls_domain_code_idx ON urls (domain, code);
+DROP INDEX IF EXISTS clicks_short_url_idx;
+CREATE INDEX IF NOT EXISTS clicks_domain_code_idx ON clicks (domain, code);
+
+-- после смены BASE_URL у одного кода могли остаться проверки под разными адресами, оставляем последнюю
+DELETE FROM link_health a USING link_health b
+   WHERE a.domain = b.domain AND a.code = b.code AND (a.checked_at, a.ctid) < (b.checked_at, b.ctid);
+ALTER TABLE link_health
+   DROP CONSTRAINT IF EXISTS link_health_pkey;
+ALTER TABLE link_health
+   ADD PRIMARY KEY (domain, code);
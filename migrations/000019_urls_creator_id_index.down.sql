@@ -0,0 +1 @@
+DROP INDEX IF EXISTS urls_creator_id_idx;
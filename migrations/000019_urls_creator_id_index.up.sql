@@ -0,0 +1,2 @@
+-- квота считает ссылки пользователя при каждом сокращении
+CREATE INDEX IF NOT EXISTS urls_creator_id_idx ON urls (creator_id) WHERE is_deleted = false;
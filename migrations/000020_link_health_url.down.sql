@@ -0,0 +1 @@
+ALTER TABLE link_health DROP COLUMN IF EXISTS url;
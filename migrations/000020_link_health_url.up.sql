@@ -0,0 +1,2 @@
+-- у ссылки с таргетингом и вариантами проверяется несколько адресов, храним тот, что не открылся
+ALTER TABLE link_health ADD COLUMN IF NOT EXISTS url text NOT NULL DEFAULT '';
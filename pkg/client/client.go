@@ -0,0 +1,491 @@
+// Package client - Go SDK для HTTP API сокращателя ссылок.
+//
+// Токен, который выдает сервис при первом сокращении, клиент запоминает сам и отправляет
+// в следующих запросах, поэтому List и Delete видят ссылки, созданные этим же клиентом.
+// Истекший токен клиент обновляет по refresh-токену и повторяет запрос.
+package client
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultTimeout = 10 * time.Second
+	defaultRetries = 3
+	defaultBackoff = 100 * time.Millisecond
+	maxBackoff     = 5 * time.Second
+
+	tokenCookie   = "user_id_signed"
+	refreshCookie = "user_id_refresh"
+	refreshHeader = "X-Refresh-Token"
+	refreshPath   = "/api/auth/refresh"
+	registerPath  = "/api/auth/register"
+	loginPath     = "/api/auth/login"
+	apiKeyHeader  = "X-API-Key"
+
+	codeTokenExpired = "token_expired"
+	codeEmailTaken   = "email_taken"
+)
+
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	retries    int
+	backoff    time.Duration
+	apiKey     string
+
+	mu           sync.RWMutex
+	token        string
+	refreshToken string
+}
+
+type Option func(c *Client)
+
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithToken - токен, выданный раньше. Без него сервис выдаст новый при первом сокращении.
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithRefreshToken - refresh-токен, выданный вместе с токеном из WithToken.
+func WithRefreshToken(token string) Option {
+	return func(c *Client) {
+		c.refreshToken = token
+	}
+}
+
+// WithAPIKey - ключ из POST /api/user/keys для интеграций между сервисами. С ключом токены не нужны.
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.apiKey = key
+	}
+}
+
+// WithRetries - сколько раз повторить запрос после сетевой ошибки, 429 или 5xx.
+// Пауза растет вдвое с каждой попыткой, начиная с backoff.
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(c *Client) {
+		c.retries = retries
+		c.backoff = backoff
+	}
+}
+
+// New создает клиент. baseURL - корень сервиса, например http://localhost:8080.
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.ParseRequestURI(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base url: %w", err)
+	}
+
+	c := &Client{
+		baseURL:    u,
+		httpClient: &http.Client{Timeout: defaultTimeout},
+		retries:    defaultRetries,
+		backoff:    defaultBackoff,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c, nil
+}
+
+// Token - текущий токен. Его стоит сохранить, чтобы позже работать с теми же ссылками.
+func (c *Client) Token() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.token
+}
+
+// RefreshToken - текущий refresh-токен, хранить его нужно вместе с Token.
+func (c *Client) RefreshToken() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.refreshToken
+}
+
+// Refresh меняет refresh-токен на новую пару. Вызывать его вручную не обязательно:
+// клиент делает это сам, когда сервис отвечает token_expired.
+func (c *Client) Refresh(ctx context.Context) error {
+	request := struct {
+		RefreshToken string `json:"refresh_token"`
+	}{RefreshToken: c.RefreshToken()}
+
+	return c.call(ctx, http.MethodPost, refreshPath, request, nil)
+}
+
+// Register заводит аккаунт. Ссылки, созданные клиентом до этого анонимно, остаются за аккаунтом,
+// а токены меняются на токены пользователя.
+func (c *Client) Register(ctx context.Context, email, password string) (User, error) {
+	return c.session(ctx, registerPath, email, password)
+}
+
+// Login входит в аккаунт. Анонимные ссылки клиента переходят к аккаунту.
+func (c *Client) Login(ctx context.Context, email, password string) (User, error) {
+	return c.session(ctx, loginPath, email, password)
+}
+
+func (c *Client) session(ctx context.Context, path, email, password string) (User, error) {
+	request := struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+	}{Email: email, Password: password}
+	var response struct {
+		User User `json:"user"`
+	}
+
+	if err := c.call(ctx, http.MethodPost, path, request, &response); err != nil {
+		return User{}, err
+	}
+
+	return response.User, nil
+}
+
+type User struct {
+	ID        string    `json:"id"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type BatchItem struct {
+	CorrelationID string `json:"correlation_id"`
+	OriginalURL   string `json:"original_url"`
+}
+
+type BatchResult struct {
+	CorrelationID string `json:"correlation_id"`
+	ShortURL      string `json:"short_url"`
+	// Duplicate - URL уже был сокращен, ShortURL - существующая ссылка.
+	Duplicate bool `json:"duplicate,omitempty"`
+}
+
+type Link struct {
+	ShortURL    string `json:"short_url"`
+	OriginalURL string `json:"original_url"`
+}
+
+// Shorten сокращает URL. Если он уже был сокращен, возвращается существующая ссылка вместе с ErrDuplicate.
+func (c *Client) Shorten(ctx context.Context, originalURL string) (string, error) {
+	request := struct {
+		URL string `json:"url"`
+	}{URL: originalURL}
+	var response struct {
+		Result string `json:"result"`
+	}
+
+	err := c.call(ctx, http.MethodPost, "/api/shorten", request, &response)
+	if err != nil {
+		var apiErr *Error
+		if errors.As(err, &apiErr) && apiErr.Result != "" {
+			return apiErr.Result, err
+		}
+
+		return "", err
+	}
+
+	return response.Result, nil
+}
+
+func (c *Client) ShortenBatch(ctx context.Context, items []BatchItem) ([]BatchResult, error) {
+	var results []BatchResult
+	if err := c.call(ctx, http.MethodPost, "/api/shorten/batch", items, &results); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// List возвращает ссылки владельца токена. Пустой список - не ошибка.
+func (c *Client) List(ctx context.Context) ([]Link, error) {
+	var links []Link
+	if err := c.call(ctx, http.MethodGet, "/api/user/urls", nil, &links); err != nil {
+		return nil, err
+	}
+
+	return links, nil
+}
+
+// Delete удаляет ссылки по кодам или полным коротким ссылкам. Код без адреса относится к основному домену,
+// ссылки других коротких доменов нужно передавать целиком. Сервис удаляет асинхронно:
+// ссылки начинают отдавать ErrGone не сразу.
+func (c *Client) Delete(ctx context.Context, codes ...string) error {
+	if len(codes) == 0 {
+		return c.call(ctx, http.MethodDelete, "/api/user/urls", []string{}, nil)
+	}
+
+	// сервис удаляет за один запрос ссылки одного домена
+	var domains []string
+	byDomain := map[string][]string{}
+	for _, code := range codes {
+		domain := ""
+		if u, err := url.Parse(code); err == nil && u.IsAbs() {
+			code = path.Base(u.Path)
+			if !strings.EqualFold(u.Host, c.baseURL.Host) {
+				domain = u.Host
+			}
+		}
+		if _, ok := byDomain[domain]; !ok {
+			domains = append(domains, domain)
+		}
+		byDomain[domain] = append(byDomain[domain], code)
+	}
+
+	for _, domain := range domains {
+		target := "/api/user/urls"
+		if domain != "" {
+			target += "?" + url.Values{"domain": {domain}}.Encode()
+		}
+		if err := c.call(ctx, http.MethodDelete, target, byDomain[domain], nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Resolve возвращает адрес, куда ведет ссылка, не переходя по нему.
+// Принимает код или полную короткую ссылку.
+func (c *Client) Resolve(ctx context.Context, code string) (string, error) {
+	target := c.baseURL.JoinPath(code).String()
+	if u, err := url.Parse(code); err == nil && u.IsAbs() {
+		target = code
+	}
+
+	noRedirect := *c.httpClient
+	noRedirect.CheckRedirect = func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+
+	resp, body, err := c.send(ctx, &noRedirect, http.MethodGet, target, nil)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case resp.StatusCode >= http.StatusBadRequest:
+		return "", newError(resp.StatusCode, body)
+	case resp.StatusCode >= http.StatusMultipleChoices && resp.Header.Get("Location") != "":
+		return resp.Header.Get("Location"), nil
+	default:
+		return "", fmt.Errorf("shortener: unexpected status %d for %s", resp.StatusCode, code)
+	}
+}
+
+func (c *Client) call(ctx context.Context, method, path string, in, out any) error {
+	var payload []byte
+	if in != nil {
+		var err error
+		if payload, err = json.Marshal(in); err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+	}
+
+	endpoint, query, _ := strings.Cut(path, "?")
+	u := c.baseURL.JoinPath(endpoint)
+	u.RawQuery = query
+	target := u.String()
+	resp, body, err := c.send(ctx, c.httpClient, method, target, payload)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		apiErr := newError(resp.StatusCode, body)
+		if apiErr.Code != codeTokenExpired || path == refreshPath || c.RefreshToken() == "" {
+			return apiErr
+		}
+
+		if err := c.Refresh(ctx); err != nil {
+			return err
+		}
+		if resp, body, err = c.send(ctx, c.httpClient, method, target, payload); err != nil {
+			return err
+		}
+		if resp.StatusCode >= http.StatusBadRequest {
+			return newError(resp.StatusCode, body)
+		}
+	}
+
+	if out == nil || len(body) == 0 || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return nil
+}
+
+// send отправляет запрос с повторами. Тело JSON уходит сжатым, ответ возвращается уже прочитанным.
+func (c *Client) send(ctx context.Context, httpClient *http.Client, method, target string,
+	payload []byte) (*http.Response, []byte, error) {
+	var compressed []byte
+	if payload != nil {
+		var err error
+		if compressed, err = gzipBytes(payload); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, body, err := c.try(ctx, httpClient, method, target, compressed)
+		if attempt >= c.retries || !retryable(ctx, resp, err) {
+			return resp, body, err
+		}
+
+		timer := time.NewTimer(c.delay(attempt, resp))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return nil, nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (c *Client) try(ctx context.Context, httpClient *http.Client, method, target string,
+	compressed []byte) (*http.Response, []byte, error) {
+	var reqBody io.Reader
+	if compressed != nil {
+		reqBody = bytes.NewReader(compressed)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, reqBody)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	if compressed != nil {
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	// заголовок выставлен явно, поэтому транспорт не распаковывает ответ сам
+	req.Header.Set("Accept-Encoding", "gzip")
+	if c.apiKey != "" {
+		req.Header.Set(apiKeyHeader, c.apiKey)
+	} else if token := c.Token(); token != "" {
+		req.Header.Set("Authorization", token)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	c.rememberToken(resp)
+
+	body, err := readBody(resp)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	return resp, body, nil
+}
+
+// rememberToken сохраняет токены, выданные AuthMiddleware: они приходят в заголовках и в cookie.
+func (c *Client) rememberToken(resp *http.Response) {
+	token := fromResponse(resp, "Authorization", tokenCookie)
+	refresh := fromResponse(resp, refreshHeader, refreshCookie)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if token != "" {
+		c.token = token
+	}
+	if refresh != "" {
+		c.refreshToken = refresh
+	}
+}
+
+func fromResponse(resp *http.Response, header, cookieName string) string {
+	if value := resp.Header.Get(header); value != "" {
+		return value
+	}
+	for _, cookie := range resp.Cookies() {
+		if cookie.Name == cookieName {
+			return cookie.Value
+		}
+	}
+
+	return ""
+}
+
+func (c *Client) delay(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 {
+			return min(time.Duration(seconds)*time.Second, maxBackoff)
+		}
+	}
+
+	d := min(c.backoff<<attempt, maxBackoff)
+	if d <= 0 {
+		return 0
+	}
+
+	// разброс, чтобы клиенты после сбоя не приходили все разом
+	return d/2 + rand.N(d/2+1)
+}
+
+func retryable(ctx context.Context, resp *http.Response, err error) bool {
+	if err != nil {
+		return ctx.Err() == nil
+	}
+
+	return resp.StatusCode == http.StatusTooManyRequests ||
+		(resp.StatusCode >= http.StatusInternalServerError && resp.StatusCode != http.StatusNotImplemented)
+}
+
+func readBody(resp *http.Response) ([]byte, error) {
+	if !strings.EqualFold(resp.Header.Get("Content-Encoding"), "gzip") {
+		return io.ReadAll(resp.Body)
+	}
+
+	zr, err := gzip.NewReader(resp.Body)
+	if errors.Is(err, io.EOF) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	return io.ReadAll(zr)
+}
+
+func gzipBytes(payload []byte) ([]byte, error) {
+	var buf bytes.Buffer
+
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(payload); err != nil {
+		return nil, fmt.Errorf("failed to compress request: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress request: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
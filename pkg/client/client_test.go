@@ -0,0 +1,426 @@
+package client_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/patrick-devel/shorturl/internal/models"
+	"github.com/patrick-devel/shorturl/internal/router"
+	"github.com/patrick-devel/shorturl/internal/service"
+	"github.com/patrick-devel/shorturl/internal/storage"
+	"github.com/patrick-devel/shorturl/internal/stream"
+	"github.com/patrick-devel/shorturl/internal/tokens"
+	"github.com/patrick-devel/shorturl/pkg/client"
+)
+
+// newServer поднимает сервис целиком: настоящий роутер, сервис и хранилище в памяти.
+// wrap позволяет вклиниться перед роутером.
+func newServer(t *testing.T, wrap func(http.Handler) http.Handler, opts ...tokens.Option) *httptest.Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	srv := httptest.NewUnstartedServer(nil)
+	baseURL := &url.URL{Scheme: "http", Host: srv.Listener.Addr().String()}
+
+	logger := logrus.New()
+	logger.SetLevel(logrus.ErrorLevel)
+
+	shortService := service.New(baseURL, storage.NewMemoryStorage(map[models.LinkRef]models.Event{}), ctx)
+	issuer, err := tokens.New([]tokens.Key{tokens.NewKey("test-secret")}, opts...)
+	require.NoError(t, err)
+	mux, err := router.New(shortService, stream.NewHub(0), issuer, logger)
+	require.NoError(t, err)
+
+	var handler http.Handler = mux
+	if wrap != nil {
+		handler = wrap(mux)
+	}
+	srv.Config.Handler = handler
+	srv.Start()
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestClientFlow(t *testing.T) {
+	srv := newServer(t, nil)
+	ctx := context.Background()
+
+	c, err := client.New(srv.URL)
+	require.NoError(t, err)
+
+	shortURL, err := c.Shorten(ctx, "https://practicum.yandex.ru/")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(shortURL, srv.URL+"/"))
+	require.NotEmpty(t, c.Token(), "token issued by the server must be remembered")
+
+	results, err := c.ShortenBatch(ctx, []client.BatchItem{
+		{CorrelationID: "a", OriginalURL: "https://go.dev/"},
+		{CorrelationID: "b", OriginalURL: "https://pkg.go.dev/"},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "a", results[0].CorrelationID)
+
+	links, err := c.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, links, 3)
+
+	code := strings.TrimPrefix(shortURL, srv.URL+"/")
+	destination, err := c.Resolve(ctx, code)
+	require.NoError(t, err)
+	assert.Equal(t, "https://practicum.yandex.ru/", destination)
+
+	destination, err = c.Resolve(ctx, results[1].ShortURL)
+	require.NoError(t, err)
+	assert.Equal(t, "https://pkg.go.dev/", destination)
+
+	// чужой клиент с тем же токеном видит те же ссылки
+	same, err := client.New(srv.URL, client.WithToken(c.Token()))
+	require.NoError(t, err)
+	links, err = same.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, links, 3)
+
+	// удаление асинхронное, а хранилище в памяти пометки не хранит - проверяем только, что запрос принят
+	assert.NoError(t, c.Delete(ctx, code))
+}
+
+func TestClientDeleteDomains(t *testing.T) {
+	var mu sync.Mutex
+	var domains []string
+	srv := newServer(t, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == http.MethodDelete {
+				mu.Lock()
+				domains = append(domains, r.URL.Query().Get("domain"))
+				mu.Unlock()
+				w.WriteHeader(http.StatusAccepted)
+
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+
+	c, err := client.New(srv.URL)
+	require.NoError(t, err)
+
+	// ссылки основного домена уходят одним запросом, ссылки другого домена - отдельным с его именем
+	require.NoError(t, c.Delete(context.Background(), "abc", srv.URL+"/def", "https://go.example.com/xyz"))
+	assert.Equal(t, []string{"", "go.example.com"}, domains)
+}
+
+func TestClientErrors(t *testing.T) {
+	srv := newServer(t, nil)
+	ctx := context.Background()
+
+	c, err := client.New(srv.URL, client.WithRetries(0, 0))
+	require.NoError(t, err)
+
+	_, err = c.List(ctx)
+	assert.ErrorIs(t, err, client.ErrUnauthorized)
+
+	_, err = c.Resolve(ctx, "missing")
+	assert.ErrorIs(t, err, client.ErrNotFound)
+
+	_, err = c.Shorten(ctx, srv.URL+"/loop")
+	require.ErrorIs(t, err, client.ErrPolicyBlocked)
+	var apiErr *client.Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusUnprocessableEntity, apiErr.StatusCode)
+	assert.Equal(t, "policy_blocked", apiErr.Code)
+
+	_, err = c.ShortenBatch(ctx, nil)
+	assert.ErrorIs(t, err, client.ErrBadRequest)
+
+	bad, err := client.New(srv.URL, client.WithToken("garbage"), client.WithRetries(0, 0))
+	require.NoError(t, err)
+	_, err = bad.List(ctx)
+	assert.ErrorIs(t, err, client.ErrUnauthorized)
+}
+
+func TestClientStatusMapping(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		expErr error
+	}{
+		{
+			name:   "Duplicate",
+			status: http.StatusConflict,
+			body:   `{"status":409,"code":"duplicate","title":"URL is already shortened","result":"http://short/abc"}`,
+			expErr: client.ErrDuplicate,
+		},
+		{
+			name:   "Forbidden",
+			status: http.StatusForbidden,
+			body:   `{"status":403,"code":"forbidden"}`,
+			expErr: client.ErrForbidden,
+		},
+		{
+			name:   "RateLimited",
+			status: http.StatusTooManyRequests,
+			expErr: client.ErrRateLimited,
+		},
+		{
+			name:   "NotProblemBody",
+			status: http.StatusBadGateway,
+			body:   `<html>bad gateway</html>`,
+			expErr: client.ErrServer,
+		},
+	}
+
+	for _, tc := range tests {
+		testcase := tc
+		t.Run(testcase.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/problem+json")
+				w.WriteHeader(testcase.status)
+				_, _ = w.Write([]byte(testcase.body))
+			}))
+			defer srv.Close()
+
+			c, err := client.New(srv.URL, client.WithRetries(0, 0))
+			require.NoError(t, err)
+
+			shortURL, err := c.Shorten(context.Background(), "https://practicum.yandex.ru/")
+			require.ErrorIs(t, err, testcase.expErr)
+
+			var apiErr *client.Error
+			require.ErrorAs(t, err, &apiErr)
+			assert.Equal(t, testcase.status, apiErr.StatusCode)
+			if testcase.expErr == client.ErrDuplicate {
+				assert.Equal(t, "http://short/abc", shortURL)
+			}
+		})
+	}
+}
+
+func TestClientRetriesAndGzip(t *testing.T) {
+	var failures atomic.Int32
+	var gzipped atomic.Bool
+
+	srv := newServer(t, func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Content-Encoding") == "gzip" {
+				gzipped.Store(true)
+			}
+			if failures.Add(-1) >= 0 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	})
+	ctx := context.Background()
+
+	failures.Store(2)
+	c, err := client.New(srv.URL, client.WithRetries(3, time.Millisecond))
+	require.NoError(t, err)
+	shortURL, err := c.Shorten(ctx, "https://practicum.yandex.ru/")
+	require.NoError(t, err)
+	assert.NotEmpty(t, shortURL)
+	assert.True(t, gzipped.Load(), "request body must be sent compressed")
+
+	failures.Store(2)
+	impatient, err := client.New(srv.URL, client.WithRetries(1, time.Millisecond))
+	require.NoError(t, err)
+	_, err = impatient.Shorten(ctx, "https://go.dev/")
+	assert.ErrorIs(t, err, client.ErrServer)
+
+	failures.Store(5)
+	cancelled, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	slow, err := client.New(srv.URL, client.WithRetries(10, time.Second))
+	require.NoError(t, err)
+	_, err = slow.List(cancelled)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestClientRefresh(t *testing.T) {
+	var offset atomic.Int64
+	clock := func() time.Time { return time.Now().Add(time.Duration(offset.Load())) }
+
+	srv := newServer(t, nil, tokens.WithClock(clock), tokens.WithAccessTTL(time.Minute), tokens.WithRefreshTTL(time.Hour))
+	ctx := context.Background()
+
+	c, err := client.New(srv.URL, client.WithRetries(0, 0))
+	require.NoError(t, err)
+	_, err = c.Shorten(ctx, "https://practicum.yandex.ru/")
+	require.NoError(t, err)
+	require.NotEmpty(t, c.RefreshToken())
+	token := c.Token()
+
+	// access истек - клиент сам обновляет пару и повторяет запрос
+	offset.Store(int64(10 * time.Minute))
+	links, err := c.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, links, 1)
+	assert.NotEqual(t, token, c.Token())
+
+	// без refresh-токена истекший токен - обычная ошибка авторизации
+	offset.Store(int64(20 * time.Minute))
+	stale, err := client.New(srv.URL, client.WithToken(token), client.WithRetries(0, 0))
+	require.NoError(t, err)
+	_, err = stale.List(ctx)
+	var apiErr *client.Error
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "token_expired", apiErr.Code)
+
+	// истек и refresh - пользователю остается только новый токен
+	offset.Store(int64(2 * time.Hour))
+	_, err = c.List(ctx)
+	assert.ErrorIs(t, err, client.ErrUnauthorized)
+}
+
+func TestClientAPIKey(t *testing.T) {
+	srv := newServer(t, nil)
+	ctx := context.Background()
+
+	owner, err := client.New(srv.URL)
+	require.NoError(t, err)
+	_, err = owner.Shorten(ctx, "https://practicum.yandex.ru/")
+	require.NoError(t, err)
+
+	createKey := func(body string) models.APIKey {
+		req, err := http.NewRequest(http.MethodPost, srv.URL+"/api/user/keys", strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", owner.Token())
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusCreated, resp.StatusCode)
+
+		var key models.APIKey
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&key))
+		require.True(t, strings.HasPrefix(key.Key, key.Prefix))
+
+		return key
+	}
+
+	full := createKey(`{"name": "crm"}`)
+	shortenOnly := createKey(`{"scopes": ["shorten"]}`)
+
+	integration, err := client.New(srv.URL, client.WithAPIKey(full.Key), client.WithRetries(0, 0))
+	require.NoError(t, err)
+	_, err = integration.Shorten(ctx, "https://go.dev/")
+	require.NoError(t, err)
+	// ключ работает от имени владельца и не заводит нового пользователя
+	assert.Empty(t, integration.Token())
+	links, err := integration.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, links, 2)
+
+	limited, err := client.New(srv.URL, client.WithAPIKey(shortenOnly.Key), client.WithRetries(0, 0))
+	require.NoError(t, err)
+	_, err = limited.Shorten(ctx, "https://pkg.go.dev/")
+	require.NoError(t, err)
+	_, err = limited.List(ctx)
+	assert.ErrorIs(t, err, client.ErrForbidden)
+
+	req, err := http.NewRequest(http.MethodGet, srv.URL+"/api/user/keys", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", owner.Token())
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	var keys []models.APIKey
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&keys))
+	resp.Body.Close()
+	require.Len(t, keys, 2)
+	for _, k := range keys {
+		assert.Empty(t, k.Key, "key value is shown only once")
+		assert.NotNil(t, k.LastUsedAt)
+	}
+
+	req, err = http.NewRequest(http.MethodDelete, srv.URL+"/api/user/keys/"+full.ID, nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", owner.Token())
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+
+	// отозванный ключ не принимается, даже для POST, где анонимам выдается токен
+	_, err = integration.Shorten(ctx, "https://go.dev/doc/")
+	assert.ErrorIs(t, err, client.ErrUnauthorized)
+	_, err = integration.List(ctx)
+	assert.ErrorIs(t, err, client.ErrUnauthorized)
+}
+
+func TestClientAccounts(t *testing.T) {
+	srv := newServer(t, nil)
+	ctx := context.Background()
+
+	// ссылка, созданная до регистрации, остается за аккаунтом
+	anonymous, err := client.New(srv.URL, client.WithRetries(0, 0))
+	require.NoError(t, err)
+	_, err = anonymous.Shorten(ctx, "https://practicum.yandex.ru/")
+	require.NoError(t, err)
+
+	user, err := anonymous.Register(ctx, " User@Example.com ", "correct horse")
+	require.NoError(t, err)
+	assert.Equal(t, "user@example.com", user.Email)
+	links, err := anonymous.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, links, 1)
+
+	_, err = anonymous.Register(ctx, "user@example.com", "another password")
+	assert.ErrorIs(t, err, client.ErrEmailTaken)
+
+	// на другом устройстве анонимные ссылки переходят к аккаунту при входе
+	device, err := client.New(srv.URL, client.WithRetries(0, 0))
+	require.NoError(t, err)
+	_, err = device.Shorten(ctx, "https://go.dev/")
+	require.NoError(t, err)
+
+	_, err = device.Login(ctx, "user@example.com", "wrong password")
+	assert.ErrorIs(t, err, client.ErrUnauthorized)
+	_, err = device.Login(ctx, "nobody@example.com", "correct horse")
+	assert.ErrorIs(t, err, client.ErrUnauthorized)
+
+	same, err := device.Login(ctx, "user@example.com", "correct horse")
+	require.NoError(t, err)
+	assert.Equal(t, user.ID, same.ID)
+	links, err = device.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, links, 2)
+
+	// токен аккаунта не отдает его ссылки другому аккаунту
+	other, err := client.New(srv.URL, client.WithToken(device.Token()), client.WithRetries(0, 0))
+	require.NoError(t, err)
+	otherUser, err := other.Register(ctx, "other@example.com", "correct horse")
+	require.NoError(t, err)
+	assert.NotEqual(t, user.ID, otherUser.ID)
+	links, err = other.List(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, links)
+
+	_, err = other.Login(ctx, "user@example.com", "correct horse")
+	require.NoError(t, err)
+	links, err = other.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, links, 2)
+
+	_, err = other.Register(ctx, "short@example.com", "short")
+	assert.ErrorIs(t, err, client.ErrBadRequest)
+}
@@ -0,0 +1,80 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	ErrBadRequest    = errors.New("bad request")
+	ErrUnauthorized  = errors.New("unauthorized")
+	ErrForbidden     = errors.New("forbidden")
+	ErrNotFound      = errors.New("not found")
+	ErrDuplicate     = errors.New("url already shortened")
+	ErrEmailTaken    = errors.New("email already registered")
+	ErrGone          = errors.New("link deleted")
+	ErrPolicyBlocked = errors.New("destination is blocked by policy")
+	ErrRateLimited   = errors.New("rate limited")
+	ErrServer        = errors.New("server error")
+)
+
+// Error - ответ сервиса с кодом 4xx/5xx. Поля повторяют application/problem+json,
+// сравнивать удобнее через errors.Is с ErrNotFound и остальными.
+type Error struct {
+	StatusCode int    `json:"status"`
+	Code       string `json:"code"`
+	Title      string `json:"title"`
+	Detail     string `json:"detail"`
+	// Result - существующая короткая ссылка, если URL уже был сокращен.
+	Result string `json:"result"`
+}
+
+func (e *Error) Error() string {
+	msg := e.Title
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
+	}
+	if e.Detail != "" {
+		msg += ": " + e.Detail
+	}
+
+	return fmt.Sprintf("shortener: %d %s", e.StatusCode, msg)
+}
+
+func (e *Error) Unwrap() error {
+	switch {
+	case e.StatusCode == http.StatusBadRequest:
+		return ErrBadRequest
+	case e.StatusCode == http.StatusUnauthorized:
+		return ErrUnauthorized
+	case e.StatusCode == http.StatusForbidden:
+		return ErrForbidden
+	case e.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case e.StatusCode == http.StatusConflict && e.Code == codeEmailTaken:
+		return ErrEmailTaken
+	case e.StatusCode == http.StatusConflict:
+		return ErrDuplicate
+	case e.StatusCode == http.StatusGone:
+		return ErrGone
+	case e.StatusCode == http.StatusUnprocessableEntity:
+		return ErrPolicyBlocked
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case e.StatusCode >= http.StatusInternalServerError:
+		return ErrServer
+	default:
+		return nil
+	}
+}
+
+func newError(statusCode int, body []byte) *Error {
+	e := &Error{}
+	// тело не problem+json (прокси, балансировщик) - хватит и статуса
+	_ = json.Unmarshal(body, e)
+	e.StatusCode = statusCode
+
+	return e
+}